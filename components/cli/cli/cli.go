@@ -29,6 +29,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/manifoldco/promptui"
 
 	"cellery.io/cellery/components/cli/pkg/ballerina"
@@ -37,6 +38,7 @@ import (
 	"cellery.io/cellery/components/cli/pkg/registry"
 	"cellery.io/cellery/components/cli/pkg/registry/credentials"
 	cliRuntime "cellery.io/cellery/components/cli/pkg/runtime"
+	"cellery.io/cellery/components/cli/pkg/trust"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
@@ -44,10 +46,13 @@ import (
 type Cli interface {
 	Out() io.Writer
 	ExecuteTask(startMessage, errorMessage, successMessage string, function func() error) error
+	ExecuteTaskWithProgress(startMessage, errorMessage, successMessage string,
+		function func(progress func(current, total int64)) error) error
 	FileSystem() FileSystemManager
 	BalExecutor() ballerina.BalExecutor
 	KubeCli() kubernetes.KubeCli
 	Registry() registry.Registry
+	TrustStore() *trust.Store
 	OpenBrowser(url string) error
 	DockerCli() docker.Docker
 	CredManager() credentials.CredManager
@@ -68,13 +73,15 @@ type CelleryCli struct {
 	credManager       credentials.CredManager
 	credReader        credentials.CredReader
 	runtime           cliRuntime.Runtime
+	trustStore        *trust.Store
 }
 
 // NewCelleryCli returns a CelleryCli instance.
 func NewCelleryCli(opts ...func(*CelleryCli)) *CelleryCli {
 	cli := &CelleryCli{
-		kubecli: kubernetes.NewCelleryKubeCli(),
-		docker:  docker.NewCelleryDockerCli(),
+		kubecli:    kubernetes.NewCelleryKubeCli(),
+		docker:     docker.NewCelleryDockerCli(),
+		trustStore: trust.NewStore(nil),
 	}
 	for _, opt := range opts {
 		opt(cli)
@@ -82,12 +89,24 @@ func NewCelleryCli(opts ...func(*CelleryCli)) *CelleryCli {
 	return cli
 }
 
+func SetKubeCli(kubeCli kubernetes.KubeCli) func(*CelleryCli) {
+	return func(cli *CelleryCli) {
+		cli.kubecli = kubeCli
+	}
+}
+
 func SetRegistry(registry registry.Registry) func(*CelleryCli) {
 	return func(cli *CelleryCli) {
 		cli.registry = registry
 	}
 }
 
+func SetTrustStore(trustStore *trust.Store) func(*CelleryCli) {
+	return func(cli *CelleryCli) {
+		cli.trustStore = trustStore
+	}
+}
+
 func SetFileSystem(manager FileSystemManager) func(*CelleryCli) {
 	return func(cli *CelleryCli) {
 		cli.fileSystemManager = manager
@@ -122,8 +141,25 @@ func (cli *CelleryCli) Out() io.Writer {
 // Spinner exits with a success message (optional) if the function execution was successful.
 // Spinner exists with an error message (optional) if the function execution failed.
 func (cli *CelleryCli) ExecuteTask(startMessage, errorMessage, successMessage string, function func() error) error {
+	return cli.ExecuteTaskWithProgress(startMessage, errorMessage, successMessage,
+		func(progress func(current, total int64)) error {
+			return function()
+		})
+}
+
+// ExecuteTaskWithProgress executes a function which reports the number of bytes processed.
+// The progress is shown next to the spinner while the function is executing.
+func (cli *CelleryCli) ExecuteTaskWithProgress(startMessage, errorMessage, successMessage string,
+	function func(progress func(current, total int64)) error) error {
 	spinner := util.StartNewSpinner(startMessage)
-	err := function()
+	err := function(func(current, total int64) {
+		if total > 0 {
+			spinner.SetProgress(fmt.Sprintf("%s / %s (%d%%)", units.HumanSize(float64(current)),
+				units.HumanSize(float64(total)), current*100/total))
+		} else {
+			spinner.SetProgress(units.HumanSize(float64(current)))
+		}
+	})
 	if err != nil {
 		spinner.Stop(false)
 		if errorMessage != "" {
@@ -158,6 +194,11 @@ func (cli *CelleryCli) Registry() registry.Registry {
 	return cli.registry
 }
 
+// TrustStore returns the trust store used for signing and verifying cell images.
+func (cli *CelleryCli) TrustStore() *trust.Store {
+	return cli.trustStore
+}
+
 // FileSystem returns FileSystemManager instance.
 func (cli *CelleryCli) DockerCli() docker.Docker {
 	return cli.docker
@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/stack"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newApplyCommand(cli cli.Cli) *cobra.Command {
+	var file string
+	var insecureSkipVerify bool
+	cmd := &cobra.Command{
+		Use:   "apply -f <stack-file>",
+		Short: "Create or update the instances defined in a stack file",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+			if file == "" {
+				return fmt.Errorf("expects a stack file, provide the stack file using the --file flag")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := stack.RunApply(cli, file, insecureSkipVerify); err != nil {
+				util.ExitWithErrorMessage("Cellery apply command failed", err)
+			}
+		},
+		Example: "  cellery apply -f stack.yaml",
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Stack file defining the instances")
+	cmd.Flags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false,
+		"Skip verifying the signatures of the cell images")
+	return cmd
+}
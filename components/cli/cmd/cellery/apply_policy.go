@@ -32,6 +32,7 @@ func newApplyPolicyCommand(cli cli.Cli) *cobra.Command {
 
 	cmd.AddCommand(
 		newApplyAutoscalePolicyCommand(cli),
+		newApplyResiliencePolicyCommand(cli),
 	)
 	return cmd
 }
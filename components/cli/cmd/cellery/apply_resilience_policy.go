@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newApplyResiliencePolicyCommand(cli cli.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resilience <instance> <file>",
+		Short: "apply a resilience policy to the requests from an instance to its dependencies",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+			return validateInstanceName(args[0])
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := instance.RunApplyResiliencePolicy(cli, args[0], args[1])
+			if err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Unable to apply resilience policy to instance %s", args[0]), err)
+			}
+		},
+		Example: "  cellery apply-policy resilience hr-client-inst1 myresiliencepolicy.yaml",
+	}
+	return cmd
+}
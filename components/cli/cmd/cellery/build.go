@@ -35,6 +35,7 @@ import (
 
 // newBuildCommand creates a cobra command which can be invoked to build a cell image from a cell file
 func newBuildCommand(cli cli.Cli) *cobra.Command {
+	var insecureSkipVerify bool
 	cmd := &cobra.Command{
 		Use:   "build <cell-file-or-project>",
 		Short: "Build an immutable cell image with the required dependencies",
@@ -65,12 +66,14 @@ func newBuildCommand(cli cli.Cli) *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image2.RunBuild(cli, args[1], args[0]); err != nil {
+			if err := image2.RunBuild(cli, args[1], args[0], insecureSkipVerify); err != nil {
 				util.ExitWithErrorMessage("Cellery build command failed", err)
 			}
 		},
 		Example: "  cellery build employee.bal cellery-samples/employee:1.0.0\n" +
 			"  cellery build employee/ cellery-samples/employee:1.0.0",
 	}
+	cmd.Flags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false,
+		"Skip verifying the signatures of the dependency images pulled during the build")
 	return cmd
 }
@@ -30,10 +30,13 @@ import (
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/ballerina"
+	"cellery.io/cellery/components/cli/pkg/config"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
 	"cellery.io/cellery/components/cli/pkg/registry"
 	"cellery.io/cellery/components/cli/pkg/registry/credentials"
 	celleryRuntime "cellery.io/cellery/components/cli/pkg/runtime"
+	"cellery.io/cellery/components/cli/pkg/trust"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
@@ -72,10 +75,17 @@ func newCliCommand(cli cli.Cli) *cobra.Command {
 		newViewCommand(cli),
 		newTestCommand(cli),
 		newDeleteImageCommand(cli),
+		newImageCommand(cli),
+		newApplyCommand(cli),
 		newExportPolicyCommand(cli),
 		newApplyPolicyCommand(cli),
+		newValidateCommand(cli),
 		newPatchComponentsCommand(cli),
 		newRouteTrafficCommand(cli),
+		newRolloutCommand(cli),
+		newSwitchCommand(cli),
+		newScaleCommand(cli),
+		newInjectFaultCommand(cli),
 		newSetCommand(cli),
 		newDesignerCommand(cli),
 	)
@@ -85,6 +95,24 @@ func newCliCommand(cli cli.Cli) *cobra.Command {
 	return cmd
 }
 
+// newKubeCli returns the kubernetes client selected in the config. The kubectl client is used as the fallback
+// if the native client cannot be initialized.
+func newKubeCli(conf *config.Conf) kubernetes.KubeCli {
+	if conf.Kubernetes != nil && conf.Kubernetes.Client == config.KubeClientNative {
+		kubeConfig := conf.Kubernetes.KubeConfig
+		if kubeConfig == "" {
+			kubeConfig = kubernetes.KubeConfigPath()
+		}
+		nativeKubeCli, err := kubernetes.NewNativeKubeCli(kubeConfig)
+		if err == nil {
+			return nativeKubeCli
+		}
+		util.PrintWarningMessage(fmt.Sprintf("Failed to initialize native kubernetes client, "+
+			"falling back to kubectl: %v", err))
+	}
+	return kubernetes.NewCelleryKubeCli()
+}
+
 func main() {
 	fileSystem, err := cli.NewCelleryFileSystem()
 	if err != nil {
@@ -108,8 +136,11 @@ func main() {
 	}
 	credReader := credentials.NewCelleryCredReader()
 	runtime := celleryRuntime.NewCelleryRuntime()
+	conf := config.LoadConfig()
 	// Initialize the Cellery CLI.
 	celleryCli := cli.NewCelleryCli(
+		cli.SetKubeCli(newKubeCli(conf)),
+		cli.SetTrustStore(trust.NewStore(conf.Trust)),
 		cli.SetRegistry(registry.NewCelleryRegistry()),
 		cli.SetFileSystem(fileSystem),
 		cli.SetBallerinaExecutor(ballerinaExecutor),
@@ -23,16 +23,21 @@ import (
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/image"
+	"cellery.io/cellery/components/cli/pkg/commands/stack"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newDeleteImageCommand(cli cli.Cli) *cobra.Command {
 	var deleteAll = false
 	var regex = ""
+	var stackFile = ""
 	cmd := &cobra.Command{
 		Use:   "delete <cell-image(s)>",
-		Short: "Delete cell image(s) from repo",
+		Short: "Delete cell image(s) from repo or the instances of a stack",
 		Args: func(cmd *cobra.Command, args []string) error {
+			if stackFile != "" {
+				return cobra.NoArgs(cmd, args)
+			}
 			if !deleteAll && regex == "" {
 				err := cobra.MinimumNArgs(1)(cmd, args)
 				if err != nil {
@@ -42,6 +47,12 @@ func newDeleteImageCommand(cli cli.Cli) *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			if stackFile != "" {
+				if err := stack.RunDelete(cli, stackFile); err != nil {
+					util.ExitWithErrorMessage("Cellery delete command failed", err)
+				}
+				return
+			}
 			if err := image.RunDeleteImage(cli, args, regex, deleteAll); err != nil {
 				util.ExitWithErrorMessage("Cellery delete command failed", err)
 			}
@@ -49,9 +60,11 @@ func newDeleteImageCommand(cli cli.Cli) *cobra.Command {
 		Example: "  cellery delete cellery-samples/employee:1.0.0  my-org/hr:1.0.0\n" +
 			"  cellery delete cellery-samples/employee:1.0.0 --regex '.*/employee:.*'\n" +
 			"  cellery delete --all\n" +
-			"  cellery delete --regex .*/employee:.*\n",
+			"  cellery delete --regex .*/employee:.*\n" +
+			"  cellery delete -f stack.yaml\n",
 	}
 	cmd.Flags().BoolVar(&deleteAll, "all", false, "Delete all cell images")
 	cmd.Flags().StringVar(&regex, "regex", "", "Regular expression of cell images to be deleted")
+	cmd.Flags().StringVarP(&stackFile, "file", "f", "", "Stack file of which the instances are deleted")
 	return cmd
 }
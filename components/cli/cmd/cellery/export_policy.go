@@ -32,6 +32,7 @@ func newExportPolicyCommand(cli cli.Cli) *cobra.Command {
 
 	cmd.AddCommand(
 		newExportAutoscalePolicies(cli),
+		newExportResiliencePolicyCommand(cli),
 	)
 	return cmd
 }
@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newExportResiliencePolicyCommand(cli cli.Cli) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:   "resilience <instance_name>",
+		Short: "Export the resilience policy of the requests from an instance to its dependencies",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+			return validateInstanceName(args[0])
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := instance.RunExportResiliencePolicy(cli, args[0], file)
+			if err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Unable to export resilience policy from instance %s", args[0]), err)
+			}
+		},
+		Example: "  cellery export-policy resilience hr-client-inst1 -f myresiliencepolicy.yaml",
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "output file for resilience policy")
+	return cmd
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/image"
+	image2 "cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newImagePruneCommand(cli cli.Cli) *cobra.Command {
+	var olderThan, maxSize string
+	var keepLast int
+	var dryRun bool
+	policy := image2.PrunePolicy{}
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove unused cell images from the local repository",
+		Args: func(cmd *cobra.Command, args []string) error {
+			err := cobra.NoArgs(cmd, args)
+			if err != nil {
+				return err
+			}
+			if olderThan == "" && keepLast == 0 && maxSize == "" {
+				return fmt.Errorf("at least one of --older-than, --keep-last or --max-size is required")
+			}
+			if olderThan != "" {
+				if policy.OlderThan, err = parseAge(olderThan); err != nil {
+					return fmt.Errorf("invalid value for --older-than, %v", err)
+				}
+			}
+			if keepLast < 0 {
+				return fmt.Errorf("invalid value for --keep-last, expected a positive number")
+			}
+			policy.KeepLast = keepLast
+			if maxSize != "" {
+				if policy.MaxSize, err = units.FromHumanSize(maxSize); err != nil {
+					return fmt.Errorf("invalid value for --max-size, %v", err)
+				}
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := image.RunPrune(cli, policy, dryRun); err != nil {
+				util.ExitWithErrorMessage("Cellery image prune command failed", err)
+			}
+		},
+		Example: "  cellery image prune --older-than 30d\n" +
+			"  cellery image prune --keep-last 3\n" +
+			"  cellery image prune --max-size 2GB --dry-run",
+	}
+	cmd.Flags().StringVar(&olderThan, "older-than", "",
+		"Delete images which have not been used for the given duration, e.g. 72h or 30d")
+	cmd.Flags().IntVar(&keepLast, "keep-last", 0, "Keep only the given number of most recently used versions of each image")
+	cmd.Flags().StringVar(&maxSize, "max-size", "",
+		"Delete the least recently used images until the repository fits in the given size, e.g. 2GB")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the images which would be deleted without deleting them")
+	return cmd
+}
+
+// parseAge parses a duration which additionally accepts days, e.g. 30d.
+func parseAge(age string) (time.Duration, error) {
+	if strings.HasSuffix(age, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(age, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(age)
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newInjectFaultCommand(cli cli.Cli) *cobra.Command {
+	var clear bool
+	opts := instance.FaultOptions{}
+	cmd := &cobra.Command{
+		Use:   "inject-fault <source_instance_name> --dependency|-d <dependency_instance_name> [--delay <duration>] [--abort <http_status>]",
+		Short: "inject delays and aborts into the requests from a cell instance to a dependency",
+		Example: "cellery inject-fault hr-client-inst1 --dependency hr-inst-1 --delay 2s --percent 10 \n" +
+			"cellery inject-fault hr-client-inst1 --dependency hr-inst-1 --abort 503 --percent 5 --duration 10m \n" +
+			"cellery inject-fault hr-client-inst1 --clear",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+			if err := validateInstanceName(args[0]); err != nil {
+				return err
+			}
+			opts.Source = args[0]
+			if opts.Dependency != "" {
+				if err := validateInstanceName(opts.Dependency); err != nil {
+					return err
+				}
+			}
+			if clear {
+				return nil
+			}
+			if opts.Dependency == "" {
+				return fmt.Errorf("mandatory flag dependency/d not provided")
+			}
+			if opts.Delay <= 0 && opts.Abort == 0 {
+				return fmt.Errorf("expects either a delay or an abort")
+			}
+			if opts.Abort != 0 && (opts.Abort < http.StatusOK || opts.Abort > 599) {
+				return fmt.Errorf("expects a http status for --abort, received %d", opts.Abort)
+			}
+			if opts.Percent <= 0 || opts.Percent > 100 {
+				return fmt.Errorf("expects a percentage between 0 and 100 for --percent, received %v", opts.Percent)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if clear {
+				err = instance.RunClearFault(cli, opts.Source, opts.Dependency)
+			} else {
+				err = instance.RunInjectFault(cli, opts)
+			}
+			if err != nil {
+				util.ExitWithErrorMessage("Unable to inject faults", err)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&opts.Dependency, "dependency", "d", "", "dependency instance receiving the faulty requests")
+	cmd.Flags().DurationVar(&opts.Delay, "delay", 0, "delay added to the requests")
+	cmd.Flags().IntVar(&opts.Abort, "abort", 0, "http status returned for the aborted requests")
+	cmd.Flags().Float64Var(&opts.Percent, "percent", 100, "percentage of the requests affected by the faults")
+	cmd.Flags().DurationVar(&opts.Duration, "duration", 0,
+		"clear the faults after the duration, the faults are kept until cleared if not given")
+	cmd.Flags().BoolVar(&clear, "clear", false, "clear the injected faults")
+	return cmd
+}
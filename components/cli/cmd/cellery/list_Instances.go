@@ -23,21 +23,31 @@ import (
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newListInstancesCommand(cli cli.Cli) *cobra.Command {
+	var opts output.Options
 	cmd := &cobra.Command{
 		Use:     "instances",
 		Short:   "List all running cells",
 		Aliases: []string{"instance", "inst"},
-		Args:    cobra.NoArgs,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+			return opts.Validate()
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := instance.RunListInstances(cli); err != nil {
+			if err := instance.RunListInstances(cli, opts); err != nil {
 				util.ExitWithErrorMessage("Cellery list instances command failed", err)
 			}
 		},
-		Example: "  cellery list instances",
+		Example: "  cellery list instances\n" +
+			"  cellery list instances -o wide\n" +
+			"  cellery list instances --template '{{range .}}{{.name}} {{.status}}{{\"\\n\"}}{{end}}'",
 	}
+	addOutputFlags(cmd, &opts)
 	return cmd
 }
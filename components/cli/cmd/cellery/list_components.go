@@ -27,10 +27,12 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/image"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newListComponentsCommand(cli cli.Cli) *cobra.Command {
+	var opts output.Options
 	cmd := &cobra.Command{
 		Use:     "components <instance-name|cell-image-name>",
 		Short:   "List the components which the cell encapsulates",
@@ -40,6 +42,9 @@ func newListComponentsCommand(cli cli.Cli) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if err := opts.Validate(); err != nil {
+				return err
+			}
 			isCellValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
 			if err != nil || !isCellValid {
 				isCellImageValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CellImagePattern), args[0])
@@ -50,12 +55,13 @@ func newListComponentsCommand(cli cli.Cli) *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image.RunListComponents(cli, args[0]); err != nil {
+			if err := image.RunListComponents(cli, args[0], opts); err != nil {
 				util.ExitWithErrorMessage("Cellery list components command failed", err)
 			}
 		},
 		Example: "  cellery list components employee\n" +
 			"  cellery list components cellery-samples/employee:1.0.0",
 	}
+	addOutputFlags(cmd, &opts)
 	return cmd
 }
@@ -27,10 +27,12 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/instance"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newListDependenciesCommand(cli cli.Cli) *cobra.Command {
+	var opts output.Options
 	cmd := &cobra.Command{
 		Use:     "dependencies <instance-name>",
 		Aliases: []string{"dep"},
@@ -40,6 +42,9 @@ func newListDependenciesCommand(cli cli.Cli) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if err := opts.Validate(); err != nil {
+				return err
+			}
 			if isCellValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0]); err == nil {
 				if !isCellValid {
 					return fmt.Errorf("expects a valid cell instance name, received %s", args[0])
@@ -50,11 +55,12 @@ func newListDependenciesCommand(cli cli.Cli) *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := instance.RunListDependencies(cli, args[0]); err != nil {
+			if err := instance.RunListDependencies(cli, args[0], opts); err != nil {
 				util.ExitWithErrorMessage("Unable to list dependencies", err)
 			}
 		},
 		Example: "  cellery list dependencies mypetstoreportal",
 	}
+	addOutputFlags(cmd, &opts)
 	return cmd
 }
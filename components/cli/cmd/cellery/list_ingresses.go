@@ -27,11 +27,13 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/image"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 // newApisCommand creates a cobra command which can be invoked to get the APIs exposed by a cell
 func newListIngressesCommand(cli cli.Cli) *cobra.Command {
+	var opts output.Options
 	cmd := &cobra.Command{
 		Use:     "ingresses <instance-name|cell-image-name>",
 		Aliases: []string{"ingress", "ing"},
@@ -41,6 +43,9 @@ func newListIngressesCommand(cli cli.Cli) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if err := opts.Validate(); err != nil {
+				return err
+			}
 			isCellValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
 			if err != nil || !isCellValid {
 				isCellImageValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CellImagePattern), args[0])
@@ -51,12 +56,13 @@ func newListIngressesCommand(cli cli.Cli) *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image.RunListIngresses(cli, args[0]); err != nil {
+			if err := image.RunListIngresses(cli, args[0], opts); err != nil {
 				util.ExitWithErrorMessage("Cellery list ingresses command failed", err)
 			}
 		},
 		Example: "  cellery list ingresses employee\n" +
 			"  cellery list ingresses cellery-samples/employee:1.0.0\n",
 	}
+	addOutputFlags(cmd, &opts)
 	return cmd
 }
@@ -31,39 +31,58 @@ import (
 )
 
 func newLogsCommand(cli cli.Cli) *cobra.Command {
-	var component string
-	var syslog bool
-	var follow bool
+	opts := instance.LogsOptions{}
 	cmd := &cobra.Command{
-		Use:   "logs <instance-name>",
-		Short: "Displays logs for either the cell instance, or a component of a running cell instance.",
+		Use:   "logs [<instance-name>...]",
+		Short: "Displays logs for either the cell instances, or a component of running cell instances.",
 		Args: func(cmd *cobra.Command, args []string) error {
-			err := cobra.ExactArgs(1)(cmd, args)
-			if err != nil {
-				return err
+			if len(args) == 0 && opts.Selector == "" {
+				return fmt.Errorf("expects at least one instance name or a label selector")
 			}
-			isCellValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
-			if err != nil || !isCellValid {
-				return fmt.Errorf("expects a valid cell name, received %s", args[0])
+			for _, arg := range args {
+				isCellValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), arg)
+				if err != nil || !isCellValid {
+					return fmt.Errorf("expects a valid cell name, received %s", arg)
+				}
 			}
-			if component != "" {
-				isComponentValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), component)
+			if opts.Component != "" {
+				isComponentValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern),
+					opts.Component)
 				if err != nil || !isComponentValid {
-					return fmt.Errorf("expects a valid component name, received %s", args[0])
+					return fmt.Errorf("expects a valid component name, received %s", opts.Component)
 				}
 			}
+			if opts.Since < 0 {
+				return fmt.Errorf("expects a positive duration for --since, received %s", opts.Since)
+			}
+			if opts.Tail < -1 {
+				return fmt.Errorf("expects a positive number of lines for --tail, received %d", opts.Tail)
+			}
+			if _, err := regexp.Compile(opts.Grep); err != nil {
+				return fmt.Errorf("expects a valid regular expression for --grep, %v", err)
+			}
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := instance.RunLogs(cli, args[0], component, syslog, follow); err != nil {
+			if err := instance.RunLogs(cli, args, opts); err != nil {
 				util.ExitWithErrorMessage("Cellery logs command failed", err)
 			}
 		},
 		Example: "  cellery logs employee\n" +
-			"  cellery logs employee -c salary",
+			"  cellery logs employee -c salary\n" +
+			"  cellery logs employee stock hr -f --since 10m\n" +
+			"  cellery logs -l stack.cellery.io/name=hr-stack --tail 100\n" +
+			"  cellery logs employee --grep \"(?i)error\" --field level --field msg",
 	}
-	cmd.Flags().StringVarP(&component, "component", "c", "", "component of the cell")
-	cmd.Flags().BoolVarP(&syslog, "syslog", "s", false, "view system logs")
-	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "follow logs")
+	cmd.Flags().StringVarP(&opts.Component, "component", "c", "", "component of the cell")
+	cmd.Flags().BoolVarP(&opts.SysLog, "syslog", "s", false, "view system logs")
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "follow logs")
+	cmd.Flags().StringVarP(&opts.Selector, "selector", "l", "",
+		"label selector of the instances of which the logs are displayed")
+	cmd.Flags().DurationVar(&opts.Since, "since", 0, "only display logs newer than a relative duration like 5s or 2m")
+	cmd.Flags().IntVar(&opts.Tail, "tail", -1, "number of recent lines to display of each container")
+	cmd.Flags().StringVar(&opts.Grep, "grep", "", "only display lines matching the regular expression")
+	cmd.Flags().StringSliceVar(&opts.Fields, "field", nil,
+		"fields to extract from JSON log lines, nested fields are separated by dots")
 	return cmd
 }
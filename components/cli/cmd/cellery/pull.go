@@ -33,6 +33,7 @@ func newPullCommand(cli cli.Cli) *cobra.Command {
 	var username string
 	var password string
 	var isSilent bool
+	var insecureSkipVerify bool
 	cmd := &cobra.Command{
 		Use:   "pull [<registry>/]<organization>/<cell-image>:<version>",
 		Short: "Pull cell image from the remote repository",
@@ -51,7 +52,7 @@ func newPullCommand(cli cli.Cli) *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image2.RunPull(cli, args[0], isSilent, username, password); err != nil {
+			if err := image2.RunPull(cli, args[0], isSilent, insecureSkipVerify, username, password); err != nil {
 				util.ExitWithErrorMessage("Cellery pull command failed", err)
 			}
 		},
@@ -61,5 +62,7 @@ func newPullCommand(cli cli.Cli) *cobra.Command {
 	cmd.Flags().StringVarP(&username, "username", "u", "", "Username for Cellery Registry")
 	cmd.Flags().StringVarP(&password, "password", "p", "", "Password for Cellery Registry")
 	cmd.Flags().BoolVarP(&isSilent, "silent", "s", false, "Pull image silently")
+	cmd.Flags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false,
+		"Skip verifying the signature of the cell image")
 	return cmd
 }
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newRolloutCommand(cli cli.Cli) *cobra.Command {
+	var sourceInstance string
+	var steps string
+	opts := instance.RolloutOptions{}
+	cmd := &cobra.Command{
+		Use:   "rollout [--source|-s=<list_of_source_cell_instances>] --dependency|-d <dependency_instance_name> --target|-t <target instance name> [--steps <x,y,z>]",
+		Short: "progressively route traffic to a cell instance, reverting if the target is unhealthy",
+		Example: "cellery rollout --dependency hr-inst-1 --target hr-inst-2 \n" +
+			"cellery rollout --dependency hr-inst-1 --target hr-inst-2 --steps 5,25,50,100 --pause 10m \n" +
+			"cellery rollout --source hr-client-inst1 --dependency hr-inst-1 --target hr-inst-2 " +
+			"--metrics-endpoint http://prometheus:9090 --max-error-rate 0.01 --max-latency 500ms",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := validateArguments(opts.Dependency, opts.Target); err != nil {
+				return err
+			}
+			opts.Sources = getSourceCellInstanceArr(sourceInstance)
+			for _, srcInstance := range opts.Sources {
+				if err := validateInstanceName(srcInstance); err != nil {
+					return err
+				}
+			}
+			if err := validateInstanceName(opts.Target); err != nil {
+				return err
+			}
+			var err error
+			if opts.Steps, err = parseRolloutSteps(steps); err != nil {
+				return err
+			}
+			if opts.Pause < 0 {
+				return fmt.Errorf("expects a positive duration for --pause, received %s", opts.Pause)
+			}
+			if opts.MaxErrorRate > 1 {
+				return fmt.Errorf("expects a ratio between 0 and 1 for --max-error-rate, received %v",
+					opts.MaxErrorRate)
+			}
+			if opts.MetricsEndpoint == "" && (cmd.Flags().Changed("max-error-rate") ||
+				cmd.Flags().Changed("max-latency")) {
+				return fmt.Errorf("thresholds require a metrics endpoint, provide one with --metrics-endpoint")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunRollout(cli, opts); err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Unable to roll out the target instance: %s", opts.Target),
+					err)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&sourceInstance, "source", "s", "", "comma separated source instance list")
+	cmd.Flags().StringVarP(&opts.Dependency, "dependency", "d", "", "existing dependency instance name")
+	cmd.Flags().StringVarP(&opts.Target, "target", "t", "", "target instance to which the traffic should be re-routed")
+	cmd.Flags().StringVar(&steps, "steps", "5,25,50,100", "comma separated percentages of traffic to route to the target")
+	cmd.Flags().DurationVar(&opts.Pause, "pause", 5*time.Minute, "time to wait after each step before analysing the target")
+	cmd.Flags().BoolVarP(&opts.SessionAware, "enable-session-awareness", "a", false,
+		"flag to enable session awareness based on user name")
+	cmd.Flags().BoolVarP(&opts.AssumeYes, "assume-yes", "y", false, "flag to assume yes for user confirmations")
+	cmd.Flags().StringVar(&opts.MetricsEndpoint, "metrics-endpoint", "",
+		"Prometheus compatible endpoint to query the metrics of the target from")
+	cmd.Flags().Float64Var(&opts.MaxErrorRate, "max-error-rate", 0.05,
+		"highest ratio of failed requests to the target, a negative value disables the check")
+	cmd.Flags().DurationVar(&opts.MaxLatency, "max-latency", 0, "highest 99th percentile latency of the target")
+	cmd.Flags().StringVar(&opts.ErrorRateQuery, "error-rate-query", instance.DefaultErrorRateQuery,
+		"query for the error rate of the target, {{.Target}}, {{.Dependency}} and {{.Window}} are replaced")
+	cmd.Flags().StringVar(&opts.LatencyQuery, "latency-query", instance.DefaultLatencyQuery,
+		"query for the latency of the target in seconds, {{.Target}}, {{.Dependency}} and {{.Window}} are replaced")
+	return cmd
+}
+
+// parseRolloutSteps parses a comma separated list of increasing percentages.
+func parseRolloutSteps(steps string) ([]int, error) {
+	var percentages []int
+	for _, step := range strings.Split(steps, ",") {
+		percentage, err := strconv.Atoi(strings.TrimSpace(step))
+		if err != nil || percentage < 1 || percentage > 100 {
+			return nil, fmt.Errorf("invalid rollout step %s, expects a percentage between 1 and 100", step)
+		}
+		if len(percentages) > 0 && percentage <= percentages[len(percentages)-1] {
+			return nil, fmt.Errorf("invalid rollout steps %s, expects increasing percentages", steps)
+		}
+		percentages = append(percentages, percentage)
+	}
+	return percentages, nil
+}
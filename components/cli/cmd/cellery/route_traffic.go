@@ -29,6 +29,8 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/instance"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
@@ -41,13 +43,25 @@ func newRouteTrafficCommand(cli cli.Cli) *cobra.Command {
 	var srcInstances []string
 	var enableSessionAwareness bool
 	var assumeYes bool
+	var dryRun bool
+	var outputDir string
+	var matchExpressions []string
+	var matches []kubernetes.HTTPMatch
+	var mirror bool
+	var stopMirroring bool
 	cmd := &cobra.Command{
 		Use:   "route-traffic [--source|-s=<list_of_source_cell_instances>] --dependency|-d <dependency_instance_name> --target|-t <target instance name> [--percentage|-p <x>]",
 		Short: "route a percentage of the traffic to a cell instance",
 		Example: "cellery route-traffic --source hr-client-inst1 --dependency hr-inst-1 --target hr-inst-2 --percentage 20 \n" +
 			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --percentage 20 \n" +
 			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 \n" +
-			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --percentage 25 --enable-session-awareness",
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --percentage 25 --enable-session-awareness \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --percentage 20 --dry-run \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --dry-run --output-dir ./routing \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --match header:x-tenant=beta \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --match 'cookie:canary=true&uri^=/api' \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --mirror --percentage 50 \n" +
+			"cellery route-traffic --dependency hr-inst-1 --target hr-inst-2 --stop-mirroring",
 		Args: func(cmd *cobra.Command, args []string) error {
 			// validate
 			err := validateArguments(dependencyInstance, targetInstance)
@@ -73,10 +87,25 @@ func newRouteTrafficCommand(cli cli.Cli) *cobra.Command {
 			if err != nil {
 				util.ExitWithErrorMessage("Error in running route traffic command", err)
 			}
+			matches, err = getMatches(matchExpressions, enableSessionAwareness)
+			if err != nil {
+				util.ExitWithErrorMessage("Error in running route traffic command", err)
+			}
+			if err = validateMirroring(mirror, stopMirroring, len(matches) > 0, enableSessionAwareness); err != nil {
+				util.ExitWithErrorMessage("Error in running route traffic command", err)
+			}
+			// stopping mirroring is mirroring none of the requests
+			if stopMirroring {
+				mirror = true
+				percentage = 0
+			}
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			err := instance.RunRouteTrafficCommand(cli, srcInstances, dependencyInstance, targetInstance, percentage, enableSessionAwareness, assumeYes)
+			err := instance.RunRouteTrafficCommand(cli, srcInstances, dependencyInstance, targetInstance,
+				routing.RouteOptions{Percentage: percentage, SessionAware: enableSessionAwareness, Matches: matches,
+					Mirror: mirror},
+				assumeYes, dryRun, outputDir)
 			if err != nil {
 				util.ExitWithErrorMessage(fmt.Sprintf("Unable to route traffic to the target instance: %s, percentage: %d", targetInstance, percentage), err)
 			}
@@ -88,9 +117,52 @@ func newRouteTrafficCommand(cli cli.Cli) *cobra.Command {
 	cmd.Flags().StringVarP(&targetPercentage, "percentage", "p", "", "percentage to be switched to the target instance")
 	cmd.Flags().BoolVarP(&enableSessionAwareness, "enable-session-awareness", "a", false, "flag to enable session awareness based on user name")
 	cmd.Flags().BoolVarP(&assumeYes, "assume-yes", "y", false, "flag to assume yes for user confirmations")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes to the live routing rules without applying them")
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "directory to keep the generated routing artifacts in")
+	cmd.Flags().StringArrayVarP(&matchExpressions, "match", "m", []string{},
+		"route only the requests matching the expression, e.g. header:x-tenant=beta (can be repeated)")
+	cmd.Flags().BoolVar(&mirror, "mirror", false,
+		"mirror the percentage of traffic to the target instance, discarding its responses")
+	cmd.Flags().BoolVar(&stopMirroring, "stop-mirroring", false, "stop mirroring traffic to the target instance")
+	cmd.AddCommand(
+		newRouteTrafficHistoryCommand(cli),
+		newRouteTrafficRollbackCommand(cli),
+		newRouteTrafficApplyCommand(cli),
+	)
 	return cmd
 }
 
+func getMatches(matchExpressions []string, enableSessionAwareness bool) ([]kubernetes.HTTPMatch, error) {
+	if len(matchExpressions) > 0 && enableSessionAwareness {
+		return nil, fmt.Errorf("flag match/m cannot be used with enable-session-awareness/a")
+	}
+	var matches []kubernetes.HTTPMatch
+	for _, expression := range matchExpressions {
+		match, err := routing.ParseMatch(expression)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+func validateMirroring(mirror bool, stopMirroring bool, hasMatches bool, enableSessionAwareness bool) error {
+	if !mirror && !stopMirroring {
+		return nil
+	}
+	if mirror && stopMirroring {
+		return fmt.Errorf("flags mirror and stop-mirroring cannot be used together")
+	}
+	if hasMatches {
+		return fmt.Errorf("flag match/m cannot be used when mirroring traffic")
+	}
+	if enableSessionAwareness {
+		return fmt.Errorf("flag enable-session-awareness/a cannot be used when mirroring traffic")
+	}
+	return nil
+}
+
 func getSourceCellInstanceArr(sourceCellInstances string) []string {
 	var trimmedInstances []string
 	if len(sourceCellInstances) == 0 {
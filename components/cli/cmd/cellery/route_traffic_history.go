@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newRouteTrafficHistoryCommand(cli cli.Cli) *cobra.Command {
+	var opts output.Options
+	cmd := &cobra.Command{
+		Use:   "history [<instance-name>]",
+		Short: "list the changes made to the routing of traffic",
+		Example: "cellery route-traffic history \n" +
+			"cellery route-traffic history hr-inst-1",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				if err := validateInstanceName(args[0]); err != nil {
+					return err
+				}
+			}
+			return opts.Validate()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var instanceName string
+			if len(args) > 0 {
+				instanceName = args[0]
+			}
+			if err := instance.RunRouteTrafficHistory(cli, instanceName, opts); err != nil {
+				util.ExitWithErrorMessage("Unable to list routing history", err)
+			}
+		},
+	}
+	addOutputFlags(cmd, &opts)
+	return cmd
+}
+
+func newRouteTrafficRollbackCommand(cli cli.Cli) *cobra.Command {
+	var toRevision int
+	var assumeYes bool
+	cmd := &cobra.Command{
+		Use:   "rollback [--to-revision <revision>]",
+		Short: "restore the routing of traffic as it was at a previous revision",
+		Example: "cellery route-traffic rollback \n" +
+			"cellery route-traffic rollback --to-revision 3",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunRouteTrafficRollback(cli, toRevision, assumeYes); err != nil {
+				util.ExitWithErrorMessage("Unable to roll back routing", err)
+			}
+		},
+	}
+	cmd.Flags().IntVar(&toRevision, "to-revision", -1, "revision to roll back to, defaults to the previous revision")
+	cmd.Flags().BoolVarP(&assumeYes, "assume-yes", "y", false, "flag to assume yes for user confirmations")
+	return cmd
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newRouteTrafficApplyCommand(cli cli.Cli) *cobra.Command {
+	var assumeYes bool
+	var dryRun bool
+	var outputDir string
+	cmd := &cobra.Command{
+		Use:   "apply <routing-plan-file>",
+		Short: "route the traffic of several dependencies in a routing plan at once",
+		Example: "cellery route-traffic apply upgrade-plan.yaml \n" +
+			"cellery route-traffic apply upgrade-plan.yaml --dry-run",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunApplyRoutingPlan(cli, args[0], assumeYes, dryRun, outputDir); err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Unable to apply routing plan %s", args[0]), err)
+			}
+		},
+	}
+	cmd.Flags().BoolVarP(&assumeYes, "assume-yes", "y", false, "flag to assume yes for user confirmations")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes to the live routing rules without applying them")
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "directory to keep the generated routing artifacts in")
+	return cmd
+}
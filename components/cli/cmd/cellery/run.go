@@ -37,6 +37,7 @@ func newRunCommand(cli cli.Cli) *cobra.Command {
 	var shareAllInstances bool
 	var dependencyLinks []string
 	var envVars []string
+	var insecureSkipVerify bool
 	cmd := &cobra.Command{
 		Use:   "run [<registry>/]<organization>/<cell-image>:<version>",
 		Short: "Use a cell image to create a running instance",
@@ -74,7 +75,8 @@ func newRunCommand(cli cli.Cli) *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := image2.RunRun(cli, args[0], name, startDependencies, shareAllInstances, dependencyLinks, envVars); err != nil {
+			if err := image2.RunRun(cli, args[0], name, startDependencies, shareAllInstances, dependencyLinks, envVars,
+				insecureSkipVerify); err != nil {
 				util.ExitWithErrorMessage("Cellery run command failed", err)
 			}
 		},
@@ -98,5 +100,7 @@ func newRunCommand(cli cli.Cli) *cobra.Command {
 		"Link an instance with a dependency alias")
 	cmd.Flags().StringArrayVarP(&envVars, "env", "e", []string{},
 		"Set an environment variable for the cellery run method in the Cell file")
+	cmd.Flags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false,
+		"Skip verifying the signature of the cell image")
 	return cmd
 }
@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newScaleCommand(cli cli.Cli) *cobra.Command {
+	opts := instance.ScaleOptions{}
+	var replicas, minReplicas, maxReplicas int
+	cmd := &cobra.Command{
+		Use: "scale <instance_name> [--component <component_name> | --gateway] " +
+			"(--replicas <count> | --min <count> --max <count>)",
+		Short: "scale the components or the gateway of a cell instance",
+		Example: "cellery scale employee --component job --replicas 3 \n" +
+			"cellery scale employee --gateway --min 2 --max 5 \n" +
+			"cellery scale hr-composite --max 10 --timeout 10m",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+			if err := validateInstanceName(args[0]); err != nil {
+				return err
+			}
+			opts.Instance = args[0]
+			if opts.Gateway && opts.Component != "" {
+				return fmt.Errorf("--component and --gateway cannot be used together")
+			}
+			autoscaled := cmd.Flags().Changed("min") || cmd.Flags().Changed("max")
+			if cmd.Flags().Changed("replicas") == autoscaled {
+				return fmt.Errorf("expects either --replicas, or --min and/or --max")
+			}
+			if cmd.Flags().Changed("replicas") {
+				if replicas < 0 {
+					return fmt.Errorf("expects a non negative number of --replicas, received %d", replicas)
+				}
+				opts.Replicas = &replicas
+			}
+			if cmd.Flags().Changed("min") {
+				if minReplicas < 0 {
+					return fmt.Errorf("expects a non negative number for --min, received %d", minReplicas)
+				}
+				opts.MinReplicas = &minReplicas
+			}
+			if cmd.Flags().Changed("max") {
+				if maxReplicas < 1 {
+					return fmt.Errorf("expects a positive number for --max, received %d", maxReplicas)
+				}
+				opts.MaxReplicas = &maxReplicas
+			}
+			if opts.Timeout < 0 {
+				return fmt.Errorf("expects a positive duration for --timeout, received %s", opts.Timeout)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunScale(cli, opts); err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Unable to scale instance %s", opts.Instance), err)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Component, "component", "",
+		"component to scale, all the components are scaled if neither a component nor the gateway is given")
+	cmd.Flags().BoolVar(&opts.Gateway, "gateway", false, "scale the gateway of the cell instance")
+	cmd.Flags().IntVar(&replicas, "replicas", 0, "number of replicas of a target which is not autoscaled")
+	cmd.Flags().IntVar(&minReplicas, "min", 0, "min replicas of an autoscaled target")
+	cmd.Flags().IntVar(&maxReplicas, "max", 0, "max replicas of an autoscaled target")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 5*time.Minute,
+		"time to wait for the replicas to be ready, 0 to not wait")
+	return cmd
+}
@@ -32,6 +32,7 @@ func newSetCommand(cli cli.Cli) *cobra.Command {
 
 	cmd.AddCommand(
 		newSetNamespaceCommand(cli),
+		newSetResilienceCommand(cli),
 	)
 	return cmd
 }
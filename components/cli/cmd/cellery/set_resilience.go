@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newSetResilienceCommand(cli cli.Cli) *cobra.Command {
+	var source string
+	opts := instance.ResilienceOptions{}
+	cmd := &cobra.Command{
+		Use:   "resilience <source_instance_name> --dependency|-d <dependency_instance_name>",
+		Short: "Set the timeout, retries and circuit breaking of the requests from an instance to a dependency",
+		Example: "  cellery set resilience hr-client-inst1 --dependency hr-inst-1 --timeout 5s --retries 3 " +
+			"--per-try-timeout 2s \n" +
+			"  cellery set resilience hr-client-inst1 --dependency hr-inst-1 --consecutive-errors 5 --interval 10s " +
+			"--base-ejection-time 30s --max-ejection-percent 50",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+			if err := validateInstanceName(args[0]); err != nil {
+				return err
+			}
+			source = args[0]
+			if opts.Dependency == "" {
+				return fmt.Errorf("mandatory flag dependency/d not provided")
+			}
+			if err := validateInstanceName(opts.Dependency); err != nil {
+				return err
+			}
+			if opts.Retries < 0 || opts.ConsecutiveErrors < 0 {
+				return fmt.Errorf("expects positive values for --retries and --consecutive-errors")
+			}
+			if opts.MaxEjectionPercent < 0 || opts.MaxEjectionPercent > 100 {
+				return fmt.Errorf("expects a percentage between 0 and 100 for --max-ejection-percent, received %d",
+					opts.MaxEjectionPercent)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunSetResilience(cli, source, opts); err != nil {
+				util.ExitWithErrorMessage("Unable to set resilience", err)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&opts.Dependency, "dependency", "d", "", "dependency instance receiving the requests")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 0, "timeout of the requests")
+	cmd.Flags().IntVar(&opts.Retries, "retries", 0, "number of retries of the failed requests")
+	cmd.Flags().DurationVar(&opts.PerTryTimeout, "per-try-timeout", 0, "timeout of each retry")
+	cmd.Flags().StringVar(&opts.RetryOn, "retry-on", "", "comma separated conditions on which the requests are retried, "+
+		"e.g. 5xx,connect-failure")
+	cmd.Flags().IntVar(&opts.ConsecutiveErrors, "consecutive-errors", 0,
+		"consecutive errors after which a host of the dependency is ejected")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", 0, "interval between the ejection analyses")
+	cmd.Flags().DurationVar(&opts.BaseEjectionTime, "base-ejection-time", 0, "minimum ejection duration of a host")
+	cmd.Flags().IntVar(&opts.MaxEjectionPercent, "max-ejection-percent", 0,
+		"maximum percentage of the hosts of the dependency that can be ejected")
+	return cmd
+}
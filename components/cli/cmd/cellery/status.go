@@ -21,16 +21,21 @@ package main
 import (
 	"fmt"
 	"regexp"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/commands/instance"
 	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func newStatusCommand(cli cli.Cli) *cobra.Command {
+	var opts output.Options
+	var watch bool
+	var interval, timeout time.Duration
 	cmd := &cobra.Command{
 		Use:   "status <instance-name>",
 		Short: "Performs a health check of a cell.",
@@ -39,6 +44,18 @@ func newStatusCommand(cli cli.Cli) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			if watch && (opts.Structured() || opts.Wide()) {
+				return fmt.Errorf("--watch cannot be used with --output or --template")
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval should be a positive duration, received %s", interval)
+			}
+			if timeout > 0 && !watch {
+				return fmt.Errorf("--timeout can only be used with --watch")
+			}
 			isCellValid, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), args[0])
 			if err != nil || !isCellValid {
 				return fmt.Errorf("expects a valid cell name, received %s", args[0])
@@ -46,11 +63,25 @@ func newStatusCommand(cli cli.Cli) *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := instance.RunStatus(cli, args[0]); err != nil {
+			if watch {
+				if err := instance.RunStatusWatch(cli, args[0], interval, timeout); err != nil {
+					util.ExitWithErrorMessage("Cellery status command failed", err)
+				}
+				return
+			}
+			if err := instance.RunStatus(cli, args[0], opts); err != nil {
 				util.ExitWithErrorMessage("Cellery status command failed", err)
 			}
 		},
-		Example: "  cellery status employee",
+		Example: "  cellery status employee\n" +
+			"  cellery status employee -o yaml\n" +
+			"  cellery status employee --watch\n" +
+			"  cellery status employee --watch --timeout 5m",
 	}
+	addOutputFlags(cmd, &opts)
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Refresh the status of the instance until interrupted")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Refresh interval of the watch")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0,
+		"Stop watching once the instance is Ready and fail if it is not Ready within the given duration")
 	return cmd
 }
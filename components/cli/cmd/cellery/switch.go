@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newSwitchCommand(cli cli.Cli) *cobra.Command {
+	opts := instance.SwitchOptions{}
+	cmd := &cobra.Command{
+		Use:   "switch <dependency_instance_name> <target_instance_name> [--terminate-old --metrics-endpoint <url>]",
+		Short: "route all traffic from a cell instance to a new instance, optionally terminating the old instance",
+		Example: "cellery switch hr-inst-1 hr-inst-2 \n" +
+			"cellery switch hr-inst-1 hr-inst-2 --drain 60s --terminate-old --metrics-endpoint http://prometheus:9090",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+			for _, instanceName := range args {
+				if err := validateInstanceName(instanceName); err != nil {
+					return err
+				}
+			}
+			if args[0] == args[1] {
+				return fmt.Errorf("expects different dependency and target instances, received %s", args[0])
+			}
+			opts.Dependency = args[0]
+			opts.Target = args[1]
+			if opts.Drain < 0 {
+				return fmt.Errorf("expects a positive duration for --drain, received %s", opts.Drain)
+			}
+			if !opts.TerminateOld && (cmd.Flags().Changed("drain") || cmd.Flags().Changed("metrics-endpoint")) {
+				return fmt.Errorf("--drain and --metrics-endpoint can only be used with --terminate-old")
+			}
+			if opts.TerminateOld && opts.MetricsEndpoint == "" {
+				return fmt.Errorf("--terminate-old requires --metrics-endpoint to check that the requests to the " +
+					"old instance are drained")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunSwitch(cli, opts); err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Unable to switch to instance %s", opts.Target), err)
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&opts.Drain, "drain", 30*time.Second,
+		"time to wait for the in-flight requests to the old instance to complete before terminating it")
+	cmd.Flags().BoolVar(&opts.TerminateOld, "terminate-old", false,
+		"terminate the old instance once its requests are drained and no instance depends on it")
+	cmd.Flags().BoolVarP(&opts.AssumeYes, "assume-yes", "y", false, "flag to assume yes for user confirmations")
+	cmd.Flags().StringVar(&opts.MetricsEndpoint, "metrics-endpoint", "",
+		"Prometheus compatible endpoint to check whether the old instance still receives requests")
+	cmd.Flags().StringVar(&opts.DrainQuery, "drain-query", instance.DefaultDrainQuery,
+		"query for the request rate of the old instance, {{.Dependency}}, {{.Target}} and {{.Window}} are replaced")
+	return cmd
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+func newValidateCommand(cli cli.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate <command>",
+		Short: "validate files before applying them",
+	}
+	cmd.AddCommand(
+		newValidatePolicyCommand(cli),
+	)
+	return cmd
+}
+
+func newValidatePolicyCommand(cli cli.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy <command>",
+		Short: "validate policy files against a cell/composite instance",
+	}
+	cmd.AddCommand(
+		newValidateAutoscalePolicyCommand(cli),
+	)
+	return cmd
+}
+
+func newValidateAutoscalePolicyCommand(cli cli.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "autoscale <command>",
+		Short: "validate autoscale policy files against a cell/composite instance",
+	}
+	cmd.AddCommand(
+		newValidateInstanceAutoscalePolicyCommand(cli, "cell", kubernetes.InstanceKindCell),
+		newValidateInstanceAutoscalePolicyCommand(cli, "composite", kubernetes.InstanceKindComposite),
+	)
+	return cmd
+}
+
+func newValidateInstanceAutoscalePolicyCommand(cli cli.Cli, kindName string,
+	kind kubernetes.InstanceKind) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s <instance> <file>", kindName),
+		Short: fmt.Sprintf("validate an autoscale policy file against a %s instance", kindName),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+				return err
+			}
+			if err := validateInstanceName(args[0]); err != nil {
+				return err
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := instance.RunValidateAutoscalePolicy(cli, kind, args[0], args[1]); err != nil {
+				util.ExitWithErrorMessage(fmt.Sprintf("Invalid autoscale policy file %s", args[1]), err)
+			}
+		},
+		Example: fmt.Sprintf("  cellery validate policy autoscale %s myinstance myscalepolicy.yaml", kindName),
+	}
+	return cmd
+}
@@ -30,6 +30,7 @@ import (
 	"cellery.io/cellery/components/cli/pkg/registry"
 	"cellery.io/cellery/components/cli/pkg/registry/credentials"
 	"cellery.io/cellery/components/cli/pkg/runtime"
+	"cellery.io/cellery/components/cli/pkg/trust"
 )
 
 type MockCli struct {
@@ -43,6 +44,7 @@ type MockCli struct {
 	credManager       credentials.CredManager
 	credReader        credentials.CredReader
 	runtime           runtime.Runtime
+	trustStore        *trust.Store
 	actionItem        int
 	selection         cli.Selection
 }
@@ -51,8 +53,9 @@ type MockCli struct {
 func NewMockCli(opts ...func(*MockCli)) *MockCli {
 	outBuffer := new(bytes.Buffer)
 	mockCli := &MockCli{
-		out:       outBuffer,
-		outBuffer: outBuffer,
+		out:        outBuffer,
+		outBuffer:  outBuffer,
+		trustStore: trust.NewStore(nil),
 	}
 	for _, opt := range opts {
 		opt(mockCli)
@@ -78,6 +81,12 @@ func SetFileSystem(manager cli.FileSystemManager) func(*MockCli) {
 	}
 }
 
+func SetTrustStore(trustStore *trust.Store) func(*MockCli) {
+	return func(cli *MockCli) {
+		cli.trustStore = trustStore
+	}
+}
+
 func SetDockerCli(docker docker.Docker) func(*MockCli) {
 	return func(cli *MockCli) {
 		cli.docker = docker
@@ -127,6 +136,12 @@ func (cli *MockCli) ExecuteTask(startMessage, errorMessage, successMessage strin
 	return nil
 }
 
+// ExecuteTaskWithProgress mocks execution of a function which reports progress.
+func (cli *MockCli) ExecuteTaskWithProgress(startMessage, errorMessage, successMessage string,
+	function func(progress func(current, total int64)) error) error {
+	return function(func(current, total int64) {})
+}
+
 // FileSystem returns a mock FileSystemManager instance.
 func (cli *MockCli) FileSystem() cli.FileSystemManager {
 	return cli.manager
@@ -147,6 +162,11 @@ func (cli *MockCli) Registry() registry.Registry {
 	return cli.registry
 }
 
+// TrustStore returns the trust store set to the mock cli.
+func (cli *MockCli) TrustStore() *trust.Store {
+	return cli.trustStore
+}
+
 // OpenBrowser mocks opening up of the provided URL in a browser
 func (cli *MockCli) OpenBrowser(url string) error {
 	return nil
@@ -21,6 +21,11 @@ package test
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+
+	"github.com/ghodss/yaml"
 
 	errorpkg "cellery.io/cellery/components/cli/pkg/error"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
@@ -28,6 +33,11 @@ import (
 
 const celleryInstance = "cells.mesh.cellery.io"
 const celleryComposite = "composites.mesh.cellery.io"
+const virtualService = "virtualservices.networking.istio.io"
+const configMap = "configmaps"
+const destinationRule = "destinationrules.networking.istio.io"
+const apiService = "apiservices.apiregistration.k8s.io"
+const cronJob = "cronjobs"
 
 type MockKubeCli struct {
 	clusterName      string
@@ -41,6 +51,17 @@ type MockKubeCli struct {
 	k8sClientVersion string
 	services         map[string]kubernetes.Services
 	virtualServices  map[string]kubernetes.VirtualService
+	pods             map[string]kubernetes.Pods
+	deployments      map[string]kubernetes.Deployments
+	events           kubernetes.Events
+	logs             []kubernetes.LogLine
+	configMaps       map[string][]byte
+	destinationRules map[string][]byte
+	failingApplies   map[string]bool
+	failingQueries   bool
+	apiServices      map[string][]byte
+	cronJobs         map[string][]byte
+	jsonPatches      []string
 }
 
 // NewMockKubeCli returns a mock cli for the cli.KubeCli interface.
@@ -91,6 +112,60 @@ func WithVirtualServices(virtualServices map[string]kubernetes.VirtualService) f
 	}
 }
 
+func WithPods(pods map[string]kubernetes.Pods) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		cli.pods = pods
+	}
+}
+
+func WithDeployments(deployments map[string]kubernetes.Deployments) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		cli.deployments = deployments
+	}
+}
+
+func WithEvents(events kubernetes.Events) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		cli.events = events
+	}
+}
+
+func WithLogs(logs []kubernetes.LogLine) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		cli.logs = logs
+	}
+}
+
+// WithFailingApply makes applying a file fail if it contains an object with the given name.
+func WithFailingApply(objectName string) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		if cli.failingApplies == nil {
+			cli.failingApplies = map[string]bool{}
+		}
+		cli.failingApplies[objectName] = true
+	}
+}
+
+// WithFailingDeploymentsAndEvents makes getting the deployments of instances and the events fail.
+func WithFailingDeploymentsAndEvents() func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		cli.failingQueries = true
+	}
+}
+
+// WithAvailableApiServices registers the API services, e.g. v1beta1.custom.metrics.k8s.io, as available.
+func WithAvailableApiServices(names ...string) func(*MockKubeCli) {
+	return func(cli *MockKubeCli) {
+		if cli.apiServices == nil {
+			cli.apiServices = map[string][]byte{}
+		}
+		for _, name := range names {
+			cli.apiServices[name] = []byte(fmt.Sprintf(`{"kind":"APIService","metadata":{"name":%q},`+
+				`"status":{"conditions":[{"type":"Available","status":"True"}]}}`, name))
+		}
+	}
+}
+
 func SetK8sVersions(serverVersion, clientVersion string) func(*MockKubeCli) {
 	return func(cli *MockKubeCli) {
 		cli.k8sServerVersion = serverVersion
@@ -151,7 +226,22 @@ func (kubeCli *MockKubeCli) GetComposite(compositeName string) (kubernetes.Compo
 	return kubernetes.Composite{}, fmt.Errorf("composite %s not found", compositeName)
 }
 
+// DeleteResource deletes the stored cron jobs, config maps and destination rules.
 func (kubeCli *MockKubeCli) DeleteResource(kind, instance string) (string, error) {
+	var objects map[string][]byte
+	if kind == configMap {
+		objects = kubeCli.configMaps
+	} else if kind == cronJob {
+		objects = kubeCli.cronJobs
+	} else if kind == destinationRule {
+		objects = kubeCli.destinationRules
+	} else {
+		return "", nil
+	}
+	if _, ok := objects[instance]; !ok {
+		return "", fmt.Errorf("Error from server (NotFound): %s %q not found", kind, instance)
+	}
+	delete(objects, instance)
 	return "", nil
 }
 
@@ -171,17 +261,29 @@ func (kubeCli *MockKubeCli) GetInstanceBytes(instanceKind, InstanceName string)
 		return kubeCli.cellsBytes[InstanceName], nil
 	} else if instanceKind == celleryComposite {
 		return kubeCli.cellsBytes[InstanceName], nil
+	} else if instanceKind == virtualService {
+		if vs, ok := kubeCli.virtualServices[InstanceName]; ok {
+			return json.Marshal(vs)
+		}
+	} else if instanceKind == configMap {
+		return kubeCli.configMaps[InstanceName], nil
+	} else if instanceKind == destinationRule {
+		return kubeCli.destinationRules[InstanceName], nil
+	} else if instanceKind == apiService {
+		return kubeCli.apiServices[InstanceName], nil
+	} else if instanceKind == cronJob {
+		return kubeCli.cronJobs[InstanceName], nil
 	}
 	return nil, nil
 }
 
-func (kubeCli *MockKubeCli) DescribeCell(cellName string) error {
+func (kubeCli *MockKubeCli) DescribeCell(cellName string) ([]byte, error) {
 	for _, cell := range kubeCli.cells.Items {
 		if cell.CellMetaData.Name == cellName {
-			return nil
+			return []byte(fmt.Sprintf("Name:         %s\n", cellName)), nil
 		}
 	}
-	return fmt.Errorf(fmt.Sprintf("cell instance %s not found", cellName))
+	return nil, fmt.Errorf("cell instance %s not found", cellName)
 }
 
 func (kubeCli *MockKubeCli) Version() (string, string, error) {
@@ -195,23 +297,98 @@ func (kubeCli *MockKubeCli) GetServices(cellName string) (kubernetes.Services, e
 	return kubeCli.services[cellName], nil
 }
 
-func (kubeCli *MockKubeCli) StreamCellLogsUserComponents(instanceName string, follow bool) error {
+func (kubeCli *MockKubeCli) StreamLogs(instances []string, component string, sysLog bool,
+	options kubernetes.LogOptions, handle func(kubernetes.LogLine)) error {
+	lines := append([]kubernetes.LogLine{}, kubeCli.logs...)
+	if !options.Follow {
+		// The lines of all containers are interleaved in the order in which they were logged
+		sort.SliceStable(lines, func(i, j int) bool {
+			return lines[i].Time.Before(lines[j].Time)
+		})
+	}
+	for _, line := range lines {
+		if component != "" && (line.Component != component || line.Container != component) {
+			continue
+		}
+		if !sysLog && line.Component == "" {
+			continue
+		}
+		for _, instance := range instances {
+			if line.Instance == instance {
+				handle(line)
+			}
+		}
+	}
 	return nil
 }
 
-func (kubeCli *MockKubeCli) StreamCellLogsAllComponents(instanceName string, follow bool) error {
+// JsonPatch records the patches, which are not applied.
+func (kubeCli *MockKubeCli) JsonPatch(kind, instance, jsonPatch string) error {
+	kubeCli.jsonPatches = append(kubeCli.jsonPatches, jsonPatch)
 	return nil
 }
 
-func (kubeCli *MockKubeCli) StreamComponentLogs(instanceName, componentName string, follow bool) error {
-	return nil
-}
-
-func (kubeCli *MockKubeCli) JsonPatch(kind, instance, jsonPatch string) error {
-	return nil
+// JsonPatches returns the recorded patches.
+func (kubeCli *MockKubeCli) JsonPatches() []string {
+	return kubeCli.jsonPatches
 }
 
+// ApplyFile stores the cells, virtual services, destination rules, config maps and cron jobs in the file so that they
+// can be read back.
 func (kubeCli *MockKubeCli) ApplyFile(file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	for _, document := range regexp.MustCompile(`(?m)^---\s*$`).Split(string(content), -1) {
+		objectJson, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return err
+		}
+		object := struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}{}
+		if err := json.Unmarshal(objectJson, &object); err != nil {
+			return err
+		}
+		if kubeCli.failingApplies[object.Metadata.Name] {
+			return fmt.Errorf("error applying %s", object.Metadata.Name)
+		}
+		switch object.Kind {
+		case "Cell":
+			if kubeCli.cellsBytes == nil {
+				kubeCli.cellsBytes = map[string][]byte{}
+			}
+			kubeCli.cellsBytes[object.Metadata.Name] = objectJson
+		case "VirtualService":
+			vs := kubernetes.VirtualService{}
+			if err := json.Unmarshal(objectJson, &vs); err != nil {
+				return err
+			}
+			if kubeCli.virtualServices == nil {
+				kubeCli.virtualServices = map[string]kubernetes.VirtualService{}
+			}
+			kubeCli.virtualServices[object.Metadata.Name] = vs
+		case "ConfigMap":
+			if kubeCli.configMaps == nil {
+				kubeCli.configMaps = map[string][]byte{}
+			}
+			kubeCli.configMaps[object.Metadata.Name] = objectJson
+		case "DestinationRule":
+			if kubeCli.destinationRules == nil {
+				kubeCli.destinationRules = map[string][]byte{}
+			}
+			kubeCli.destinationRules[object.Metadata.Name] = objectJson
+		case "CronJob":
+			if kubeCli.cronJobs == nil {
+				kubeCli.cronJobs = map[string][]byte{}
+			}
+			kubeCli.cronJobs[object.Metadata.Name] = objectJson
+		}
+	}
 	return nil
 }
 
@@ -227,15 +404,43 @@ func (kubeCli *MockKubeCli) GetCompositeInstanceAsMapInterface(composite string)
 }
 
 func (kubeCli *MockKubeCli) GetPodsForCell(cellName string) (kubernetes.Pods, error) {
-	return kubernetes.Pods{}, nil
+	return kubeCli.pods[cellName], nil
 }
 
 func (kubeCli *MockKubeCli) GetPodsForComposite(compName string) (kubernetes.Pods, error) {
-	return kubernetes.Pods{}, nil
+	return kubeCli.pods[compName], nil
+}
+
+func (kubeCli *MockKubeCli) GetDeploymentsForCell(cellName string) (kubernetes.Deployments, error) {
+	if kubeCli.failingQueries {
+		return kubernetes.Deployments{}, fmt.Errorf("failed to get deployments")
+	}
+	return kubeCli.deployments[cellName], nil
+}
+
+func (kubeCli *MockKubeCli) GetDeploymentsForComposite(compName string) (kubernetes.Deployments, error) {
+	if kubeCli.failingQueries {
+		return kubernetes.Deployments{}, fmt.Errorf("failed to get deployments")
+	}
+	return kubeCli.deployments[compName], nil
+}
+
+func (kubeCli *MockKubeCli) GetEvents() (kubernetes.Events, error) {
+	if kubeCli.failingQueries {
+		return kubernetes.Events{}, fmt.Errorf("failed to get events")
+	}
+	return kubeCli.events, nil
 }
 
 func (kubeCli *MockKubeCli) GetVirtualService(vs string) (kubernetes.VirtualService, error) {
-	return kubeCli.virtualServices[vs], nil
+	// Return a copy as the callers modify the virtual service in place
+	virtualService := kubernetes.VirtualService{}
+	vsBytes, err := json.Marshal(kubeCli.virtualServices[vs])
+	if err != nil {
+		return virtualService, err
+	}
+	err = json.Unmarshal(vsBytes, &virtualService)
+	return virtualService, err
 }
 
 func (kubeCli *MockKubeCli) IsInstanceAvailable(instanceName string) error {
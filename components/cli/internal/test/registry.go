@@ -23,19 +23,22 @@ import (
 	"io"
 
 	"cellery.io/cellery/components/cli/pkg/image"
+	registry2 "cellery.io/cellery/components/cli/pkg/registry"
 )
 
 type MockRegistry struct {
-	out       io.Writer
-	outBuffer *bytes.Buffer
-	images    map[string][]byte
+	out        io.Writer
+	outBuffer  *bytes.Buffer
+	images     map[string][]byte
+	signatures map[string][]byte
 }
 
 func NewMockRegistry(opts ...func(*MockRegistry)) *MockRegistry {
 	outBuffer := new(bytes.Buffer)
 	registry := &MockRegistry{
-		out:       outBuffer,
-		outBuffer: outBuffer,
+		out:        outBuffer,
+		outBuffer:  outBuffer,
+		signatures: map[string][]byte{},
 	}
 	for _, opt := range opts {
 		opt(registry)
@@ -49,13 +52,44 @@ func SetImages(images map[string][]byte) func(*MockRegistry) {
 	}
 }
 
-func (registry *MockRegistry) Push(parsedCellImage *image.CellImage, fileBytes []byte, username, password string) error {
+func SetSignatures(signatures map[string][]byte) func(*MockRegistry) {
+	return func(registry *MockRegistry) {
+		registry.signatures = signatures
+	}
+}
+
+func (registry *MockRegistry) Push(parsedCellImage *image.CellImage, cellImage io.ReaderAt, size int64, username,
+	password string, progress registry2.Progress) error {
+	return nil
+}
+
+func (registry *MockRegistry) Pull(parsedCellImage *image.CellImage, cellImage io.Writer, username, password string,
+	progress registry2.Progress) error {
+	imageName := parsedCellImage.Organization + "/" + parsedCellImage.ImageName + ":" + parsedCellImage.ImageVersion
+	_, err := cellImage.Write(registry.images[imageName])
+	return err
+}
+
+func (registry *MockRegistry) PushSignature(parsedCellImage *image.CellImage, signature []byte, username,
+	password string) error {
+	imageName := parsedCellImage.Organization + "/" + parsedCellImage.ImageName + ":" + parsedCellImage.ImageVersion
+	registry.signatures[imageName] = signature
 	return nil
 }
 
-func (registry *MockRegistry) Pull(parsedCellImage *image.CellImage, username string, password string) ([]byte, error) {
+func (registry *MockRegistry) PullSignature(parsedCellImage *image.CellImage, username,
+	password string) ([]byte, error) {
 	imageName := parsedCellImage.Organization + "/" + parsedCellImage.ImageName + ":" + parsedCellImage.ImageVersion
-	return registry.images[imageName], nil
+	signature, ok := registry.signatures[imageName]
+	if !ok {
+		return nil, registry2.ErrSignatureNotFound
+	}
+	return signature, nil
+}
+
+// Signatures returns the signatures pushed to the mock registry.
+func (registry *MockRegistry) Signatures() map[string][]byte {
+	return registry.signatures
 }
 
 // Out returns the mock writer used for the stdout.
@@ -39,7 +39,8 @@ import (
 
 // RunBuild executes the cell's build life cycle method and saves the generated cell image to the local repo.
 // This also copies the relevant ballerina files to the ballerina repo directory.
-func RunBuild(cli cli.Cli, tag string, balSource string) error {
+// Dependency images pulled during the build are verified unless insecureSkipVerify is set.
+func RunBuild(cli cli.Cli, tag string, balSource string, insecureSkipVerify bool) error {
 	var err error
 	var tmpProjectDir string
 	var tmpCellSource string
@@ -127,7 +128,7 @@ func RunBuild(cli cli.Cli, tag string, balSource string) error {
 	// Generate metadata.
 	if err = cli.ExecuteTask("Generating metadata", "Failed to generate metadata",
 		"", func() error {
-			err := generateMetaData(cli, parsedCellImage, tmpProjectDir)
+			err := generateMetaData(cli, parsedCellImage, tmpProjectDir, insecureSkipVerify)
 			return err
 		}); err != nil {
 		return err
@@ -166,13 +167,17 @@ func RunBuild(cli cli.Cli, tag string, balSource string) error {
 	if err = os.Remove(zipSrc); err != nil {
 		return fmt.Errorf("error occurred while removing zipSrc dir, %v", err)
 	}
+	if err = addToIndex(cli, parsedCellImage); err != nil {
+		return fmt.Errorf("error occurred while indexing image, %v", err)
+	}
 	util.PrintSuccessMessage(fmt.Sprintf("Successfully built image: %s", util.Bold(tag)))
 	util.PrintWhatsNextMessage("run the image", "cellery run "+tag)
 	return nil
 }
 
 // generateMetaData generates the metadata file for cellery
-func generateMetaData(cli cli.Cli, cellImage *image.CellImage, projectDir string) error {
+func generateMetaData(cli cli.Cli, cellImage *image.CellImage, projectDir string,
+	insecureSkipVerify bool) error {
 	targetDir := filepath.Join(projectDir, "target")
 	var err error
 	var metadataJSON []byte
@@ -207,15 +212,18 @@ func generateMetaData(cli cli.Cli, cellImage *image.CellImage, projectDir string
 	}
 	for componentName, componentMetadata := range metadata.Components {
 		for alias, dependencyMetadata := range componentMetadata.Dependencies.Cells {
-			if dependencyMetadata, err = extractDependenciesFromMetaData(cli, dependencyMetadata, cellImage); err != nil {
-				return fmt.Errorf("error extracting cell dependencies from meta of image %s", cellImage)
+			if dependencyMetadata, err = extractDependenciesFromMetaData(cli, dependencyMetadata, cellImage,
+				insecureSkipVerify); err != nil {
+				return fmt.Errorf("error extracting cell dependencies from meta of image %s, %v", cellImage, err)
 			}
 			metadata.Components[componentName].Dependencies.Cells[alias] = dependencyMetadata
 		}
 
 		for alias, dependencyMetadata := range componentMetadata.Dependencies.Composites {
-			if dependencyMetadata, err = extractDependenciesFromMetaData(cli, dependencyMetadata, cellImage); err != nil {
-				return fmt.Errorf("error extracting composite dependencies from meta of image %s", cellImage)
+			if dependencyMetadata, err = extractDependenciesFromMetaData(cli, dependencyMetadata, cellImage,
+				insecureSkipVerify); err != nil {
+				return fmt.Errorf("error extracting composite dependencies from meta of image %s, %v", cellImage,
+					err)
 			}
 			metadata.Components[componentName].Dependencies.Composites[alias] = dependencyMetadata
 
@@ -282,7 +290,8 @@ func generateMetaData(cli cli.Cli, cellImage *image.CellImage, projectDir string
 	return nil
 }
 
-func extractDependenciesFromMetaData(cli cli.Cli, dependencyMetadata *image.MetaData, cellImage *image.CellImage) (*image.MetaData, error) {
+func extractDependenciesFromMetaData(cli cli.Cli, dependencyMetadata *image.MetaData, cellImage *image.CellImage,
+	insecureSkipVerify bool) (*image.MetaData, error) {
 	var err error
 	cellImageZip := path.Join(cli.FileSystem().Repository(), dependencyMetadata.Organization, dependencyMetadata.Name,
 		dependencyMetadata.Version, dependencyMetadata.Name+cellImageExt)
@@ -297,7 +306,9 @@ func extractDependenciesFromMetaData(cli cli.Cli, dependencyMetadata *image.Meta
 		return nil, fmt.Errorf("error checking if dependency exists, %v", err)
 	}
 	if !dependencyExists {
-		RunPull(cli, dependencyImage, true, "", "")
+		if err = RunPull(cli, dependencyImage, true, insecureSkipVerify, "", ""); err != nil {
+			return nil, fmt.Errorf("error pulling dependency %s, %v", dependencyImage, err)
+		}
 	}
 	// Create temp directory
 	currentTime := time.Now()
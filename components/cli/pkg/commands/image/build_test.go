@@ -149,7 +149,7 @@ func TestRunBuild(t *testing.T) {
 				test.SetYamlContent(tst.yaml),
 				test.SetMetadataJsonContent(tst.metadataJson),
 				test.SetReferenceJsonContent(tst.referenceJson))
-			err := RunBuild(test.NewMockCli(test.SetFileSystem(mockFileSystem), test.SetBalExecutor(mockBalExecutor)), tst.image, tst.file.Name())
+			err := RunBuild(test.NewMockCli(test.SetFileSystem(mockFileSystem), test.SetBalExecutor(mockBalExecutor)), tst.image, tst.file.Name(), false)
 			if err != nil {
 				t.Errorf("error in RunBuild, %v", err)
 			}
@@ -28,3 +28,4 @@ const celleryArgEnvVarPattern = "(((?P<instance>" + celleryIdPattern + "):" +
 const src = "src"
 const celleryHome = ".cellery"
 const cellImageExt = ".zip"
+const cellImageSignatureExt = ".sig"
@@ -28,12 +28,26 @@ import (
 	"cellery.io/cellery/components/cli/pkg/image"
 )
 
-func RunDeleteImage(cli cli.Cli, images []string, regex string, deleteAll bool) error {
+func RunDeleteImage(cli cli.Cli, images []string, regex string, deleteAll bool) (err error) {
 	repoLocation := cli.FileSystem().Repository()
 	imagesInRepo, err := getImagesArray(cli)
 	if err != nil {
 		return fmt.Errorf("error getting images array, %v", err)
 	}
+	var deleted []string
+	defer func() {
+		if len(deleted) == 0 {
+			return
+		}
+		if indexErr := updateIndex(cli, func(idx *image.Index) error {
+			for _, name := range deleted {
+				idx.Remove(name)
+			}
+			return nil
+		}); indexErr != nil && err == nil {
+			err = fmt.Errorf("error updating repository index, %v", indexErr)
+		}
+	}()
 	for _, imageInRepo := range imagesInRepo {
 		parsedCellImage, err := image.ParseImageTag(imageInRepo.name)
 		if err != nil {
@@ -45,6 +59,7 @@ func RunDeleteImage(cli cli.Cli, images []string, regex string, deleteAll bool)
 			if err := os.RemoveAll(cellImagePath); err != nil {
 				return err
 			}
+			deleted = append(deleted, imageInRepo.name)
 		} else {
 			if regex != "" {
 				// Check if image name matches regex pattern
@@ -53,18 +68,20 @@ func RunDeleteImage(cli cli.Cli, images []string, regex string, deleteAll bool)
 					return fmt.Errorf("error checking if pattern matches with image name, %v", err)
 				}
 				if regexMatches {
-					if os.RemoveAll(cellImagePath); err != nil {
+					if err := os.RemoveAll(cellImagePath); err != nil {
 						return err
 					}
+					deleted = append(deleted, imageInRepo.name)
 					continue
 				}
 			}
 			if len(images) > 0 {
 				for _, imageToBeDeleted := range images {
 					if imageInRepo.name == imageToBeDeleted {
-						if os.RemoveAll(cellImagePath); err != nil {
+						if err := os.RemoveAll(cellImagePath); err != nil {
 							return err
 						}
+						deleted = append(deleted, imageInRepo.name)
 						break
 					}
 				}
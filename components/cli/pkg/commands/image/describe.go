@@ -22,25 +22,43 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/ghodss/yaml"
+
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/constants"
 	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
-func RunDescribe(cli cli.Cli, name string) error {
+func RunDescribe(cli cli.Cli, name string, opts output.Options) error {
 	instancePattern, _ := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), name)
 	if instancePattern {
 		// If the input of user is an instance describe running cell instance.
-		err := cli.KubeCli().DescribeCell(name)
+		if opts.Structured() {
+			cell, err := cli.KubeCli().GetCell(name)
+			if err != nil {
+				return fmt.Errorf("error describing cell instance, %v", err)
+			}
+			return output.Write(cli.Out(), opts, cell)
+		}
+		out, err := cli.KubeCli().DescribeCell(name)
 		if err != nil {
 			return fmt.Errorf("error describing cell instance, %v", err)
 		}
+		fmt.Fprint(cli.Out(), string(out))
 	} else {
 		// If the input of user is a cell image print the cell yaml
 		cellYamlContent, err := image.ReadCellImageYaml(cli.FileSystem().Repository(), name)
 		if err != nil {
 			return fmt.Errorf("error describing cell image, %v", err)
 		}
+		if opts.Structured() {
+			var cell interface{}
+			if err := yaml.Unmarshal(cellYamlContent, &cell); err != nil {
+				return fmt.Errorf("error describing cell image, %v", err)
+			}
+			return output.Write(cli.Out(), opts, cell)
+		}
 		fmt.Fprintln(cli.Out(), string(cellYamlContent))
 	}
 	return nil
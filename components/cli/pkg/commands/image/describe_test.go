@@ -26,6 +26,7 @@ import (
 
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
 func TestRunDescribeInstance(t *testing.T) {
@@ -63,7 +64,7 @@ func TestRunDescribeInstance(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunDescribe(tst.MockCli, tst.instance)
+			err := RunDescribe(tst.MockCli, tst.instance, output.Options{})
 			if tst.expectedToPass {
 				if err != nil {
 					t.Errorf("error in RunDescribe instance")
@@ -106,7 +107,7 @@ func TestRunDescribeImage(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunDescribe(mockCli, tst.image)
+			err := RunDescribe(mockCli, tst.image, output.Options{})
 			if tst.expectedToPass {
 				if err != nil {
 					t.Errorf("error in RunDescribe image")
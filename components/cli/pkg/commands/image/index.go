@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"fmt"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+// updateIndex loads the index of the local repository, applies the update and saves the index.
+func updateIndex(cli cli.Cli, update func(idx *image.Index) error) error {
+	idx, err := image.LoadIndex(cli.FileSystem().Repository())
+	if err != nil {
+		return fmt.Errorf("error loading repository index, %v", err)
+	}
+	if err := update(idx); err != nil {
+		return err
+	}
+	return idx.Save()
+}
+
+// addToIndex records a cell image which was saved to the local repository in the index.
+func addToIndex(cli cli.Cli, cellImage *image.CellImage) error {
+	return updateIndex(cli, func(idx *image.Index) error {
+		_, err := idx.Add(fmt.Sprintf("%s/%s:%s", cellImage.Organization, cellImage.ImageName,
+			cellImage.ImageVersion))
+		return err
+	})
+}
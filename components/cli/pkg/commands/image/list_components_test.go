@@ -24,6 +24,7 @@ import (
 
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
 func TestRunListComponentsForInstance(t *testing.T) {
@@ -53,7 +54,7 @@ func TestRunListComponentsForInstance(t *testing.T) {
 	}
 	for _, testIteration := range tests {
 		t.Run(testIteration.name, func(t *testing.T) {
-			err := RunListComponents(mockCli, testIteration.instance)
+			err := RunListComponents(mockCli, testIteration.instance, output.Options{})
 			if err != nil {
 				t.Errorf("error in RunListComponents, %v", err)
 			}
@@ -79,7 +80,7 @@ func TestRunListComponentsForImage(t *testing.T) {
 	}
 	for _, testIteration := range tests {
 		t.Run(testIteration.name, func(t *testing.T) {
-			err := RunListComponents(mockCli, testIteration.image)
+			err := RunListComponents(mockCli, testIteration.image, output.Options{})
 			if err != nil {
 				t.Errorf("error in RunListComponents, %v", err)
 			}
@@ -20,102 +20,97 @@ package image
 
 import (
 	"fmt"
-	"log"
-	"os"
-	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/docker/go-units"
-	"github.com/olekukonko/tablewriter"
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/image"
-	"cellery.io/cellery/components/cli/pkg/util"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
 type imageData struct {
-	name    string
-	size    string
-	created string
-	kind    string
+	name           string
+	size           string
+	created        string
+	kind           string
+	sizeBytes      int64
+	buildTimestamp int64
+	celleryVersion string
+	components     int
+	digest         string
 }
 
-func RunListImages(cli cli.Cli) error {
-	var data [][]string
+// imageSchema is the json and yaml representation of a cell image in the local repository.
+type imageSchema struct {
+	Name           string    `json:"name"`
+	Kind           string    `json:"kind"`
+	Size           int64     `json:"size"`
+	Created        time.Time `json:"created"`
+	CelleryVersion string    `json:"celleryVersion"`
+	Components     int       `json:"components"`
+	Digest         string    `json:"digest"`
+}
+
+func RunListImages(cli cli.Cli, opts output.Options) error {
 	images, err := getImagesArray(cli)
 	if err != nil {
 		return fmt.Errorf("error getting images arrays, %v", err)
 	}
+	if opts.Structured() {
+		schemas := []imageSchema{}
+		for _, i := range images {
+			schemas = append(schemas, imageSchema{
+				Name:           i.name,
+				Kind:           i.kind,
+				Size:           i.sizeBytes,
+				Created:        time.Unix(i.buildTimestamp, 0).UTC(),
+				CelleryVersion: i.celleryVersion,
+				Components:     i.components,
+				Digest:         i.digest,
+			})
+		}
+		return output.Write(cli.Out(), opts, schemas)
+	}
 	if len(images) == 0 {
 		fmt.Fprintln(cli.Out(), "No images found.")
-	} else {
-		for _, i := range images {
-			data = append(data, []string{i.name, i.size, i.created, i.kind})
+		return nil
+	}
+	header := []string{"IMAGE", "SIZE", "CREATED", "KIND"}
+	if opts.Wide() {
+		header = append(header, "COMPONENTS", "CELLERY VERSION", "DIGEST")
+	}
+	table := output.NewTable(cli.Out(), header)
+	for _, i := range images {
+		record := []string{i.name, i.size, i.created, i.kind}
+		if opts.Wide() {
+			record = append(record, strconv.Itoa(i.components), i.celleryVersion, i.digest)
 		}
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"IMAGE", "SIZE", "CREATED", "KIND"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-		table.AppendBulk(data)
-		table.Render()
+		table.Append(record)
 	}
+	table.Render()
 	return nil
 }
 
 func getImagesArray(cli cli.Cli) ([]imageData, error) {
 	var images []imageData
-	repoLocation := cli.FileSystem().Repository()
-	organizations, err := util.GetSubDirectoryNames(repoLocation)
+	idx, err := image.LoadIndex(cli.FileSystem().Repository())
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error loading repository index, %v", err)
 	}
-	for _, organization := range organizations {
-		projects, err := util.GetSubDirectoryNames(filepath.Join(repoLocation, organization))
-		if err != nil {
-			log.Fatal(err)
-		}
-		for _, project := range projects {
-			versions, err := util.GetSubDirectoryNames(filepath.Join(repoLocation, organization, project))
-			if err != nil {
-				log.Fatal(err)
-			}
-			for _, version := range versions {
-				zipFile := filepath.Join(repoLocation, organization, project, version, project+cellImageExt)
-				zipFileExists, err := util.FileExists(zipFile)
-				if err != nil {
-					return nil, fmt.Errorf("error checking if zip file exists, %v", err)
-				}
-				if zipFileExists {
-					size, err := util.GetFileSize(zipFile)
-					if err != nil {
-						log.Fatal(err)
-					}
-					meta, err := image.ReadMetaData(repoLocation, organization, project, version)
-					if err != nil {
-						return nil, fmt.Errorf("error while listing images, %v", err)
-					}
-					images = append(images, imageData{
-						fmt.Sprintf("%s/%s:%s", organization, project, version),
-						units.HumanSize(float64(size)),
-						fmt.Sprintf("%s ago", units.HumanDuration(time.Since(time.Unix(meta.BuildTimestamp, 0)))),
-						fmt.Sprintf("%s", meta.Kind),
-					})
-				}
-			}
-		}
+	for _, entry := range idx.Entries() {
+		images = append(images, imageData{
+			entry.Name,
+			units.HumanSize(float64(entry.Size)),
+			fmt.Sprintf("%s ago", units.HumanDuration(time.Since(time.Unix(entry.MetaData.BuildTimestamp, 0)))),
+			entry.MetaData.Kind,
+			entry.Size,
+			entry.MetaData.BuildTimestamp,
+			entry.MetaData.BuildCelleryVersion,
+			len(entry.MetaData.Components),
+			entry.Digest.String(),
+		})
 	}
 	return images, nil
 }
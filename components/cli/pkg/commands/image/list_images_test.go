@@ -25,6 +25,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 
 	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
 func TestRunListImages(t *testing.T) {
@@ -41,7 +42,7 @@ func TestRunListImages(t *testing.T) {
 	}
 	for _, testIteration := range tests {
 		t.Run(testIteration.name, func(t *testing.T) {
-			err := RunListImages(mockCli)
+			err := RunListImages(mockCli, output.Options{})
 			if err != nil {
 				t.Errorf("error in RunListImages, %v", err)
 			}
@@ -29,28 +29,70 @@ import (
 	"strings"
 
 	"github.com/ghodss/yaml"
-	"github.com/olekukonko/tablewriter"
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/constants"
 	errorpkg "cellery.io/cellery/components/cli/pkg/error"
 	"cellery.io/cellery/components/cli/pkg/image"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
-func RunListIngresses(cli cli.Cli, name string) error {
+// ingressColumn maps a column of an ingress table to the corresponding key in the json and yaml output.
+type ingressColumn struct {
+	header string
+	key    string
+}
+
+var compositeInstanceIngressColumns = []ingressColumn{
+	{"COMPONENT", "component"},
+	{"INGRESS TYPE", "ingressType"},
+	{"INGRESS PORT", "port"},
+}
+
+var cellInstanceIngressColumns = []ingressColumn{
+	{"CONTEXT", "context"},
+	{"INGRESS TYPE", "ingressType"},
+	{"VERSION", "version"},
+	{"METHOD", "method"},
+	{"RESOURCE", "resource"},
+	{"LOCAL CELL GATEWAY", "gatewayUrl"},
+	{"VHOST", "vhost"},
+}
+
+var compositeImageIngressColumns = []ingressColumn{
+	{"COMPONENT", "component"},
+	{"INGRESS TYPE", "ingressType"},
+	{"INGRESS PORT", "port"},
+	{"INGRESS_KEY", "ingressKey"},
+}
+
+var cellImageIngressColumns = []ingressColumn{
+	{"COMPONENT", "component"},
+	{"INGRESS TYPE", "ingressType"},
+	{"INGRESS CONTEXT", "context"},
+	{"INGRESS_VERSION", "version"},
+	{"INGRESS PORT", "port"},
+	{"RESOURCE", "resource"},
+	{"METHOD", "method"},
+	{"EXPOSED", "exposed"},
+	{"VHOST", "vhost"},
+	{"INGRESS_KEY", "ingressKey"},
+}
+
+func RunListIngresses(cli cli.Cli, name string, opts output.Options) error {
 	instancePattern, err := regexp.MatchString(fmt.Sprintf("^%s$", constants.CelleryIdPattern), name)
 	if err != nil {
 		return fmt.Errorf("%s is neither instance nor an image", name)
 	}
 	if instancePattern {
-		return displayInstanceApisTable(cli, name)
+		return displayInstanceApisTable(cli, name, opts)
 	} else {
-		return displayImageApisTable(cli, name)
+		return displayImageApisTable(cli, name, opts)
 	}
 }
 
-func displayInstanceApisTable(cli cli.Cli, instanceName string) error {
+func displayInstanceApisTable(cli cli.Cli, instanceName string, opts output.Options) error {
 	var canBeComposite bool
 	cell, err := cli.KubeCli().GetCell(instanceName)
 	if err != nil {
@@ -60,7 +102,7 @@ func displayInstanceApisTable(cli cli.Cli, instanceName string) error {
 			return fmt.Errorf("failed to check available Cells, %v", err)
 		}
 	} else {
-		displayCellInstanceApisTable(cli, cell, instanceName)
+		return displayCellInstanceApisTable(cli, cell, instanceName, opts)
 	}
 
 	if canBeComposite {
@@ -72,13 +114,14 @@ func displayInstanceApisTable(cli cli.Cli, instanceName string) error {
 				return fmt.Errorf("failed to check available Composites, %v", err)
 			}
 		} else {
-			displayCompositeInstanceApisTable(cli, composite, instanceName)
+			return displayCompositeInstanceApisTable(cli, composite, instanceName, opts)
 		}
 	}
 	return nil
 }
 
-func displayCompositeInstanceApisTable(cli cli.Cli, composite kubernetes.Composite, compositeInstance string) {
+func displayCompositeInstanceApisTable(cli cli.Cli, composite kubernetes.Composite, compositeInstance string,
+	opts output.Options) error {
 	var tableData [][]string
 	for _, component := range composite.CompositeSpec.ComponentTemplates {
 		for _, port := range component.Spec.Ports {
@@ -86,30 +129,11 @@ func displayCompositeInstanceApisTable(cli cli.Cli, composite kubernetes.Composi
 			tableData = append(tableData, tableRecord)
 		}
 	}
-	if len(tableData) > 0 {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"COMPONENT", "INGRESS TYPE", "INGRESS PORT"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-		table.AppendBulk(tableData)
-		table.Render()
-	} else {
-		fmt.Fprintln(cli.Out(), fmt.Sprintf("No ingresses found for composite instance %s", compositeInstance))
-	}
+	return renderIngresses(cli, opts, compositeInstanceIngressColumns, tableData,
+		fmt.Sprintf("No ingresses found for composite instance %s", compositeInstance))
 }
 
-func displayCellInstanceApisTable(cli cli.Cli, cell kubernetes.Cell, cellInstanceName string) {
+func displayCellInstanceApisTable(cli cli.Cli, cell kubernetes.Cell, cellInstanceName string, opts output.Options) error {
 	apiArray := cell.CellSpec.GateWayTemplate.GatewaySpec.Ingress.HttpApis
 	var ingressType = "web"
 	globalContext := ""
@@ -169,42 +193,15 @@ func displayCellInstanceApisTable(cli cli.Cli, cell kubernetes.Cell, cellInstanc
 			tableData = append(tableData, tableRecord)
 		}
 	}
-	if len(tableData) > 0 {
-		table := tablewriter.NewWriter(os.Stdout)
-		if ingressType == "http" {
-			table.SetHeader([]string{"CONTEXT", "INGRESS TYPE", "VERSION", "METHOD", "RESOURCE", "LOCAL CELL GATEWAY", "GLOBAL API URL"})
-		} else {
-			table.SetHeader([]string{"CONTEXT", "INGRESS TYPE", "VERSION", "METHOD", "RESOURCE", "LOCAL CELL GATEWAY", "VHOST"})
-		}
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-		table.AppendBulk(tableData)
-		table.Render()
-	} else {
-		fmt.Fprintln(cli.Out(), fmt.Sprintf("No ingresses found for cell instance, %s", cellInstanceName))
+	columns := cellInstanceIngressColumns
+	if ingressType == "http" {
+		columns = append(columns[:len(columns)-1:len(columns)-1], ingressColumn{"GLOBAL API URL", "globalApiUrl"})
 	}
+	return renderIngresses(cli, opts, columns, tableData,
+		fmt.Sprintf("No ingresses found for cell instance, %s", cellInstanceName))
 }
 
-func displayImageApisTable(cli cli.Cli, imageName string) error {
+func displayImageApisTable(cli cli.Cli, imageName string, opts output.Options) error {
 	cellYamlContent, err := image.ReadCellImageYaml(cli.FileSystem().Repository(), imageName)
 	if err != nil {
 		return fmt.Errorf("error while reading cell image content, %v", err)
@@ -216,18 +213,18 @@ func displayImageApisTable(cli cli.Cli, imageName string) error {
 	}
 
 	if cellImageContent.Kind == "Cell" {
-		if err := displayCellImageApisTable(cli, imageName); err != nil {
+		if err := displayCellImageApisTable(cli, imageName, opts); err != nil {
 			return fmt.Errorf("error displaying cell image apis table, %v", err)
 		}
 	} else if cellImageContent.Kind == "Composite" {
-		if err := displayCompositeImageApisTable(cli, imageName); err != nil {
+		if err := displayCompositeImageApisTable(cli, imageName, opts); err != nil {
 			return fmt.Errorf("error displaying composite image apis table, %v", err)
 		}
 	}
 	return nil
 }
 
-func displayCompositeImageApisTable(cli cli.Cli, compositeImageContent string) error {
+func displayCompositeImageApisTable(cli cli.Cli, compositeImageContent string, opts output.Options) error {
 	cell, err := getIngressValues(cli, compositeImageContent)
 	if err != nil {
 		return fmt.Errorf("error occurred while displaying composite image ingress, %v", err)
@@ -257,33 +254,11 @@ func displayCompositeImageApisTable(cli cli.Cli, compositeImageContent string) e
 			tableData = append(tableData, ingressData)
 		}
 	}
-	if len(tableData) > 0 {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"COMPONENT", "INGRESS TYPE", "INGRESS PORT", "INGRESS_KEY"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-		table.AppendBulk(tableData)
-		table.Render()
-	} else {
-		fmt.Fprintln(cli.Out(), fmt.Sprintf("No ingresses found for composite image, %s", compositeImageContent))
-	}
-	return nil
+	return renderIngresses(cli, opts, compositeImageIngressColumns, tableData,
+		fmt.Sprintf("No ingresses found for composite image, %s", compositeImageContent))
 }
 
-func displayCellImageApisTable(cli cli.Cli, cellImageContent string) error {
+func displayCellImageApisTable(cli cli.Cli, cellImageContent string, opts output.Options) error {
 	cell, err := getIngressValues(cli, cellImageContent)
 	if err != nil {
 		return fmt.Errorf("error occurred while displaying cell image ingress, %v", err)
@@ -344,47 +319,44 @@ func displayCellImageApisTable(cli cli.Cli, cellImageContent string) error {
 			}
 		}
 	}
-	if len(tableData) > 0 {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"COMPONENT", "INGRESS TYPE", "INGRESS CONTEXT", "INGRESS_VERSION", "INGRESS PORT", "RESOURCE", "METHOD", "EXPOSED", "VHOST", "INGRESS_KEY"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-		table.AppendBulk(tableData)
-		table.Render()
-	} else {
-		fmt.Fprintln(cli.Out(), fmt.Sprintf("No ingresses found for cell image, %s", cellImageContent))
+	return renderIngresses(cli, opts, cellImageIngressColumns, tableData,
+		fmt.Sprintf("No ingresses found for cell image, %s", cellImageContent))
+}
+
+// renderIngresses writes the ingresses as a table, or as a list of objects keyed by the column keys when a
+// structured output is requested. Columns without a value are omitted from the structured output.
+func renderIngresses(cli cli.Cli, opts output.Options, columns []ingressColumn, tableData [][]string,
+	notFoundMessage string) error {
+	if opts.Structured() {
+		ingresses := []map[string]string{}
+		for _, record := range tableData {
+			ingress := map[string]string{}
+			for i, column := range columns {
+				if record[i] != "" && record[i] != constants.NA && record[i] != "--" {
+					ingress[column.key] = record[i]
+				}
+			}
+			ingresses = append(ingresses, ingress)
+		}
+		return output.Write(cli.Out(), opts, ingresses)
+	}
+	if len(tableData) == 0 {
+		fmt.Fprintln(cli.Out(), notFoundMessage)
+		return nil
+	}
+	var header []string
+	for _, column := range columns {
+		header = append(header, column.header)
 	}
+	table := output.NewTable(cli.Out(), header)
+	table.AppendBulk(tableData)
+	table.Render()
 	return nil
 }
 
 func getIngressValues(cli cli.Cli, cellImageContent string) (kubernetes.Cell, error) {
 	parsedCellImage, err := image.ParseImageTag(cellImageContent)
-	imageDir, err := ExtractImage(cli, parsedCellImage, false)
+	imageDir, err := ExtractImage(cli, parsedCellImage, false, false)
 	if err != nil {
 		return kubernetes.Cell{}, fmt.Errorf("error occurred while extracting image: %s", err)
 	}
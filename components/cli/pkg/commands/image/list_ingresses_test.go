@@ -24,6 +24,7 @@ import (
 
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
 func TestRunListIngresses(t *testing.T) {
@@ -112,7 +113,7 @@ func TestRunListIngresses(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunListIngresses(tst.mockCli, tst.arg)
+			err := RunListIngresses(tst.mockCli, tst.arg, output.Options{})
 			if err != nil {
 				t.Errorf("error in RunListIngresses, %v", err)
 			}
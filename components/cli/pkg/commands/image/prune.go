@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// RunPrune removes the cell images selected by the policy from the local repository.
+func RunPrune(cli cli.Cli, policy image.PrunePolicy, dryRun bool) error {
+	idx, err := image.LoadIndex(cli.FileSystem().Repository())
+	if err != nil {
+		return fmt.Errorf("error loading repository index, %v", err)
+	}
+	candidates := idx.PruneCandidates(policy, time.Now())
+	if len(candidates) == 0 {
+		fmt.Fprintln(cli.Out(), "No images to prune.")
+		return nil
+	}
+	before := image.DiskUsage(idx.Entries())
+	for _, entry := range candidates {
+		if dryRun {
+			fmt.Fprintf(cli.Out(), "Would delete %s (%s)\n", entry.Name, units.HumanSize(float64(entry.Size)))
+			continue
+		}
+		parsedCellImage, err := image.ParseImageTag(entry.Name)
+		if err != nil {
+			return fmt.Errorf("error occurred while parsing cell image, %v", err)
+		}
+		if err := os.RemoveAll(filepath.Join(cli.FileSystem().Repository(), parsedCellImage.Organization,
+			parsedCellImage.ImageName, parsedCellImage.ImageVersion)); err != nil {
+			return fmt.Errorf("error deleting cell image %s, %v", entry.Name, err)
+		}
+		idx.Remove(entry.Name)
+		fmt.Fprintf(cli.Out(), "Deleted %s\n", entry.Name)
+	}
+	if dryRun {
+		return nil
+	}
+	if err := idx.Save(); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Pruned %d image(s), reclaimed %s", len(candidates),
+		units.HumanSize(float64(before-image.DiskUsage(idx.Entries())))))
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/go-units"
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+func TestRunPrune(t *testing.T) {
+	sampleImage, err := ioutil.ReadFile(filepath.Join("testdata", "repo", "myorg", "hello", "1.0.0", "hello.zip"))
+	if err != nil {
+		t.Fatalf("error reading sample image file, %v", err)
+	}
+	tests := []struct {
+		name      string
+		dryRun    bool
+		want      string
+		remaining []string
+	}{
+		{
+			name:      "prune images",
+			want:      "Deleted myorg/hello:1.0.0\n",
+			remaining: []string{"myorg/hello:2.0.0"},
+		},
+		{
+			name:      "dry run",
+			dryRun:    true,
+			remaining: []string{"myorg/hello:1.0.0", "myorg/hello:2.0.0"},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockRepo, err := ioutil.TempDir("", "mock-repo")
+			if err != nil {
+				t.Fatalf("failed to create mock repository, %v", err)
+			}
+			defer os.RemoveAll(mockRepo)
+			for _, version := range []string{"1.0.0", "2.0.0"} {
+				dir := filepath.Join(mockRepo, "myorg", "hello", version)
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(filepath.Join(dir, "hello.zip"), sampleImage, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			mockCli := test.NewMockCli(test.SetFileSystem(test.NewMockFileSystem(test.SetRepository(mockRepo))))
+			if err := RunPrune(mockCli, image.PrunePolicy{KeepLast: 1}, tst.dryRun); err != nil {
+				t.Fatalf("error in RunPrune, %v", err)
+			}
+			want := tst.want
+			if tst.dryRun {
+				want = "Would delete myorg/hello:1.0.0 (" + units.HumanSize(float64(len(sampleImage))) + ")\n"
+			}
+			if diff := cmp.Diff(want, mockCli.OutBuffer().String()); diff != "" {
+				t.Errorf("RunPrune: unexpected output (-want, +got)\n%v", diff)
+			}
+			idx, err := image.LoadIndex(mockRepo)
+			if err != nil {
+				t.Fatalf("error loading index, %v", err)
+			}
+			var remaining []string
+			for _, entry := range idx.Entries() {
+				remaining = append(remaining, entry.Name)
+			}
+			if diff := cmp.Diff(tst.remaining, remaining); diff != "" {
+				t.Errorf("RunPrune: unexpected images (-want, +got)\n%v", diff)
+			}
+			_, err = os.Stat(filepath.Join(mockRepo, "myorg", "hello", "1.0.0"))
+			if tst.dryRun == os.IsNotExist(err) {
+				t.Errorf("RunPrune: unexpected image directory state, %v", err)
+			}
+		})
+	}
+}
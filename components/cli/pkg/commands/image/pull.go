@@ -27,14 +27,18 @@ import (
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/registry"
 	"cellery.io/cellery/components/cli/pkg/registry/credentials"
 	"cellery.io/cellery/components/cli/pkg/util"
 	"cellery.io/cellery/components/cli/pkg/version"
 )
 
 // RunPull connects to the Cellery Registry and pulls the cell image and saves it in the local repository.
+// The signature of the image is verified against the trust store if keys are trusted for the organization of the
+// image, unless insecureSkipVerify is set.
 // This also adds the relevant ballerina files to the ballerina repo directory.
-func RunPull(cli cli.Cli, cellImage string, isSilent bool, username string, password string) error {
+func RunPull(cli cli.Cli, cellImage string, isSilent bool, insecureSkipVerify bool, username string,
+	password string) error {
 	parsedCellImage, err := image.ParseImageTag(cellImage)
 	if err != nil {
 		return fmt.Errorf("error occurred while parsing cell image, %v", err)
@@ -65,12 +69,18 @@ func RunPull(cli cli.Cli, cellImage string, isSilent bool, username string, pass
 			isCredentialsPresent = false
 		}
 	}
+	var tempCellImage string
+	var signature []byte
 	if isCredentialsPresent {
 		// Pulling the image using the saved credentials
-		err = pullImage(cli, parsedCellImage, registryCredentials.Username, registryCredentials.Password)
+		tempCellImage, signature, err = pullImage(cli, parsedCellImage, registryCredentials.Username,
+			registryCredentials.Password)
 	} else {
 		// Pulling image without credentials
-		err = pullImage(cli, parsedCellImage, "", "")
+		tempCellImage, signature, err = pullImage(cli, parsedCellImage, "", "")
+	}
+	if tempCellImage != "" {
+		defer os.Remove(tempCellImage)
 	}
 	if err != nil {
 		// Need to check 404 since docker auth does not validates the image tag
@@ -82,6 +92,19 @@ func RunPull(cli cli.Cli, cellImage string, isSilent bool, username string, pass
 			return fmt.Errorf("failed to pull image, %v", err)
 		}
 	}
+	if insecureSkipVerify {
+		util.PrintWarningMessage(fmt.Sprintf("Skipped signature verification of image %s", cellImage))
+	} else if required, err := isVerificationRequired(cli, parsedCellImage); err != nil {
+		return err
+	} else if required {
+		if err = cli.TrustStore().VerifyImage(parsedCellImage, tempCellImage, signature); err != nil {
+			return fmt.Errorf("image verification failed, %v. Use --insecure-skip-verify to pull the image "+
+				"without verification", err)
+		}
+	}
+	if err = saveImage(cli, parsedCellImage, tempCellImage, signature); err != nil {
+		return err
+	}
 	// Validating image compatibility with Cellery installation
 	repoLocation := cli.FileSystem().Repository()
 	metadata, err := image.ReadMetaData(repoLocation, parsedCellImage.Organization, parsedCellImage.ImageName,
@@ -89,6 +112,9 @@ func RunPull(cli cli.Cli, cellImage string, isSilent bool, username string, pass
 	if err != nil {
 		return fmt.Errorf("invalid cell image, %v", err)
 	}
+	if err = addToIndex(cli, parsedCellImage); err != nil {
+		return fmt.Errorf("error occurred while indexing cell image, %v", err)
+	}
 	// TODO : Add a proper validation based on major, minor, patch, version before stable release
 	if metadata.BuildCelleryVersion != "" && metadata.BuildCelleryVersion != version.BuildVersion() {
 		fmt.Fprint(cli.Out(), fmt.Sprintf("\r\x1b[2K%s Pulled cell image's build version (%s) and Cellery "+
@@ -103,19 +129,37 @@ func RunPull(cli cli.Cli, cellImage string, isSilent bool, username string, pass
 	return nil
 }
 
-func pullImage(cli cli.Cli, parsedCellImage *image.CellImage, username string, password string) error {
-	var err error
-	var cellImage []byte
-	if err := cli.ExecuteTask("Pulling cell image", "Failed to pull image",
-		"", func() error {
-			cellImage, err = cli.Registry().Pull(parsedCellImage, username, password)
-			if err != nil {
-				return err
-			}
-			return nil
+// pullImage downloads the cell image into a temporary file and returns its path along with the signature of the
+// image. The signature is nil if the image is not signed.
+func pullImage(cli cli.Cli, parsedCellImage *image.CellImage, username string, password string) (string, []byte,
+	error) {
+	// Downloading to a temporary file first to avoid replacing the existing image with a partial download
+	tempCellImage, err := ioutil.TempFile(cli.FileSystem().TempDir(), parsedCellImage.ImageName+"-*"+cellImageExt)
+	if err != nil {
+		return "", nil, fmt.Errorf("error occurred while creating temporary file for cell image, %v", err)
+	}
+	defer tempCellImage.Close()
+	if err := cli.ExecuteTaskWithProgress("Pulling cell image", "Failed to pull image",
+		"", func(progress func(current, total int64)) error {
+			return cli.Registry().Pull(parsedCellImage, tempCellImage, username, password, progress)
 		}); err != nil {
-		return fmt.Errorf("error pulling image, %v", err)
+		return tempCellImage.Name(), nil, fmt.Errorf("error pulling image, %v", err)
 	}
+	if err := tempCellImage.Close(); err != nil {
+		return tempCellImage.Name(), nil, fmt.Errorf("error occurred while saving cell image, %v", err)
+	}
+	signature, err := cli.Registry().PullSignature(parsedCellImage, username, password)
+	if err == registry.ErrSignatureNotFound {
+		return tempCellImage.Name(), nil, nil
+	}
+	if err != nil {
+		return tempCellImage.Name(), nil, fmt.Errorf("error pulling image signature, %v", err)
+	}
+	return tempCellImage.Name(), signature, nil
+}
+
+// saveImage moves the pulled cell image and its signature to the local repository.
+func saveImage(cli cli.Cli, parsedCellImage *image.CellImage, tempCellImage string, signature []byte) error {
 	repoLocation := filepath.Join(cli.FileSystem().Repository(), parsedCellImage.Organization,
 		parsedCellImage.ImageName, parsedCellImage.ImageVersion)
 	// Cleaning up the old image if it already exists
@@ -135,11 +179,14 @@ func pullImage(cli cli.Cli, parsedCellImage *image.CellImage, username string, p
 	if err != nil {
 		return fmt.Errorf("error occurred while saving cell image to local repo, %v", err)
 	}
-	// Writing the Cell Image to local file
+	// Moving the Cell Image to the local repo
 	cellImageFile := filepath.Join(repoLocation, parsedCellImage.ImageName+cellImageExt)
-	err = ioutil.WriteFile(cellImageFile, cellImage, 0644)
+	err = util.CopyFile(tempCellImage, cellImageFile)
 	if err != nil {
 		return fmt.Errorf("error occurred while saving cell image to local repo, %v", err)
 	}
+	if signature != nil {
+		return writeSignature(cli, parsedCellImage, signature)
+	}
 	return nil
 }
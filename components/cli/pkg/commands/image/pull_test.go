@@ -19,14 +19,25 @@
 package image
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/opencontainers/go-digest"
 
 	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/config"
 	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/trust"
 )
 
 func TestRunPull(t *testing.T) {
@@ -43,20 +54,31 @@ func TestRunPull(t *testing.T) {
 	}
 	imagesMap := make(map[string][]byte)
 	imagesMap["myorg/hello:1.0.0"] = sampleImage
-	mockRegistry := test.NewMockRegistry(test.SetImages(imagesMap))
+	imagesMap["myorg/unsigned:1.0.0"] = sampleImage
+	imagesMap["myorg/tampered:1.0.0"] = sampleImage
+	imagesMap["otherorg/unsigned:1.0.0"] = sampleImage
+	trustStore, signingKey := newTrustStore(t, mockRepo, "myorg")
+	signatures := map[string][]byte{
+		"myorg/hello:1.0.0":    signImage(t, signingKey, "myorg/hello:1.0.0", sampleImage),
+		"myorg/tampered:1.0.0": signImage(t, signingKey, "myorg/tampered:1.0.0", []byte("tampered")),
+	}
+	mockRegistry := test.NewMockRegistry(test.SetImages(imagesMap), test.SetSignatures(signatures))
 	mockCli := test.NewMockCli(
 		test.SetFileSystem(mockFileSystem),
 		test.SetCredManager(mockCredManager),
 		test.SetRegistry(mockRegistry),
+		test.SetTrustStore(trustStore),
 	)
 	tests := []struct {
-		name             string
-		image            string
-		silent           bool
-		username         string
-		password         string
-		expectedToPass   bool
-		expectedErrorMsg string
+		name               string
+		image              string
+		silent             bool
+		insecureSkipVerify bool
+		allowUntrusted     bool
+		username           string
+		password           string
+		expectedToPass     bool
+		expectedErrorMsg   string
 	}{
 		{
 			name:             "pull valid image",
@@ -68,18 +90,78 @@ func TestRunPull(t *testing.T) {
 			expectedErrorMsg: "",
 		},
 		{
-			name:             "pull invalid image",
-			image:            "myorg/foo:1.0.0",
-			silent:           true,
-			username:         "alice",
-			password:         "alice123",
-			expectedToPass:   false,
-			expectedErrorMsg: "invalid cell image, zip: not a valid zip file",
+			name:               "pull invalid image",
+			image:              "myorg/foo:1.0.0",
+			silent:             true,
+			insecureSkipVerify: true,
+			username:           "alice",
+			password:           "alice123",
+			expectedToPass:     false,
+			expectedErrorMsg:   "invalid cell image, zip: not a valid zip file",
+		},
+		{
+			name:           "pull unsigned image",
+			image:          "myorg/unsigned:1.0.0",
+			silent:         true,
+			username:       "alice",
+			password:       "alice123",
+			expectedToPass: false,
+			expectedErrorMsg: "image verification failed, image myorg/unsigned:1.0.0 is not signed. " +
+				"Use --insecure-skip-verify to pull the image without verification",
+		},
+		{
+			name:               "pull unsigned image skipping verification",
+			image:              "myorg/unsigned:1.0.0",
+			silent:             true,
+			insecureSkipVerify: true,
+			username:           "alice",
+			password:           "alice123",
+			expectedToPass:     true,
+		},
+		{
+			name:           "pull unsigned image of an organization without trusted keys",
+			image:          "otherorg/unsigned:1.0.0",
+			silent:         true,
+			username:       "alice",
+			password:       "alice123",
+			expectedToPass: false,
+			expectedErrorMsg: "image verification failed, no trusted keys configured for organization otherorg. " +
+				"Trust a key for the organization in the trust section of the Cellery config or use " +
+				"--insecure-skip-verify to skip the verification",
+		},
+		{
+			name:           "pull unsigned image of an organization without trusted keys allowing untrusted organizations",
+			image:          "otherorg/unsigned:1.0.0",
+			silent:         true,
+			allowUntrusted: true,
+			username:       "alice",
+			password:       "alice123",
+			expectedToPass: true,
+		},
+		{
+			name:           "pull tampered image",
+			image:          "myorg/tampered:1.0.0",
+			silent:         true,
+			username:       "alice",
+			password:       "alice123",
+			expectedToPass: false,
+			expectedErrorMsg: fmt.Sprintf("image verification failed, image myorg/tampered:1.0.0 has been "+
+				"modified, expected digest %s but found %s. Use --insecure-skip-verify to pull the image "+
+				"without verification", digest.FromBytes([]byte("tampered")), digest.FromBytes(sampleImage)),
 		},
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunPull(mockCli, tst.image, tst.silent, tst.username, tst.password)
+			cli := mockCli
+			if tst.allowUntrusted {
+				cli = test.NewMockCli(
+					test.SetFileSystem(mockFileSystem),
+					test.SetCredManager(mockCredManager),
+					test.SetRegistry(mockRegistry),
+					test.SetTrustStore(trust.NewStore(&config.TrustConf{AllowUntrustedOrganizations: true})),
+				)
+			}
+			err := RunPull(cli, tst.image, tst.silent, tst.insecureSkipVerify, tst.username, tst.password)
 			if tst.expectedToPass {
 				if err != nil {
 					t.Errorf("error in RunPull, %v", err)
@@ -105,9 +187,41 @@ func TestPullImage(t *testing.T) {
 		t.Errorf("failed to create mock repository, %v", err)
 	}
 	mockFileSystem := test.NewMockFileSystem(test.SetRepository(mockRepo))
-	err = pullImage(test.NewMockCli(test.SetRegistry(test.NewMockRegistry()), test.SetFileSystem(mockFileSystem)),
-		parsedCellImage, "alice", "alice123")
+	tempCellImage, signature, err := pullImage(test.NewMockCli(test.SetRegistry(test.NewMockRegistry()),
+		test.SetFileSystem(mockFileSystem)), parsedCellImage, "alice", "alice123")
 	if err != nil {
 		t.Errorf("pullImage err, %v", err)
 	}
+	defer os.Remove(tempCellImage)
+	if signature != nil {
+		t.Errorf("pullImage: expected no signature for unsigned image")
+	}
+}
+
+// newTrustStore creates a trust store which trusts a newly generated key for the organization.
+func newTrustStore(t *testing.T, keyDir, organization string) (*trust.Store, crypto.Signer) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key, %v", err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("failed to marshal public key, %v", err)
+	}
+	publicKeyFile := filepath.Join(keyDir, organization+".pub")
+	if err := ioutil.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY",
+		Bytes: publicKey}), 0644); err != nil {
+		t.Fatalf("failed to write public key, %v", err)
+	}
+	return trust.NewStore(&config.TrustConf{
+		TrustedKeys: map[string][]string{organization: {publicKeyFile}},
+	}), key
+}
+
+func signImage(t *testing.T, signingKey crypto.Signer, imageName string, cellImage []byte) []byte {
+	signature, err := trust.Sign(signingKey, imageName, digest.FromBytes(cellImage))
+	if err != nil {
+		t.Fatalf("failed to sign image, %v", err)
+	}
+	return signature
 }
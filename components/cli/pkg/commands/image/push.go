@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -29,10 +30,13 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/opencontainers/go-digest"
+
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/constants"
 	"cellery.io/cellery/components/cli/pkg/image"
 	"cellery.io/cellery/components/cli/pkg/registry/credentials"
+	"cellery.io/cellery/components/cli/pkg/trust"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
@@ -41,7 +45,7 @@ import (
 func RunPush(cli cli.Cli, cellImage string, username string, password string) error {
 	parsedCellImage, err := image.ParseImageTag(cellImage)
 	//Read docker images from metadata.json
-	imageDir, err := ExtractImage(cli, parsedCellImage, false)
+	imageDir, err := ExtractImage(cli, parsedCellImage, false, false)
 	if err != nil {
 		return fmt.Errorf("error occurred while extracting image, %v", err)
 	}
@@ -201,19 +205,40 @@ func pushImage(cli cli.Cli, parsedCellImage *image.CellImage, username string, p
 			return nil
 		}()
 	}
-	cellImageFileBytes, err := ioutil.ReadAll(cellImageFile)
+	cellImageFileInfo, err := cellImageFile.Stat()
 	if err != nil {
 		return fmt.Errorf("error occurred while reading the cell image, %v", err)
 	}
-	if err := cli.ExecuteTask("Pushing cell image", "Failed to push image",
-		"", func() error {
-			err = cli.Registry().Push(parsedCellImage, cellImageFileBytes, username, password)
-			if err != nil {
-				return err
-			}
-			return nil
+	signingKey, err := cli.TrustStore().SigningKey(parsedCellImage.Organization)
+	if err != nil {
+		return err
+	}
+	if err := cli.ExecuteTaskWithProgress("Pushing cell image", "Failed to push image",
+		"", func(progress func(current, total int64)) error {
+			return cli.Registry().Push(parsedCellImage, cellImageFile, cellImageFileInfo.Size(), username, password,
+				progress)
 		}); err != nil {
 		return fmt.Errorf("error pushing image, %v", err)
 	}
-	return nil
+	if signingKey == nil {
+		util.PrintWarningMessage(fmt.Sprintf("Signing key not configured for organization %s, "+
+			"image %s pushed without a signature", parsedCellImage.Organization, imageName))
+		return nil
+	}
+	cellImageDigest, err := digest.SHA256.FromReader(io.NewSectionReader(cellImageFile, 0, cellImageFileInfo.Size()))
+	if err != nil {
+		return fmt.Errorf("error occurred while reading the cell image, %v", err)
+	}
+	signature, err := trust.Sign(signingKey, imageName, cellImageDigest)
+	if err != nil {
+		return err
+	}
+	if err := cli.ExecuteTask("Pushing cell image signature", "Failed to push image signature",
+		"", func() error {
+			return cli.Registry().PushSignature(parsedCellImage, signature, username, password)
+		}); err != nil {
+		return fmt.Errorf("error pushing image signature, %v", err)
+	}
+	// Keeping the signature in the local repository for verifying the image on run
+	return writeSignature(cli, parsedCellImage, signature)
 }
@@ -41,16 +41,31 @@ const celleryImageDirEnvVar = "CELLERY_IMAGE_DIR"
 // RunRun starts Cell instance (along with dependency instances if specified by the user)
 // This also support linking instances to parts of the dependency tree
 // This command also strictly validates whether the requested Cell (and the dependencies are valid)
+// The signature of the cell image is verified against the trust store unless insecureSkipVerify is set.
 func RunRun(cli cli.Cli, cellImageTag string, instanceName string, startDependencies bool, shareDependencies bool,
-	dependencyLinks []string, envVars []string) error {
+	dependencyLinks []string, envVars []string, insecureSkipVerify bool) error {
 	var err error
 	if err = cli.Runtime().Validate(); err != nil {
 		return fmt.Errorf("runtime validation failed. %v", err)
 	}
-	extractedImage, err := extractImage(cli, cellImageTag, instanceName, dependencyLinks, envVars)
+	if insecureSkipVerify {
+		util.PrintWarningMessage(fmt.Sprintf("Skipping signature verification of image %s", cellImageTag))
+	}
+	extractedImage, err := extractImage(cli, cellImageTag, instanceName, dependencyLinks, envVars,
+		insecureSkipVerify)
 	if err != nil {
 		return err
 	}
+	if startDependencies && !insecureSkipVerify {
+		parsedCellImage, err := image.ParseImageTag(cellImageTag)
+		if err != nil {
+			return fmt.Errorf("error occurred while parsing cell image, %v", err)
+		}
+		if err = verifyDependencies(cli, parsedCellImage.Registry, extractedImage.MainNode.MetaData,
+			extractedImage.RootNodeDependencies); err != nil {
+			return err
+		}
+	}
 
 	if err = cli.ExecuteTask(fmt.Sprintf("Starting main instance %v", util.Bold(instanceName)),
 		fmt.Sprintf("Failed to start main instance %v", util.Bold(instanceName)),
@@ -142,7 +157,9 @@ func startCellInstance(cli cli.Cli, extractedImage *ExtractedImage, instanceName
 
 // extractImage extracts the image into a temporary directory and returns the path.
 // Cleaning the path after finishing your work is your responsibility.
-func ExtractImage(cli cli.Cli, cellImage *image.CellImage, pullIfNotPresent bool) (string, error) {
+// The signature of a pulled image is verified unless insecureSkipVerify is set.
+func ExtractImage(cli cli.Cli, cellImage *image.CellImage, pullIfNotPresent bool, insecureSkipVerify bool) (string,
+	error) {
 	var err error
 	repoLocation := filepath.Join(cli.FileSystem().Repository(), cellImage.Organization,
 		cellImage.ImageName, cellImage.ImageVersion)
@@ -156,7 +173,7 @@ func ExtractImage(cli cli.Cli, cellImage *image.CellImage, pullIfNotPresent bool
 		if pullIfNotPresent {
 			cellImageTag := cellImage.Registry + "/" + cellImage.Organization + "/" + cellImage.ImageName +
 				":" + cellImage.ImageVersion
-			err = RunPull(cli, cellImageTag, true, "", "")
+			err = RunPull(cli, cellImageTag, true, insecureSkipVerify, "", "")
 			if err != nil {
 				return "", err
 			}
@@ -215,20 +232,40 @@ func runCmdArgs(instanceName string, dependencyLinks map[string]*dependencyInfo,
 }
 
 func extractImage(cli cli.Cli, cellImageTag, instanceName string, dependencyLinks []string,
-	envVars []string) (*ExtractedImage, error) {
+	envVars []string, insecureSkipVerify bool) (*ExtractedImage, error) {
 	var err error
 	var parsedCellImage *image.CellImage
 	if parsedCellImage, err = image.ParseImageTag(cellImageTag); err != nil {
 		return nil, fmt.Errorf("error occurred while parsing cell image, %v", err)
 	}
+	var imageExists bool
+	if imageExists, err = util.FileExists(filepath.Join(cli.FileSystem().Repository(), parsedCellImage.Organization,
+		parsedCellImage.ImageName, parsedCellImage.ImageVersion, parsedCellImage.ImageName+cellImageExt)); err != nil {
+		return nil, err
+	}
+	if !imageExists {
+		if err = RunPull(cli, cellImageTag, true, insecureSkipVerify, "", ""); err != nil {
+			return nil, err
+		}
+	}
+	if !insecureSkipVerify {
+		if err = verifyImage(cli, parsedCellImage); err != nil {
+			return nil, err
+		}
+	}
 	var imageDir string
 	if err = cli.ExecuteTask("Extracting cell image", "Failed to extract cell image",
 		"", func() error {
-			imageDir, err = ExtractImage(cli, parsedCellImage, true)
+			imageDir, err = ExtractImage(cli, parsedCellImage, false, insecureSkipVerify)
 			return err
 		}); err != nil {
 		return nil, err
 	}
+	// The last used time is only used to select images to prune, hence failing to record it is not fatal
+	updateIndex(cli, func(idx *image.Index) error {
+		idx.Touch(cellImageTag)
+		return nil
+	})
 	// Reading Cell Image metadata
 	var metadataFileContent []byte
 	if metadataFileContent, err = ioutil.ReadFile(filepath.Join(imageDir, artifacts, "cellery",
@@ -24,6 +24,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/image"
 )
@@ -112,7 +114,7 @@ func TestRunRun(t *testing.T) {
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
 			err := RunRun(mockCli, tst.image, tst.instance, tst.startDependencies, tst.shareDependencies,
-				tst.dependencyLinks, tst.envVars)
+				tst.dependencyLinks, tst.envVars, true)
 			if err != nil {
 				t.Errorf("error in RunRun, %v", err)
 			}
@@ -120,6 +122,53 @@ func TestRunRun(t *testing.T) {
 	}
 }
 
+func TestRunRunVerifySignature(t *testing.T) {
+	currentDir, err := ioutil.TempDir("", "current-dir")
+	if err != nil {
+		t.Errorf("failed to create current dir")
+	}
+	tempRepo, err := ioutil.TempDir("", "repo")
+	if err != nil {
+		t.Errorf("error creating temp repo, %v", err)
+	}
+	defer func() {
+		os.RemoveAll(currentDir)
+		os.RemoveAll(tempRepo)
+	}()
+	if err := copyDir(filepath.Join("testdata", "repo"), tempRepo); err != nil {
+		t.Errorf("error copying mock repo to temp repo, %v", err)
+	}
+	trustStore, signingKey := newTrustStore(t, currentDir, "myorg")
+	mockCli := test.NewMockCli(test.SetFileSystem(test.NewMockFileSystem(test.SetCurrentDir(currentDir),
+		test.SetRepository(tempRepo))),
+		test.SetBalExecutor(test.NewMockBalExecutor(test.SetBalCurrentDir(currentDir))),
+		test.SetRuntime(test.NewMockRuntime()),
+		test.SetTrustStore(trustStore))
+
+	err = RunRun(mockCli, "myorg/hello:1.0.0", "hello", false, false, nil, nil, false)
+	expected := "image verification failed, image myorg/hello:1.0.0 is not signed. " +
+		"Use --insecure-skip-verify to skip the verification"
+	if err == nil {
+		t.Fatalf("RunRun: expected unsigned image to be refused")
+	}
+	if diff := cmp.Diff(expected, err.Error()); diff != "" {
+		t.Errorf("RunRun: unexpected error (-want, +got)\n%v", diff)
+	}
+
+	cellImage, err := ioutil.ReadFile(filepath.Join(tempRepo, "myorg", "hello", "1.0.0", "hello.zip"))
+	if err != nil {
+		t.Fatalf("error reading sample image file, %v", err)
+	}
+	err = ioutil.WriteFile(filepath.Join(tempRepo, "myorg", "hello", "1.0.0", "hello.sig"),
+		signImage(t, signingKey, "myorg/hello:1.0.0", cellImage), 0644)
+	if err != nil {
+		t.Fatalf("error writing signature, %v", err)
+	}
+	if err = RunRun(mockCli, "myorg/hello:1.0.0", "hello", false, false, nil, nil, false); err != nil {
+		t.Errorf("error in RunRun with signed image, %v", err)
+	}
+}
+
 func TestStartCellInstance(t *testing.T) {
 	mockBalExecutor := test.NewMockBalExecutor()
 	mockCli := test.NewMockCli(test.SetBalExecutor(mockBalExecutor))
@@ -163,3 +212,62 @@ func TestStartCellInstance(t *testing.T) {
 		t.Errorf("startCellInstance failed: %v", err)
 	}
 }
+
+func TestRunRunVerifyDependencies(t *testing.T) {
+	currentDir, err := ioutil.TempDir("", "current-dir")
+	if err != nil {
+		t.Errorf("failed to create current dir")
+	}
+	tempRepo, err := ioutil.TempDir("", "repo")
+	if err != nil {
+		t.Errorf("error creating temp repo, %v", err)
+	}
+	defer func() {
+		os.RemoveAll(currentDir)
+		os.RemoveAll(tempRepo)
+	}()
+	if err := copyDir(filepath.Join("testdata", "repo"), tempRepo); err != nil {
+		t.Errorf("error copying mock repo to temp repo, %v", err)
+	}
+	trustStore, signingKey := newTrustStore(t, currentDir, "myorg")
+	mockCli := test.NewMockCli(test.SetFileSystem(test.NewMockFileSystem(test.SetCurrentDir(currentDir),
+		test.SetRepository(tempRepo))),
+		test.SetBalExecutor(test.NewMockBalExecutor(test.SetBalCurrentDir(currentDir))),
+		test.SetRuntime(test.NewMockRuntime()),
+		test.SetTrustStore(trustStore))
+	sign := func(name string) {
+		imageDir := filepath.Join(tempRepo, "myorg", name, "1.0.0")
+		cellImage, err := ioutil.ReadFile(filepath.Join(imageDir, name+".zip"))
+		if err != nil {
+			t.Fatalf("error reading sample image file, %v", err)
+		}
+		err = ioutil.WriteFile(filepath.Join(imageDir, name+".sig"),
+			signImage(t, signingKey, "myorg/"+name+":1.0.0", cellImage), 0644)
+		if err != nil {
+			t.Fatalf("error writing signature, %v", err)
+		}
+	}
+	sign("hr")
+	// The employee dependency is linked to a running instance, hence only the stock dependency is started
+	links := []string{"employeeCellDep:employee"}
+
+	if err = RunRun(mockCli, "myorg/hr:1.0.0", "hr", false, false, links, nil, false); err != nil {
+		t.Errorf("error in RunRun without starting dependencies, %v", err)
+	}
+	err = RunRun(mockCli, "myorg/hr:1.0.0", "hr", true, false, links, nil, false)
+	expected := "image verification failed, image myorg/stock:1.0.0 is not signed. " +
+		"Use --insecure-skip-verify to skip the verification"
+	if err == nil {
+		t.Fatalf("RunRun: expected unsigned dependency to be refused")
+	}
+	if diff := cmp.Diff(expected, err.Error()); diff != "" {
+		t.Errorf("RunRun: unexpected error (-want, +got)\n%v", diff)
+	}
+	if err = RunRun(mockCli, "myorg/hr:1.0.0", "hr", true, false, links, nil, true); err != nil {
+		t.Errorf("error in RunRun skipping verification, %v", err)
+	}
+	sign("stock")
+	if err = RunRun(mockCli, "myorg/hr:1.0.0", "hr", true, false, links, nil, false); err != nil {
+		t.Errorf("error in RunRun with signed dependencies, %v", err)
+	}
+}
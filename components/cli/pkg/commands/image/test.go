@@ -41,7 +41,8 @@ const Logs = "logs"
 // RunTest starts Cell instance (along with dependency instances if specified by the user)\
 func RunTest(cli cli.Cli, cellImageTag string, instanceName string, startDependencies bool, shareDependencies bool,
 	dependencyLinks []string, envVars []string, assumeYes bool, debug bool, verbose bool, disableTelepresence bool, incell bool, projLocation string) error {
-	extractedImage, err := extractImage(cli, cellImageTag, instanceName, dependencyLinks, envVars)
+	// Tests are run against images under development, hence the signature is not verified
+	extractedImage, err := extractImage(cli, cellImageTag, instanceName, dependencyLinks, envVars, true)
 	if err != nil {
 		return err
 	}
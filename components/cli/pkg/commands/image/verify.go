@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// signatureFile returns the location of the signature of a cell image in the local repository.
+func signatureFile(cli cli.Cli, parsedCellImage *image.CellImage) string {
+	return filepath.Join(cli.FileSystem().Repository(), parsedCellImage.Organization, parsedCellImage.ImageName,
+		parsedCellImage.ImageVersion, parsedCellImage.ImageName+cellImageSignatureExt)
+}
+
+// writeSignature saves the signature of a cell image next to the cell image in the local repository.
+func writeSignature(cli cli.Cli, parsedCellImage *image.CellImage, signature []byte) error {
+	if err := ioutil.WriteFile(signatureFile(cli, parsedCellImage), signature, 0644); err != nil {
+		return fmt.Errorf("error occurred while saving image signature to local repo, %v", err)
+	}
+	return nil
+}
+
+// isVerificationRequired checks whether the cell image needs to be verified using the trust store. A warning is
+// printed if the image is not verified since untrusted organizations are allowed in the trust configuration.
+func isVerificationRequired(cli cli.Cli, parsedCellImage *image.CellImage) (bool, error) {
+	required, err := cli.TrustStore().IsVerificationRequired(parsedCellImage.Organization)
+	if err != nil {
+		return false, fmt.Errorf("image verification failed, %v", err)
+	}
+	if !required {
+		util.PrintWarningMessage(fmt.Sprintf("Signature of image %s is NOT verified since no keys are trusted for "+
+			"organization %s and untrusted organizations are allowed", imageTag(parsedCellImage),
+			parsedCellImage.Organization))
+	}
+	return required, nil
+}
+
+// verifyImage verifies the cell image in the local repository against its signature using the trust store.
+func verifyImage(cli cli.Cli, parsedCellImage *image.CellImage) error {
+	if required, err := isVerificationRequired(cli, parsedCellImage); err != nil || !required {
+		return err
+	}
+	signature, err := ioutil.ReadFile(signatureFile(cli, parsedCellImage))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error occurred while reading image signature, %v", err)
+	}
+	cellImageFile := filepath.Join(cli.FileSystem().Repository(), parsedCellImage.Organization,
+		parsedCellImage.ImageName, parsedCellImage.ImageVersion, parsedCellImage.ImageName+cellImageExt)
+	if err := cli.TrustStore().VerifyImage(parsedCellImage, cellImageFile, signature); err != nil {
+		return fmt.Errorf("image verification failed, %v. Use --insecure-skip-verify to skip the verification",
+			err)
+	}
+	return nil
+}
+
+// verifyDependencies verifies the images of the dependencies started along with an instance. Dependencies linked
+// to running instances are not started, hence they are not verified. Dependencies which are not in the local
+// repository are pulled from the registry of the main image.
+func verifyDependencies(cli cli.Cli, registry string, metadata *image.MetaData,
+	linkedDependencies map[string]*dependencyInfo) error {
+	for _, component := range metadata.Components {
+		if component.Dependencies == nil {
+			continue
+		}
+		for _, dependencies := range []map[string]*image.MetaData{component.Dependencies.Cells,
+			component.Dependencies.Composites} {
+			for alias, dependency := range dependencies {
+				if _, isLinked := linkedDependencies[alias]; isLinked {
+					continue
+				}
+				if err := verifyDependency(cli, registry, dependency); err != nil {
+					return err
+				}
+				if err := verifyDependencies(cli, registry, dependency, nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func verifyDependency(cli cli.Cli, registry string, dependency *image.MetaData) error {
+	parsedCellImage := &image.CellImage{
+		Registry:     registry,
+		Organization: dependency.Organization,
+		ImageName:    dependency.Name,
+		ImageVersion: dependency.Version,
+	}
+	imageExists, err := util.FileExists(filepath.Join(cli.FileSystem().Repository(), parsedCellImage.Organization,
+		parsedCellImage.ImageName, parsedCellImage.ImageVersion, parsedCellImage.ImageName+cellImageExt))
+	if err != nil {
+		return err
+	}
+	if !imageExists {
+		// The dependency is verified while pulling
+		return RunPull(cli, imageTag(parsedCellImage), true, false, "", "")
+	}
+	return verifyImage(cli, parsedCellImage)
+}
+
+// imageTag returns the tag of the cell image including the registry.
+func imageTag(parsedCellImage *image.CellImage) string {
+	tag := fmt.Sprintf("%s/%s:%s", parsedCellImage.Organization, parsedCellImage.ImageName,
+		parsedCellImage.ImageVersion)
+	if parsedCellImage.Registry != "" {
+		tag = parsedCellImage.Registry + "/" + tag
+	}
+	return tag
+}
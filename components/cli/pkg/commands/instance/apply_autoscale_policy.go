@@ -22,22 +22,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/mattbaird/jsonpatch"
 
 	"cellery.io/cellery/components/cli/cli"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/policies"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 func RunApplyAutoscalePolicies(cli cli.Cli, kind kubernetes.InstanceKind, instance string, file string) error {
 	var err error
 	var originalData, desiredData []byte
+	var schedules []kubernetes.ScalingSchedule
 	ik := string(kind)
 	if err = cli.ExecuteTask("Preparing autoscale policy data to apply", "Failed to prepare patch",
 		"", func() error {
-			originalData, desiredData, err = createPatch(cli, kind, instance, file)
+			originalData, desiredData, schedules, err = createPatch(cli, kind, instance, file)
 			return err
 		}); err != nil {
 		return fmt.Errorf("failed to create patch, %v", err)
@@ -46,49 +49,65 @@ func RunApplyAutoscalePolicies(cli cli.Cli, kind kubernetes.InstanceKind, instan
 	if err != nil {
 		return fmt.Errorf("failed to create patch, %v", err)
 	}
+	currentSchedules, err := getScalingSchedules(cli, instance)
+	if err != nil {
+		return err
+	}
+	schedulesChanged := scalingSchedulesChanged(currentSchedules, schedules)
 
-	if len(patch) == 0 {
+	if len(patch) == 0 && !schedulesChanged {
 		util.PrintSuccessMessage(fmt.Sprintf("Nothing to apply. Scaling policies for %q matches with policy file %q", instance, file))
 		return nil
 	}
 
-	patchBytes, err := json.Marshal(patch)
-	if err != nil {
-		return fmt.Errorf("failed to marshall patch, %v", err)
+	if len(patch) > 0 {
+		patchBytes, err := json.Marshal(patch)
+		if err != nil {
+			return fmt.Errorf("failed to marshall patch, %v", err)
+		}
+		if err = cli.ExecuteTask("Applying autoscale policies", "Failed to apply autoscale policies",
+			"", func() error {
+				err = cli.KubeCli().JsonPatch(ik, instance, string(patchBytes))
+				return err
+			}); err != nil {
+			return fmt.Errorf("failed to apply patch, %v", err)
+		}
 	}
-	if err = cli.ExecuteTask("Applying autoscale policies", "Failed to apply autoscale policies",
-		"", func() error {
-			err = cli.KubeCli().JsonPatch(ik, instance, string(patchBytes))
-			return err
-		}); err != nil {
-		return fmt.Errorf("failed to apply patch, %v", err)
+	if schedulesChanged {
+		if err = cli.ExecuteTask("Applying scaling schedules", "Failed to apply scaling schedules",
+			"", func() error {
+				return applyScalingSchedules(cli, kind, instance, currentSchedules, schedules)
+			}); err != nil {
+			return fmt.Errorf("failed to apply scaling schedules, %v", err)
+		}
 	}
 	util.PrintSuccessMessage(fmt.Sprintf("Successfully applied autoscale policies for instance %q", instance))
 	return nil
 }
 
-func createPatch(cli cli.Cli, kind kubernetes.InstanceKind, instance string, file string) ([]byte, []byte, error) {
+// createPatch returns the scale resource of the instance before and after applying the policy file, along with the
+// scaling schedules of the file. The replicas of the schedule which ran the latest are applied right away.
+func createPatch(cli cli.Cli, kind kubernetes.InstanceKind, instance string, file string) ([]byte, []byte,
+	[]kubernetes.ScalingSchedule, error) {
 	var originalData, desiredData []byte
 	fileData, err := ioutil.ReadFile(file)
 	if err != nil {
-		return originalData, desiredData, fmt.Errorf("error reading file %s, %v", file, err)
+		return originalData, desiredData, nil, fmt.Errorf("error reading file %s, %v", file, err)
 	}
 	newScalePolicy := &kubernetes.AutoScalingPolicy{}
 	if err = yaml.Unmarshal(fileData, &newScalePolicy); err != nil {
-		return originalData, desiredData, fmt.Errorf("failed to unmarshall data in file %s, %v", file, err)
+		return originalData, desiredData, nil, fmt.Errorf("failed to unmarshall data in file %s, %v", file, err)
 	}
-	ik := string(kind)
-	instanceData, err := cli.KubeCli().GetInstanceBytes(ik, instance)
+	originalResource, err := getScaleResource(cli, kind, instance)
 	if err != nil {
-		return originalData, desiredData, fmt.Errorf("failed to get instance bytes of instance %s, %v", instance, err)
+		return originalData, desiredData, nil, err
 	}
-	originalResource := &kubernetes.ScaleResource{}
-	if err = json.Unmarshal(instanceData, originalResource); err != nil {
-		return originalData, desiredData, fmt.Errorf("failed to unmarshall instance data of instance %s, %v", instance, err)
+	if err = validateAutoscalePolicy(cli, fileData, file, kind, originalResource); err != nil {
+		return originalData, desiredData, nil, err
 	}
 	originalData, err = json.Marshal(originalResource)
 	if err != nil {
-		return originalData, desiredData, fmt.Errorf("failed to marshall original data, %v", err)
+		return originalData, desiredData, nil, fmt.Errorf("failed to marshall original data, %v", err)
 	}
 	// we are modifying the original resource here as we already Marshal the required data
 	desiredResource := originalResource
@@ -97,10 +116,13 @@ func createPatch(cli cli.Cli, kind kubernetes.InstanceKind, instance string, fil
 			if spComponent.Name == originalResource.Spec.Components[i].Metadata.Name {
 				overridable, err := isOverridable(originalResource.Spec.Components[i].Spec.ScalingPolicy)
 				if err != nil {
-					return originalData, desiredData, err
+					return originalData, desiredData, nil, err
 				}
 				if overridable {
 					desiredResource.Spec.Components[i].Spec.ScalingPolicy = spComponent.ScalingPolicy
+					// the tuning of the kpa is passed to Knative as annotations of the component
+					desiredResource.Spec.Components[i].Metadata.Annotations = policies.GetKnativeAutoscalingAnnotations(
+						originalResource.Spec.Components[i].Metadata.Annotations, spComponent.ScalingPolicy)
 				}
 			}
 		}
@@ -108,18 +130,22 @@ func createPatch(cli cli.Cli, kind kubernetes.InstanceKind, instance string, fil
 	if kind == kubernetes.InstanceKindCell && newScalePolicy.Gateway.ScalingPolicy != nil {
 		overridable, err := isOverridable(originalResource.Spec.Gateway.Spec.ScalingPolicy)
 		if err != nil {
-			return originalData, desiredData, err
+			return originalData, desiredData, nil, err
 		}
 		if overridable {
 			desiredResource.Spec.Gateway.Spec.ScalingPolicy = newScalePolicy.Gateway.ScalingPolicy
 		}
 	}
 
+	if schedule, _ := getActiveScalingSchedule(newScalePolicy.Schedules, time.Now().UTC()); schedule != nil {
+		applyScheduledReplicas(desiredResource, *schedule)
+	}
+
 	desiredData, err = json.Marshal(desiredResource)
 	if err != nil {
-		return originalData, desiredData, fmt.Errorf("failed to marshall desired resource, %v", err)
+		return originalData, desiredData, nil, fmt.Errorf("failed to marshall desired resource, %v", err)
 	}
-	return originalData, desiredData, nil
+	return originalData, desiredData, newScalePolicy.Schedules, nil
 }
 
 func isOverridable(o interface{}) (bool, error) {
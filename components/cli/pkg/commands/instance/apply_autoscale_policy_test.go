@@ -19,10 +19,14 @@
 package instance
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"cellery.io/cellery/components/cli/internal/test"
 )
 
@@ -33,22 +37,94 @@ func TestRunApplyAutoscalePolicies(t *testing.T) {
 	}
 	cellMap := make(map[string][]byte)
 	cellMap["pet-be-auto"] = petBeAutoCell
-	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCellsAsBytes(cellMap))))
 	tests := []struct {
-		name     string
-		instance string
+		name        string
+		instance    string
+		file        string
+		apiServices []string
+		wantErr     string
 	}{
 		{
 			name:     "apply autoscale policy",
 			instance: "pet-be-auto",
+			file:     "myscalepolicy.yaml",
+		},
+		{
+			name:        "apply custom and external metrics",
+			instance:    "pet-be-auto",
+			file:        "custom-metrics-policy.yaml",
+			apiServices: []string{"v1beta1.custom.metrics.k8s.io", "v1beta1.external.metrics.k8s.io"},
+		},
+		{
+			name:        "external metrics api not available",
+			instance:    "pet-be-auto",
+			file:        "custom-metrics-policy.yaml",
+			apiServices: []string{"v1beta1.custom.metrics.k8s.io"},
+			wantErr: "line 15: components[0].scalingPolicy.hpa.metrics[1].type: External metrics are served by " +
+				"the external.metrics.k8s.io API, which is not available in the cluster",
 		},
 	}
 	for _, testIteration := range tests {
 		t.Run(testIteration.name, func(t *testing.T) {
-			err := RunApplyAutoscalePolicies(mockCli, celleryInstance, testIteration.instance, filepath.Join("testdata", "policies", "autoscale", "myscalepolicy.yaml"))
+			mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+				test.WithAvailableApiServices(testIteration.apiServices...))))
+			err := RunApplyAutoscalePolicies(mockCli, celleryInstance, testIteration.instance,
+				filepath.Join("testdata", "policies", "autoscale", testIteration.file))
+			if testIteration.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), testIteration.wantErr) {
+					t.Errorf("expected error %s, got %v", testIteration.wantErr, err)
+				}
+				return
+			}
 			if err != nil {
 				t.Errorf("error in RunApplyAutoscalePolicies, %v", err)
 			}
 		})
 	}
 }
+
+func TestRunApplyAutoscalePoliciesKnativeTuning(t *testing.T) {
+	petBeAutoCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-be-auto.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock cell file")
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(map[string][]byte{"pet-be-auto": petBeAutoCell}))
+	err = RunApplyAutoscalePolicies(test.NewMockCli(test.SetKubeCli(mockKubeCli)), celleryInstance, "pet-be-auto",
+		filepath.Join("testdata", "policies", "autoscale", "kpa-tuning-policy.yaml"))
+	if err != nil {
+		t.Fatalf("error in RunApplyAutoscalePolicies, %v", err)
+	}
+	patches := mockKubeCli.JsonPatches()
+	if len(patches) != 1 {
+		t.Fatalf("expected a patch, got %v", patches)
+	}
+	var operations []struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}
+	if err = json.Unmarshal([]byte(patches[0]), &operations); err != nil {
+		t.Fatalf("error parsing patch, %v", err)
+	}
+	annotations := map[string]interface{}{}
+	for _, operation := range operations {
+		switch {
+		case operation.Path == "/spec/components/2/metadata/annotations":
+			for key, value := range operation.Value.(map[string]interface{}) {
+				annotations[key] = value
+			}
+		case strings.HasPrefix(operation.Path, "/spec/components/2/metadata/annotations/"):
+			key := strings.Replace(strings.TrimPrefix(operation.Path, "/spec/components/2/metadata/annotations/"),
+				"~1", "/", -1)
+			annotations[key] = operation.Value
+		}
+	}
+	want := map[string]interface{}{
+		"autoscaling.knative.dev/targetUtilizationPercentage":        "70",
+		"autoscaling.knative.dev/window":                             "60s",
+		"autoscaling.knative.dev/scale-to-zero-pod-retention-period": "30s",
+	}
+	if diff := cmp.Diff(want, annotations); diff != "" {
+		t.Errorf("invalid knative autoscaling annotations (-want, +got)\n%v", diff)
+	}
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/policies"
+)
+
+// scalingSchedulerImage runs the kubectl patches of the scaling schedules.
+const scalingSchedulerImage = "bitnami/kubectl:1.17"
+const scalingSchedulesKey = "schedules"
+const scalingSchedulesLabel = "mesh.cellery.io/scaling-schedules-of"
+
+// maxCronJobNameLength is the length of the cron job names, leaving room for the suffix of the created jobs.
+const maxCronJobNameLength = 52
+
+func getScalingSchedulesName(instance string) string {
+	return fmt.Sprintf("%s--scaling-schedules", instance)
+}
+
+func getScalingSchedulerName(instance string) string {
+	return fmt.Sprintf("%s--scaling-scheduler", instance)
+}
+
+func getScalingScheduleJobName(instance string, schedule string) string {
+	return fmt.Sprintf("%s--%s-scaling", instance, schedule)
+}
+
+// getScalingSchedules returns the scaling schedules applied to the instance, which are kept in a config map.
+func getScalingSchedules(cli cli.Cli, instance string) ([]kubernetes.ScalingSchedule, error) {
+	content, err := cli.KubeCli().GetInstanceBytes("configmaps", getScalingSchedulesName(instance))
+	if err != nil && !isNotFoundError(err) {
+		return nil, fmt.Errorf("error getting scaling schedules of instance %s, %v", instance, err)
+	}
+	if len(content) == 0 {
+		return nil, nil
+	}
+	configMap := struct {
+		Data map[string]string `json:"data"`
+	}{}
+	if err := json.Unmarshal(content, &configMap); err != nil {
+		return nil, fmt.Errorf("error parsing scaling schedules of instance %s, %v", instance, err)
+	}
+	var schedules []kubernetes.ScalingSchedule
+	if err := yaml.Unmarshal([]byte(configMap.Data[scalingSchedulesKey]), &schedules); err != nil {
+		return nil, fmt.Errorf("error parsing scaling schedules of instance %s, %v", instance, err)
+	}
+	return schedules, nil
+}
+
+func scalingSchedulesChanged(current []kubernetes.ScalingSchedule, schedules []kubernetes.ScalingSchedule) bool {
+	if len(current) == 0 && len(schedules) == 0 {
+		return false
+	}
+	return !reflect.DeepEqual(current, schedules)
+}
+
+// applyScalingSchedules creates a cron job for each schedule, which patches the replicas of the autoscalers of the
+// instance at the scheduled times, and deletes the cron jobs of the current schedules which are removed.
+func applyScalingSchedules(cli cli.Cli, kind kubernetes.InstanceKind, instance string,
+	current []kubernetes.ScalingSchedule, schedules []kubernetes.ScalingSchedule) error {
+	if len(schedules) > 0 {
+		scaleResource, err := getScaleResource(cli, kind, instance)
+		if err != nil {
+			return err
+		}
+		objects := buildScalingSchedulerObjects(kind, instance)
+		for _, schedule := range schedules {
+			job, err := buildScalingScheduleJob(kind, instance, schedule, scaleResource)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, job)
+		}
+		schedulesYaml, err := yaml.Marshal(schedules)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   scalingScheduleMetadata(getScalingSchedulesName(instance), instance),
+			"data": map[string]interface{}{
+				scalingSchedulesKey: string(schedulesYaml),
+			},
+		})
+		if err := applyObjects(cli, objects); err != nil {
+			return fmt.Errorf("error applying scaling schedules, %v", err)
+		}
+	}
+	var removed []string
+	for _, schedule := range current {
+		if findScalingSchedule(schedules, schedule.Name) == nil {
+			removed = append(removed, "cronjobs/"+getScalingScheduleJobName(instance, schedule.Name))
+		}
+	}
+	if len(schedules) == 0 {
+		schedulerName := getScalingSchedulerName(instance)
+		removed = append(removed, "configmaps/"+getScalingSchedulesName(instance),
+			"rolebindings.rbac.authorization.k8s.io/"+schedulerName, "roles.rbac.authorization.k8s.io/"+schedulerName,
+			"serviceaccounts/"+schedulerName)
+	}
+	for _, resource := range removed {
+		parts := strings.SplitN(resource, "/", 2)
+		if _, err := cli.KubeCli().DeleteResource(parts[0], parts[1]); err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("error deleting %s, %v", resource, err)
+		}
+	}
+	return nil
+}
+
+// buildScalingSchedulerObjects returns the service account of the cron jobs, which is only allowed to patch the
+// instance.
+func buildScalingSchedulerObjects(kind kubernetes.InstanceKind, instance string) []map[string]interface{} {
+	name := getScalingSchedulerName(instance)
+	resource := strings.SplitN(string(kind), ".", 2)
+	return []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "ServiceAccount",
+			"metadata":   scalingScheduleMetadata(name, instance),
+		},
+		{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "Role",
+			"metadata":   scalingScheduleMetadata(name, instance),
+			"rules": []interface{}{
+				map[string]interface{}{
+					"apiGroups":     []interface{}{resource[1]},
+					"resources":     []interface{}{resource[0]},
+					"resourceNames": []interface{}{instance},
+					"verbs":         []interface{}{"get", "patch"},
+				},
+			},
+		},
+		{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "RoleBinding",
+			"metadata":   scalingScheduleMetadata(name, instance),
+			"roleRef": map[string]interface{}{
+				"apiGroup": "rbac.authorization.k8s.io",
+				"kind":     "Role",
+				"name":     name,
+			},
+			"subjects": []interface{}{
+				map[string]interface{}{
+					"kind": "ServiceAccount",
+					"name": name,
+				},
+			},
+		},
+	}
+}
+
+// buildScalingScheduleJob returns the cron job patching the instance with the replicas of the schedule.
+func buildScalingScheduleJob(kind kubernetes.InstanceKind, instance string, schedule kubernetes.ScalingSchedule,
+	scaleResource *kubernetes.ScaleResource) (map[string]interface{}, error) {
+	name := getScalingScheduleJobName(instance, schedule.Name)
+	if len(name) > maxCronJobNameLength {
+		return nil, fmt.Errorf("name %s of the cron job of schedule %s is longer than %d characters, use a "+
+			"shorter schedule name", name, schedule.Name, maxCronJobNameLength)
+	}
+	patch, err := buildScalingSchedulePatch(schedule, scaleResource)
+	if err != nil {
+		return nil, err
+	}
+	patchJson, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"apiVersion": "batch/v1beta1",
+		"kind":       "CronJob",
+		"metadata":   scalingScheduleMetadata(name, instance),
+		"spec": map[string]interface{}{
+			"schedule": schedule.Schedule,
+			// a delayed run is skipped if the next schedule is due
+			"concurrencyPolicy":          "Replace",
+			"startingDeadlineSeconds":    300,
+			"successfulJobsHistoryLimit": 1,
+			"failedJobsHistoryLimit":     1,
+			"jobTemplate": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"backoffLimit": 3,
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"serviceAccountName": getScalingSchedulerName(instance),
+							"restartPolicy":      "OnFailure",
+							"containers": []interface{}{
+								map[string]interface{}{
+									"name":  "scaling-scheduler",
+									"image": scalingSchedulerImage,
+									"command": []interface{}{"kubectl", "patch", string(kind), instance,
+										"--type=json", "-p", string(patchJson)},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// buildScalingSchedulePatch returns the json patch overriding the replicas of the autoscalers. The name of each
+// component is tested so that the patch fails instead of scaling another component if the components are
+// reordered by an update of the instance.
+func buildScalingSchedulePatch(schedule kubernetes.ScalingSchedule,
+	scaleResource *kubernetes.ScaleResource) ([]map[string]interface{}, error) {
+	var patch []map[string]interface{}
+	for _, replicas := range schedule.Components {
+		index := -1
+		for i, component := range scaleResource.Spec.Components {
+			if component.Metadata.Name == replicas.Name {
+				index = i
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("component %s of schedule %s not found in the instance", replicas.Name,
+				schedule.Name)
+		}
+		path := fmt.Sprintf("/spec/components/%d", index)
+		patch = append(patch, map[string]interface{}{"op": "test", "path": path + "/metadata/name",
+			"value": replicas.Name})
+		autoscalerPatch, err := buildScheduledReplicasPatch(path+"/spec/scalingPolicy", replicas,
+			scaleResource.Spec.Components[index].Spec.ScalingPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("error scheduling replicas of component %s, %v", replicas.Name, err)
+		}
+		patch = append(patch, autoscalerPatch...)
+	}
+	if schedule.Gateway != nil {
+		autoscalerPatch, err := buildScheduledReplicasPatch("/spec/gateway/spec/scalingPolicy", *schedule.Gateway,
+			scaleResource.Spec.Gateway.Spec.ScalingPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("error scheduling replicas of gateway, %v", err)
+		}
+		patch = append(patch, autoscalerPatch...)
+	}
+	return patch, nil
+}
+
+func buildScheduledReplicasPatch(path string, replicas kubernetes.ScheduledReplicas,
+	scalingPolicy interface{}) ([]map[string]interface{}, error) {
+	autoscalerType, _ := policies.GetAutoscaler(scalingPolicy)
+	if autoscalerType == "" {
+		return nil, fmt.Errorf("the scaling policy does not have an hpa or kpa")
+	}
+	var patch []map[string]interface{}
+	if replicas.MinReplicas != nil {
+		patch = append(patch, map[string]interface{}{"op": "add",
+			"path": fmt.Sprintf("%s/%s/minReplicas", path, autoscalerType), "value": *replicas.MinReplicas})
+	}
+	if replicas.MaxReplicas != nil {
+		patch = append(patch, map[string]interface{}{"op": "add",
+			"path": fmt.Sprintf("%s/%s/maxReplicas", path, autoscalerType), "value": *replicas.MaxReplicas})
+	}
+	return patch, nil
+}
+
+// applyScheduledReplicas overrides the replicas of the autoscalers of the scale resource with those of the schedule.
+func applyScheduledReplicas(scaleResource *kubernetes.ScaleResource, schedule kubernetes.ScalingSchedule) {
+	override := func(scalingPolicy interface{}, replicas kubernetes.ScheduledReplicas) {
+		_, autoscaler := policies.GetAutoscaler(scalingPolicy)
+		if autoscaler == nil {
+			return
+		}
+		if replicas.MinReplicas != nil {
+			autoscaler["minReplicas"] = *replicas.MinReplicas
+		}
+		if replicas.MaxReplicas != nil {
+			autoscaler["maxReplicas"] = *replicas.MaxReplicas
+		}
+	}
+	for _, replicas := range schedule.Components {
+		for _, component := range scaleResource.Spec.Components {
+			if component.Metadata.Name == replicas.Name {
+				override(component.Spec.ScalingPolicy, replicas)
+			}
+		}
+	}
+	if schedule.Gateway != nil {
+		override(scaleResource.Spec.Gateway.Spec.ScalingPolicy, *schedule.Gateway)
+	}
+}
+
+// getActiveScalingSchedule returns the schedule which ran the latest at or before the given time, along with the
+// time it ran. Nil is returned if none of the schedules ran.
+func getActiveScalingSchedule(schedules []kubernetes.ScalingSchedule, now time.Time) (*kubernetes.ScalingSchedule,
+	time.Time) {
+	var active *kubernetes.ScalingSchedule
+	var since time.Time
+	for i := range schedules {
+		cronSchedule, err := policies.ParseCronSchedule(schedules[i].Schedule)
+		if err != nil {
+			continue
+		}
+		if ran, ok := cronSchedule.Previous(now); ok && (active == nil || ran.After(since)) {
+			active, since = &schedules[i], ran
+		}
+	}
+	return active, since
+}
+
+func findScalingSchedule(schedules []kubernetes.ScalingSchedule, name string) *kubernetes.ScalingSchedule {
+	for i := range schedules {
+		if schedules[i].Name == name {
+			return &schedules[i]
+		}
+	}
+	return nil
+}
+
+func scalingScheduleMetadata(name string, instance string) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"labels": map[string]interface{}{
+			scalingSchedulesLabel: instance,
+		},
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+)
+
+func TestScalingSchedules(t *testing.T) {
+	petBeAutoCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-be-auto.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock cell file")
+	}
+	cellMap := make(map[string][]byte)
+	cellMap["pet-be-auto"] = petBeAutoCell
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap))
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+
+	err = RunApplyAutoscalePolicies(mockCli, celleryInstance, "pet-be-auto",
+		filepath.Join("testdata", "policies", "autoscale", "scheduled-policy.yaml"))
+	if err != nil {
+		t.Fatalf("error in RunApplyAutoscalePolicies, %v", err)
+	}
+	jobJson, err := mockKubeCli.GetInstanceBytes("cronjobs", "pet-be-auto--office-hours-scaling")
+	if err != nil || len(jobJson) == 0 {
+		t.Fatalf("cron job of the office-hours schedule not applied")
+	}
+	job := struct {
+		Spec struct {
+			Schedule    string `json:"schedule"`
+			JobTemplate struct {
+				Spec struct {
+					Template struct {
+						Spec struct {
+							ServiceAccountName string `json:"serviceAccountName"`
+							Containers         []struct {
+								Command []string `json:"command"`
+							} `json:"containers"`
+						} `json:"spec"`
+					} `json:"template"`
+				} `json:"spec"`
+			} `json:"jobTemplate"`
+		} `json:"spec"`
+	}{}
+	if err := json.Unmarshal(jobJson, &job); err != nil {
+		t.Fatalf("error parsing cron job, %v", err)
+	}
+	podSpec := job.Spec.JobTemplate.Spec.Template.Spec
+	if job.Spec.Schedule != "0 8 * * mon-fri" || podSpec.ServiceAccountName != "pet-be-auto--scaling-scheduler" {
+		t.Errorf("unexpected cron job %s", string(jobJson))
+	}
+	command := podSpec.Containers[0].Command
+	wantCommand := []string{"kubectl", "patch", "cells.mesh.cellery.io", "pet-be-auto", "--type=json", "-p"}
+	if diff := cmp.Diff(wantCommand, command[:len(command)-1]); diff != "" {
+		t.Errorf("unexpected patch command (-want, +got)\n%v", diff)
+	}
+	var patch []map[string]interface{}
+	if err := json.Unmarshal([]byte(command[len(command)-1]), &patch); err != nil {
+		t.Fatalf("error parsing patch, %v", err)
+	}
+	wantPatch := []map[string]interface{}{
+		{"op": "test", "path": "/spec/components/0/metadata/name", "value": "controller"},
+		{"op": "add", "path": "/spec/components/0/spec/scalingPolicy/hpa/minReplicas", "value": float64(3)},
+		{"op": "add", "path": "/spec/components/0/spec/scalingPolicy/hpa/maxReplicas", "value": float64(10)},
+	}
+	if diff := cmp.Diff(wantPatch, patch); diff != "" {
+		t.Errorf("unexpected patch (-want, +got)\n%v", diff)
+	}
+
+	outputFile, err := ioutil.TempFile("", "exportpolicy*.yaml")
+	if err != nil {
+		t.Fatalf("failed create yaml file to export to")
+	}
+	defer os.Remove(outputFile.Name())
+	if err := RunExportAutoscalePolicies(mockCli, celleryInstance, "pet-be-auto", outputFile.Name()); err != nil {
+		t.Fatalf("error in RunExportAutoscalePolicies, %v", err)
+	}
+	exported, err := ioutil.ReadFile(outputFile.Name())
+	if err != nil {
+		t.Fatalf("error reading exported policy, %v", err)
+	}
+	if !strings.Contains(string(exported), "schedule: 0 18 * * mon-fri") {
+		t.Errorf("schedules not exported, %s", string(exported))
+	}
+
+	// the schedules are removed once a policy without schedules is applied, even if a cron job is already deleted
+	if _, err := mockKubeCli.DeleteResource("cronjobs", "pet-be-auto--after-hours-scaling"); err != nil {
+		t.Fatalf("error deleting cron job, %v", err)
+	}
+	err = RunApplyAutoscalePolicies(mockCli, celleryInstance, "pet-be-auto",
+		filepath.Join("testdata", "policies", "autoscale", "myscalepolicy.yaml"))
+	if err != nil {
+		t.Fatalf("error in RunApplyAutoscalePolicies, %v", err)
+	}
+	for _, name := range []string{"pet-be-auto--office-hours-scaling", "pet-be-auto--after-hours-scaling"} {
+		if jobJson, _ := mockKubeCli.GetInstanceBytes("cronjobs", name); len(jobJson) > 0 {
+			t.Errorf("cron job %s not deleted", name)
+		}
+	}
+	if schedules, _ := getScalingSchedules(mockCli, "pet-be-auto"); len(schedules) > 0 {
+		t.Errorf("scaling schedules not removed, %v", schedules)
+	}
+}
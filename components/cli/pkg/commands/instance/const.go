@@ -20,3 +20,5 @@ package instance
 
 const celleryInstance = "cells.mesh.cellery.io"
 const celleryComposite = "composites.mesh.cellery.io"
+const virtualService = "virtualservices.networking.istio.io"
+const apiService = "apiservices.apiregistration.k8s.io"
@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/ghodss/yaml"
 
@@ -65,6 +66,10 @@ func RunExportAutoscalePolicies(cli cli.Cli, kind kubernetes.InstanceKind, insta
 	if err = writeToFile(yamlBytes, file); err != nil {
 		return err
 	}
+	if schedule, since := getActiveScalingSchedule(sp.Schedules, time.Now().UTC()); schedule != nil {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Active scaling schedule: %s (since %s)", schedule.Name,
+			since.Format("2006-01-02 15:04 MST")))
+	}
 	util.PrintSuccessMessage(fmt.Sprintf("Successfully exported autoscale policies for instance %s to %s", instance, file))
 	return nil
 }
@@ -102,6 +107,9 @@ func getAutoscalePolicies(cli cli.Cli, kind kubernetes.InstanceKind, instance st
 			}
 		}
 	}
+	if sp.Schedules, err = getScalingSchedules(cli, instance); err != nil {
+		return nil, err
+	}
 	return sp, nil
 }
 
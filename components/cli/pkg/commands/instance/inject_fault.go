@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// FaultOptions describes the faults injected into the requests from the source instance to the dependency.
+type FaultOptions struct {
+	Source     string
+	Dependency string
+	// Delay of the requests, 0 disables delays
+	Delay time.Duration
+	// Abort is the http status returned for the aborted requests, 0 disables aborts
+	Abort int
+	// Percent of the requests affected by the faults
+	Percent float64
+	// Duration after which the faults are cleared, 0 keeps the faults until cleared
+	Duration time.Duration
+}
+
+// RunInjectFault injects delays and aborts into the requests from the source instance to the dependency. If a
+// duration is given, the faults are cleared once the duration elapses or the command is interrupted or terminated.
+// The expiry is recorded on the virtual service as well, so that a later run clears the faults if this command does
+// not complete.
+func RunInjectFault(cli cli.Cli, opts FaultOptions) error {
+	fault := &kubernetes.HTTPFault{}
+	percentage := &kubernetes.Percent{Value: opts.Percent}
+	if opts.Delay > 0 {
+		fault.Delay = &kubernetes.FaultDelay{
+			FixedDelay: formatDuration(opts.Delay),
+			Percentage: percentage,
+		}
+	}
+	if opts.Abort > 0 {
+		fault.Abort = &kubernetes.FaultAbort{HttpStatus: opts.Abort, Percentage: percentage}
+	}
+	if fault.Delay == nil && fault.Abort == nil {
+		return fmt.Errorf("no delay or abort given")
+	}
+	var expiresAt time.Time
+	if opts.Duration > 0 {
+		expiresAt = time.Now().Add(opts.Duration)
+	}
+	change := fmt.Sprintf("inject %s", describeFault(opts))
+	if err := setFault(cli, opts.Source, opts.Dependency, fault, expiresAt, change); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully injected %s into the requests from instance %s to "+
+		"instance %s", describeFault(opts), opts.Source, opts.Dependency))
+	if opts.Duration <= 0 {
+		return nil
+	}
+	fmt.Fprintln(cli.Out(), fmt.Sprintf("Clearing the faults in %s, press Ctrl+C to clear them now", opts.Duration))
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupted)
+	select {
+	case <-time.After(opts.Duration):
+	case <-interrupted:
+	}
+	return RunClearFault(cli, opts.Source, opts.Dependency)
+}
+
+// RunClearFault clears the faults injected into the requests from the source instance to the dependency, or to
+// all dependencies if no dependency is given.
+func RunClearFault(cli cli.Cli, source string, dependency string) error {
+	change := "clear faults"
+	if dependency != "" {
+		change = fmt.Sprintf("clear faults to %s", dependency)
+	}
+	if err := setFault(cli, source, dependency, nil, time.Time{}, change); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully cleared the faults injected into the requests from "+
+		"instance %s", source))
+	return nil
+}
+
+// setFault applies the virtual service of the source instance with the given fault, recording the change in
+// the routing history.
+func setFault(cli cli.Cli, source string, dependency string, fault *kubernetes.HTTPFault, expiresAt time.Time,
+	change string) error {
+	vs, err := routing.SetFault(cli, source, dependency, fault, expiresAt)
+	if err != nil {
+		return fmt.Errorf("error building fault rules, %v", err)
+	}
+	objects, err := toObjects(vs)
+	if err != nil {
+		return err
+	}
+	previousObjects, err := snapshotObjects(cli, objects)
+	if err != nil {
+		return err
+	}
+	if err = cli.ExecuteTask("Applying fault rules", "Failed to apply fault rules", "", func() error {
+		if err := applyObjects(cli, objects); err != nil {
+			return fmt.Errorf("error occurred while applying fault rules, %v", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if _, err = recordRoutingRevision(cli, routingRevision{
+		Sources:    []string{source},
+		Dependency: dependency,
+		Change:     change,
+		Objects:    previousObjects,
+	}); err != nil {
+		return fmt.Errorf("fault rules are applied, but %v", err)
+	}
+	return nil
+}
+
+func describeFault(opts FaultOptions) string {
+	var description string
+	if opts.Delay > 0 {
+		description = fmt.Sprintf("a %s delay", opts.Delay)
+	}
+	if opts.Abort > 0 {
+		if description != "" {
+			description += " and "
+		}
+		description += fmt.Sprintf("%d aborts", opts.Abort)
+	}
+	return fmt.Sprintf("%s to %v%% of requests", description, opts.Percent)
+}
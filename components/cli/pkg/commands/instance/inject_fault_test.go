@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+func TestRunInjectFault(t *testing.T) {
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-fe-src-vs file")
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	if err = json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs); err != nil {
+		t.Fatalf("failed to unmarshall petFeSrcVsBytes, %v", err)
+	}
+	mockKubeCli := test.NewMockKubeCli(
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": petFeSrcVs}))
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+
+	err = RunInjectFault(mockCli, FaultOptions{Source: "pet-fe-src", Dependency: "hr-inst", Abort: 503,
+		Percent: 10})
+	if err == nil {
+		t.Errorf("expected an error injecting faults to an instance which is not a dependency")
+	}
+	err = RunInjectFault(mockCli, FaultOptions{Source: "pet-fe-src", Dependency: "pet-be-dep",
+		Delay: 1500 * time.Millisecond, Abort: 503, Percent: 10})
+	if err != nil {
+		t.Fatalf("error in RunInjectFault, %v", err)
+	}
+	vs, err := mockKubeCli.GetVirtualService("pet-fe-src--vs")
+	if err != nil {
+		t.Fatalf("error getting virtual service, %v", err)
+	}
+	wantFault := &kubernetes.HTTPFault{
+		Delay: &kubernetes.FaultDelay{FixedDelay: "1.5s", Percentage: &kubernetes.Percent{Value: 10}},
+		Abort: &kubernetes.FaultAbort{HttpStatus: 503, Percentage: &kubernetes.Percent{Value: 10}},
+	}
+	for i, rule := range vs.VsSpec.HTTP {
+		if diff := cmp.Diff(wantFault, rule.Fault); diff != "" {
+			t.Errorf("invalid fault of rule %d (-want, +got)\n%v", i, diff)
+		}
+	}
+
+	if err = RunClearFault(mockCli, "pet-fe-src", ""); err != nil {
+		t.Fatalf("error in RunClearFault, %v", err)
+	}
+	vs, err = mockKubeCli.GetVirtualService("pet-fe-src--vs")
+	if err != nil {
+		t.Fatalf("error getting virtual service, %v", err)
+	}
+	if diff := cmp.Diff(petFeSrcVs, vs); diff != "" {
+		t.Errorf("expected the faults to be cleared (-want, +got)\n%v", diff)
+	}
+
+	// faults with a duration are cleared once it elapses
+	err = RunInjectFault(mockCli, FaultOptions{Source: "pet-fe-src", Dependency: "pet-be-dep", Abort: 500,
+		Percent: 100, Duration: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("error in RunInjectFault, %v", err)
+	}
+	vs, err = mockKubeCli.GetVirtualService("pet-fe-src--vs")
+	if err != nil {
+		t.Fatalf("error getting virtual service, %v", err)
+	}
+	if diff := cmp.Diff(petFeSrcVs, vs); diff != "" {
+		t.Errorf("expected the faults to be cleared after the duration (-want, +got)\n%v", diff)
+	}
+	history, err := getRoutingHistory(mockCli)
+	if err != nil {
+		t.Fatalf("error getting routing history, %v", err)
+	}
+	var changes []string
+	for _, revision := range history {
+		changes = append(changes, revision.Change)
+	}
+	wantChanges := []string{"inject a 1.5s delay and 503 aborts to 10% of requests", "clear faults",
+		"inject 500 aborts to 100% of requests", "clear faults to pet-be-dep"}
+	if diff := cmp.Diff(wantChanges, changes); diff != "" {
+		t.Errorf("invalid routing history (-want, +got)\n%v", diff)
+	}
+}
@@ -20,131 +20,125 @@ package instance
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 
-	"github.com/olekukonko/tablewriter"
-
 	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/stack"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
-func RunListInstances(cli cli.Cli) error {
+// instanceSchema is the json and yaml representation of a running instance.
+type instanceSchema struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Image      string `json:"image"`
+	Status     string `json:"status"`
+	Gateway    string `json:"gateway,omitempty"`
+	Components int    `json:"components"`
+	Created    string `json:"created"`
+	Stack      string `json:"stack,omitempty"`
+}
+
+func RunListInstances(cli cli.Cli, opts output.Options) error {
 	var err error
-	var cellTableData, compositeTableData [][]string
-	if cellTableData, err = getCellTableData(cli); err != nil {
+	var cells, composites []instanceSchema
+	if cells, err = getCellInstances(cli); err != nil {
 		return fmt.Errorf("error getting cell data, %v", err)
 	}
-	if len(cellTableData) > 0 {
-		displayCellTable(cli, cellTableData)
-	}
-	if compositeTableData, err = getCompositeTableData(cli); err != nil {
+	if composites, err = getCompositeInstances(cli); err != nil {
 		return fmt.Errorf("error getting composite data, %v", err)
 	}
-	if len(compositeTableData) > 0 {
-		displayCompositeTable(cli, compositeTableData)
+	if opts.Structured() {
+		return output.Write(cli.Out(), opts, append(append([]instanceSchema{}, cells...), composites...))
+	}
+	if len(cells) > 0 {
+		displayCellTable(cli, cells, opts.Wide())
+	}
+	if len(composites) > 0 {
+		displayCompositeTable(cli, composites, opts.Wide())
 	}
-	if len(cellTableData) == 0 && len(compositeTableData) == 0 {
+	if len(cells) == 0 && len(composites) == 0 {
 		fmt.Fprintln(cli.Out(), "No running instances.")
 	}
 	return nil
 }
 
-func displayCellTable(cli cli.Cli, tableData [][]string) error {
-	if len(tableData) > 0 {
-		fmt.Fprintf(cli.Out(), "\n %s\n", util.Bold("Cell Instances:"))
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"INSTANCE", "IMAGE", "STATUS", "GATEWAY", "COMPONENTS", "AGE"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-
-		table.AppendBulk(tableData)
-		table.Render()
+func displayCellTable(cli cli.Cli, instances []instanceSchema, wide bool) {
+	fmt.Fprintf(cli.Out(), "\n %s\n", util.Bold("Cell Instances:"))
+	header := []string{"INSTANCE", "IMAGE", "STATUS", "GATEWAY", "COMPONENTS", "AGE"}
+	if wide {
+		header = append(header, "CREATED", "STACK")
 	}
-	return nil
+	table := output.NewTable(cli.Out(), header)
+	for _, instance := range instances {
+		record := []string{instance.Name, instance.Image, instance.Status, instance.Gateway,
+			strconv.Itoa(instance.Components), util.GetDuration(util.ConvertStringToTime(instance.Created))}
+		if wide {
+			record = append(record, instance.Created, instance.Stack)
+		}
+		table.Append(record)
+	}
+	table.Render()
 }
 
-func displayCompositeTable(cli cli.Cli, tableData [][]string) error {
-	if len(tableData) > 0 {
-		fmt.Fprintf(cli.Out(), " \n %s\n", util.Bold("Composite Instances:"))
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"INSTANCE", "IMAGE", "STATUS", "COMPONENTS", "AGE"})
-		table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-		table.SetAlignment(3)
-		table.SetRowSeparator("-")
-		table.SetCenterSeparator(" ")
-		table.SetColumnSeparator(" ")
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold},
-			tablewriter.Colors{tablewriter.Bold})
-		table.SetColumnColor(
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{},
-			tablewriter.Colors{})
-
-		table.AppendBulk(tableData)
-		table.Render()
+func displayCompositeTable(cli cli.Cli, instances []instanceSchema, wide bool) {
+	fmt.Fprintf(cli.Out(), " \n %s\n", util.Bold("Composite Instances:"))
+	header := []string{"INSTANCE", "IMAGE", "STATUS", "COMPONENTS", "AGE"}
+	if wide {
+		header = append(header, "CREATED", "STACK")
 	}
-	return nil
+	table := output.NewTable(cli.Out(), header)
+	for _, instance := range instances {
+		record := []string{instance.Name, instance.Image, instance.Status, strconv.Itoa(instance.Components),
+			util.GetDuration(util.ConvertStringToTime(instance.Created))}
+		if wide {
+			record = append(record, instance.Created, instance.Stack)
+		}
+		table.Append(record)
+	}
+	table.Render()
 }
 
-func getCellTableData(cli cli.Cli) ([][]string, error) {
-	var tableData [][]string
+func getCellInstances(cli cli.Cli) ([]instanceSchema, error) {
+	var instances []instanceSchema
 	cells, err := cli.KubeCli().GetCells()
 	if err != nil {
 		return nil, fmt.Errorf("error getting information of cells, %v", err)
 	}
-	for i := 0; i < len(cells); i++ {
-		age := util.GetDuration(util.ConvertStringToTime(cells[i].CellMetaData.CreationTimestamp))
-		instance := cells[i].CellMetaData.Name
-		cellImage := cells[i].CellMetaData.Annotations.Organization + "/" + cells[i].CellMetaData.Annotations.Name + ":" + cells[i].CellMetaData.Annotations.Version
-		gateway := cells[i].CellStatus.Gateway
-		components := cells[i].CellStatus.ServiceCount
-		status := cells[i].CellStatus.Status
-		tableRecord := []string{instance, cellImage, status, gateway, strconv.Itoa(components), age}
-		tableData = append(tableData, tableRecord)
+	for _, cell := range cells {
+		instances = append(instances, instanceSchema{
+			Name: cell.CellMetaData.Name,
+			Kind: "Cell",
+			Image: cell.CellMetaData.Annotations.Organization + "/" + cell.CellMetaData.Annotations.Name + ":" +
+				cell.CellMetaData.Annotations.Version,
+			Status:     cell.CellStatus.Status,
+			Gateway:    cell.CellStatus.Gateway,
+			Components: cell.CellStatus.ServiceCount,
+			Created:    cell.CellMetaData.CreationTimestamp,
+			Stack:      cell.CellMetaData.Labels[stack.LabelStack],
+		})
 	}
-	return tableData, nil
+	return instances, nil
 }
 
-func getCompositeTableData(cli cli.Cli) ([][]string, error) {
-	compositeData, err := cli.KubeCli().GetComposites()
+func getCompositeInstances(cli cli.Cli) ([]instanceSchema, error) {
+	var instances []instanceSchema
+	composites, err := cli.KubeCli().GetComposites()
 	if err != nil {
 		return nil, fmt.Errorf("error getting information of composites, %v", err)
 	}
-	var tableData [][]string
-	for i := 0; i < len(compositeData); i++ {
-		age := util.GetDuration(util.ConvertStringToTime(compositeData[i].CompositeMetaData.CreationTimestamp))
-		instance := compositeData[i].CompositeMetaData.Name
-		cellImage := compositeData[i].CompositeMetaData.Annotations.Organization + "/" +
-			compositeData[i].CompositeMetaData.Annotations.Name + ":" +
-			compositeData[i].CompositeMetaData.Annotations.Version
-		components := compositeData[i].CompositeStatus.ServiceCount
-		status := compositeData[i].CompositeStatus.Status
-		tableRecord := []string{instance, cellImage, status, strconv.Itoa(components), age}
-		tableData = append(tableData, tableRecord)
+	for _, composite := range composites {
+		instances = append(instances, instanceSchema{
+			Name: composite.CompositeMetaData.Name,
+			Kind: "Composite",
+			Image: composite.CompositeMetaData.Annotations.Organization + "/" +
+				composite.CompositeMetaData.Annotations.Name + ":" + composite.CompositeMetaData.Annotations.Version,
+			Status:     composite.CompositeStatus.Status,
+			Components: composite.CompositeStatus.ServiceCount,
+			Created:    composite.CompositeMetaData.CreationTimestamp,
+			Stack:      composite.CompositeMetaData.Labels[stack.LabelStack],
+		})
 	}
-	return tableData, nil
+	return instances, nil
 }
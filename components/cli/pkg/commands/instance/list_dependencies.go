@@ -20,16 +20,22 @@ package instance
 
 import (
 	"fmt"
-	"os"
-
-	"github.com/olekukonko/tablewriter"
 
 	"cellery.io/cellery/components/cli/cli"
 	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/routing"
 )
 
-func RunListDependencies(cli cli.Cli, instanceName string) error {
+// dependencySchema is the json and yaml representation of a dependency of an instance.
+type dependencySchema struct {
+	Instance string `json:"instance"`
+	Image    string `json:"image"`
+	Version  string `json:"version"`
+	Kind     string `json:"kind"`
+}
+
+func RunListDependencies(cli cli.Cli, instanceName string, opts output.Options) error {
 	var depJson string
 	var canBeComposite bool
 	cellInst, err := cli.KubeCli().GetCell(instanceName)
@@ -63,32 +69,31 @@ func RunListDependencies(cli cli.Cli, instanceName string) error {
 	if len(dependencies) == 0 {
 		return fmt.Errorf("no dependencies found in instance %s", instanceName)
 	}
-	var tableData [][]string
+	if opts.Structured() {
+		schemas := []dependencySchema{}
+		for _, dependency := range dependencies {
+			schemas = append(schemas, dependencySchema{
+				Instance: dependency["instance"],
+				Image:    fmt.Sprintf("%s/%s", dependency["org"], dependency["name"]),
+				Version:  dependency["version"],
+				Kind:     dependency["kind"],
+			})
+		}
+		return output.Write(cli.Out(), opts, schemas)
+	}
+	header := []string{"CELL INSTANCE", "IMAGE", "VERSION"}
+	if opts.Wide() {
+		header = append(header, "KIND")
+	}
+	table := output.NewTable(cli.Out(), header)
 	for _, dependency := range dependencies {
 		record := []string{dependency["instance"], fmt.Sprintf("%s/%s", dependency["org"], dependency["name"]),
 			dependency["version"]}
-		tableData = append(tableData, record)
+		if opts.Wide() {
+			record = append(record, dependency["kind"])
+		}
+		table.Append(record)
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"CELL INSTANCE", "IMAGE", "VERSION"})
-	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-	table.SetAlignment(3)
-	table.SetRowSeparator("-")
-	table.SetCenterSeparator(" ")
-	table.SetColumnSeparator(" ")
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold},
-	)
-	table.SetColumnColor(
-		tablewriter.Colors{},
-		tablewriter.Colors{},
-		tablewriter.Colors{},
-	)
-
-	table.AppendBulk(tableData)
 	table.Render()
-
 	return nil
 }
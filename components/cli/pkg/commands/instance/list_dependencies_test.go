@@ -25,6 +25,7 @@ import (
 
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
 func TestRunListDependencies(t *testing.T) {
@@ -108,7 +109,7 @@ func TestRunListDependencies(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunListDependencies(mockCli, tst.instance)
+			err := RunListDependencies(mockCli, tst.instance, output.Options{})
 			if tst.expectedToPass {
 				if err != nil {
 					t.Errorf("error in RunListDependencies, %v", err)
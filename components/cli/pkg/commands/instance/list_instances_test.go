@@ -25,6 +25,7 @@ import (
 
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
 )
 
 func TestRunListInstances(t *testing.T) {
@@ -81,7 +82,7 @@ func TestRunListInstances(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunListInstances(tst.mockCli)
+			err := RunListInstances(tst.mockCli, output.Options{})
 			if tst.instancesRunning {
 				if err != nil {
 					t.Errorf("error in RunListInstances, %v", err)
@@ -94,3 +95,88 @@ func TestRunListInstances(t *testing.T) {
 		})
 	}
 }
+
+func TestRunListInstancesStructuredOutput(t *testing.T) {
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:              "employee",
+					CreationTimestamp: "2019-10-18T11:40:36Z",
+					Annotations: kubernetes.CellAnnotations{
+						Organization: "myorg",
+						Name:         "employee",
+						Version:      "1.0.0",
+					},
+					Labels: map[string]string{"stack.cellery.io/name": "hr-stack"},
+				},
+				CellStatus: kubernetes.CellStatus{
+					Status:       "Ready",
+					Gateway:      "employee--gateway",
+					ServiceCount: 2,
+				},
+			},
+		},
+	}
+	composites := kubernetes.Composites{
+		Items: []kubernetes.Composite{
+			{
+				CompositeMetaData: kubernetes.K8SMetaData{
+					Name:              "stock",
+					CreationTimestamp: "2019-10-19T11:40:36Z",
+					Annotations: kubernetes.CellAnnotations{
+						Organization: "myorg",
+						Name:         "stock",
+						Version:      "1.0.0",
+					},
+				},
+				CompositeStatus: kubernetes.CompositeStatus{
+					Status:       "Ready",
+					ServiceCount: 1,
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		opts     output.Options
+		expected string
+	}{
+		{
+			name: "list instances as yaml",
+			opts: output.Options{Format: output.FormatYaml},
+			expected: `- components: 2
+  created: "2019-10-18T11:40:36Z"
+  gateway: employee--gateway
+  image: myorg/employee:1.0.0
+  kind: Cell
+  name: employee
+  stack: hr-stack
+  status: Ready
+- components: 1
+  created: "2019-10-19T11:40:36Z"
+  image: myorg/stock:1.0.0
+  kind: Composite
+  name: stock
+  status: Ready
+`,
+		},
+		{
+			name:     "list instances with a template",
+			opts:     output.Options{Template: "{{range .}}{{.name}} {{.kind}} {{.status}}\n{{end}}"},
+			expected: "employee Cell Ready\nstock Composite Ready\n",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells),
+				test.WithComposites(composites))))
+			if err := RunListInstances(mockCli, tst.opts); err != nil {
+				t.Fatalf("error in RunListInstances, %v", err)
+			}
+			if diff := cmp.Diff(tst.expected, mockCli.OutBuffer().String()); diff != "" {
+				t.Errorf("RunListInstances: unexpected output (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/routing"
+)
+
+// routeSchema is the json and yaml representation of the routing of the requests from a source instance to one
+// of its dependencies.
+type routeSchema struct {
+	Source       string              `json:"source"`
+	Alias        string              `json:"alias"`
+	Dependency   string              `json:"dependency"`
+	Targets      []routeTargetSchema `json:"targets"`
+	SessionAware bool                `json:"sessionAware"`
+	ApisMatch    *bool               `json:"apisMatch,omitempty"`
+}
+
+type routeTargetSchema struct {
+	Instance string `json:"instance"`
+	Weight   int    `json:"weight"`
+}
+
+// RunListRoutes lists how the requests from each instance to its dependencies are split among the targets.
+func RunListRoutes(cli cli.Cli, opts output.Options) error {
+	edges, err := routing.GetRouteEdges(cli)
+	if err != nil {
+		return fmt.Errorf("error getting routes, %v", err)
+	}
+	if opts.Structured() {
+		schemas := []routeSchema{}
+		for _, edge := range edges {
+			schema := routeSchema{
+				Source:       edge.Source,
+				Alias:        edge.Alias,
+				Dependency:   edge.Dependency,
+				Targets:      []routeTargetSchema{},
+				SessionAware: edge.SessionAware,
+				ApisMatch:    edge.ApisMatch,
+			}
+			for _, target := range edge.Targets {
+				schema.Targets = append(schema.Targets, routeTargetSchema{Instance: target.Instance,
+					Weight: target.Weight})
+			}
+			schemas = append(schemas, schema)
+		}
+		return output.Write(cli.Out(), opts, schemas)
+	}
+	if len(edges) == 0 {
+		fmt.Fprintln(cli.Out(), "No routes found")
+		return nil
+	}
+	table := output.NewTable(cli.Out(), []string{"SOURCE", "ALIAS", "DEPENDENCY", "TARGETS", "SESSION AWARE",
+		"APIS MATCH"})
+	for _, edge := range edges {
+		var targets []string
+		for _, target := range edge.Targets {
+			targets = append(targets, fmt.Sprintf("%s (%d%%)", target.Instance, target.Weight))
+		}
+		alias := "-"
+		if edge.Alias != "" {
+			alias = edge.Alias
+		}
+		apisMatch := "-"
+		if edge.ApisMatch != nil {
+			apisMatch = strconv.FormatBool(*edge.ApisMatch)
+		}
+		table.Append([]string{edge.Source, alias, edge.Dependency, strings.Join(targets, ", "),
+			strconv.FormatBool(edge.SessionAware), apisMatch})
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/routing"
+)
+
+func TestRunListRoutes(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	cells := kubernetes.Cells{}
+	for _, name := range []string{"pet-be-dep", "pet-be-target", "pet-fe-src"} {
+		cellBytes, err := ioutil.ReadFile(filepath.Join("testdata", "cells", name+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file", name)
+		}
+		cellMap[name] = cellBytes
+		cell := kubernetes.Cell{}
+		if err = json.Unmarshal(cellBytes, &cell); err != nil {
+			t.Fatalf("failed to unmarshall %s cell, %v", name, err)
+		}
+		cells.Items = append(cells.Items, cell)
+	}
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-fe-src-vs file")
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	if err = json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs); err != nil {
+		t.Fatalf("failed to unmarshall petFeSrcVsBytes, %v", err)
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithCells(cells), test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": petFeSrcVs}))
+	// the source image in the local repository refers to the dependency as petBackend
+	repo, err := ioutil.TempDir("", "repo")
+	if err != nil {
+		t.Fatalf("error creating temp repo, %v", err)
+	}
+	defer os.RemoveAll(repo)
+	writeImageMetadata(t, filepath.Join(repo, "myorg", "petfe", "1.0.0"), "petfe", `{"org": "myorg",
+		"name": "petfe", "ver": "1.0.0", "kind": "Cell", "components": {"portal": {"dependencies": {"cells":
+		{"petBackend": {"org": "myorg", "name": "petbe", "ver": "1.0.0"}}}}}}`)
+	mockFileSystem := test.NewMockFileSystem(test.SetRepository(repo))
+
+	tests := []struct {
+		name         string
+		routeOptions *routing.RouteOptions
+		expected     []routeSchema
+	}{
+		{
+			name: "list routes before routing traffic",
+			expected: []routeSchema{
+				{
+					Source:     "pet-fe-src",
+					Alias:      "petBackend",
+					Dependency: "pet-be-dep",
+					Targets:    []routeTargetSchema{{Instance: "pet-be-dep", Weight: 100}},
+				},
+			},
+		},
+		{
+			name:         "list routes after routing traffic",
+			routeOptions: &routing.RouteOptions{Percentage: 40, SessionAware: true},
+			expected: []routeSchema{
+				{
+					Source:     "pet-fe-src",
+					Alias:      "petBackend",
+					Dependency: "pet-be-dep",
+					Targets: []routeTargetSchema{
+						{Instance: "pet-be-dep", Weight: 60},
+						{Instance: "pet-be-target", Weight: 40},
+					},
+					SessionAware: true,
+					ApisMatch:    boolPtr(true),
+				},
+			},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli), test.SetFileSystem(mockFileSystem))
+			if tst.routeOptions != nil {
+				err := RunRouteTrafficCommand(mockCli, []string{"pet-fe-src"}, "pet-be-dep", "pet-be-target",
+					*tst.routeOptions, true, false, "")
+				if err != nil {
+					t.Fatalf("error in RunRouteTrafficCommand, %v", err)
+				}
+				mockCli = test.NewMockCli(test.SetKubeCli(mockKubeCli), test.SetFileSystem(mockFileSystem))
+			}
+			if err := RunListRoutes(mockCli, output.Options{Format: output.FormatJson}); err != nil {
+				t.Fatalf("error in RunListRoutes, %v", err)
+			}
+			var actual []routeSchema
+			if err := json.Unmarshal(mockCli.OutBuffer().Bytes(), &actual); err != nil {
+				t.Fatalf("error parsing routes, %v", err)
+			}
+			if diff := cmp.Diff(tst.expected, actual); diff != "" {
+				t.Errorf("invalid routes (-want, +got)\n%v", diff)
+			}
+		})
+	}
+
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli), test.SetFileSystem(mockFileSystem))
+	if err := RunListRoutes(mockCli, output.Options{}); err != nil {
+		t.Fatalf("error in RunListRoutes, %v", err)
+	}
+	lines := strings.Split(mockCli.OutBuffer().String(), "\n")
+	if len(lines) < 3 || !strings.Contains(lines[0], "ALIAS") || !strings.Contains(lines[2], "petBackend") {
+		t.Errorf("expected the alias in the routes table, got\n%s", mockCli.OutBuffer().String())
+	}
+
+	// the alias is unknown if the source image is not in the local repository
+	mockCli = test.NewMockCli(test.SetKubeCli(mockKubeCli), test.SetFileSystem(test.NewMockFileSystem(
+		test.SetRepository(filepath.Join(repo, "missing")))))
+	if err := RunListRoutes(mockCli, output.Options{Format: output.FormatJson}); err != nil {
+		t.Fatalf("error in RunListRoutes, %v", err)
+	}
+	var routes []routeSchema
+	if err := json.Unmarshal(mockCli.OutBuffer().Bytes(), &routes); err != nil {
+		t.Fatalf("error parsing routes, %v", err)
+	}
+	if len(routes) != 1 || routes[0].Alias != "" {
+		t.Errorf("expected no alias without the source image, got %v", routes)
+	}
+}
+
+// writeImageMetadata writes a cell image zip with the given metadata to the image directory.
+func writeImageMetadata(t *testing.T, imageDir string, name string, metadata string) {
+	if err := os.MkdirAll(imageDir, 0755); err != nil {
+		t.Fatalf("error creating image dir, %v", err)
+	}
+	file, err := os.Create(filepath.Join(imageDir, name+".zip"))
+	if err != nil {
+		t.Fatalf("error creating image zip, %v", err)
+	}
+	defer file.Close()
+	writer := zip.NewWriter(file)
+	entry, err := writer.Create("artifacts/cellery/metadata.json")
+	if err != nil {
+		t.Fatalf("error writing image zip, %v", err)
+	}
+	if _, err = entry.Write([]byte(metadata)); err != nil {
+		t.Fatalf("error writing image zip, %v", err)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf("error writing image zip, %v", err)
+	}
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
@@ -19,57 +19,248 @@
 package instance
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/hashicorp/go-version"
+	"github.com/fatih/color"
 
 	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
-const minimumKubernetesVersionToFollowLogs = "v1.14.0"
+// LogsOptions holds the instances, components and filters of the logs to be displayed.
+type LogsOptions struct {
+	Selector  string
+	Component string
+	SysLog    bool
+	Follow    bool
+	Since     time.Duration
+	Tail      int
+	Grep      string
+	Fields    []string
+}
 
-func RunLogs(cli cli.Cli, instanceName string, componentName string, sysLog bool, follow bool) error {
-	if err := cli.KubeCli().IsInstanceAvailable(instanceName); err != nil {
-		return fmt.Errorf(fmt.Sprintf("No logs found"), fmt.Errorf("cannot find running "+
-			"instance %s", instanceName))
-	}
+var logPrefixColors = []color.Attribute{
+	color.FgHiCyan,
+	color.FgHiGreen,
+	color.FgHiYellow,
+	color.FgHiMagenta,
+	color.FgHiBlue,
+	color.FgHiRed,
+}
 
-	if follow {
-		_, clientVersion, err := cli.KubeCli().Version()
+func RunLogs(cli cli.Cli, instances []string, opts LogsOptions) error {
+	var grep *regexp.Regexp
+	if opts.Grep != "" {
+		var err error
+		if grep, err = regexp.Compile(opts.Grep); err != nil {
+			return fmt.Errorf("invalid grep expression %s, %v", opts.Grep, err)
+		}
+	}
+	if opts.Selector != "" {
+		selected, err := getInstancesForSelector(cli, opts.Selector)
 		if err != nil {
 			return err
 		}
-		kubectlClientVersion, err := version.NewVersion(clientVersion)
-		minimumKubernetesClientVersion, err := version.NewVersion(minimumKubernetesVersionToFollowLogs)
+		if len(selected) == 0 {
+			return fmt.Errorf("no instances match the selector %s", opts.Selector)
+		}
+		for _, name := range selected {
+			if !util.ContainsInStringArray(instances, name) {
+				instances = append(instances, name)
+			}
+		}
+	}
+	for _, instanceName := range instances {
+		if err := cli.KubeCli().IsInstanceAvailable(instanceName); err != nil {
+			return fmt.Errorf(fmt.Sprintf("No logs found"), fmt.Errorf("cannot find running "+
+				"instance %s", instanceName))
+		}
+		if opts.Component == "" {
+			continue
+		}
+		if err := cli.KubeCli().IsComponentAvailable(instanceName, opts.Component); err != nil {
+			return fmt.Errorf(fmt.Sprintf("No logs found"), fmt.Errorf("cannot find component "+
+				"%s of cell instance %s", opts.Component, instanceName))
+		}
+	}
+
+	printer := &logPrinter{
+		out: cli.Out(),
+		// Logs of a single component are printed as they are
+		prefix: len(instances) > 1 || opts.Component == "",
+		grep:   grep,
+		fields: opts.Fields,
+		colors: map[string]*color.Color{},
+	}
+	// Unless following, the logs of all containers are interleaved in the order in which they were logged
+	err := cli.KubeCli().StreamLogs(instances, opts.Component, opts.SysLog, kubernetes.LogOptions{
+		Follow: opts.Follow,
+		Since:  opts.Since,
+		Tail:   opts.Tail,
+	}, printer.print)
+	if err != nil {
+		return fmt.Errorf("error getting logs of %s, %v", strings.Join(instances, ", "), err)
+	}
+	return nil
+}
+
+// logPrinter filters log lines and prints them prefixed with the coloured name of the container they belong to.
+type logPrinter struct {
+	out    io.Writer
+	prefix bool
+	grep   *regexp.Regexp
+	fields []string
+	colors map[string]*color.Color
+}
+
+func (printer *logPrinter) print(line kubernetes.LogLine) {
+	if printer.grep != nil && !printer.grep.MatchString(line.Text) {
+		return
+	}
+	text := extractLogFields(line.Text, printer.fields)
+	if !printer.prefix {
+		fmt.Fprintln(printer.out, text)
+		return
+	}
+	name := line.Instance
+	if line.Component != "" {
+		name += "/" + line.Component
+	}
+	if line.Container != line.Component {
+		name += ":" + line.Container
+	}
+	prefixColor, ok := printer.colors[name]
+	if !ok {
+		prefixColor = color.New(logPrefixColors[len(printer.colors)%len(logPrefixColors)])
+		printer.colors[name] = prefixColor
+	}
+	fmt.Fprintf(printer.out, "%s %s\n", prefixColor.Sprintf("[%s]", name), text)
+}
 
-		if kubectlClientVersion.LessThan(minimumKubernetesClientVersion) {
-			util.PrintWarningMessage(fmt.Sprintf("Unable to follow cellery logs for instance %s."+
-				"Please upgrade kubernetes client version to 1.14 or higher", instanceName))
-			return nil
+// extractLogFields returns the given fields of a JSON log line as key=value pairs. Lines which are not JSON objects
+// or do not contain any of the fields are returned as they are. Nested fields are separated by dots.
+func extractLogFields(text string, fields []string) string {
+	if len(fields) == 0 {
+		return text
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &entry); err != nil {
+		return text
+	}
+	var values []string
+	for _, field := range fields {
+		var value interface{} = entry
+		for _, key := range strings.Split(field, ".") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = object[key]
 		}
+		if value == nil {
+			continue
+		}
+		values = append(values, field+"="+formatLogField(value))
+	}
+	if len(values) == 0 {
+		return text
 	}
+	return strings.Join(values, " ")
+}
 
-	if componentName == "" {
-		var err error
-		if sysLog {
-			err = cli.KubeCli().StreamCellLogsAllComponents(instanceName, follow)
-		} else {
-			err = cli.KubeCli().StreamCellLogsUserComponents(instanceName, follow)
+func formatLogField(value interface{}) string {
+	if text, ok := value.(string); ok {
+		if text == "" || strings.ContainsAny(text, " \t\"=") {
+			return strconv.Quote(text)
 		}
+		return text
+	}
+	out, _ := json.Marshal(value)
+	return string(out)
+}
 
-		if err != nil {
-			return fmt.Errorf(fmt.Sprintf("Error getting logs for instance %s", instanceName), err)
+// getInstancesForSelector returns the names of the cell and composite instances of which the labels match the
+// selector.
+func getInstancesForSelector(cli cli.Cli, selector string) ([]string, error) {
+	matches, err := parseLabelSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	var instances []string
+	cells, err := cli.KubeCli().GetCells()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cell instances, %v", err)
+	}
+	for _, cell := range cells {
+		if matches(cell.CellMetaData.Labels) {
+			instances = append(instances, cell.CellMetaData.Name)
 		}
-	} else {
-		if err := cli.KubeCli().IsComponentAvailable(instanceName, componentName); err != nil {
-			return fmt.Errorf(fmt.Sprintf("No logs found"), fmt.Errorf("cannot find component "+
-				"%s of cell instance %s", componentName, instanceName))
+	}
+	composites, err := cli.KubeCli().GetComposites()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get composite instances, %v", err)
+	}
+	for _, composite := range composites {
+		if matches(composite.CompositeMetaData.Labels) {
+			instances = append(instances, composite.CompositeMetaData.Name)
 		}
-		if err := cli.KubeCli().StreamComponentLogs(instanceName, componentName, follow); err != nil {
-			return fmt.Errorf(fmt.Sprintf("Error getting logs for component %s of instance %s",
-				componentName, instanceName), err)
+	}
+	return instances, nil
+}
+
+// parseLabelSelector parses an equality based label selector such as "team=hr,tier!=frontend,stage,!legacy".
+func parseLabelSelector(selector string) (func(map[string]string) bool, error) {
+	var requirements []func(map[string]string) bool
+	for _, requirement := range strings.Split(selector, ",") {
+		requirement = strings.TrimSpace(requirement)
+		var key, value string
+		var matches func(map[string]string) bool
+		switch {
+		case strings.Contains(requirement, "!="):
+			parts := strings.SplitN(requirement, "!=", 2)
+			key, value = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			matches = func(labels map[string]string) bool {
+				return labels[key] != value
+			}
+		case strings.Contains(requirement, "="):
+			parts := strings.SplitN(strings.Replace(requirement, "==", "=", 1), "=", 2)
+			key, value = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			matches = func(labels map[string]string) bool {
+				actual, ok := labels[key]
+				return ok && actual == value
+			}
+		case strings.HasPrefix(requirement, "!"):
+			key = strings.TrimSpace(strings.TrimPrefix(requirement, "!"))
+			matches = func(labels map[string]string) bool {
+				_, ok := labels[key]
+				return !ok
+			}
+		default:
+			key = requirement
+			matches = func(labels map[string]string) bool {
+				_, ok := labels[key]
+				return ok
+			}
 		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid label selector %s", selector)
+		}
+		requirements = append(requirements, matches)
 	}
-	return nil
+	return func(labels map[string]string) bool {
+		for _, matches := range requirements {
+			if !matches(labels) {
+				return false
+			}
+		}
+		return true
+	}, nil
 }
@@ -19,8 +19,11 @@
 package instance
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/google/go-cmp/cmp"
 
 	"cellery.io/cellery/components/cli/internal/test"
@@ -157,7 +160,12 @@ func TestRunLogs(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunLogs(mockCli, tst.instance, tst.component, tst.sysLog, tst.follow)
+			err := RunLogs(mockCli, []string{tst.instance}, LogsOptions{
+				Component: tst.component,
+				SysLog:    tst.sysLog,
+				Follow:    tst.follow,
+				Tail:      -1,
+			})
 			if err != nil {
 				t.Errorf("error in RunLogs, %v", err)
 			}
@@ -204,7 +212,166 @@ func TestRunLogsError(t *testing.T) {
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
 			err := RunLogs(test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells)))),
-				tst.instance, tst.component, false, false)
+				[]string{tst.instance}, LogsOptions{Component: tst.component, Tail: -1})
+			if diff := cmp.Diff(tst.errMessage, err.Error()); diff != "" {
+				t.Errorf("RunLogs: unexpected error (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestRunLogsAggregated(t *testing.T) {
+	color.NoColor = true
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:   "employee",
+					Labels: map[string]string{"stack.cellery.io/name": "hr-stack"},
+				},
+			},
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name: "hr",
+				},
+			},
+		},
+	}
+	composites := kubernetes.Composites{
+		Items: []kubernetes.Composite{
+			{
+				CompositeMetaData: kubernetes.K8SMetaData{
+					Name:   "stock",
+					Labels: map[string]string{"stack.cellery.io/name": "hr-stack"},
+				},
+			},
+		},
+	}
+	logTime := func(seconds int) time.Time {
+		return time.Date(2020, 3, 1, 10, 0, seconds, 0, time.UTC)
+	}
+	logs := []kubernetes.LogLine{
+		{Instance: "employee", Component: "salary", Container: "salary", Time: logTime(3),
+			Text: `{"level":"error","msg":"salary lookup failed","ctx":{"id":42}}`},
+		{Instance: "stock", Component: "stock", Container: "stock", Time: logTime(1), Text: "stock request received"},
+		{Instance: "employee", Component: "salary", Container: "istio-proxy", Time: logTime(2),
+			Text: "GET /salary 500"},
+		{Instance: "employee", Container: "envoy-gateway", Time: logTime(0), Text: "gateway started"},
+		{Instance: "hr", Component: "hr", Container: "hr", Time: logTime(4), Text: "hr request received"},
+	}
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells),
+		test.WithComposites(composites), test.WithLogs(logs), test.WithComponents(kubernetes.Components{
+			Items: []kubernetes.Component{{ComponentMetaData: kubernetes.K8SMetaData{Name: "salary"}}},
+		}))))
+
+	tests := []struct {
+		name      string
+		instances []string
+		opts      LogsOptions
+		want      []string
+	}{
+		{
+			name:      "multiple instances interleaved",
+			instances: []string{"employee", "stock"},
+			opts:      LogsOptions{Tail: -1},
+			want: []string{
+				"[stock/stock] stock request received",
+				"[employee/salary:istio-proxy] GET /salary 500",
+				`[employee/salary] {"level":"error","msg":"salary lookup failed","ctx":{"id":42}}`,
+			},
+		},
+		{
+			name:      "system logs",
+			instances: []string{"employee"},
+			opts:      LogsOptions{SysLog: true, Tail: -1},
+			want: []string{
+				"[employee:envoy-gateway] gateway started",
+				"[employee/salary:istio-proxy] GET /salary 500",
+				`[employee/salary] {"level":"error","msg":"salary lookup failed","ctx":{"id":42}}`,
+			},
+		},
+		{
+			name: "label selector",
+			opts: LogsOptions{Selector: "stack.cellery.io/name=hr-stack", Tail: -1},
+			want: []string{
+				"[stock/stock] stock request received",
+				"[employee/salary:istio-proxy] GET /salary 500",
+				`[employee/salary] {"level":"error","msg":"salary lookup failed","ctx":{"id":42}}`,
+			},
+		},
+		{
+			name:      "label selector and instance",
+			instances: []string{"hr"},
+			opts:      LogsOptions{Selector: "stack.cellery.io/name,!tier", Tail: -1},
+			want: []string{
+				"[stock/stock] stock request received",
+				"[employee/salary:istio-proxy] GET /salary 500",
+				`[employee/salary] {"level":"error","msg":"salary lookup failed","ctx":{"id":42}}`,
+				"[hr/hr] hr request received",
+			},
+		},
+		{
+			name:      "grep",
+			instances: []string{"employee", "stock", "hr"},
+			opts:      LogsOptions{Grep: "(?i)request|500", Tail: -1},
+			want: []string{
+				"[stock/stock] stock request received",
+				"[employee/salary:istio-proxy] GET /salary 500",
+				"[hr/hr] hr request received",
+			},
+		},
+		{
+			name:      "json fields of a single component",
+			instances: []string{"employee"},
+			opts:      LogsOptions{Component: "salary", Fields: []string{"level", "msg", "ctx.id", "missing"}, Tail: -1},
+			want: []string{
+				`level=error msg="salary lookup failed" ctx.id=42`,
+			},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli.OutBuffer().Reset()
+			if err := RunLogs(mockCli, tst.instances, tst.opts); err != nil {
+				t.Fatalf("error in RunLogs, %v", err)
+			}
+			got := strings.Split(strings.TrimSuffix(mockCli.OutBuffer().String(), "\n"), "\n")
+			if diff := cmp.Diff(tst.want, got); diff != "" {
+				t.Errorf("RunLogs: unexpected output (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestRunLogsSelectorError(t *testing.T) {
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli()))
+	tests := []struct {
+		name       string
+		opts       LogsOptions
+		errMessage string
+	}{
+		{
+			name:       "no matching instances",
+			opts:       LogsOptions{Selector: "team=hr"},
+			errMessage: "no instances match the selector team=hr",
+		},
+		{
+			name:       "invalid selector",
+			opts:       LogsOptions{Selector: "team=hr,=foo"},
+			errMessage: "invalid label selector team=hr,=foo",
+		},
+		{
+			name:       "invalid grep expression",
+			opts:       LogsOptions{Selector: "team=hr", Grep: "(error"},
+			errMessage: "invalid grep expression (error, error parsing regexp: missing closing ): `(error`",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := RunLogs(mockCli, nil, tst.opts)
+			if err == nil {
+				t.Fatalf("expected error %s", tst.errMessage)
+			}
 			if diff := cmp.Diff(tst.errMessage, err.Error()); diff != "" {
 				t.Errorf("RunLogs: unexpected error (-want, +got)\n%v", diff)
 			}
@@ -0,0 +1,359 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// ResilienceOptions describes the timeout, retries and circuit breaking of the requests from a source instance
+// to a dependency. Zero values leave the corresponding setting unset.
+type ResilienceOptions struct {
+	Dependency    string
+	Timeout       time.Duration
+	Retries       int
+	PerTryTimeout time.Duration
+	RetryOn       string
+	// ConsecutiveErrors after which a host of the dependency is ejected, 0 disables circuit breaking
+	ConsecutiveErrors  int
+	Interval           time.Duration
+	BaseEjectionTime   time.Duration
+	MaxEjectionPercent int
+}
+
+// RunSetResilience sets the timeout, retries and circuit breaking of the requests from the source instance to
+// the dependency, replacing the settings made earlier.
+func RunSetResilience(cli cli.Cli, source string, opts ResilienceOptions) error {
+	policy, err := buildResiliencePolicy(opts)
+	if err != nil {
+		return err
+	}
+	if err = setResilience(cli, source, []kubernetes.DependencyResiliencePolicy{*policy},
+		fmt.Sprintf("set resilience of %s", opts.Dependency)); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully set the resilience of the requests from instance %s to "+
+		"instance %s", source, opts.Dependency))
+	return nil
+}
+
+// RunApplyResiliencePolicy applies the resilience policy in the file to the requests from the source instance.
+// The settings of the dependencies which are not in the policy are cleared.
+func RunApplyResiliencePolicy(cli cli.Cli, source string, file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("error reading file %s, %v", file, err)
+	}
+	policy := &kubernetes.ResiliencePolicy{}
+	if err = yaml.Unmarshal(content, policy); err != nil {
+		return fmt.Errorf("failed to unmarshall data in file %s, %v", file, err)
+	}
+	for i := range policy.Dependencies {
+		if err = normalizeResiliencePolicy(&policy.Dependencies[i]); err != nil {
+			return fmt.Errorf("invalid resilience policy in file %s, %v", file, err)
+		}
+	}
+	current, err := routing.GetResilience(cli, source)
+	if err != nil {
+		return fmt.Errorf("error getting current resilience policy, %v", err)
+	}
+	dependencies := policy.Dependencies
+	for _, currentPolicy := range current.Dependencies {
+		if findResiliencePolicy(policy, currentPolicy.Instance) == nil {
+			dependencies = append(dependencies, kubernetes.DependencyResiliencePolicy{Instance: currentPolicy.Instance})
+		}
+	}
+	if len(dependencies) == 0 {
+		util.PrintSuccessMessage(fmt.Sprintf("Nothing to apply. Resilience policy of %q is empty", file))
+		return nil
+	}
+	if err = setResilience(cli, source, dependencies, "apply resilience policy"); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully applied resilience policy for instance %q", source))
+	return nil
+}
+
+// RunExportResiliencePolicy exports the resilience of the requests from the source instance to its dependencies
+// as a policy file which can be applied with RunApplyResiliencePolicy.
+func RunExportResiliencePolicy(cli cli.Cli, source string, outputFile string) error {
+	var err error
+	var policy *kubernetes.ResiliencePolicy
+	if err = cli.ExecuteTask("Exporting resilience policy", "Failed to export resilience policy",
+		"", func() error {
+			policy, err = routing.GetResilience(cli, source)
+			return err
+		}); err != nil {
+		return fmt.Errorf("failed to retrieve resilience policy, %v", err)
+	}
+	policyJson, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	yamlBytes, err := yaml.JSONToYAML(policyJson)
+	if err != nil {
+		return err
+	}
+	file := outputFile
+	if file == "" {
+		file = filepath.Join("./", fmt.Sprintf("%s-resiliencepolicy.yaml", source))
+	} else {
+		if err := ensureDir(file); err != nil {
+			return err
+		}
+	}
+	if err = writeToFile(yamlBytes, file); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully exported resilience policy for instance %s to %s", source,
+		file))
+	return nil
+}
+
+// setResilience applies the virtual service of the source instance and the destination rules of the dependencies
+// with the given policies, recording the change in the routing history.
+func setResilience(cli cli.Cli, source string, policies []kubernetes.DependencyResiliencePolicy, change string) error {
+	vs, destinationRules, err := routing.SetResilience(cli, source, policies)
+	if err != nil {
+		return fmt.Errorf("error building resilience rules, %v", err)
+	}
+	var resources []interface{}
+	resources = append(resources, vs)
+	for _, destinationRule := range destinationRules {
+		resources = append(resources, destinationRule)
+	}
+	objects, err := toObjects(resources...)
+	if err != nil {
+		return err
+	}
+	previousObjects, err := snapshotObjects(cli, objects)
+	if err != nil {
+		return err
+	}
+	if err = cli.ExecuteTask("Applying resilience rules", "Failed to apply resilience rules", "", func() error {
+		if err := applyObjects(cli, objects); err != nil {
+			return fmt.Errorf("error occurred while applying resilience rules, %v", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	var dependency string
+	if len(policies) == 1 {
+		dependency = policies[0].Instance
+	}
+	if _, err = recordRoutingRevision(cli, routingRevision{
+		Sources:    []string{source},
+		Dependency: dependency,
+		Change:     change,
+		Objects:    previousObjects,
+		Created:    createdObjects(objects, previousObjects),
+	}); err != nil {
+		return fmt.Errorf("resilience rules are applied, but %v", err)
+	}
+	return nil
+}
+
+// appendTargetDestinationRules appends the destination rules which carry the circuit breaking of the dependency
+// over to the target to the routing artifacts.
+func appendTargetDestinationRules(cli cli.Cli, artifactFile string, dependency string, target string) error {
+	objects, err := readArtifactObjects(artifactFile)
+	if err != nil {
+		return err
+	}
+	var destinationRules []interface{}
+	for _, object := range objects {
+		if kind, _ := object["kind"].(string); kind != "VirtualService" {
+			continue
+		}
+		objectJson, err := json.Marshal(object)
+		if err != nil {
+			return err
+		}
+		vs := &kubernetes.VirtualService{}
+		if err = json.Unmarshal(objectJson, vs); err != nil {
+			return err
+		}
+		targetRules, err := routing.GetTargetDestinationRules(cli, vs, dependency, target)
+		if err != nil {
+			return err
+		}
+		for _, targetRule := range targetRules {
+			if !containsDestinationRule(destinationRules, targetRule.Metadata.Name) {
+				destinationRules = append(destinationRules, targetRule)
+			}
+		}
+	}
+	if len(destinationRules) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(artifactFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	for _, destinationRule := range destinationRules {
+		content, err := yaml.Marshal(destinationRule)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append([]byte("---\n"), content...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsDestinationRule(destinationRules []interface{}, name string) bool {
+	for _, destinationRule := range destinationRules {
+		if destinationRule.(kubernetes.DestinationRule).Metadata.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func buildResiliencePolicy(opts ResilienceOptions) (*kubernetes.DependencyResiliencePolicy, error) {
+	if opts.Dependency == "" {
+		return nil, fmt.Errorf("no dependency given")
+	}
+	policy := &kubernetes.DependencyResiliencePolicy{
+		Instance: opts.Dependency,
+		Timeout:  formatDuration(opts.Timeout),
+	}
+	if opts.Retries > 0 {
+		policy.Retries = &kubernetes.HTTPRetry{
+			Attempts:      opts.Retries,
+			PerTryTimeout: formatDuration(opts.PerTryTimeout),
+			RetryOn:       opts.RetryOn,
+		}
+	} else if opts.PerTryTimeout > 0 || opts.RetryOn != "" {
+		return nil, fmt.Errorf("per try timeout and retry conditions require retries")
+	}
+	if opts.ConsecutiveErrors > 0 {
+		policy.OutlierDetection = &kubernetes.OutlierDetection{
+			ConsecutiveErrors:  opts.ConsecutiveErrors,
+			Interval:           formatDuration(opts.Interval),
+			BaseEjectionTime:   formatDuration(opts.BaseEjectionTime),
+			MaxEjectionPercent: opts.MaxEjectionPercent,
+		}
+	} else if opts.Interval > 0 || opts.BaseEjectionTime > 0 || opts.MaxEjectionPercent > 0 {
+		return nil, fmt.Errorf("ejection interval, time and percent require consecutive errors")
+	}
+	if err := normalizeResiliencePolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// normalizeResiliencePolicy validates the policy and converts its durations to the format expected by istio.
+func normalizeResiliencePolicy(policy *kubernetes.DependencyResiliencePolicy) error {
+	if policy.Instance == "" {
+		return fmt.Errorf("dependency instance is not given")
+	}
+	var err error
+	if policy.Timeout, err = normalizeDuration(policy.Timeout); err != nil {
+		return err
+	}
+	if policy.Retries != nil {
+		if policy.Retries.Attempts <= 0 {
+			return fmt.Errorf("retry attempts should be greater than 0")
+		}
+		if policy.Retries.PerTryTimeout, err = normalizeDuration(policy.Retries.PerTryTimeout); err != nil {
+			return err
+		}
+	}
+	if outlierDetection := policy.OutlierDetection; outlierDetection != nil {
+		if outlierDetection.ConsecutiveErrors <= 0 {
+			return fmt.Errorf("consecutive errors should be greater than 0")
+		}
+		if outlierDetection.MaxEjectionPercent < 0 || outlierDetection.MaxEjectionPercent > 100 {
+			return fmt.Errorf("max ejection percent should be between 0 and 100")
+		}
+		if outlierDetection.Interval, err = normalizeDuration(outlierDetection.Interval); err != nil {
+			return err
+		}
+		if outlierDetection.BaseEjectionTime, err = normalizeDuration(outlierDetection.BaseEjectionTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeDuration(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid duration %s, %v", value, err)
+	}
+	if duration <= 0 {
+		return "", fmt.Errorf("duration %s should be positive", value)
+	}
+	return formatDuration(duration), nil
+}
+
+// formatDuration formats the duration in seconds as expected by istio, or returns an empty string for 0.
+func formatDuration(duration time.Duration) string {
+	if duration <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(duration.Seconds(), 'f', -1, 64) + "s"
+}
+
+func findResiliencePolicy(policy *kubernetes.ResiliencePolicy, dependency string) *kubernetes.DependencyResiliencePolicy {
+	for i, dependencyPolicy := range policy.Dependencies {
+		if dependencyPolicy.Instance == dependency {
+			return &policy.Dependencies[i]
+		}
+	}
+	return nil
+}
+
+func toObjects(resources ...interface{}) ([]map[string]interface{}, error) {
+	var objects []map[string]interface{}
+	for _, resource := range resources {
+		resourceJson, err := json.Marshal(resource)
+		if err != nil {
+			return nil, err
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal(resourceJson, &object); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
+)
+
+func TestRunSetResilience(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	for _, name := range []string{"pet-be-dep", "pet-be-target", "pet-fe-src"} {
+		cell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", name+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file", name)
+		}
+		cellMap[name] = cell
+	}
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-fe-src-vs file")
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	if err = json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs); err != nil {
+		t.Fatalf("failed to unmarshall petFeSrcVsBytes, %v", err)
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": petFeSrcVs}))
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+
+	err = RunSetResilience(mockCli, "pet-fe-src", ResilienceOptions{Dependency: "pet-be-dep", PerTryTimeout: time.Second})
+	if err == nil {
+		t.Errorf("expected an error setting a per try timeout without retries")
+	}
+	err = RunSetResilience(mockCli, "pet-fe-src", ResilienceOptions{Dependency: "pet-be-dep",
+		Timeout: 5 * time.Second, Retries: 3, PerTryTimeout: 1500 * time.Millisecond, RetryOn: "5xx",
+		ConsecutiveErrors: 5, Interval: 10 * time.Second, BaseEjectionTime: 30 * time.Second,
+		MaxEjectionPercent: 50})
+	if err != nil {
+		t.Fatalf("error in RunSetResilience, %v", err)
+	}
+	want := &kubernetes.ResiliencePolicy{
+		Dependencies: []kubernetes.DependencyResiliencePolicy{
+			{
+				Instance: "pet-be-dep",
+				Timeout:  "5s",
+				Retries:  &kubernetes.HTTPRetry{Attempts: 3, PerTryTimeout: "1.5s", RetryOn: "5xx"},
+				OutlierDetection: &kubernetes.OutlierDetection{ConsecutiveErrors: 5, Interval: "10s",
+					BaseEjectionTime: "30s", MaxEjectionPercent: 50},
+			},
+		},
+	}
+	got, err := routing.GetResilience(mockCli, "pet-fe-src")
+	if err != nil {
+		t.Fatalf("error in GetResilience, %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("invalid resilience policy (-want, +got)\n%v", diff)
+	}
+
+	// the exported policy restores the settings once cleared
+	dir, err := ioutil.TempDir("", "resilience")
+	if err != nil {
+		t.Fatalf("error creating temp dir, %v", err)
+	}
+	defer os.RemoveAll(dir)
+	policyFile := filepath.Join(dir, "policy.yaml")
+	if err = RunExportResiliencePolicy(mockCli, "pet-fe-src", policyFile); err != nil {
+		t.Fatalf("error in RunExportResiliencePolicy, %v", err)
+	}
+	emptyPolicyFile := filepath.Join(dir, "empty.yaml")
+	if err = ioutil.WriteFile(emptyPolicyFile, []byte("dependencies: []\n"), 0644); err != nil {
+		t.Fatalf("error writing policy file, %v", err)
+	}
+	if err = RunApplyResiliencePolicy(mockCli, "pet-fe-src", emptyPolicyFile); err != nil {
+		t.Fatalf("error in RunApplyResiliencePolicy, %v", err)
+	}
+	vs, err := mockKubeCli.GetVirtualService("pet-fe-src--vs")
+	if err != nil {
+		t.Fatalf("error getting virtual service, %v", err)
+	}
+	if diff := cmp.Diff(petFeSrcVs, vs); diff != "" {
+		t.Errorf("expected the resilience to be cleared (-want, +got)\n%v", diff)
+	}
+	if err = RunApplyResiliencePolicy(mockCli, "pet-fe-src", policyFile); err != nil {
+		t.Fatalf("error in RunApplyResiliencePolicy, %v", err)
+	}
+	if got, err = routing.GetResilience(mockCli, "pet-fe-src"); err != nil {
+		t.Fatalf("error in GetResilience, %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("invalid resilience policy after applying the exported policy (-want, +got)\n%v", diff)
+	}
+
+	// routing traffic keeps the timeouts and retries and carries the circuit breaking over to the target
+	err = RunRouteTrafficCommand(mockCli, []string{"pet-fe-src"}, "pet-be-dep", "pet-be-target",
+		routing.RouteOptions{Percentage: 40}, true, false, "")
+	if err != nil {
+		t.Fatalf("error in RunRouteTrafficCommand, %v", err)
+	}
+	if vs, err = mockKubeCli.GetVirtualService("pet-fe-src--vs"); err != nil {
+		t.Fatalf("error getting virtual service, %v", err)
+	}
+	for i, rule := range vs.VsSpec.HTTP {
+		if rule.Timeout != "5s" || rule.Retries == nil {
+			t.Errorf("expected the timeout and retries of rule %d to be kept, got %q and %v", i, rule.Timeout,
+				rule.Retries)
+		}
+	}
+	targetRule, err := mockKubeCli.GetInstanceBytes("destinationrules.networking.istio.io",
+		"pet-be-target--gateway-service--dr")
+	if err != nil || len(targetRule) == 0 {
+		t.Errorf("expected a destination rule for the target instance, %v", err)
+	}
+
+	// the destination rules created are deleted on rollback
+	if err = RunRouteTrafficRollback(mockCli, -1, true); err != nil {
+		t.Fatalf("error in RunRouteTrafficRollback, %v", err)
+	}
+	if targetRule, _ = mockKubeCli.GetInstanceBytes("destinationrules.networking.istio.io",
+		"pet-be-target--gateway-service--dr"); len(targetRule) != 0 {
+		t.Errorf("expected the destination rule of the target instance to be deleted")
+	}
+	dependencyRule, _ := mockKubeCli.GetInstanceBytes("destinationrules.networking.istio.io",
+		"pet-be-dep--gateway-service--dr")
+	if len(dependencyRule) == 0 {
+		t.Errorf("expected the destination rule of the dependency instance to be kept")
+	}
+	if err = RunRouteTrafficRollback(mockCli, 0, true); err != nil {
+		t.Fatalf("error in RunRouteTrafficRollback, %v", err)
+	}
+	if dependencyRule, _ = mockKubeCli.GetInstanceBytes("destinationrules.networking.istio.io",
+		"pet-be-dep--gateway-service--dr"); len(dependencyRule) != 0 {
+		t.Errorf("expected the destination rule of the dependency instance to be deleted")
+	}
+	if vs, err = mockKubeCli.GetVirtualService("pet-fe-src--vs"); err != nil {
+		t.Fatalf("error getting virtual service, %v", err)
+	}
+	if diff := cmp.Diff(petFeSrcVs, vs); diff != "" {
+		t.Errorf("invalid restored virtual service (-want, +got)\n%v", diff)
+	}
+}
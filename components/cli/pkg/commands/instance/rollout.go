@@ -0,0 +1,223 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/template"
+	"time"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/metrics"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// DefaultErrorRateQuery is the ratio of 5xx responses served by the workloads of the target instance.
+const DefaultErrorRateQuery = `sum(rate(istio_requests_total{destination_workload=~"{{.Target}}--.*",` +
+	`response_code=~"5.."}[{{.Window}}])) / ` +
+	`sum(rate(istio_requests_total{destination_workload=~"{{.Target}}--.*"}[{{.Window}}]))`
+
+// DefaultLatencyQuery is the 99th percentile of the request duration of the workloads of the target instance
+// in seconds.
+const DefaultLatencyQuery = `histogram_quantile(0.99, sum(rate(istio_request_duration_seconds_bucket{` +
+	`destination_workload=~"{{.Target}}--.*"}[{{.Window}}])) by (le))`
+
+// minAnalysisWindow is the shortest range over which the metrics are aggregated between steps.
+const minAnalysisWindow = 30 * time.Second
+
+// RolloutOptions describes how traffic is shifted from the dependency instance to the target instance.
+type RolloutOptions struct {
+	Sources      []string
+	Dependency   string
+	Target       string
+	Steps        []int
+	Pause        time.Duration
+	SessionAware bool
+	AssumeYes    bool
+	// MetricsEndpoint is the Prometheus compatible API which is queried between steps. The metrics are not
+	// analysed if it is empty.
+	MetricsEndpoint string
+	// MaxErrorRate is the highest ratio of failed requests to the target. It is not checked if negative.
+	MaxErrorRate float64
+	// MaxLatency is the highest 99th percentile latency of the target. It is not checked if zero.
+	MaxLatency     time.Duration
+	ErrorRateQuery string
+	LatencyQuery   string
+}
+
+// rolloutAnalysis checks the metrics of the target instance against the thresholds of a rollout.
+type rolloutAnalysis struct {
+	client         *metrics.Client
+	errorRateQuery string
+	latencyQuery   string
+	maxErrorRate   float64
+	maxLatency     time.Duration
+}
+
+// RunRollout shifts traffic from the dependency instance to the target instance in steps. Between steps the
+// metrics of the target are analysed and the traffic is routed back to the dependency instance if the thresholds
+// are breached or the rollout is interrupted.
+func RunRollout(cli cli.Cli, opts RolloutOptions) error {
+	routes, err := getRoutes(cli, opts.Sources, opts.Dependency, opts.Target)
+	if err != nil {
+		return err
+	}
+	// check once before starting so that the user is not prompted at each step
+	for _, route := range routes {
+		canContinue, err := checkRoute(route, opts.AssumeYes)
+		if err != nil {
+			return err
+		}
+		if !canContinue {
+			fmt.Fprintln(cli.Out(), "Aborting rollout")
+			return nil
+		}
+	}
+	analysis, err := newRolloutAnalysis(opts)
+	if err != nil {
+		return err
+	}
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+	for i, step := range opts.Steps {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Rollout step %d/%d: %d%% of traffic to instance %s", i+1,
+			len(opts.Steps), step, opts.Target))
+		if err := RunRouteTrafficCommand(cli, opts.Sources, opts.Dependency, opts.Target,
+			routing.RouteOptions{Percentage: step, SessionAware: opts.SessionAware}, true, false, ""); err != nil {
+			return revertRollout(cli, opts, fmt.Errorf("rollout failed at %d%%, %v", step, err))
+		}
+		// once all traffic is routed to the target, the dependency instance is no longer used by the sources
+		if step == 100 {
+			break
+		}
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Waiting %s before analysing instance %s", opts.Pause, opts.Target))
+		select {
+		case <-time.After(opts.Pause):
+		case <-interrupted:
+			return revertRollout(cli, opts, fmt.Errorf("rollout interrupted at %d%%", step))
+		}
+		if analysis == nil {
+			continue
+		}
+		if err := analysis.check(cli); err != nil {
+			return revertRollout(cli, opts, fmt.Errorf("rollout aborted at %d%%, %v", step, err))
+		}
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully rolled out instance %s", opts.Target))
+	return nil
+}
+
+// revertRollout routes all traffic back to the dependency instance and returns the cause of the revert.
+func revertRollout(cli cli.Cli, opts RolloutOptions, cause error) error {
+	util.PrintWarningMessage(fmt.Sprintf("%v, routing all traffic back to instance %s", cause, opts.Dependency))
+	// session awareness is disabled so that the requests of all users are routed back
+	if err := RunRouteTrafficCommand(cli, opts.Sources, opts.Dependency, opts.Target,
+		routing.RouteOptions{Percentage: 0}, true, false, ""); err != nil {
+		return fmt.Errorf("%v, failed to route traffic back to instance %s, %v", cause, opts.Dependency, err)
+	}
+	return cause
+}
+
+func newRolloutAnalysis(opts RolloutOptions) (*rolloutAnalysis, error) {
+	if opts.MetricsEndpoint == "" {
+		return nil, nil
+	}
+	window := opts.Pause
+	if window < minAnalysisWindow {
+		window = minAnalysisWindow
+	}
+	values := struct {
+		Target     string
+		Dependency string
+		Window     string
+	}{
+		Target:     opts.Target,
+		Dependency: opts.Dependency,
+		Window:     fmt.Sprintf("%ds", int(window.Seconds())),
+	}
+	analysis := &rolloutAnalysis{
+		client:       metrics.NewClient(opts.MetricsEndpoint),
+		maxErrorRate: opts.MaxErrorRate,
+		maxLatency:   opts.MaxLatency,
+	}
+	var err error
+	if opts.MaxErrorRate >= 0 {
+		if analysis.errorRateQuery, err = renderQuery(opts.ErrorRateQuery, values); err != nil {
+			return nil, err
+		}
+	}
+	if opts.MaxLatency > 0 {
+		if analysis.latencyQuery, err = renderQuery(opts.LatencyQuery, values); err != nil {
+			return nil, err
+		}
+	}
+	return analysis, nil
+}
+
+func renderQuery(query string, values interface{}) (string, error) {
+	tmpl, err := template.New("query").Parse(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid metrics query %s, %v", query, err)
+	}
+	out := &bytes.Buffer{}
+	if err := tmpl.Execute(out, values); err != nil {
+		return "", fmt.Errorf("invalid metrics query %s, %v", query, err)
+	}
+	return out.String(), nil
+}
+
+func (analysis *rolloutAnalysis) check(cli cli.Cli) error {
+	if analysis.errorRateQuery != "" {
+		errorRate, found, err := analysis.client.Query(analysis.errorRateQuery)
+		if err != nil {
+			return err
+		}
+		if !found {
+			fmt.Fprintln(cli.Out(), "No requests were served, skipping the error rate check")
+		} else {
+			fmt.Fprintln(cli.Out(), fmt.Sprintf("Error rate: %.2f%% (max %.2f%%)", errorRate*100,
+				analysis.maxErrorRate*100))
+			if errorRate > analysis.maxErrorRate {
+				return fmt.Errorf("error rate %.2f%% exceeds %.2f%%", errorRate*100, analysis.maxErrorRate*100)
+			}
+		}
+	}
+	if analysis.latencyQuery != "" {
+		seconds, found, err := analysis.client.Query(analysis.latencyQuery)
+		if err != nil {
+			return err
+		}
+		if !found {
+			fmt.Fprintln(cli.Out(), "No requests were served, skipping the latency check")
+		} else {
+			latency := time.Duration(seconds * float64(time.Second))
+			fmt.Fprintln(cli.Out(), fmt.Sprintf("Latency: %s (max %s)", latency, analysis.maxLatency))
+			if latency > analysis.maxLatency {
+				return fmt.Errorf("latency %s exceeds %s", latency, analysis.maxLatency)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+func newRolloutMockCli(t *testing.T) *test.MockCli {
+	cellMap := make(map[string][]byte)
+	for _, cell := range []string{"pet-be-dep", "pet-be-target", "pet-fe-src"} {
+		cellBytes, err := ioutil.ReadFile(filepath.Join("testdata", "cells", cell+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file", cell)
+		}
+		cellMap[cell] = cellBytes
+	}
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-fe-src-vs file")
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	if err := json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs); err != nil {
+		t.Fatalf("failed to unmarshall petFeSrcVsBytes, %v", err)
+	}
+	return test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": petFeSrcVs}))))
+}
+
+// The steps routing all traffic to the target are not tested as they read the gateways using kubectl.
+func TestRunRollout(t *testing.T) {
+	var queries []string
+	var errorRate string
+	// latencies are served in order, the last one is repeated
+	var latencies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+		value := errorRate
+		if strings.HasPrefix(query, "histogram_quantile") {
+			value = latencies[0]
+			if len(latencies) > 1 {
+				latencies = latencies[1:]
+			}
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[`+
+			`{"metric":{},"value":[1583056800.781,"%s"]}]}}`, value)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		opts        RolloutOptions
+		errorRate   string
+		latencies   []string
+		wantErr     string
+		wantSteps   []string
+		wantQueries int
+	}{
+		{
+			name: "rollout without analysis",
+			opts: RolloutOptions{Steps: []int{10, 50, 75}, MaxErrorRate: 0.05},
+			wantSteps: []string{
+				"Starting to route 10% of traffic to instance pet-be-target",
+				"Starting to route 50% of traffic to instance pet-be-target",
+				"Starting to route 75% of traffic to instance pet-be-target",
+			},
+		},
+		{
+			name: "healthy target",
+			opts: RolloutOptions{Steps: []int{10, 50}, MetricsEndpoint: server.URL, MaxErrorRate: 0.05,
+				MaxLatency: 500 * time.Millisecond},
+			errorRate: "0.01",
+			latencies: []string{"0.25"},
+			wantSteps: []string{
+				"Starting to route 10% of traffic to instance pet-be-target",
+				"Starting to route 50% of traffic to instance pet-be-target",
+			},
+			wantQueries: 4,
+		},
+		{
+			name:      "target without traffic",
+			opts:      RolloutOptions{Steps: []int{10, 50}, MetricsEndpoint: server.URL, MaxErrorRate: 0.05},
+			errorRate: "NaN",
+			wantSteps: []string{
+				"Starting to route 10% of traffic to instance pet-be-target",
+				"Starting to route 50% of traffic to instance pet-be-target",
+			},
+			wantQueries: 2,
+		},
+		{
+			name:      "error rate breached",
+			opts:      RolloutOptions{Steps: []int{10, 50, 100}, MetricsEndpoint: server.URL, MaxErrorRate: 0.05},
+			errorRate: "0.2",
+			wantErr:   "rollout aborted at 10%, error rate 20.00% exceeds 5.00%",
+			wantSteps: []string{
+				"Starting to route 10% of traffic to instance pet-be-target",
+				"Starting to route 0% of traffic to instance pet-be-target",
+			},
+			wantQueries: 1,
+		},
+		{
+			name: "latency breached",
+			opts: RolloutOptions{Steps: []int{10, 50, 100}, MetricsEndpoint: server.URL, MaxErrorRate: -1,
+				MaxLatency: 500 * time.Millisecond},
+			latencies: []string{"0.1", "0.75"},
+			wantErr:   "rollout aborted at 50%, latency 750ms exceeds 500ms",
+			wantSteps: []string{
+				"Starting to route 10% of traffic to instance pet-be-target",
+				"Starting to route 50% of traffic to instance pet-be-target",
+				"Starting to route 0% of traffic to instance pet-be-target",
+			},
+			wantQueries: 2,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			queries = nil
+			errorRate, latencies = tst.errorRate, tst.latencies
+			mockCli := newRolloutMockCli(t)
+			opts := tst.opts
+			opts.Sources = []string{"pet-fe-src"}
+			opts.Dependency = "pet-be-dep"
+			opts.Target = "pet-be-target"
+			opts.Pause = time.Millisecond
+			opts.AssumeYes = true
+			opts.ErrorRateQuery = DefaultErrorRateQuery
+			opts.LatencyQuery = DefaultLatencyQuery
+			err := RunRollout(mockCli, opts)
+			if tst.wantErr == "" {
+				if err != nil {
+					t.Fatalf("error in RunRollout, %v", err)
+				}
+			} else if err == nil {
+				t.Fatalf("expected error %s", tst.wantErr)
+			} else if diff := cmp.Diff(tst.wantErr, err.Error()); diff != "" {
+				t.Errorf("RunRollout: unexpected error (-want, +got)\n%v", diff)
+			}
+			var steps []string
+			for _, line := range strings.Split(mockCli.OutBuffer().String(), "\n") {
+				if strings.HasPrefix(line, "Starting to route") {
+					steps = append(steps, line)
+				}
+			}
+			if diff := cmp.Diff(tst.wantSteps, steps); diff != "" {
+				t.Errorf("RunRollout: unexpected steps (-want, +got)\n%v", diff)
+			}
+			if len(queries) != tst.wantQueries {
+				t.Errorf("RunRollout: expected %d metrics queries, got %d", tst.wantQueries, len(queries))
+			}
+		})
+	}
+}
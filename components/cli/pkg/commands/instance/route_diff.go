@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/diff"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+// serverMetadataFields are the metadata fields populated by the API server, which are left out of the diff.
+var serverMetadataFields = []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields",
+	"selfLink"}
+
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// diffArtifacts returns the unified diff of the objects in the artifacts file against the live objects in the
+// cluster. The objects in the file are merged into the live objects the same way they are when applied.
+func diffArtifacts(cli cli.Cli, artifactFile string) (string, error) {
+	objects, err := readArtifactObjects(artifactFile)
+	if err != nil {
+		return "", err
+	}
+	changes := &strings.Builder{}
+	for _, modified := range objects {
+		resourceName, name, err := getObjectResource(modified)
+		if err != nil {
+			return "", err
+		}
+		liveJson, err := getLiveObject(cli, modified)
+		if err != nil {
+			return "", err
+		}
+		liveYaml := ""
+		if len(liveJson) > 0 {
+			var live, merged map[string]interface{}
+			if err := json.Unmarshal(liveJson, &live); err != nil {
+				return "", fmt.Errorf("error parsing %s %s, %v", resourceName, name, err)
+			}
+			if err := json.Unmarshal(liveJson, &merged); err != nil {
+				return "", fmt.Errorf("error parsing %s %s, %v", resourceName, name, err)
+			}
+			if liveYaml, err = toDiffYaml(live); err != nil {
+				return "", err
+			}
+			modified = mergeObject(merged, modified)
+		}
+		modifiedYaml, err := toDiffYaml(modified)
+		if err != nil {
+			return "", err
+		}
+		changes.WriteString(diff.Unified(fmt.Sprintf("live/%s/%s", resourceName, name),
+			fmt.Sprintf("modified/%s/%s", resourceName, name), liveYaml, modifiedYaml))
+	}
+	return changes.String(), nil
+}
+
+// readArtifactObjects returns the objects in the artifacts file. Objects which are not modified are written
+// as null, hence are left out.
+func readArtifactObjects(artifactFile string) ([]map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(artifactFile)
+	if err != nil {
+		return nil, err
+	}
+	var objects []map[string]interface{}
+	for _, document := range regexp.MustCompile(`(?m)^---\s*$`).Split(string(content), -1) {
+		objectJson, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing routing artifacts, %v", err)
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal(objectJson, &object); err != nil {
+			return nil, fmt.Errorf("error parsing routing artifacts, %v", err)
+		}
+		if object != nil {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// getObjectResource returns the fully qualified resource name and the name of the object.
+func getObjectResource(object map[string]interface{}) (string, string, error) {
+	apiVersion, _ := object["apiVersion"].(string)
+	kind, _ := object["kind"].(string)
+	metadata, _ := object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	resourceName, err := kubernetes.ResourceName(apiVersion, kind)
+	return resourceName, name, err
+}
+
+// getLiveObject returns the live object in the cluster with the same kind and name as the given object, or
+// nothing if it does not exist.
+func getLiveObject(cli cli.Cli, object map[string]interface{}) ([]byte, error) {
+	resourceName, name, err := getObjectResource(object)
+	if err != nil {
+		return nil, err
+	}
+	liveJson, err := cli.KubeCli().GetInstanceBytes(resourceName, name)
+	if err != nil && !isNotFoundError(err) {
+		return nil, fmt.Errorf("error getting %s %s, %v", resourceName, name, err)
+	}
+	return liveJson, nil
+}
+
+// mergeObject merges the fields of the patch into the object. Nested objects are merged while other values
+// including lists are replaced.
+func mergeObject(object, patch map[string]interface{}) map[string]interface{} {
+	for key, value := range patch {
+		patchObject, isObject := value.(map[string]interface{})
+		current, isCurrentObject := object[key].(map[string]interface{})
+		if isObject && isCurrentObject {
+			object[key] = mergeObject(current, patchObject)
+		} else {
+			object[key] = value
+		}
+	}
+	return object
+}
+
+// toDiffYaml converts the object to yaml leaving out the status and the fields populated by the API server.
+func toDiffYaml(object map[string]interface{}) (string, error) {
+	out, err := yaml.Marshal(stripServerFields(object))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// stripServerFields removes the status and the fields populated by the API server from the object.
+func stripServerFields(object map[string]interface{}) map[string]interface{} {
+	delete(object, "status")
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		for _, field := range serverMetadataFields {
+			delete(metadata, field)
+		}
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, lastAppliedConfigAnnotation)
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+	return object
+}
+
+func isNotFoundError(err error) bool {
+	return strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "not found")
+}
@@ -0,0 +1,439 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// routingHistoryConfigMap keeps the routing revisions, each of which is stored gzipped under a revision-<n> key.
+const routingHistoryConfigMap = "cellery-routing-history"
+const routingRevisionKeyPrefix = "revision-"
+
+// routingHistoryLimit is the number of routing revisions kept in the history.
+const routingHistoryLimit = 10
+
+// routingRevision is a change to the routing rules along with the objects it modified, as they were before the
+// change was applied.
+type routingRevision struct {
+	Revision   int                      `json:"revision"`
+	Time       time.Time                `json:"time"`
+	Sources    []string                 `json:"sources,omitempty"`
+	Dependency string                   `json:"dependency,omitempty"`
+	Target     string                   `json:"target,omitempty"`
+	Change     string                   `json:"change"`
+	Objects    []map[string]interface{} `json:"objects,omitempty"`
+	// Created are the objects created by the change, recorded by kind and name only, which are deleted when
+	// rolling back to an earlier revision.
+	Created []map[string]interface{} `json:"created,omitempty"`
+}
+
+// routingRevisionSchema is the json and yaml representation of a routing revision.
+type routingRevisionSchema struct {
+	Revision   int       `json:"revision"`
+	Time       time.Time `json:"time"`
+	Sources    []string  `json:"sources"`
+	Dependency string    `json:"dependency"`
+	Target     string    `json:"target"`
+	Change     string    `json:"change"`
+	Objects    []string  `json:"objects"`
+}
+
+// RunRouteTrafficHistory lists the routing revisions. If an instance is given, only the revisions routing
+// traffic from or to the instance are listed.
+func RunRouteTrafficHistory(cli cli.Cli, instanceName string, opts output.Options) error {
+	history, err := getRoutingHistory(cli)
+	if err != nil {
+		return err
+	}
+	var revisions []routingRevision
+	for _, revision := range history {
+		if instanceName == "" || revision.involves(instanceName) {
+			revisions = append(revisions, revision)
+		}
+	}
+	if opts.Structured() {
+		schemas := []routingRevisionSchema{}
+		for _, revision := range revisions {
+			schemas = append(schemas, routingRevisionSchema{
+				Revision:   revision.Revision,
+				Time:       revision.Time,
+				Sources:    append([]string{}, revision.Sources...),
+				Dependency: revision.Dependency,
+				Target:     revision.Target,
+				Change:     revision.Change,
+				Objects:    revision.objectNames(),
+			})
+		}
+		return output.Write(cli.Out(), opts, schemas)
+	}
+	if len(revisions) == 0 {
+		fmt.Fprintln(cli.Out(), "No routing history found")
+		return nil
+	}
+	header := []string{"REVISION", "TIME", "SOURCES", "DEPENDENCY", "TARGET", "CHANGE"}
+	if opts.Wide() {
+		header = append(header, "OBJECTS")
+	}
+	table := output.NewTable(cli.Out(), header)
+	for _, revision := range revisions {
+		record := []string{strconv.Itoa(revision.Revision), revision.Time.Format(time.RFC3339),
+			strings.Join(revision.Sources, ","), revision.Dependency, revision.Target, revision.Change}
+		if opts.Wide() {
+			record = append(record, strings.Join(revision.objectNames(), ","))
+		}
+		table.Append(record)
+	}
+	table.Render()
+	return nil
+}
+
+// RunRouteTrafficRollback restores the routing rules, gateways and instance dependencies as they were at the
+// given revision, deleting the objects created since. A negative revision rolls back the latest change. The
+// rollback is recorded as a new revision, hence can be rolled back as well.
+func RunRouteTrafficRollback(cli cli.Cli, toRevision int, assumeYes bool) error {
+	history, err := getRoutingHistory(cli)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("no routing history found")
+	}
+	latest := history[len(history)-1].Revision
+	if toRevision < 0 {
+		toRevision = latest - 1
+	}
+	if toRevision >= latest {
+		return fmt.Errorf("revision %d is not older than the current revision %d", toRevision, latest)
+	}
+	if oldest := history[0].Revision; toRevision < oldest-1 {
+		return fmt.Errorf("revision %d is no longer in the routing history, the oldest revision is %d",
+			toRevision, oldest-1)
+	}
+	// an object is restored from the first change made to it after the revision, or deleted if that change
+	// created it
+	var objects, deleted []map[string]interface{}
+	restored := map[string]bool{}
+	for _, revision := range history {
+		if revision.Revision <= toRevision {
+			continue
+		}
+		for _, object := range revision.Objects {
+			resourceName, name, err := getObjectResource(object)
+			if err != nil {
+				return err
+			}
+			if !restored[resourceName+"/"+name] {
+				restored[resourceName+"/"+name] = true
+				objects = append(objects, object)
+			}
+		}
+		for _, object := range revision.Created {
+			resourceName, name, err := getObjectResource(object)
+			if err != nil {
+				return err
+			}
+			if !restored[resourceName+"/"+name] {
+				restored[resourceName+"/"+name] = true
+				deleted = append(deleted, object)
+			}
+		}
+	}
+	if !assumeYes {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Objects to be restored: %s",
+			strings.Join((&routingRevision{Objects: objects}).objectNames(), ", ")))
+		if len(deleted) > 0 {
+			fmt.Fprintln(cli.Out(), fmt.Sprintf("Objects to be deleted: %s",
+				strings.Join((&routingRevision{Objects: deleted}).objectNames(), ", ")))
+		}
+		canContinue, _, err := util.GetYesOrNoFromUser(fmt.Sprintf("Roll back routing to revision %d",
+			toRevision), false)
+		if err != nil {
+			return err
+		}
+		if !canContinue {
+			fmt.Fprintln(cli.Out(), "Aborting routing rollback")
+			return nil
+		}
+	}
+	current, err := snapshotObjects(cli, append(append([]map[string]interface{}{}, objects...), deleted...))
+	if err != nil {
+		return err
+	}
+	if err = cli.ExecuteTask(fmt.Sprintf("Restoring routing revision %d", toRevision),
+		"Failed to restore routing revision", "", func() error {
+			if err := restoreObjects(cli, objects, deleted); err != nil {
+				// objects might have been partially restored, hence re-apply the objects as they were
+				if revertErr := applyObjects(cli, current); revertErr != nil {
+					return fmt.Errorf("error restoring routing revision %d, %v, failed to revert the partially "+
+						"restored objects, %v", toRevision, err, revertErr)
+				}
+				return fmt.Errorf("error restoring routing revision %d, %v", toRevision, err)
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+	revision, err := recordRoutingRevision(cli, routingRevision{
+		Change:  fmt.Sprintf("rollback to revision %d", toRevision),
+		Objects: current,
+		Created: createdObjects(objects, current),
+	})
+	if err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully rolled back routing to revision %d as revision %d",
+		toRevision, revision))
+	return nil
+}
+
+// snapshotObjects returns the live objects with the same kind and name as the given objects. Objects which do
+// not exist are left out.
+func snapshotObjects(cli cli.Cli, objects []map[string]interface{}) ([]map[string]interface{}, error) {
+	var snapshot []map[string]interface{}
+	for _, object := range objects {
+		liveJson, err := getLiveObject(cli, object)
+		if err != nil {
+			return nil, err
+		}
+		if len(liveJson) == 0 {
+			continue
+		}
+		var live map[string]interface{}
+		if err := json.Unmarshal(liveJson, &live); err != nil {
+			return nil, fmt.Errorf("error parsing live object, %v", err)
+		}
+		snapshot = append(snapshot, stripServerFields(live))
+	}
+	return snapshot, nil
+}
+
+// createdObjects returns the kind and name of the objects which did not exist before they were applied, i.e.
+// which have no previous object.
+func createdObjects(objects []map[string]interface{},
+	previousObjects []map[string]interface{}) []map[string]interface{} {
+	var created []map[string]interface{}
+	for _, object := range objects {
+		resourceName, name, err := getObjectResource(object)
+		if err != nil || findObject(previousObjects, resourceName, name) != nil {
+			continue
+		}
+		created = append(created, map[string]interface{}{
+			"apiVersion": object["apiVersion"],
+			"kind":       object["kind"],
+			"metadata":   map[string]interface{}{"name": name},
+		})
+	}
+	return created
+}
+
+// restoreObjects applies the restored objects and deletes the objects which did not exist at the restored
+// revision.
+func restoreObjects(cli cli.Cli, restored []map[string]interface{}, deleted []map[string]interface{}) error {
+	if len(restored) > 0 {
+		if err := applyObjects(cli, restored); err != nil {
+			return err
+		}
+	}
+	for _, object := range deleted {
+		resourceName, name, err := getObjectResource(object)
+		if err != nil {
+			return err
+		}
+		if _, err = cli.KubeCli().DeleteResource(resourceName, name); err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("error deleting %s %s, %v", resourceName, name, err)
+		}
+	}
+	return nil
+}
+
+// applyObjects applies all the given objects at once.
+func applyObjects(cli cli.Cli, objects []map[string]interface{}) error {
+	file, err := ioutil.TempFile("", "cellery-routing-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := writeObjects(file, objects); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return cli.KubeCli().ApplyFile(file.Name())
+}
+
+// writeObjects writes the objects as yaml documents.
+func writeObjects(writer io.Writer, objects []map[string]interface{}) error {
+	for i, object := range objects {
+		content, err := yaml.Marshal(object)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			content = append([]byte("---\n"), content...)
+		}
+		if _, err := writer.Write(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getRoutingHistory returns the routing revisions ordered from the oldest to the latest.
+func getRoutingHistory(cli cli.Cli) ([]routingRevision, error) {
+	content, err := cli.KubeCli().GetInstanceBytes("configmaps", routingHistoryConfigMap)
+	if err != nil && !isNotFoundError(err) {
+		return nil, fmt.Errorf("error getting routing history, %v", err)
+	}
+	if len(content) == 0 {
+		return nil, nil
+	}
+	configMap := struct {
+		BinaryData map[string][]byte `json:"binaryData"`
+	}{}
+	if err := json.Unmarshal(content, &configMap); err != nil {
+		return nil, fmt.Errorf("error parsing routing history, %v", err)
+	}
+	var history []routingRevision
+	for key, data := range configMap.BinaryData {
+		if !strings.HasPrefix(key, routingRevisionKeyPrefix) {
+			continue
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("error reading routing %s, %v", key, err)
+		}
+		revisionJson, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error reading routing %s, %v", key, err)
+		}
+		var revision routingRevision
+		if err := json.Unmarshal(revisionJson, &revision); err != nil {
+			return nil, fmt.Errorf("error parsing routing %s, %v", key, err)
+		}
+		history = append(history, revision)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Revision < history[j].Revision
+	})
+	return history, nil
+}
+
+// recordRoutingRevision adds the change to the routing history as the latest revision, dropping the oldest
+// revisions beyond the history limit, and returns the number of the new revision.
+func recordRoutingRevision(cli cli.Cli, revision routingRevision) (int, error) {
+	history, err := getRoutingHistory(cli)
+	if err != nil {
+		return 0, err
+	}
+	revision.Revision = 1
+	if len(history) > 0 {
+		revision.Revision = history[len(history)-1].Revision + 1
+	}
+	revision.Time = time.Now().UTC().Truncate(time.Second)
+	history = append(history, revision)
+	if len(history) > routingHistoryLimit {
+		history = history[len(history)-routingHistoryLimit:]
+	}
+	binaryData := map[string][]byte{}
+	for _, revision := range history {
+		revisionJson, err := json.Marshal(revision)
+		if err != nil {
+			return 0, err
+		}
+		var data bytes.Buffer
+		writer := gzip.NewWriter(&data)
+		if _, err := writer.Write(revisionJson); err != nil {
+			return 0, err
+		}
+		if err := writer.Close(); err != nil {
+			return 0, err
+		}
+		binaryData[routingRevisionKeyPrefix+strconv.Itoa(revision.Revision)] = data.Bytes()
+	}
+	configMap := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": routingHistoryConfigMap,
+		},
+		"binaryData": binaryData,
+	}
+	// the object is converted through json so that the binary data is base64 encoded
+	configMapJson, err := json.Marshal(configMap)
+	if err != nil {
+		return 0, err
+	}
+	var configMapObject map[string]interface{}
+	if err := json.Unmarshal(configMapJson, &configMapObject); err != nil {
+		return 0, err
+	}
+	if err := applyObjects(cli, []map[string]interface{}{configMapObject}); err != nil {
+		return 0, fmt.Errorf("error recording routing revision %d, %v", revision.Revision, err)
+	}
+	return revision.Revision, nil
+}
+
+// involves returns true if the revision routed traffic from or to the instance.
+func (revision *routingRevision) involves(instanceName string) bool {
+	// a routing plan changes several dependencies, which are recorded comma separated
+	instances := append(strings.Split(revision.Dependency, ","), strings.Split(revision.Target, ",")...)
+	for _, instance := range append(instances, revision.Sources...) {
+		if instance == instanceName {
+			return true
+		}
+	}
+	for _, object := range append(append([]map[string]interface{}{}, revision.Objects...), revision.Created...) {
+		_, name, _ := getObjectResource(object)
+		if name == instanceName || strings.HasPrefix(name, instanceName+"--") {
+			return true
+		}
+	}
+	return false
+}
+
+// objectNames returns the kind and name of the objects modified or created by the revision, e.g.
+// VirtualService/hr--vs.
+func (revision *routingRevision) objectNames() []string {
+	names := []string{}
+	for _, object := range append(append([]map[string]interface{}{}, revision.Objects...), revision.Created...) {
+		kind, _ := object["kind"].(string)
+		metadata, _ := object["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		names = append(names, kind+"/"+name)
+	}
+	return names
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/routing"
+)
+
+func TestRouteTrafficHistoryAndRollback(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	for _, cell := range []string{"pet-be-dep", "pet-be-target", "pet-fe-src"} {
+		cellBytes, err := ioutil.ReadFile(filepath.Join("testdata", "cells", cell+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file", cell)
+		}
+		cellMap[cell] = cellBytes
+	}
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-fe-src-vs file")
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	if err = json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs); err != nil {
+		t.Fatalf("failed to unmarshall petFeSrcVsBytes, %v", err)
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": petFeSrcVs}))
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+
+	if err := RunRouteTrafficRollback(mockCli, -1, true); err == nil {
+		t.Errorf("expected an error rolling back without routing history")
+	}
+	match, err := routing.ParseMatch("header:x-tenant=beta")
+	if err != nil {
+		t.Fatalf("error in ParseMatch, %v", err)
+	}
+	for _, options := range []routing.RouteOptions{{Percentage: 40}, {Percentage: 100,
+		Matches: []kubernetes.HTTPMatch{match}}} {
+		if err := RunRouteTrafficCommand(mockCli, []string{"pet-fe-src"}, "pet-be-dep", "pet-be-target", options,
+			true, false, ""); err != nil {
+			t.Fatalf("error in RunRouteTrafficCommand, %v", err)
+		}
+	}
+	routedVs, err := mockKubeCli.GetVirtualService("pet-fe-src--vs")
+	if err != nil {
+		t.Fatalf("error getting virtual service, %v", err)
+	}
+	if diff := cmp.Diff(petFeSrcVs, routedVs); diff == "" {
+		t.Errorf("expected the virtual service to be modified by the routing")
+	}
+
+	if err := RunRouteTrafficRollback(mockCli, 2, true); err == nil {
+		t.Errorf("expected an error rolling back to the current revision")
+	}
+	if err := RunRouteTrafficRollback(mockCli, 0, true); err != nil {
+		t.Fatalf("error in RunRouteTrafficRollback, %v", err)
+	}
+	restoredVs, err := mockKubeCli.GetVirtualService("pet-fe-src--vs")
+	if err != nil {
+		t.Fatalf("error getting virtual service, %v", err)
+	}
+	if diff := cmp.Diff(petFeSrcVs, restoredVs); diff != "" {
+		t.Errorf("invalid restored virtual service (-want, +got)\n%v", diff)
+	}
+
+	mockCli.OutBuffer().Reset()
+	opts := output.Options{Template: "{{range .}}{{.revision}}|{{.sources}}|{{.dependency}}|{{.target}}|" +
+		"{{.change}}|{{.objects}}\n{{end}}"}
+	if err := RunRouteTrafficHistory(mockCli, "pet-fe-src", opts); err != nil {
+		t.Fatalf("error in RunRouteTrafficHistory, %v", err)
+	}
+	want := "1|[pet-fe-src]|pet-be-dep|pet-be-target|40% of traffic|[VirtualService/pet-fe-src--vs]\n" +
+		"2|[pet-fe-src]|pet-be-dep|pet-be-target|100% of matching traffic|[VirtualService/pet-fe-src--vs]\n" +
+		"3|[]|||rollback to revision 0|[VirtualService/pet-fe-src--vs]\n"
+	if diff := cmp.Diff(want, mockCli.OutBuffer().String()); diff != "" {
+		t.Errorf("invalid history (-want, +got)\n%v", diff)
+	}
+	mockCli.OutBuffer().Reset()
+	if err := RunRouteTrafficHistory(mockCli, "pet-be-auto", opts); err != nil {
+		t.Fatalf("error in RunRouteTrafficHistory, %v", err)
+	}
+	if got := mockCli.OutBuffer().String(); got != "" {
+		t.Errorf("expected no revisions for an instance not involved in routing, got %q", got)
+	}
+}
@@ -0,0 +1,416 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// plannedRouting is a route of a routing plan along with the options it is built with.
+type plannedRouting struct {
+	kubernetes.PlannedRoute
+	options routing.RouteOptions
+}
+
+// RunApplyRoutingPlan routes the traffic of all the dependencies in the routing plan file to their targets in a
+// single operation. All the routes are checked before any rule is built, and the rules are applied all or nothing.
+func RunApplyRoutingPlan(cli cli.Cli, file string, assumeYes bool, dryRun bool, outputDir string) error {
+	plannedRoutes, err := readRoutingPlan(file)
+	if err != nil {
+		return err
+	}
+	for _, plannedRoute := range plannedRoutes {
+		routes, err := getRoutes(cli, plannedRoute.Sources, plannedRoute.Dependency, plannedRoute.Target)
+		if err != nil {
+			return err
+		}
+		canContinue, err := checkRoutes(routes, assumeYes)
+		if err != nil {
+			return err
+		}
+		if !canContinue {
+			fmt.Fprintln(cli.Out(), "Aborting traffic routing")
+			return nil
+		}
+	}
+	// a route is built on the rules built by the previous routes, as routes might modify the same objects
+	staged := newStagedCli(cli)
+	for _, plannedRoute := range plannedRoutes {
+		change, _ := describeRouting(plannedRoute.options)
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Starting to %s of instance %s to instance %s", change,
+			plannedRoute.Dependency, plannedRoute.Target))
+		objects, err := buildPlannedRoute(staged, plannedRoute)
+		if err != nil {
+			return fmt.Errorf("error building routes of dependency %s, %v", plannedRoute.Dependency, err)
+		}
+		staged.kubeCli.stage(objects)
+	}
+	objects := staged.kubeCli.objects
+
+	planName := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	artifactFile := fmt.Sprintf("./%s-routing-artifacts.yaml", planName)
+	if outputDir != "" {
+		if err = os.MkdirAll(outputDir, os.ModePerm); err != nil {
+			return fmt.Errorf("error creating output directory %s, %v", outputDir, err)
+		}
+		artifactFile = filepath.Join(outputDir, fmt.Sprintf("%s-routing-artifacts.yaml", planName))
+	} else {
+		defer os.Remove(artifactFile)
+	}
+	if err = writeArtifactObjects(artifactFile, objects); err != nil {
+		return fmt.Errorf("error writing routing artifacts, %v", err)
+	}
+	if outputDir != "" {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Routing artifacts written to %s", artifactFile))
+	}
+	if dryRun {
+		changes, err := diffArtifacts(cli, artifactFile)
+		if err != nil {
+			return err
+		}
+		if changes == "" {
+			fmt.Fprintln(cli.Out(), "No changes to the routing rules")
+		} else {
+			fmt.Fprint(cli.Out(), changes)
+		}
+		return nil
+	}
+
+	previousObjects, err := snapshotObjects(cli, objects)
+	if err != nil {
+		return err
+	}
+	if err = cli.ExecuteTask("Applying modified rules", "Failed to apply modified rules", "", func() error {
+		return applyAllOrNothing(cli, objects, previousObjects)
+	}); err != nil {
+		return err
+	}
+	var dependencies, targets []string
+	for _, plannedRoute := range plannedRoutes {
+		dependencies = append(dependencies, plannedRoute.Dependency)
+		targets = append(targets, plannedRoute.Target)
+	}
+	created := createdObjects(objects, previousObjects)
+	if len(previousObjects) > 0 || len(created) > 0 {
+		if _, err = recordRoutingRevision(cli, routingRevision{
+			Sources:    getRoutedSources(objects),
+			Dependency: strings.Join(dependencies, ","),
+			Target:     strings.Join(targets, ","),
+			Change:     fmt.Sprintf("apply routing plan %s", planName),
+			Objects:    previousObjects,
+			Created:    created,
+		}); err != nil {
+			return fmt.Errorf("modified rules are applied, but %v", err)
+		}
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully applied routing plan %s", file))
+	return nil
+}
+
+// readRoutingPlan reads and validates the routes of the routing plan file.
+func readRoutingPlan(file string) ([]plannedRouting, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s, %v", file, err)
+	}
+	plan := &kubernetes.RoutingPlan{}
+	if err = yaml.Unmarshal(content, plan); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall data in file %s, %v", file, err)
+	}
+	if len(plan.Routes) == 0 {
+		return nil, fmt.Errorf("no routes found in routing plan %s", file)
+	}
+	var plannedRoutes []plannedRouting
+	// an instance can be routed only once, since each route is built on the live targets
+	routedInstances := map[string]bool{}
+	for _, route := range plan.Routes {
+		plannedRoute, err := validatePlannedRoute(route)
+		if err != nil {
+			return nil, fmt.Errorf("invalid routing plan %s, %v", file, err)
+		}
+		for _, instance := range []string{route.Dependency, route.Target} {
+			if routedInstances[instance] {
+				return nil, fmt.Errorf("invalid routing plan %s, instance %s is routed more than once", file,
+					instance)
+			}
+			routedInstances[instance] = true
+		}
+		plannedRoutes = append(plannedRoutes, plannedRoute)
+	}
+	return plannedRoutes, nil
+}
+
+// validatePlannedRoute validates the route and returns it along with its route options.
+func validatePlannedRoute(route kubernetes.PlannedRoute) (plannedRouting, error) {
+	if route.Dependency == "" || route.Target == "" {
+		return plannedRouting{}, fmt.Errorf("dependency and target are mandatory for each route")
+	}
+	if route.Dependency == route.Target {
+		return plannedRouting{}, fmt.Errorf("traffic of instance %s cannot be routed to itself", route.Dependency)
+	}
+	for _, instance := range append([]string{route.Dependency, route.Target}, route.Sources...) {
+		if !regexp.MustCompile(fmt.Sprintf("^%s$", constants.CelleryIdPattern)).MatchString(instance) {
+			return plannedRouting{}, fmt.Errorf("expects a valid cell instance name, received '%s'", instance)
+		}
+	}
+	options := routing.RouteOptions{Percentage: 100, SessionAware: route.SessionAware}
+	if route.Percentage != nil {
+		options.Percentage = *route.Percentage
+	}
+	if options.Percentage < 0 || options.Percentage > 100 {
+		return plannedRouting{}, fmt.Errorf("invalid percentage %d for dependency %s", options.Percentage,
+			route.Dependency)
+	}
+	if len(route.Match) > 0 && route.SessionAware {
+		return plannedRouting{}, fmt.Errorf("match cannot be used with enableSessionAwareness for dependency %s",
+			route.Dependency)
+	}
+	for _, expression := range route.Match {
+		match, err := routing.ParseMatch(expression)
+		if err != nil {
+			return plannedRouting{}, err
+		}
+		options.Matches = append(options.Matches, match)
+	}
+	return plannedRouting{PlannedRoute: route, options: options}, nil
+}
+
+// buildPlannedRoute builds the modified rules of the route and returns the modified objects.
+func buildPlannedRoute(cli cli.Cli, plannedRoute plannedRouting) ([]map[string]interface{}, error) {
+	file, err := ioutil.TempFile("", "cellery-routing-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+	routes, err := getRoutes(cli, plannedRoute.Sources, plannedRoute.Dependency, plannedRoute.Target)
+	if err != nil {
+		return nil, err
+	}
+	if err = buildRoutes(cli, routes, plannedRoute.Target, plannedRoute.options, file.Name()); err != nil {
+		return nil, err
+	}
+	if err = appendTargetDestinationRules(cli, file.Name(), plannedRoute.Dependency, plannedRoute.Target); err != nil {
+		return nil, fmt.Errorf("error building destination rules of target instance %s, %v", plannedRoute.Target,
+			err)
+	}
+	return readArtifactObjects(file.Name())
+}
+
+// writeArtifactObjects writes the objects to the artifacts file, replacing the artifacts left by a previous run.
+func writeArtifactObjects(artifactFile string, objects []map[string]interface{}) error {
+	file, err := os.Create(artifactFile)
+	if err != nil {
+		return err
+	}
+	if err = writeObjects(file, objects); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// applyAllOrNothing applies the objects one by one. If an object cannot be applied, the objects applied so far
+// are restored as they were, or deleted if they did not exist.
+func applyAllOrNothing(cli cli.Cli, objects []map[string]interface{},
+	previousObjects []map[string]interface{}) error {
+	for i, object := range objects {
+		err := applyObjects(cli, []map[string]interface{}{object})
+		if err == nil {
+			continue
+		}
+		resourceName, name, _ := getObjectResource(object)
+		if revertErr := revertObjects(cli, objects[:i], previousObjects); revertErr != nil {
+			return fmt.Errorf("error applying %s %s, %v, failed to revert the applied objects, %v", resourceName,
+				name, err, revertErr)
+		}
+		return fmt.Errorf("error applying %s %s, all the applied objects are reverted, %v", resourceName, name, err)
+	}
+	return nil
+}
+
+// revertObjects restores the applied objects from the previous objects. Applied objects which did not exist
+// previously are deleted.
+func revertObjects(cli cli.Cli, appliedObjects []map[string]interface{},
+	previousObjects []map[string]interface{}) error {
+	var restored []map[string]interface{}
+	for _, applied := range appliedObjects {
+		resourceName, name, err := getObjectResource(applied)
+		if err != nil {
+			return err
+		}
+		if previous := findObject(previousObjects, resourceName, name); previous != nil {
+			restored = append(restored, previous)
+			continue
+		}
+		if _, err = cli.KubeCli().DeleteResource(resourceName, name); err != nil {
+			return fmt.Errorf("error deleting %s %s, %v", resourceName, name, err)
+		}
+	}
+	if len(restored) == 0 {
+		return nil
+	}
+	return applyObjects(cli, restored)
+}
+
+// findObject returns the object with the given resource name and name, or nil if there is none.
+func findObject(objects []map[string]interface{}, resourceName string, name string) map[string]interface{} {
+	for _, object := range objects {
+		if objectResourceName, objectName, _ := getObjectResource(object); objectResourceName == resourceName &&
+			objectName == name {
+			return object
+		}
+	}
+	return nil
+}
+
+// stagedCli is a cli of which the kubernetes client reads the staged objects instead of the live ones.
+type stagedCli struct {
+	cli.Cli
+	kubeCli *stagedKubeCli
+}
+
+// stagedKubeCli returns the staged cells, composites, virtual services and destination rules in place of the
+// live ones.
+type stagedKubeCli struct {
+	kubernetes.KubeCli
+	objects []map[string]interface{}
+}
+
+func newStagedCli(cli cli.Cli) *stagedCli {
+	return &stagedCli{Cli: cli, kubeCli: &stagedKubeCli{KubeCli: cli.KubeCli()}}
+}
+
+func (cli *stagedCli) KubeCli() kubernetes.KubeCli {
+	return cli.kubeCli
+}
+
+// stage adds the objects to the staged objects, replacing the staged objects with the same kind and name.
+func (kubeCli *stagedKubeCli) stage(objects []map[string]interface{}) {
+	for _, object := range objects {
+		resourceName, name, _ := getObjectResource(object)
+		replaced := false
+		for i, staged := range kubeCli.objects {
+			if stagedResourceName, stagedName, _ := getObjectResource(staged); stagedResourceName == resourceName &&
+				stagedName == name {
+				kubeCli.objects[i] = object
+				replaced = true
+			}
+		}
+		if !replaced {
+			kubeCli.objects = append(kubeCli.objects, object)
+		}
+	}
+}
+
+// getStaged unmarshalls the staged object into the given value and returns true if the object is staged.
+func (kubeCli *stagedKubeCli) getStaged(resourceName string, name string, value interface{}) (bool, error) {
+	object := findObject(kubeCli.objects, resourceName, name)
+	if object == nil {
+		return false, nil
+	}
+	objectJson, err := json.Marshal(object)
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(objectJson, value)
+}
+
+func (kubeCli *stagedKubeCli) GetCell(cellName string) (kubernetes.Cell, error) {
+	cell := kubernetes.Cell{}
+	if staged, err := kubeCli.getStaged(celleryInstance, cellName, &cell); staged || err != nil {
+		return cell, err
+	}
+	return kubeCli.KubeCli.GetCell(cellName)
+}
+
+func (kubeCli *stagedKubeCli) GetComposite(compositeName string) (kubernetes.Composite, error) {
+	composite := kubernetes.Composite{}
+	if staged, err := kubeCli.getStaged(celleryComposite, compositeName, &composite); staged || err != nil {
+		return composite, err
+	}
+	return kubeCli.KubeCli.GetComposite(compositeName)
+}
+
+func (kubeCli *stagedKubeCli) GetCells() ([]kubernetes.Cell, error) {
+	cells, err := kubeCli.KubeCli.GetCells()
+	if err != nil {
+		return nil, err
+	}
+	for i, cell := range cells {
+		stagedCell := kubernetes.Cell{}
+		staged, err := kubeCli.getStaged(celleryInstance, cell.CellMetaData.Name, &stagedCell)
+		if err != nil {
+			return nil, err
+		}
+		if staged {
+			cells[i] = stagedCell
+		}
+	}
+	return cells, nil
+}
+
+func (kubeCli *stagedKubeCli) GetComposites() ([]kubernetes.Composite, error) {
+	composites, err := kubeCli.KubeCli.GetComposites()
+	if err != nil {
+		return nil, err
+	}
+	for i, composite := range composites {
+		stagedComposite := kubernetes.Composite{}
+		staged, err := kubeCli.getStaged(celleryComposite, composite.CompositeMetaData.Name, &stagedComposite)
+		if err != nil {
+			return nil, err
+		}
+		if staged {
+			composites[i] = stagedComposite
+		}
+	}
+	return composites, nil
+}
+
+func (kubeCli *stagedKubeCli) GetVirtualService(vs string) (kubernetes.VirtualService, error) {
+	stagedVs := kubernetes.VirtualService{}
+	if staged, err := kubeCli.getStaged(virtualService, vs, &stagedVs); staged || err != nil {
+		return stagedVs, err
+	}
+	return kubeCli.KubeCli.GetVirtualService(vs)
+}
+
+func (kubeCli *stagedKubeCli) GetInstanceBytes(instanceKind, InstanceName string) ([]byte, error) {
+	if object := findObject(kubeCli.objects, instanceKind, InstanceName); object != nil {
+		return json.Marshal(object)
+	}
+	return kubeCli.KubeCli.GetInstanceBytes(instanceKind, InstanceName)
+}
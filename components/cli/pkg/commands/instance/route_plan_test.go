@@ -0,0 +1,185 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+func TestRunApplyRoutingPlan(t *testing.T) {
+	readFile := func(path ...string) string {
+		content, err := ioutil.ReadFile(filepath.Join(path...))
+		if err != nil {
+			t.Fatalf("failed to read mock file %s, %v", filepath.Join(path...), err)
+		}
+		return string(content)
+	}
+	petBeDepCell := readFile("testdata", "cells", "pet-be-dep.json")
+	petBeTargetCell := readFile("testdata", "cells", "pet-be-target.json")
+	petFeSrcVs := readFile("testdata", "virtual-services", "pet-fe-src-vs.json")
+	// pet-fe-src depends on pet-be-dep and stock-dep, while stock-fe-src depends only on stock-dep
+	srcCell := func(name string, dependencies string) []byte {
+		cell := kubernetes.Cell{}
+		if err := json.Unmarshal([]byte(readFile("testdata", "cells", "pet-fe-src.json")), &cell); err != nil {
+			t.Fatalf("failed to unmarshall pet-fe-src cell, %v", err)
+		}
+		cell.CellMetaData.Name = name
+		cell.CellMetaData.Annotations.Dependencies = dependencies
+		cellBytes, err := json.Marshal(cell)
+		if err != nil {
+			t.Fatalf("failed to marshall %s cell, %v", name, err)
+		}
+		return cellBytes
+	}
+	const petBeDependency = `{"org":"myorg","name":"petbe","version":"1.0.0","instance":"pet-be-dep","kind":"Cell"}`
+	const stockDependency = `{"org":"myorg","name":"stock","version":"1.0.0","instance":"stock-dep","kind":"Cell"}`
+	srcVs := func(src string, dependencies ...string) kubernetes.VirtualService {
+		vs := kubernetes.VirtualService{}
+		for _, dependency := range dependencies {
+			dependencyVs := kubernetes.VirtualService{}
+			vsJson := strings.Replace(strings.Replace(petFeSrcVs, "pet-fe-src", src, -1), "pet-be-dep", dependency, -1)
+			if err := json.Unmarshal([]byte(vsJson), &dependencyVs); err != nil {
+				t.Fatalf("failed to unmarshall %s virtual service, %v", src, err)
+			}
+			if vs.Kind == "" {
+				vs = dependencyVs
+				continue
+			}
+			vs.VsSpec.Hosts = append(vs.VsSpec.Hosts, dependencyVs.VsSpec.Hosts...)
+			vs.VsSpec.HTTP = append(vs.VsSpec.HTTP, dependencyVs.VsSpec.HTTP...)
+		}
+		return vs
+	}
+	const plan = `
+routes:
+- dependency: pet-be-dep
+  target: pet-be-target
+  percentage: 40
+  sources: [pet-fe-src]
+- dependency: stock-dep
+  target: stock-target
+  percentage: 30
+  sources: [pet-fe-src, stock-fe-src]
+`
+	tests := []struct {
+		name           string
+		plan           string
+		failingApply   string
+		wantErr        string
+		wantPetFeSrc   map[string]int
+		wantStockFeSrc map[string]int
+	}{
+		{
+			name:           "route two dependencies",
+			plan:           plan,
+			wantPetFeSrc:   map[string]int{"pet-be-target--gateway-service": 40, "stock-target--gateway-service": 30},
+			wantStockFeSrc: map[string]int{"stock-target--gateway-service": 30},
+		},
+		{
+			name:           "revert applied objects",
+			plan:           plan,
+			failingApply:   "stock-fe-src--vs",
+			wantErr:        "error applying virtualservices.networking.istio.io stock-fe-src--vs, all the applied objects are reverted",
+			wantPetFeSrc:   map[string]int{},
+			wantStockFeSrc: map[string]int{},
+		},
+		{
+			name:    "instance routed twice",
+			plan:    strings.Replace(plan, "stock-target", "pet-be-target", 1),
+			wantErr: "instance pet-be-target is routed more than once",
+		},
+		{
+			name:    "invalid percentage",
+			plan:    strings.Replace(plan, "percentage: 30", "percentage: 130", 1),
+			wantErr: "invalid percentage 130 for dependency stock-dep",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			opts := []func(*test.MockKubeCli){
+				test.WithCellsAsBytes(map[string][]byte{
+					"pet-be-dep":    []byte(petBeDepCell),
+					"pet-be-target": []byte(petBeTargetCell),
+					"stock-dep":     []byte(strings.Replace(petBeDepCell, "pet-be-dep", "stock-dep", -1)),
+					"stock-target":  []byte(strings.Replace(petBeTargetCell, "pet-be-target", "stock-target", -1)),
+					"pet-fe-src":    srcCell("pet-fe-src", "["+petBeDependency+","+stockDependency+"]"),
+					"stock-fe-src":  srcCell("stock-fe-src", "["+stockDependency+"]"),
+				}),
+				test.WithVirtualServices(map[string]kubernetes.VirtualService{
+					"pet-fe-src--vs":   srcVs("pet-fe-src", "pet-be-dep", "stock-dep"),
+					"stock-fe-src--vs": srcVs("stock-fe-src", "stock-dep"),
+				}),
+			}
+			if tst.failingApply != "" {
+				opts = append(opts, test.WithFailingApply(tst.failingApply))
+			}
+			mockKubeCli := test.NewMockKubeCli(opts...)
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			planFile, err := ioutil.TempFile("", "routing-plan-*.yaml")
+			if err != nil {
+				t.Fatalf("error creating routing plan, %v", err)
+			}
+			defer os.Remove(planFile.Name())
+			if _, err = planFile.WriteString(tst.plan); err != nil {
+				t.Fatalf("error writing routing plan, %v", err)
+			}
+			_ = planFile.Close()
+
+			err = RunApplyRoutingPlan(mockCli, planFile.Name(), true, false, "")
+			if tst.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErr) {
+					t.Fatalf("expected error %s, got %v", tst.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("error in RunApplyRoutingPlan, %v", err)
+			}
+			if tst.wantPetFeSrc == nil {
+				return
+			}
+			for vsName, want := range map[string]map[string]int{"pet-fe-src--vs": tst.wantPetFeSrc,
+				"stock-fe-src--vs": tst.wantStockFeSrc} {
+				vs, err := mockKubeCli.GetVirtualService(vsName)
+				if err != nil {
+					t.Fatalf("error getting virtual service %s, %v", vsName, err)
+				}
+				got := map[string]int{}
+				for _, rule := range vs.VsSpec.HTTP {
+					for _, route := range rule.Route {
+						if route.Weight > 0 && strings.Contains(route.Destination.Host, "target") {
+							got[route.Destination.Host] = route.Weight
+						}
+					}
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("invalid weights of the targets in %s (-want, +got)\n%v", vsName, diff)
+				}
+			}
+		})
+	}
+}
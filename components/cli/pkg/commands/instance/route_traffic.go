@@ -21,6 +21,9 @@ package instance
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"cellery.io/cellery/components/cli/cli"
 	errorpkg "cellery.io/cellery/components/cli/pkg/error"
@@ -28,16 +31,69 @@ import (
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
-func RunRouteTrafficCommand(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string, percentage int,
-	enableUserBasedSessionAwareness bool, assumeYes bool) error {
+func RunRouteTrafficCommand(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string,
+	routeOptions routing.RouteOptions, assumeYes bool, dryRun bool, outputDir string) error {
+	_, err := routeTraffic(cli, sourceInstances, dependencyInstance, targetInstance, routeOptions, assumeYes, dryRun,
+		outputDir)
+	return err
+}
+
+// routeTraffic routes the traffic as RunRouteTrafficCommand does, and returns false if the user declined to route
+// the traffic.
+func routeTraffic(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string,
+	routeOptions routing.RouteOptions, assumeYes bool, dryRun bool, outputDir string) (bool, error) {
 	var err error
 	artifactFile := fmt.Sprintf("./%s-routing-artifacts.yaml", dependencyInstance)
-	defer func() error {
-		return os.Remove(artifactFile)
-	}()
-	buildRouteArtifact(cli, sourceInstances, dependencyInstance, targetInstance, percentage,
-		enableUserBasedSessionAwareness, assumeYes)
+	if outputDir != "" {
+		if err = os.MkdirAll(outputDir, os.ModePerm); err != nil {
+			return false, fmt.Errorf("error creating output directory %s, %v", outputDir, err)
+		}
+		artifactFile = filepath.Join(outputDir, fmt.Sprintf("%s-routing-artifacts.yaml", dependencyInstance))
+	} else {
+		defer func() error {
+			return os.Remove(artifactFile)
+		}()
+	}
+	// artifacts are appended to the file, hence remove the artifacts left by a previous run
+	if err = os.Remove(artifactFile); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	routed, err := buildRouteArtifact(cli, sourceInstances, dependencyInstance, targetInstance, routeOptions,
+		assumeYes, artifactFile)
+	if err != nil || !routed {
+		return false, err
+	}
+	if !routeOptions.Mirror {
+		if err = appendTargetDestinationRules(cli, artifactFile, dependencyInstance, targetInstance); err != nil {
+			return false, fmt.Errorf("error building destination rules of target instance %s, %v", targetInstance,
+				err)
+		}
+	}
+	if outputDir != "" {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Routing artifacts written to %s", artifactFile))
+	}
+	if dryRun {
+		changes, err := diffArtifacts(cli, artifactFile)
+		if err != nil {
+			return false, err
+		}
+		if changes == "" {
+			fmt.Fprintln(cli.Out(), "No changes to the routing rules")
+		} else {
+			fmt.Fprint(cli.Out(), changes)
+		}
+		return true, nil
+	}
 
+	// the objects are recorded as they were before the change so that the change can be rolled back
+	objects, err := readArtifactObjects(artifactFile)
+	if err != nil {
+		return false, err
+	}
+	previousObjects, err := snapshotObjects(cli, objects)
+	if err != nil {
+		return false, err
+	}
 	if err = cli.ExecuteTask("Applying modified rules", "Failed to apply modified rules", "", func() error {
 		err = cli.KubeCli().ApplyFile(artifactFile)
 		if err != nil {
@@ -45,53 +101,60 @@ func RunRouteTrafficCommand(cli cli.Cli, sourceInstances []string, dependencyIns
 		}
 		return nil
 	}); err != nil {
-		return err
+		return false, err
 	}
-	util.PrintSuccessMessage(fmt.Sprintf("Successfully routed %d%% of traffic to instance %s", percentage,
-		targetInstance))
-	return nil
+	created := createdObjects(objects, previousObjects)
+	if len(previousObjects) > 0 || len(created) > 0 {
+		if _, err = recordRoutingRevision(cli, routingRevision{
+			Sources:    getRoutedSources(objects),
+			Dependency: dependencyInstance,
+			Target:     targetInstance,
+			Change:     describeRevision(routeOptions),
+			Objects:    previousObjects,
+			Created:    created,
+		}); err != nil {
+			return false, fmt.Errorf("modified rules are applied, but %v", err)
+		}
+	}
+	_, appliedChange := describeRouting(routeOptions)
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully %s to instance %s", appliedChange, targetInstance))
+	return true, nil
 }
 
-func buildRouteArtifact(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string, percentage int,
-	enableUserBasedSessionAwareness bool, assumeYes bool) error {
-	fmt.Fprintln(cli.Out(), fmt.Sprintf("Starting to route %d%% of traffic to instance %s", percentage,
-		targetInstance))
+// buildRouteArtifact writes the modified rules to the artifacts file, and returns false if the user declined to
+// route the traffic.
+func buildRouteArtifact(cli cli.Cli, sourceInstances []string, dependencyInstance string, targetInstance string,
+	routeOptions routing.RouteOptions, assumeYes bool, artifactFile string) (bool, error) {
+	change, _ := describeRouting(routeOptions)
+	fmt.Fprintln(cli.Out(), fmt.Sprintf("Starting to %s to instance %s", change, targetInstance))
 
-	// check the source instance and see if the dependency exists in the source
-	routes, err := routing.GetRoutes(cli, sourceInstances, dependencyInstance, targetInstance)
+	routes, err := getRoutes(cli, sourceInstances, dependencyInstance, targetInstance)
 	if err != nil {
-		return err
+		return false, err
 	}
-	// now we have the source instance list which actually depend on the given dependency instance.
-	// get the virtual services corresponding to the given source instances and modify accordingly.
-	if len(routes) == 0 {
-		// no depending instances
-		return fmt.Errorf("cell/composite instance %s not found among dependencies of source instance(s)",
-			dependencyInstance)
+	canContinue, err := checkRoutes(routes, assumeYes)
+	if err != nil {
+		return false, err
 	}
-	artifactFile := fmt.Sprintf("./%s-routing-artifacts.yaml", dependencyInstance)
-	for _, route := range routes {
-		err := route.Check()
-		if err != nil {
-			// if this is a CellGwApiVersionMismatchError, need to print a warning and prompt user for action
-			if versionErr, match := err.(errorpkg.CellGwApiVersionMismatchError); match {
-				if !assumeYes {
-					// prompt confirmation from user
-					canContinue, err := canContinueWithWarning(versionErr.ApiContext, versionErr.CurrentTargetApiVersion, versionErr.NewTargetApiVersion)
-					if err != nil {
-						return err
-					}
-					if !canContinue {
-						fmt.Fprintln(cli.Out(), "Aborting traffic routing")
-						return nil
-					}
-				}
-			} else {
+	if !canContinue {
+		fmt.Fprintln(cli.Out(), "Aborting traffic routing")
+		return false, nil
+	}
+	return true, buildRoutes(cli, routes, targetInstance, routeOptions, artifactFile)
+}
+
+// buildRoutes appends the modified rules of the routes to the artifacts file.
+func buildRoutes(cli cli.Cli, routes []routing.Route, targetInstance string, routeOptions routing.RouteOptions,
+	artifactFile string) error {
+	for i, route := range routes {
+		// the artifacts of a route do not end with a document separator
+		if i > 0 {
+			if err := appendDocumentSeparator(artifactFile); err != nil {
 				return err
 			}
 		}
-		if err = cli.ExecuteTask("Building modified rules", "Failed to build modified rules", "", func() error {
-			return route.Build(cli, percentage, enableUserBasedSessionAwareness, artifactFile)
+		if err := cli.ExecuteTask("Building modified rules", "Failed to build modified rules", "", func() error {
+			err := route.Build(cli, routeOptions, artifactFile)
 			if err != nil {
 				return fmt.Errorf("error occurred while building modified rules, %v", err)
 			}
@@ -100,9 +163,131 @@ func buildRouteArtifact(cli cli.Cli, sourceInstances []string, dependencyInstanc
 			return err
 		}
 	}
+	// the routes share the target, hence its ports are reported once
+	if ports := describePorts(routes[0].ReroutedPorts(routeOptions)); ports != "" {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Ports of instance %s receiving the traffic: %s", targetInstance, ports))
+	}
 	return nil
 }
 
+func appendDocumentSeparator(artifactFile string) error {
+	f, err := os.OpenFile(artifactFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write([]byte("---\n")); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// describePorts returns the ports per protocol, e.g. HTTP 80; gRPC 9090, 9091.
+func describePorts(protocolPorts []routing.ProtocolPorts) string {
+	var descriptions []string
+	for _, ports := range protocolPorts {
+		var numbers []string
+		for _, port := range ports.Ports {
+			numbers = append(numbers, strconv.Itoa(port))
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s %s", ports.Protocol, strings.Join(numbers, ", ")))
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// describeRouting returns the routing change to be applied and as applied, e.g. route 40% of traffic and
+// routed 40% of traffic.
+func describeRouting(routeOptions routing.RouteOptions) (string, string) {
+	if routeOptions.Mirror {
+		if routeOptions.Percentage == 0 {
+			return "stop mirroring traffic", "stopped mirroring traffic"
+		}
+		return fmt.Sprintf("mirror %d%% of traffic", routeOptions.Percentage),
+			fmt.Sprintf("mirrored %d%% of traffic", routeOptions.Percentage)
+	}
+	return fmt.Sprintf("route %d%% of %s", routeOptions.Percentage, getTrafficDescription(routeOptions)),
+		fmt.Sprintf("routed %d%% of %s", routeOptions.Percentage, getTrafficDescription(routeOptions))
+}
+
+// describeRevision returns the change recorded in the routing history, e.g. 40% of traffic. Mirroring is recorded
+// along with the mirrored percentage, e.g. mirror 50% of traffic.
+func describeRevision(routeOptions routing.RouteOptions) string {
+	if routeOptions.Mirror {
+		change, _ := describeRouting(routeOptions)
+		return change
+	}
+	return fmt.Sprintf("%d%% of %s", routeOptions.Percentage, getTrafficDescription(routeOptions))
+}
+
+func getTrafficDescription(routeOptions routing.RouteOptions) string {
+	if len(routeOptions.Matches) > 0 {
+		return "matching traffic"
+	}
+	return "traffic"
+}
+
+// getRoutedSources returns the source instances of which the virtual services are modified.
+func getRoutedSources(objects []map[string]interface{}) []string {
+	var sources []string
+	for _, object := range objects {
+		if kind, _ := object["kind"].(string); kind != "VirtualService" {
+			continue
+		}
+		_, name, _ := getObjectResource(object)
+		sources = append(sources, strings.TrimSuffix(name, "--vs"))
+	}
+	return sources
+}
+
+// getRoutes returns the routes from the source instances which depend on the dependency instance.
+func getRoutes(cli cli.Cli, sourceInstances []string, dependencyInstance string,
+	targetInstance string) ([]routing.Route, error) {
+	// check the source instance and see if the dependency exists in the source
+	routes, err := routing.GetRoutes(cli, sourceInstances, dependencyInstance, targetInstance)
+	if err != nil {
+		return nil, err
+	}
+	// now we have the source instance list which actually depend on the given dependency instance.
+	// get the virtual services corresponding to the given source instances and modify accordingly.
+	if len(routes) == 0 {
+		// no depending instances
+		return nil, fmt.Errorf("cell/composite instance %s not found among dependencies of source instance(s)",
+			dependencyInstance)
+	}
+	return routes, nil
+}
+
+// checkRoutes checks whether traffic can be routed to the new target of all the routes.
+func checkRoutes(routes []routing.Route, assumeYes bool) (bool, error) {
+	for _, route := range routes {
+		canContinue, err := checkRoute(route, assumeYes)
+		if err != nil || !canContinue {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// checkRoute checks whether traffic can be routed to the new target. If the APIs of the targets do not match,
+// the user is prompted to continue unless assumeYes is set.
+func checkRoute(route routing.Route, assumeYes bool) (bool, error) {
+	err := route.Check()
+	if err == nil {
+		return true, nil
+	}
+	// if this is a CellGwApiVersionMismatchError, need to print a warning and prompt user for action
+	versionErr, match := err.(errorpkg.CellGwApiVersionMismatchError)
+	if !match {
+		return false, err
+	}
+	if assumeYes {
+		return true, nil
+	}
+	// prompt confirmation from user
+	return canContinueWithWarning(versionErr.ApiContext, versionErr.CurrentTargetApiVersion,
+		versionErr.NewTargetApiVersion)
+}
+
 func canContinueWithWarning(context string, currVersion string, newVersion string) (bool, error) {
 	var warnMsg string
 	if newVersion != "" {
@@ -24,12 +24,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/routing"
 )
 
 func TestRunRouteTraffic(t *testing.T) {
@@ -50,7 +53,19 @@ func TestRunRouteTraffic(t *testing.T) {
 	cellMap["pet-be-target"] = petBeTargetCell
 	cellMap["pet-fe-src"] = petFeSrcCell
 
-	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap))
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Errorf("failed to read mock pet-fe-src-vs cell yaml file")
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	err = json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs)
+	if err != nil {
+		t.Errorf("failed to unmarshall petFeSrcVsBytes, %v", err)
+	}
+	vsMap := make(map[string]kubernetes.VirtualService)
+	vsMap["pet-fe-src--vs"] = petFeSrcVs
+
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap), test.WithVirtualServices(vsMap))
 	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
 	tests := []struct {
 		name               string
@@ -71,7 +86,8 @@ func TestRunRouteTraffic(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunRouteTrafficCommand(mockCli, tst.sourceInstances, tst.dependencyInstance, tst.targetInstance, tst.percentage, false, true)
+			err := RunRouteTrafficCommand(mockCli, tst.sourceInstances, tst.dependencyInstance, tst.targetInstance,
+				routing.RouteOptions{Percentage: tst.percentage}, true, false, "")
 			if err != nil {
 				t.Errorf("error in RunRouteTrafficCommand, %v", err)
 			}
@@ -118,6 +134,9 @@ func TestBuildRouteArtifact(t *testing.T) {
 		dependencyInstance string
 		targetInstance     string
 		percentage         int
+		matches            []string
+		mirror             bool
+		expectedFile       string
 	}{
 		{
 			name:               "route traffic",
@@ -126,6 +145,27 @@ func TestBuildRouteArtifact(t *testing.T) {
 			dependencyInstance: "pet-be-dep",
 			targetInstance:     "pet-be-target",
 			percentage:         40,
+			expectedFile:       "pet-be-dep-routing-artifacts.yaml",
+		},
+		{
+			name:               "route matching traffic",
+			MockCli:            test.NewMockCli(test.SetKubeCli(mockKubeCli)),
+			sourceInstances:    []string{"pet-fe-src"},
+			dependencyInstance: "pet-be-dep",
+			targetInstance:     "pet-be-target",
+			percentage:         100,
+			matches:            []string{"header:X-Tenant=beta", "cookie:canary=true&uri^=/api&query:debug~=^(1|true)$"},
+			expectedFile:       "pet-be-dep-match-routing-artifacts.yaml",
+		},
+		{
+			name:               "mirror traffic",
+			MockCli:            test.NewMockCli(test.SetKubeCli(mockKubeCli)),
+			sourceInstances:    []string{"pet-fe-src"},
+			dependencyInstance: "pet-be-dep",
+			targetInstance:     "pet-be-target",
+			percentage:         50,
+			mirror:             true,
+			expectedFile:       "pet-be-dep-mirror-routing-artifacts.yaml",
 		},
 	}
 	for _, tst := range tests {
@@ -137,8 +177,17 @@ func TestBuildRouteArtifact(t *testing.T) {
 					t.Errorf("failed to remove artifacts file, %v", err)
 				}
 			}()
-			err := buildRouteArtifact(mockCli, tst.sourceInstances, tst.dependencyInstance, tst.targetInstance,
-				tst.percentage, false, true)
+			var matches []kubernetes.HTTPMatch
+			for _, expression := range tst.matches {
+				match, err := routing.ParseMatch(expression)
+				if err != nil {
+					t.Fatalf("error in ParseMatch, %v", err)
+				}
+				matches = append(matches, match)
+			}
+			_, err := buildRouteArtifact(mockCli, tst.sourceInstances, tst.dependencyInstance, tst.targetInstance,
+				routing.RouteOptions{Percentage: tst.percentage, Matches: matches, Mirror: tst.mirror}, true,
+				artifactFile)
 			if err != nil {
 				t.Errorf("error in buildRouteArtifact, %v", err)
 			}
@@ -146,7 +195,7 @@ func TestBuildRouteArtifact(t *testing.T) {
 			if err != nil {
 				t.Errorf("failed to read route artifact file")
 			}
-			expectedArtifacts, err := ioutil.ReadFile(filepath.Join("testdata", "expected", artifactFile))
+			expectedArtifacts, err := ioutil.ReadFile(filepath.Join("testdata", "expected", tst.expectedFile))
 			if err != nil {
 				t.Errorf("failed to read expected artifacts file")
 			}
@@ -157,3 +206,272 @@ func TestBuildRouteArtifact(t *testing.T) {
 		})
 	}
 }
+
+func TestRunRouteTrafficDryRun(t *testing.T) {
+	petBeDepCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-be-dep.json"))
+	if err != nil {
+		t.Errorf("failed to read mock pet-be-dep cell yaml file")
+	}
+	petBeTargetCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-be-target.json"))
+	if err != nil {
+		t.Errorf("failed to read mock pet-be-target cell yaml file")
+	}
+	petFeSrcCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-fe-src.json"))
+	if err != nil {
+		t.Errorf("failed to read mock pet-fe-src cell yaml file")
+	}
+	cellMap := make(map[string][]byte)
+	cellMap["pet-be-dep"] = petBeDepCell
+	cellMap["pet-be-target"] = petBeTargetCell
+	cellMap["pet-fe-src"] = petFeSrcCell
+
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Errorf("failed to read mock pet-fe-src-vs cell yaml file")
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	err = json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs)
+	if err != nil {
+		t.Errorf("failed to unmarshall petFeSrcVsBytes, %v", err)
+	}
+	vsMap := make(map[string]kubernetes.VirtualService)
+	vsMap["pet-fe-src--vs"] = petFeSrcVs
+
+	outputDir, err := ioutil.TempDir("", "routing")
+	if err != nil {
+		t.Fatalf("failed to create output directory, %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+	// artifacts of a previous run are replaced
+	artifactFile := filepath.Join(outputDir, "pet-be-dep-routing-artifacts.yaml")
+	if err := ioutil.WriteFile(artifactFile, []byte("stale: true\n"), 0644); err != nil {
+		t.Fatalf("failed to write stale artifacts file, %v", err)
+	}
+
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(vsMap))))
+	err = RunRouteTrafficCommand(mockCli, []string{"pet-fe-src"}, "pet-be-dep", "pet-be-target",
+		routing.RouteOptions{Percentage: 40}, true, true, outputDir)
+	if err != nil {
+		t.Fatalf("error in RunRouteTrafficCommand, %v", err)
+	}
+	expectedDiff, err := ioutil.ReadFile(filepath.Join("testdata", "expected", "pet-be-dep-routing-diff.txt"))
+	if err != nil {
+		t.Errorf("failed to read expected diff file")
+	}
+	wantOut := "Starting to route 40% of traffic to instance pet-be-target\n" +
+		"Ports of instance pet-be-target receiving the traffic: HTTP 80\n" +
+		"Routing artifacts written to " + artifactFile + "\n" + string(expectedDiff)
+	if diff := cmp.Diff(wantOut, mockCli.OutBuffer().String()); diff != "" {
+		t.Errorf("invalid dry run output (-want, +got)\n%v", diff)
+	}
+	actualArtifacts, err := ioutil.ReadFile(artifactFile)
+	if err != nil {
+		t.Errorf("failed to read route artifact file")
+	}
+	expectedArtifacts, err := ioutil.ReadFile(filepath.Join("testdata", "expected",
+		"pet-be-dep-routing-artifacts.yaml"))
+	if err != nil {
+		t.Errorf("failed to read expected artifacts file")
+	}
+	if diff := cmp.Diff(string(expectedArtifacts), string(actualArtifacts)); diff != "" {
+		t.Errorf("invalid file content (-want, +got)\n%v", diff)
+	}
+}
+
+func TestRunRouteTrafficMirror(t *testing.T) {
+	cellMap := make(map[string][]byte)
+	for _, cell := range []string{"pet-be-dep", "pet-be-target", "pet-fe-src"} {
+		cellBytes, err := ioutil.ReadFile(filepath.Join("testdata", "cells", cell+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file", cell)
+		}
+		cellMap[cell] = cellBytes
+	}
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-fe-src-vs file")
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	if err = json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs); err != nil {
+		t.Fatalf("failed to unmarshall petFeSrcVsBytes, %v", err)
+	}
+	mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap),
+		test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": petFeSrcVs}))
+	mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+
+	tests := []struct {
+		name          string
+		options       routing.RouteOptions
+		mirroredRules int
+	}{
+		{
+			name:          "mirror traffic",
+			options:       routing.RouteOptions{Percentage: 20, Mirror: true},
+			mirroredRules: 3,
+		},
+		{
+			name:          "stop mirroring traffic",
+			options:       routing.RouteOptions{Percentage: 0, Mirror: true},
+			mirroredRules: 0,
+		},
+		{
+			name:          "mirror traffic again",
+			options:       routing.RouteOptions{Percentage: 100, Mirror: true},
+			mirroredRules: 3,
+		},
+		{
+			name:          "route traffic to the mirror",
+			options:       routing.RouteOptions{Percentage: 40},
+			mirroredRules: 0,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := RunRouteTrafficCommand(mockCli, []string{"pet-fe-src"}, "pet-be-dep", "pet-be-target",
+				tst.options, true, false, "")
+			if err != nil {
+				t.Fatalf("error in RunRouteTrafficCommand, %v", err)
+			}
+			vs, err := mockKubeCli.GetVirtualService("pet-fe-src--vs")
+			if err != nil {
+				t.Fatalf("error getting virtual service, %v", err)
+			}
+			var mirroredRules int
+			for _, rule := range vs.VsSpec.HTTP {
+				if rule.Mirror != nil {
+					mirroredRules++
+				}
+			}
+			if mirroredRules != tst.mirroredRules {
+				t.Errorf("expected %d mirrored rules, found %d", tst.mirroredRules, mirroredRules)
+			}
+			if tst.options.Percentage == 0 {
+				if diff := cmp.Diff(petFeSrcVs, vs); diff != "" {
+					t.Errorf("expected the routing rules to be unchanged (-want, +got)\n%v", diff)
+				}
+			}
+		})
+	}
+
+	mockCli.OutBuffer().Reset()
+	if err := RunRouteTrafficHistory(mockCli, "pet-fe-src", output.Options{
+		Template: "{{range .}}{{.revision}}|{{.change}}\n{{end}}"}); err != nil {
+		t.Fatalf("error in RunRouteTrafficHistory, %v", err)
+	}
+	want := "1|mirror 20% of traffic\n2|stop mirroring traffic\n3|mirror 100% of traffic\n4|40% of traffic\n"
+	if diff := cmp.Diff(want, mockCli.OutBuffer().String()); diff != "" {
+		t.Errorf("invalid history (-want, +got)\n%v", diff)
+	}
+}
+
+func TestRunRouteTrafficGrpcAndTcp(t *testing.T) {
+	const grpcIngress = `"grpc": [{"port": 9090, "destination": {"host": "controller", "port": %d}}]`
+	const tcpIngress = `"tcp": [{"port": 9000, "destination": {"host": "controller", "port": 9000}}]`
+	readCell := func(name string, grpcDestinationPort int) []byte {
+		cellBytes, err := ioutil.ReadFile(filepath.Join("testdata", "cells", name+".json"))
+		if err != nil {
+			t.Fatalf("failed to read mock %s cell file", name)
+		}
+		cell := strings.Replace(string(cellBytes), `"grpc": []`, fmt.Sprintf(grpcIngress, grpcDestinationPort), 1)
+		return []byte(strings.Replace(cell, `"tcp": []`, tcpIngress, 1))
+	}
+	petFeSrcCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-fe-src.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-fe-src cell file")
+	}
+	petFeSrcVsBytes, err := ioutil.ReadFile(filepath.Join("testdata", "virtual-services", "pet-fe-src-vs.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock pet-fe-src-vs file")
+	}
+	petFeSrcVs := kubernetes.VirtualService{}
+	if err = json.Unmarshal(petFeSrcVsBytes, &petFeSrcVs); err != nil {
+		t.Fatalf("failed to unmarshall petFeSrcVsBytes, %v", err)
+	}
+	// a rule for the gRPC port of the gateway and a rule for its TCP port
+	grpcRule := petFeSrcVs.VsSpec.HTTP[len(petFeSrcVs.VsSpec.HTTP)-1]
+	grpcRule.Route = []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{
+		Host: "pet-be-dep--gateway-service", Port: &kubernetes.PortSelector{Number: 9090}}}}
+	petFeSrcVs.VsSpec.HTTP = append([]kubernetes.HTTP{grpcRule}, petFeSrcVs.VsSpec.HTTP...)
+	petFeSrcVs.VsSpec.TCP = []kubernetes.TCP{{
+		Match: []kubernetes.TCPMatch{{Port: 9000}},
+		Route: []kubernetes.TCPRoute{{Destination: kubernetes.TCPDestination{
+			Host: "pet-be-dep--gateway-service", Port: kubernetes.TCPPort{Number: 9000}}}},
+	}}
+
+	tests := []struct {
+		name                      string
+		targetGrpcDestinationPort int
+		wantErr                   string
+	}{
+		{
+			name:                      "route grpc and tcp traffic",
+			targetGrpcDestinationPort: 9090,
+		},
+		{
+			name:                      "grpc destinations do not match",
+			targetGrpcDestinationPort: 9091,
+			wantErr: "gRPC port 9090 is forwarded to controller:9090 in instance pet-be-dep, but to " +
+				"controller:9091 in instance pet-be-target",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(map[string][]byte{
+				"pet-be-dep":    readCell("pet-be-dep", 9090),
+				"pet-be-target": readCell("pet-be-target", tst.targetGrpcDestinationPort),
+				"pet-fe-src":    petFeSrcCell,
+			}), test.WithVirtualServices(map[string]kubernetes.VirtualService{"pet-fe-src--vs": petFeSrcVs}))
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			err := RunRouteTrafficCommand(mockCli, []string{"pet-fe-src"}, "pet-be-dep", "pet-be-target",
+				routing.RouteOptions{Percentage: 40}, true, false, "")
+			if tst.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tst.wantErr) {
+					t.Fatalf("expected error %s, got %v", tst.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in RunRouteTrafficCommand, %v", err)
+			}
+			if !strings.Contains(mockCli.OutBuffer().String(),
+				"Ports of instance pet-be-target receiving the traffic: HTTP 80; gRPC 9090; TCP 9000") {
+				t.Errorf("expected the rerouted ports to be reported, got %s", mockCli.OutBuffer().String())
+			}
+			vs, err := mockKubeCli.GetVirtualService("pet-fe-src--vs")
+			if err != nil {
+				t.Fatalf("error getting virtual service, %v", err)
+			}
+			wantGrpcRoutes := []kubernetes.HTTPRoute{
+				{
+					Destination: kubernetes.Destination{Host: "pet-be-dep--gateway-service",
+						Port: &kubernetes.PortSelector{Number: 9090}},
+					Weight: 60,
+				},
+				{
+					Destination: kubernetes.Destination{Host: "pet-be-target--gateway-service",
+						Port: &kubernetes.PortSelector{Number: 9090}},
+					Weight: 40,
+				},
+			}
+			if diff := cmp.Diff(wantGrpcRoutes, vs.VsSpec.HTTP[0].Route); diff != "" {
+				t.Errorf("invalid grpc routes (-want, +got)\n%v", diff)
+			}
+			wantTcpRoutes := []kubernetes.TCPRoute{
+				{
+					Destination: kubernetes.TCPDestination{Host: "pet-be-dep--gateway-service",
+						Port: kubernetes.TCPPort{Number: 9000}},
+					Weight: 60,
+				},
+				{
+					Destination: kubernetes.TCPDestination{Host: "pet-be-target--gateway-service",
+						Port: kubernetes.TCPPort{Number: 9000}},
+					Weight: 40,
+				},
+			}
+			if diff := cmp.Diff(wantTcpRoutes, vs.VsSpec.TCP[0].Route); diff != "" {
+				t.Errorf("invalid tcp routes (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cellery.io/cellery/components/cli/cli"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/policies"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// scaleWaitInterval is the interval at which the deployments are checked while waiting for the replicas.
+var scaleWaitInterval = 2 * time.Second
+
+// ScaleOptions describes the replicas of the components or the gateway of an instance.
+type ScaleOptions struct {
+	Instance string
+	// Component is scaled if set, otherwise all the components are scaled unless the gateway is scaled
+	Component string
+	Gateway   bool
+	// Replicas of a target which is not autoscaled
+	Replicas *int
+	// MinReplicas and MaxReplicas of the hpa or kpa of an autoscaled target
+	MinReplicas *int
+	MaxReplicas *int
+	// Timeout to wait for the deployments to reach the replicas, which is not waited for if 0
+	Timeout time.Duration
+}
+
+// scaleTarget is a component or the gateway of an instance.
+type scaleTarget struct {
+	name          string
+	path          string
+	scalingPolicy interface{}
+	deployment    string
+	// workloadType of a component, e.g. StatefulSet, it is empty for a deployment
+	workloadType string
+	// minReplicas and maxReplicas are the range of replicas the deployment should reach
+	minReplicas int
+	maxReplicas int
+	// skipWait is the reason the replicas are not waited for, it is empty if they are
+	skipWait string
+}
+
+// RunScale patches the replicas of the components or the gateway of a cell or composite instance and waits for
+// the deployments to reach them. Targets of which the scaling policy is not overridable are not scaled.
+func RunScale(cli cli.Cli, opts ScaleOptions) error {
+	kind, err := getInstanceKind(cli, opts.Instance)
+	if err != nil {
+		return err
+	}
+	if opts.Gateway && kind == kubernetes.InstanceKindComposite {
+		return fmt.Errorf("composites do not have a gateway to scale")
+	}
+	scaleResource, err := getScaleResource(cli, kind, opts.Instance)
+	if err != nil {
+		return err
+	}
+	targets, err := getScaleTargetsOf(scaleResource, opts)
+	if err != nil {
+		return err
+	}
+	var patch []map[string]interface{}
+	for i := range targets {
+		targetPatch, err := buildScalePatch(&targets[i], opts)
+		if err != nil {
+			return err
+		}
+		patch = append(patch, targetPatch...)
+	}
+	patchJson, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("failed to marshall patch, %v", err)
+	}
+	if err = cli.ExecuteTask(fmt.Sprintf("Scaling instance %s", opts.Instance), "Failed to scale instance",
+		"", func() error {
+			return cli.KubeCli().JsonPatch(string(kind), opts.Instance, string(patchJson))
+		}); err != nil {
+		return fmt.Errorf("failed to scale instance %s, %v", opts.Instance, err)
+	}
+	if opts.Timeout > 0 {
+		var waited bool
+		for _, target := range targets {
+			if target.skipWait != "" {
+				fmt.Fprintln(cli.Out(), fmt.Sprintf("Not waiting for the replicas of %s, %s", target.name,
+					target.skipWait))
+			} else {
+				waited = true
+			}
+		}
+		if waited {
+			if err = cli.ExecuteTask("Waiting for the replicas to be ready", "Failed waiting for the replicas",
+				"", func() error {
+					return waitForReplicas(cli, kind, opts.Instance, targets, opts.Timeout)
+				}); err != nil {
+				return err
+			}
+		}
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully scaled instance %s", opts.Instance))
+	return nil
+}
+
+// getInstanceKind returns whether the instance is a cell or a composite.
+func getInstanceKind(cli cli.Cli, instance string) (kubernetes.InstanceKind, error) {
+	_, err := cli.KubeCli().GetCell(instance)
+	if err == nil {
+		return kubernetes.InstanceKindCell, nil
+	}
+	if notFound, _ := errorpkg.IsCellInstanceNotFoundError(instance, err); !notFound {
+		return "", fmt.Errorf("error checking if cell exists, %v", err)
+	}
+	if _, err = cli.KubeCli().GetComposite(instance); err != nil {
+		if notFound, _ := errorpkg.IsCompositeInstanceNotFoundError(instance, err); notFound {
+			return "", fmt.Errorf("instance %s does not exist", instance)
+		}
+		return "", fmt.Errorf("error checking if composite exists, %v", err)
+	}
+	return kubernetes.InstanceKindComposite, nil
+}
+
+func getScaleTargetsOf(scaleResource *kubernetes.ScaleResource, opts ScaleOptions) ([]scaleTarget, error) {
+	if opts.Gateway {
+		return []scaleTarget{{
+			name:          "gateway",
+			path:          "/spec/gateway",
+			scalingPolicy: scaleResource.Spec.Gateway.Spec.ScalingPolicy,
+			deployment:    policies.GetTargetGatewayeploymentName(opts.Instance),
+		}}, nil
+	}
+	var targets []scaleTarget
+	for i, component := range scaleResource.Spec.Components {
+		if opts.Component != "" && component.Metadata.Name != opts.Component {
+			continue
+		}
+		targets = append(targets, scaleTarget{
+			name:          component.Metadata.Name,
+			path:          fmt.Sprintf("/spec/components/%d", i),
+			scalingPolicy: component.Spec.ScalingPolicy,
+			deployment:    policies.GetTargetComponentDeploymentName(opts.Instance, component.Metadata.Name),
+			workloadType:  component.Spec.Type,
+		})
+	}
+	if len(targets) == 0 {
+		if opts.Component != "" {
+			return nil, fmt.Errorf("component %s not found in instance %s", opts.Component, opts.Instance)
+		}
+		return nil, fmt.Errorf("instance %s does not have any components to scale", opts.Instance)
+	}
+	return targets, nil
+}
+
+// buildScalePatch returns the patch of the replicas of the target, and sets the range of replicas the deployment
+// of the target should reach. The name of a component is tested as in the patches of the scaling schedules.
+func buildScalePatch(target *scaleTarget, opts ScaleOptions) ([]map[string]interface{}, error) {
+	if !policies.IsOverridable(target.scalingPolicy) {
+		return nil, fmt.Errorf("the scaling policy of %s is not overridable", target.name)
+	}
+	var patch []map[string]interface{}
+	if strings.HasPrefix(target.path, "/spec/components/") {
+		patch = append(patch, map[string]interface{}{"op": "test", "path": target.path + "/metadata/name",
+			"value": target.name})
+	}
+	autoscalerType, autoscaler := policies.GetAutoscaler(target.scalingPolicy)
+	if opts.Replicas != nil {
+		if autoscaler != nil {
+			return nil, fmt.Errorf("%s is autoscaled by the %s, use the min and max replicas instead", target.name,
+				autoscalerType)
+		}
+		if target.scalingPolicy == nil {
+			patch = append(patch, map[string]interface{}{"op": "add", "path": target.path + "/spec/scalingPolicy",
+				"value": map[string]interface{}{"replicas": *opts.Replicas}})
+		} else {
+			patch = append(patch, map[string]interface{}{"op": "add",
+				"path": target.path + "/spec/scalingPolicy/replicas", "value": *opts.Replicas})
+		}
+		target.minReplicas, target.maxReplicas = *opts.Replicas, *opts.Replicas
+		target.skipWait = getSkipWaitReason(target, autoscalerType)
+		return patch, nil
+	}
+	if autoscaler == nil {
+		return nil, fmt.Errorf("%s is not autoscaled, use the replicas instead", target.name)
+	}
+	// the replicas of the live scaling policy are json numbers
+	currentMin, _ := autoscaler["minReplicas"].(float64)
+	currentMax, _ := autoscaler["maxReplicas"].(float64)
+	minReplicas, maxReplicas := int(currentMin), int(currentMax)
+	if opts.MinReplicas != nil {
+		minReplicas = *opts.MinReplicas
+	}
+	if opts.MaxReplicas != nil {
+		maxReplicas = *opts.MaxReplicas
+	}
+	if autoscalerType == "hpa" && minReplicas < 1 {
+		return nil, fmt.Errorf("the min replicas of the hpa of %s should be at least 1", target.name)
+	}
+	if maxReplicas < minReplicas {
+		return nil, fmt.Errorf("max replicas %d of %s is lower than the min replicas %d", maxReplicas, target.name,
+			minReplicas)
+	}
+	replicasPatch, err := buildScheduledReplicasPatch(target.path+"/spec/scalingPolicy", kubernetes.ScheduledReplicas{
+		MinReplicas: opts.MinReplicas,
+		MaxReplicas: opts.MaxReplicas,
+	}, target.scalingPolicy)
+	if err != nil {
+		return nil, err
+	}
+	target.minReplicas, target.maxReplicas = minReplicas, maxReplicas
+	target.skipWait = getSkipWaitReason(target, autoscalerType)
+	return append(patch, replicasPatch...), nil
+}
+
+// getSkipWaitReason returns why the replicas of the target are not waited for, or an empty string if they are.
+// Only the replicas of deployments which are not managed by the kpa are waited for, as Knative may scale the
+// deployment to zero.
+func getSkipWaitReason(target *scaleTarget, autoscalerType string) string {
+	if autoscalerType == "kpa" {
+		return "its replicas are managed by Knative which scales it on demand"
+	}
+	if target.workloadType != "" && target.workloadType != "Deployment" {
+		return fmt.Sprintf("only the replicas of deployments are checked, but it runs as a %s", target.workloadType)
+	}
+	return ""
+}
+
+// waitForReplicas waits until the deployment of each target which is waited for has a ready number of replicas
+// within its range.
+func waitForReplicas(cli cli.Cli, kind kubernetes.InstanceKind, instance string, targets []scaleTarget,
+	timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var deployments kubernetes.Deployments
+		var err error
+		if kind == kubernetes.InstanceKindComposite {
+			deployments, err = cli.KubeCli().GetDeploymentsForComposite(instance)
+		} else {
+			deployments, err = cli.KubeCli().GetDeploymentsForCell(instance)
+		}
+		if err != nil {
+			return fmt.Errorf("error getting deployments of instance %s, %v", instance, err)
+		}
+		var pending []string
+		for _, target := range targets {
+			if target.skipWait == "" && !hasReplicas(deployments, target) {
+				pending = append(pending, target.name)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s did not reach the desired replicas within %s", strings.Join(pending, ", "),
+				timeout)
+		}
+		time.Sleep(scaleWaitInterval)
+	}
+}
+
+func hasReplicas(deployments kubernetes.Deployments, target scaleTarget) bool {
+	for _, deployment := range deployments.Items {
+		if deployment.Metadata.Name != target.deployment {
+			continue
+		}
+		replicas := deployment.Spec.Replicas
+		return replicas >= target.minReplicas && replicas <= target.maxReplicas &&
+			deployment.Status.Replicas == replicas && deployment.Status.ReadyReplicas == replicas
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+func TestRunScale(t *testing.T) {
+	petBeAutoCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-be-auto.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock cell file")
+	}
+	cellMap := map[string][]byte{
+		"pet-be-auto":  petBeAutoCell,
+		"pet-be-fixed": []byte(strings.Replace(string(petBeAutoCell), `"overridable": true`, `"overridable": false`, 1)),
+		"pet-be-stateful": []byte(strings.Replace(string(petBeAutoCell), `"type": "Deployment"`,
+			`"type": "StatefulSet"`, -1)),
+	}
+	deployment := func(name string, replicas int) kubernetes.Deployment {
+		return kubernetes.Deployment{
+			Metadata: kubernetes.DeploymentMetadata{Name: name},
+			Spec:     kubernetes.DeploymentSpec{Replicas: replicas},
+			Status:   kubernetes.DeploymentStatus{Replicas: replicas, ReadyReplicas: replicas},
+		}
+	}
+	deployments := map[string]kubernetes.Deployments{
+		"pet-be-auto": {Items: []kubernetes.Deployment{
+			deployment("pet-be-auto--controller-deployment", 2),
+			deployment("pet-be-auto--catalog-deployment", 3),
+			deployment("pet-be-auto--orders-deployment", 1),
+			deployment("pet-be-auto--gateway-deployment", 2),
+		}},
+	}
+	replicas := func(count int) *int {
+		return &count
+	}
+	scaleWaitInterval = time.Millisecond
+	tests := []struct {
+		name       string
+		opts       ScaleOptions
+		wantPatch  string
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name: "replicas of a component",
+			opts: ScaleOptions{Instance: "pet-be-auto", Component: "catalog", Replicas: replicas(3),
+				Timeout: time.Second},
+			wantPatch: `[{"op":"test","path":"/spec/components/1/metadata/name","value":"catalog"},` +
+				`{"op":"add","path":"/spec/components/1/spec/scalingPolicy","value":{"replicas":3}}]`,
+		},
+		{
+			name: "min and max replicas of an autoscaled component",
+			opts: ScaleOptions{Instance: "pet-be-auto", Component: "controller", MinReplicas: replicas(2),
+				MaxReplicas: replicas(8), Timeout: time.Second},
+			wantPatch: `[{"op":"test","path":"/spec/components/0/metadata/name","value":"controller"},` +
+				`{"op":"add","path":"/spec/components/0/spec/scalingPolicy/hpa/minReplicas","value":2},` +
+				`{"op":"add","path":"/spec/components/0/spec/scalingPolicy/hpa/maxReplicas","value":8}]`,
+		},
+		{
+			name:      "replicas of the gateway",
+			opts:      ScaleOptions{Instance: "pet-be-auto", Gateway: true, Replicas: replicas(2), Timeout: time.Second},
+			wantPatch: `[{"op":"add","path":"/spec/gateway/spec/scalingPolicy/replicas","value":2}]`,
+		},
+		{
+			name:    "replicas of an autoscaled component",
+			opts:    ScaleOptions{Instance: "pet-be-auto", Component: "controller", Replicas: replicas(2)},
+			wantErr: "controller is autoscaled by the hpa, use the min and max replicas instead",
+		},
+		{
+			name:    "max replicas of a component which is not autoscaled",
+			opts:    ScaleOptions{Instance: "pet-be-auto", Component: "catalog", MaxReplicas: replicas(3)},
+			wantErr: "catalog is not autoscaled, use the replicas instead",
+		},
+		{
+			name: "max replicas lower than the min replicas",
+			opts: ScaleOptions{Instance: "pet-be-auto", Component: "controller", MinReplicas: replicas(3),
+				MaxReplicas: replicas(2)},
+			wantErr: "max replicas 2 of controller is lower than the min replicas 3",
+		},
+		{
+			name:    "scaling policy not overridable",
+			opts:    ScaleOptions{Instance: "pet-be-fixed", Component: "controller", MaxReplicas: replicas(8)},
+			wantErr: "the scaling policy of controller is not overridable",
+		},
+		{
+			name:    "component not found",
+			opts:    ScaleOptions{Instance: "pet-be-auto", Component: "portal", Replicas: replicas(2)},
+			wantErr: "component portal not found in instance pet-be-auto",
+		},
+		{
+			name:    "instance not found",
+			opts:    ScaleOptions{Instance: "pet-be", Replicas: replicas(2)},
+			wantErr: "instance pet-be does not exist",
+		},
+		{
+			name: "replicas of a stateful set not waited for",
+			opts: ScaleOptions{Instance: "pet-be-stateful", Component: "orders", Replicas: replicas(4),
+				Timeout: 10 * time.Millisecond},
+			wantPatch: `[{"op":"test","path":"/spec/components/2/metadata/name","value":"orders"},` +
+				`{"op":"add","path":"/spec/components/2/spec/scalingPolicy","value":{"replicas":4}}]`,
+			wantOutput: "Not waiting for the replicas of orders, only the replicas of deployments are checked, " +
+				"but it runs as a StatefulSet",
+		},
+		{
+			name: "replicas not ready",
+			opts: ScaleOptions{Instance: "pet-be-auto", Component: "orders", Replicas: replicas(4),
+				Timeout: 10 * time.Millisecond},
+			wantErr: "orders did not reach the desired replicas within 10ms",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockKubeCli := test.NewMockKubeCli(test.WithCellsAsBytes(cellMap), test.WithDeployments(deployments))
+			mockCli := test.NewMockCli(test.SetKubeCli(mockKubeCli))
+			err := RunScale(mockCli, tst.opts)
+			if tst.wantErr != "" {
+				if err == nil || err.Error() != tst.wantErr {
+					t.Errorf("expected error %s, got %v", tst.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in RunScale, %v", err)
+			}
+			if diff := cmp.Diff([]string{tst.wantPatch}, mockKubeCli.JsonPatches()); diff != "" {
+				t.Errorf("invalid patch (-want, +got)\n%v", diff)
+			}
+			if !strings.Contains(mockCli.OutBuffer().String(), tst.wantOutput) {
+				t.Errorf("expected output %q, got %q", tst.wantOutput, mockCli.OutBuffer().String())
+			}
+		})
+	}
+}
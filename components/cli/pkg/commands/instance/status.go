@@ -21,6 +21,7 @@ package instance
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/olekukonko/tablewriter"
@@ -28,10 +29,101 @@ import (
 	"cellery.io/cellery/components/cli/cli"
 	errorpkg "cellery.io/cellery/components/cli/pkg/error"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/policies"
+	"cellery.io/cellery/components/cli/pkg/runtime"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
-func RunStatus(cli cli.Cli, instance string) error {
+const maxStatusEvents = 10
+
+// statusSchema is the json and yaml representation of the status of an instance.
+type statusSchema struct {
+	Name       string                  `json:"name"`
+	Kind       string                  `json:"kind"`
+	Created    string                  `json:"created"`
+	Status     string                  `json:"status"`
+	Components []componentStatusSchema `json:"components"`
+	Replicas   []replicaStatusSchema   `json:"replicas"`
+	Events     []eventSchema           `json:"events"`
+	// ScaleToZero is only set if the instance has components which can be scaled to zero
+	ScaleToZero *scaleToZeroSchema `json:"scaleToZero,omitempty"`
+	// The replicas and the events are best-effort and the sections are left out if they could not be read
+	replicasUnavailable bool
+	eventsUnavailable   bool
+	warnings            []string
+}
+
+type scaleToZeroSchema struct {
+	// RuntimeEnabled is true if Knative Serving is installed in the runtime to scale the components to zero. It is
+	// nil if the runtime could not be checked.
+	RuntimeEnabled *bool                      `json:"runtimeEnabled"`
+	Components     []zeroScaleComponentSchema `json:"components"`
+}
+
+type zeroScaleComponentSchema struct {
+	Name string `json:"name"`
+	// ScaledToZero is nil if the deployment of the component could not be found
+	ScaledToZero *bool `json:"scaledToZero"`
+	Ready        int   `json:"ready"`
+	Desired      int   `json:"desired"`
+}
+
+type componentStatusSchema struct {
+	Name            string `json:"name"`
+	Pod             string `json:"pod"`
+	Status          string `json:"status"`
+	Running         string `json:"runningSince,omitempty"`
+	Restarts        int    `json:"restarts"`
+	LastTermination string `json:"lastTerminationReason,omitempty"`
+}
+
+type replicaStatusSchema struct {
+	Component string `json:"component"`
+	Ready     int    `json:"ready"`
+	Desired   int    `json:"desired"`
+}
+
+type eventSchema struct {
+	Type     string `json:"type"`
+	Reason   string `json:"reason"`
+	Object   string `json:"object"`
+	Message  string `json:"message"`
+	Count    int    `json:"count"`
+	LastSeen string `json:"lastSeen"`
+}
+
+func RunStatus(cli cli.Cli, instance string, opts output.Options) error {
+	instanceStatus, err := getInstanceStatus(cli, instance)
+	if err != nil {
+		return err
+	}
+	if opts.Structured() {
+		for _, warning := range instanceStatus.warnings {
+			// Keeping the structured output parsable
+			fmt.Fprintln(os.Stderr, "Warning: "+warning)
+		}
+		return output.Write(cli.Out(), opts, instanceStatus)
+	}
+	for _, warning := range instanceStatus.warnings {
+		util.PrintWarningMessage(warning)
+	}
+	displayStatusSummaryTable(cli, instanceStatus)
+	fmt.Fprintln(cli.Out())
+	fmt.Fprintln(cli.Out(), "  -COMPONENTS-")
+	fmt.Fprintln(cli.Out())
+	displayStatusDetailedTable(cli, instanceStatus.Components, opts.Wide())
+	if instanceStatus.ScaleToZero != nil {
+		fmt.Fprintln(cli.Out())
+		fmt.Fprintln(cli.Out(), "  -SCALE TO ZERO-")
+		fmt.Fprintln(cli.Out())
+		displayScaleToZeroTable(cli, instanceStatus.ScaleToZero)
+	}
+	return nil
+}
+
+// getInstanceStatus collects the status of the instance along with its pods, replicas and recent events.
+func getInstanceStatus(cli cli.Cli, instance string) (*statusSchema, error) {
 	creationTime, status, err := getCellSummary(cli, instance)
 	var canBeComposite bool
 	if err != nil {
@@ -39,50 +131,65 @@ func RunStatus(cli cli.Cli, instance string) error {
 			// could be a composite
 			canBeComposite = true
 		} else {
-			return fmt.Errorf("error checking if cell exists, %v", err)
+			return nil, fmt.Errorf("error checking if cell exists, %v", err)
 		}
 	}
+	instanceStatus := &statusSchema{Name: instance, Kind: "Cell", Created: creationTime, Status: status}
+	var pods kubernetes.Pods
+	var deployments kubernetes.Deployments
+	var templates []kubernetes.ComponentTemplate
 	if canBeComposite {
 		creationTime, status, err = getCompositeSummary(cli, instance)
 		if err != nil {
 			if compositeNotFound, _ := errorpkg.IsCompositeInstanceNotFoundError(instance, err); compositeNotFound {
 				// given instance name does not correspond either to a cell or a composite
-				return fmt.Errorf("instance %s does not exist", instance)
+				return nil, fmt.Errorf("instance %s does not exist", instance)
 			} else {
-				return fmt.Errorf("error checking if composite exists, %v", err)
+				return nil, fmt.Errorf("error checking if composite exists, %v", err)
 			}
 		}
-		return displayCompositeStatus(cli, instance, creationTime, status)
+		instanceStatus = &statusSchema{Name: instance, Kind: "Composite", Created: creationTime, Status: status}
+		if pods, err = cli.KubeCli().GetPodsForComposite(instance); err != nil {
+			return nil, fmt.Errorf("error getting pods information of composite %s, %v", instance, err)
+		}
+		if deployments, err = cli.KubeCli().GetDeploymentsForComposite(instance); err != nil {
+			instanceStatus.replicasUnavailable = true
+			instanceStatus.warnings = append(instanceStatus.warnings,
+				fmt.Sprintf("Replicas of composite %s are not shown, error getting deployments, %v", instance, err))
+		}
+		composite, err := cli.KubeCli().GetComposite(instance)
+		if err != nil {
+			return nil, fmt.Errorf("error getting composite %s, %v", instance, err)
+		}
+		templates = composite.CompositeSpec.ComponentTemplates
 	} else {
-		return displayCellStatus(cli, instance, creationTime, status)
+		if pods, err = cli.KubeCli().GetPodsForCell(instance); err != nil {
+			return nil, fmt.Errorf("error getting pods information of cell %s, %v", instance, err)
+		}
+		if deployments, err = cli.KubeCli().GetDeploymentsForCell(instance); err != nil {
+			instanceStatus.replicasUnavailable = true
+			instanceStatus.warnings = append(instanceStatus.warnings,
+				fmt.Sprintf("Replicas of cell %s are not shown, error getting deployments, %v", instance, err))
+		}
+		cell, err := cli.KubeCli().GetCell(instance)
+		if err != nil {
+			return nil, fmt.Errorf("error getting cell %s, %v", instance, err)
+		}
+		templates = cell.CellSpec.ComponentTemplates
 	}
-	return nil
-}
-
-func displayCellStatus(cli cli.Cli, instance, cellCreationTime, cellStatus string) error {
-	displayStatusSummaryTable(cellCreationTime, cellStatus)
-	fmt.Println()
-	fmt.Println("  -COMPONENTS-")
-	fmt.Println()
-	pods, err := cli.KubeCli().GetPodsForCell(instance)
-	if err != nil {
-		return fmt.Errorf("error getting pods information of cell %s, %v", instance, err)
+	instanceStatus.Components = getComponentStatuses(pods, instance)
+	if !instanceStatus.replicasUnavailable {
+		instanceStatus.Replicas = getReplicaStatuses(deployments, instance)
 	}
-	displayStatusDetailedTable(pods, instance)
-	return nil
-}
-
-func displayCompositeStatus(cli cli.Cli, instance, cellCreationTime, cellStatus string) error {
-	displayStatusSummaryTable(cellCreationTime, cellStatus)
-	fmt.Println()
-	fmt.Println("  -COMPONENTS-")
-	fmt.Println()
-	pods, err := cli.KubeCli().GetPodsForComposite(instance)
-	if err != nil {
-		return fmt.Errorf("error getting pods information of composite %s, %v", instance, err)
+	if events, err := cli.KubeCli().GetEvents(); err != nil {
+		instanceStatus.eventsUnavailable = true
+		instanceStatus.warnings = append(instanceStatus.warnings,
+			fmt.Sprintf("Events of instance %s are not shown, error getting events, %v", instance, err))
+	} else {
+		instanceStatus.Events = getInstanceEvents(events, instance)
 	}
-	displayStatusDetailedTable(pods, instance)
-	return nil
+	instanceStatus.ScaleToZero = getScaleToZeroStatus(cli, templates, deployments, instance)
+	return instanceStatus, nil
 }
 
 func getCellSummary(cli cli.Cli, cellName string) (cellCreationTime, cellStatus string, err error) {
@@ -92,11 +199,9 @@ func getCellSummary(cli cli.Cli, cellName string) (cellCreationTime, cellStatus
 	if err != nil {
 		return "", cellStatus, err
 	}
-	// Get the time since cell instance creation
-	duration := util.GetDuration(util.ConvertStringToTime(cell.CellMetaData.CreationTimestamp))
 	// Get the current status of the cell
 	cellStatus = cell.CellStatus.Status
-	return duration, cellStatus, err
+	return cell.CellMetaData.CreationTimestamp, cellStatus, err
 }
 
 func getCompositeSummary(cli cli.Cli, compName string) (compCreationTime, compStatus string, err error) {
@@ -106,64 +211,177 @@ func getCompositeSummary(cli cli.Cli, compName string) (compCreationTime, compSt
 	if err != nil {
 		return "", compStatus, err
 	}
-	// Get the time since composite instance creation
-	duration := util.GetDuration(util.ConvertStringToTime(composite.CompositeMetaData.CreationTimestamp))
 	// Get the current status of the composite
 	compStatus = composite.CompositeStatus.Status
-	return duration, compStatus, err
-}
-
-func displayStatusSummaryTable(cellCreationTime, cellStatus string) error {
-	tableData := []string{cellCreationTime, cellStatus}
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"CREATED", "STATUS"})
-	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-	table.SetAlignment(3)
-	table.SetRowSeparator("-")
-	table.SetCenterSeparator(" ")
-	table.SetColumnSeparator(" ")
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold})
-	table.SetColumnColor(
-		tablewriter.Colors{},
-		tablewriter.Colors{})
-
-	table.Append(tableData)
-	table.Render()
-	return nil
+	return composite.CompositeMetaData.CreationTimestamp, compStatus, err
 }
 
-func displayStatusDetailedTable(pods kubernetes.Pods, cellName string) error {
-	var tableData [][]string
+func getComponentStatuses(pods kubernetes.Pods, instance string) []componentStatusSchema {
+	components := []componentStatusSchema{}
 	for _, pod := range pods.Items {
-		name := strings.Replace(strings.Split(pod.MetaData.Name, "-deployment-")[0], cellName+"--", "", -1)
-		state := pod.PodStatus.Phase
-		if strings.EqualFold(state, "Running") {
-			// Get the time since pod's last transition to running state
-			duration := util.GetDuration(util.ConvertStringToTime(pod.PodStatus.Conditions[1].LastTransitionTime))
-			state = "Up for " + duration
-		}
-		status := []string{name, state}
-		tableData = append(tableData, status)
-	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"NAME", "STATUS"})
-	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-	table.SetAlignment(3)
-	table.SetRowSeparator("-")
-	table.SetCenterSeparator(" ")
-	table.SetColumnSeparator(" ")
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold})
-	table.SetColumnColor(
-		tablewriter.Colors{tablewriter.FgHiBlueColor},
-		tablewriter.Colors{})
-
-	table.AppendBulk(tableData)
+		component := componentStatusSchema{
+			Name:   strings.Replace(strings.Split(pod.MetaData.Name, "-deployment-")[0], instance+"--", "", -1),
+			Pod:    pod.MetaData.Name,
+			Status: pod.PodStatus.Phase,
+		}
+		if strings.EqualFold(component.Status, "Running") && len(pod.PodStatus.Conditions) > 1 {
+			// The time of the pod's last transition to running state
+			component.Running = pod.PodStatus.Conditions[1].LastTransitionTime
+		}
+		for _, container := range pod.PodStatus.ContainerStatuses {
+			component.Restarts += container.RestartCount
+			if container.State.Waiting != nil && container.State.Waiting.Reason != "" {
+				// Reasons such as CrashLoopBackOff are more useful than the pod phase
+				component.Status = container.State.Waiting.Reason
+			}
+			if container.LastState.Terminated != nil && component.LastTermination == "" {
+				component.LastTermination = container.LastState.Terminated.Reason
+			}
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+func getReplicaStatuses(deployments kubernetes.Deployments, instance string) []replicaStatusSchema {
+	replicas := []replicaStatusSchema{}
+	for _, deployment := range deployments.Items {
+		replicas = append(replicas, replicaStatusSchema{
+			Component: strings.TrimSuffix(strings.TrimPrefix(deployment.Metadata.Name, instance+"--"), "-deployment"),
+			Ready:     deployment.Status.ReadyReplicas,
+			Desired:   deployment.Spec.Replicas,
+		})
+	}
+	return replicas
+}
+
+// getScaleToZeroStatus returns the replicas of the components autoscaled by the kpa, which are served by Knative
+// revisions when scale-to-zero is enabled in the runtime. Nil is returned if there are no such components. A
+// component is only reported as scaled to zero if its deployment is found with no desired replicas.
+func getScaleToZeroStatus(cli cli.Cli, templates []kubernetes.ComponentTemplate, deployments kubernetes.Deployments,
+	instance string) *scaleToZeroSchema {
+	var components []zeroScaleComponentSchema
+	for _, template := range templates {
+		if autoscalerType, _ := policies.GetAutoscaler(template.Spec.ScalingPolicy); autoscalerType != "kpa" {
+			continue
+		}
+		component := zeroScaleComponentSchema{Name: template.Metadata.Name}
+		// the deployment of the Knative revision of the component is suffixed with -rev
+		names := []string{
+			policies.GetTargetComponentDeploymentName(instance, component.Name),
+			fmt.Sprintf("%s--%s-rev-deployment", instance, component.Name),
+		}
+		found := false
+		for _, deployment := range deployments.Items {
+			if deployment.Metadata.Name == names[0] || deployment.Metadata.Name == names[1] {
+				found = true
+				component.Ready += deployment.Status.ReadyReplicas
+				component.Desired += deployment.Spec.Replicas
+			}
+		}
+		if found {
+			scaledToZero := component.Desired == 0
+			component.ScaledToZero = &scaledToZero
+		}
+		components = append(components, component)
+	}
+	if len(components) == 0 {
+		return nil
+	}
+	status := &scaleToZeroSchema{Components: components}
+	// The runtime is reported as unknown instead of failing the status if it cannot be checked
+	if enabled, err := cli.Runtime().IsComponentEnabled(runtime.ScaleToZero); err == nil {
+		status.RuntimeEnabled = &enabled
+	}
+	return status
+}
+
+// getInstanceEvents returns the most recent events of the instance and the resources created for it.
+func getInstanceEvents(events kubernetes.Events, instance string) []eventSchema {
+	var instanceEvents []kubernetes.Event
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name == instance || strings.HasPrefix(event.InvolvedObject.Name, instance+"--") {
+			instanceEvents = append(instanceEvents, event)
+		}
+	}
+	sort.SliceStable(instanceEvents, func(i, j int) bool {
+		return instanceEvents[i].LastTimestamp < instanceEvents[j].LastTimestamp
+	})
+	if len(instanceEvents) > maxStatusEvents {
+		instanceEvents = instanceEvents[len(instanceEvents)-maxStatusEvents:]
+	}
+	result := []eventSchema{}
+	for _, event := range instanceEvents {
+		result = append(result, eventSchema{
+			Type:     event.Type,
+			Reason:   event.Reason,
+			Object:   strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name,
+			Message:  event.Message,
+			Count:    event.Count,
+			LastSeen: event.LastTimestamp,
+		})
+	}
+	return result
+}
+
+// isReady returns true if the instance is Ready and all the desired replicas of its components are ready.
+func isReady(status *statusSchema) bool {
+	if status.Status != "Ready" || status.replicasUnavailable {
+		return false
+	}
+	for _, replica := range status.Replicas {
+		if replica.Ready < replica.Desired {
+			return false
+		}
+	}
+	return true
+}
+
+func displayStatusSummaryTable(cli cli.Cli, status *statusSchema) {
+	table := output.NewTable(cli.Out(), []string{"CREATED", "STATUS"})
+	table.Append([]string{util.GetDuration(util.ConvertStringToTime(status.Created)), status.Status})
 	table.Render()
+}
 
-	return nil
+func displayStatusDetailedTable(cli cli.Cli, components []componentStatusSchema, wide bool) {
+	header := []string{"NAME", "STATUS"}
+	if wide {
+		header = append(header, "POD")
+	}
+	table := output.NewTable(cli.Out(), header, tablewriter.Colors{tablewriter.FgHiBlueColor})
+	for _, component := range components {
+		state := component.Status
+		if component.Running != "" {
+			state = "Up for " + util.GetDuration(util.ConvertStringToTime(component.Running))
+		}
+		record := []string{component.Name, state}
+		if wide {
+			record = append(record, component.Pod)
+		}
+		table.Append(record)
+	}
+	table.Render()
+}
+
+func displayScaleToZeroTable(cli cli.Cli, status *scaleToZeroSchema) {
+	runtimeStatus := "Unknown"
+	if status.RuntimeEnabled != nil {
+		runtimeStatus = "Disabled"
+		if *status.RuntimeEnabled {
+			runtimeStatus = "Enabled"
+		}
+	}
+	fmt.Fprintf(cli.Out(), "  Runtime: %s\n\n", runtimeStatus)
+	table := output.NewTable(cli.Out(), []string{"NAME", "REPLICAS"}, tablewriter.Colors{tablewriter.FgHiBlueColor})
+	for _, component := range status.Components {
+		replicas := "Unknown"
+		if component.ScaledToZero != nil {
+			replicas = fmt.Sprintf("%d/%d", component.Ready, component.Desired)
+			if *component.ScaledToZero {
+				replicas = "Scaled to zero"
+			}
+		}
+		table.Append([]string{component.Name, replicas})
+	}
+	table.Render()
 }
@@ -19,12 +19,17 @@
 package instance
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
 	"cellery.io/cellery/components/cli/internal/test"
 	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/runtime"
 )
 
 func TestRunStatus(t *testing.T) {
@@ -77,7 +82,7 @@ func TestRunStatus(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			err := RunStatus(mockCli, tst.instance)
+			err := RunStatus(mockCli, tst.instance, output.Options{})
 			if err != nil {
 				t.Errorf("error in RunStatus, %v", err)
 			}
@@ -202,3 +207,271 @@ func TestGetCompositeSummary(t *testing.T) {
 		})
 	}
 }
+
+func TestRunStatusWatch(t *testing.T) {
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:              "employee",
+					CreationTimestamp: "2019-10-18T11:40:36Z",
+				},
+				CellStatus: kubernetes.CellStatus{
+					Status: "Ready",
+				},
+			},
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:              "stock",
+					CreationTimestamp: "2019-10-18T11:40:36Z",
+				},
+				CellStatus: kubernetes.CellStatus{
+					Status: "NotReady",
+				},
+			},
+		},
+	}
+	pods := map[string]kubernetes.Pods{
+		"stock": {
+			Items: []kubernetes.Pod{
+				{
+					MetaData: kubernetes.PodMetaData{Name: "stock--stock-deployment-7d4b9c-x2x4z"},
+					PodStatus: kubernetes.PodStatus{
+						Phase: "Running",
+						ContainerStatuses: []kubernetes.ContainerStatus{
+							{
+								Name:         "stock",
+								RestartCount: 3,
+								State: kubernetes.ContainerState{
+									Waiting: &kubernetes.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+								},
+								LastState: kubernetes.ContainerState{
+									Terminated: &kubernetes.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	deployments := map[string]kubernetes.Deployments{
+		"employee": {
+			Items: []kubernetes.Deployment{
+				{
+					Metadata: kubernetes.DeploymentMetadata{Name: "employee--employee-deployment"},
+					Spec:     kubernetes.DeploymentSpec{Replicas: 1},
+					Status:   kubernetes.DeploymentStatus{ReadyReplicas: 1},
+				},
+			},
+		},
+		"stock": {
+			Items: []kubernetes.Deployment{
+				{
+					Metadata: kubernetes.DeploymentMetadata{Name: "stock--stock-deployment"},
+					Spec:     kubernetes.DeploymentSpec{Replicas: 1},
+				},
+			},
+		},
+	}
+	events := kubernetes.Events{
+		Items: []kubernetes.Event{
+			{
+				InvolvedObject: kubernetes.EventObject{Kind: "Pod", Name: "stock--stock-deployment-7d4b9c-x2x4z"},
+				Type:           "Warning",
+				Reason:         "BackOff",
+				Message:        "Back-off restarting failed container",
+				Count:          3,
+				LastTimestamp:  "2019-10-18T11:45:36Z",
+			},
+			{
+				InvolvedObject: kubernetes.EventObject{Kind: "Pod", Name: "hr--hr-deployment-5f6d7-abcde"},
+				Type:           "Normal",
+				Reason:         "Started",
+			},
+		},
+	}
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells), test.WithPods(pods),
+		test.WithDeployments(deployments), test.WithEvents(events))))
+
+	tests := []struct {
+		name        string
+		instance    string
+		wantErr     string
+		wantOutputs []string
+	}{
+		{
+			name:        "ready instance",
+			instance:    "employee",
+			wantOutputs: []string{"employee (Cell)", "1/1", "No recent events."},
+		},
+		{
+			name:     "instance not ready within timeout",
+			instance: "stock",
+			wantErr:  "instance stock did not become Ready within 20ms",
+			wantOutputs: []string{"0/1", "CrashLoopBackOff", "OOMKilled", "BackOff",
+				"Back-off restarting failed container"},
+		},
+		{
+			name:     "instance not created within timeout",
+			instance: "hr",
+			wantErr:  "instance hr did not become Ready within 20ms, instance hr does not exist",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli.OutBuffer().Reset()
+			err := RunStatusWatch(mockCli, tst.instance, 5*time.Millisecond, 20*time.Millisecond)
+			if tst.wantErr == "" {
+				if err != nil {
+					t.Errorf("error in RunStatusWatch, %v", err)
+				}
+			} else if err == nil {
+				t.Errorf("expected error %s", tst.wantErr)
+			} else if diff := cmp.Diff(tst.wantErr, err.Error()); diff != "" {
+				t.Errorf("invalid error message (-want, +got)\n%v", diff)
+			}
+			out := mockCli.OutBuffer().String()
+			for _, want := range tst.wantOutputs {
+				if !strings.Contains(out, want) {
+					t.Errorf("RunStatusWatch: output does not contain %q\n%s", want, out)
+				}
+			}
+			if strings.Contains(out, "hr--hr-deployment") {
+				t.Errorf("RunStatusWatch: output contains events of other instances\n%s", out)
+			}
+		})
+	}
+}
+
+func TestRunStatusWithoutDeploymentsAndEvents(t *testing.T) {
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:              "employee",
+					CreationTimestamp: "2019-10-18T11:40:36Z",
+				},
+				CellStatus: kubernetes.CellStatus{
+					Status: "Ready",
+				},
+			},
+		},
+	}
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells),
+		test.WithFailingDeploymentsAndEvents())))
+	if err := RunStatus(mockCli, "employee", output.Options{}); err != nil {
+		t.Errorf("error in RunStatus, %v", err)
+	}
+
+	mockCli.OutBuffer().Reset()
+	err := RunStatusWatch(mockCli, "employee", 5*time.Millisecond, 20*time.Millisecond)
+	if diff := cmp.Diff("instance employee did not become Ready within 20ms", fmt.Sprint(err)); diff != "" {
+		t.Errorf("RunStatusWatch: instance without replicas is not Ready (-want, +got)\n%v", diff)
+	}
+	out := mockCli.OutBuffer().String()
+	for _, want := range []string{"Replicas of cell employee are not shown", "Events of instance employee are not shown"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("RunStatusWatch: output does not contain %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Events:") {
+		t.Errorf("RunStatusWatch: output contains the events section\n%s", out)
+	}
+}
+
+func TestRunStatusScaleToZero(t *testing.T) {
+	component := func(name string, scalingPolicy interface{}) kubernetes.ComponentTemplate {
+		return kubernetes.ComponentTemplate{
+			Metadata: kubernetes.ComponentTemplateMetadata{Name: name},
+			Spec:     kubernetes.ComponentTemplateSpec{ScalingPolicy: scalingPolicy},
+		}
+	}
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name:              "pet",
+					CreationTimestamp: "2019-10-18T11:40:36Z",
+				},
+				CellSpec: kubernetes.CellSpec{
+					ComponentTemplates: []kubernetes.ComponentTemplate{
+						component("controller", map[string]interface{}{
+							"kpa": map[string]interface{}{"minReplicas": float64(0), "maxReplicas": float64(3)},
+						}),
+						component("catalog", map[string]interface{}{
+							"kpa": map[string]interface{}{"minReplicas": float64(0), "maxReplicas": float64(3)},
+						}),
+						component("orders", map[string]interface{}{"replicas": float64(1)}),
+						component("basket", map[string]interface{}{
+							"kpa": map[string]interface{}{"minReplicas": float64(0), "maxReplicas": float64(3)},
+						}),
+					},
+				},
+				CellStatus: kubernetes.CellStatus{
+					Status: "Ready",
+				},
+			},
+		},
+	}
+	deployments := map[string]kubernetes.Deployments{
+		"pet": {
+			Items: []kubernetes.Deployment{
+				{
+					Metadata: kubernetes.DeploymentMetadata{Name: "pet--controller-rev-deployment"},
+				},
+				{
+					Metadata: kubernetes.DeploymentMetadata{Name: "pet--catalog-rev-deployment"},
+					Spec:     kubernetes.DeploymentSpec{Replicas: 2},
+					Status:   kubernetes.DeploymentStatus{ReadyReplicas: 1},
+				},
+				{
+					Metadata: kubernetes.DeploymentMetadata{Name: "pet--orders-deployment"},
+					Spec:     kubernetes.DeploymentSpec{Replicas: 1},
+					Status:   kubernetes.DeploymentStatus{ReadyReplicas: 1},
+				},
+			},
+		},
+	}
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells),
+		test.WithDeployments(deployments))),
+		test.SetRuntime(test.NewMockRuntime(test.SetSysComponentStatus(map[runtime.SystemComponent]bool{
+			runtime.ScaleToZero: true,
+		}))))
+	status, err := getInstanceStatus(mockCli, "pet")
+	if err != nil {
+		t.Fatalf("error in getInstanceStatus, %v", err)
+	}
+	enabled, scaledToZero, notScaledToZero := true, true, false
+	want := &scaleToZeroSchema{
+		RuntimeEnabled: &enabled,
+		Components: []zeroScaleComponentSchema{
+			{Name: "controller", ScaledToZero: &scaledToZero},
+			{Name: "catalog", ScaledToZero: &notScaledToZero, Ready: 1, Desired: 2},
+			// the deployment of the basket component is not found
+			{Name: "basket"},
+		},
+	}
+	if diff := cmp.Diff(want, status.ScaleToZero); diff != "" {
+		t.Errorf("invalid scale-to-zero status (-want, +got)\n%v", diff)
+	}
+	if err := RunStatus(mockCli, "pet", output.Options{}); err != nil {
+		t.Fatalf("error in RunStatus, %v", err)
+	}
+	out := mockCli.OutBuffer().String()
+	for _, want := range []string{"-SCALE TO ZERO-", "Runtime: Enabled", "Scaled to zero", "1/2", "Unknown"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("RunStatus: output does not contain %q\n%s", want, out)
+		}
+	}
+
+	// the runtime is reported as unknown if it cannot be checked
+	mockCli = test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells),
+		test.WithDeployments(deployments))), test.SetRuntime(test.NewMockRuntime()))
+	if err := RunStatus(mockCli, "pet", output.Options{}); err != nil {
+		t.Fatalf("error in RunStatus, %v", err)
+	}
+	if out := mockCli.OutBuffer().String(); !strings.Contains(out, "Runtime: Unknown") {
+		t.Errorf("RunStatus: output does not contain the unknown runtime\n%s", out)
+	}
+}
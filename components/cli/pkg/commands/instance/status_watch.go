@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/output"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// RunStatusWatch refreshes the status of the instance in place until it is interrupted. If a timeout is given, the
+// watch ends as soon as the instance is Ready and fails if the instance does not become Ready within the timeout.
+// Errors in getting the status are retried until the timeout, and the last error is reported if it is reached.
+func RunStatusWatch(cli cli.Cli, instance string, interval, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	lines := 0
+	for {
+		status, err := getInstanceStatus(cli, instance)
+		if err != nil {
+			// The instance might not be created yet or the cluster might be unreachable for a moment
+			if timeout <= 0 {
+				return err
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("instance %s did not become Ready within %s, %v", instance, timeout, err)
+			}
+			time.Sleep(interval)
+			continue
+		}
+		frame := &bytes.Buffer{}
+		displayWatchStatus(frame, status)
+		if lines > 0 {
+			// Move the cursor to the beginning of the previous frame and clear it
+			fmt.Fprintf(cli.Out(), "\x1b[%dA\x1b[J", lines)
+		}
+		cli.Out().Write(frame.Bytes())
+		lines = strings.Count(frame.String(), "\n")
+		if timeout > 0 {
+			if isReady(status) {
+				util.PrintSuccessMessage(fmt.Sprintf("Instance %s is Ready", util.Bold(instance)))
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("instance %s did not become Ready within %s", instance, timeout)
+			}
+		}
+		time.Sleep(interval)
+	}
+}
+
+// componentSummary aggregates the replicas and pods of a component.
+type componentSummary struct {
+	name            string
+	ready           int
+	desired         int
+	status          string
+	restarts        int
+	lastTermination string
+}
+
+func displayWatchStatus(w io.Writer, status *statusSchema) {
+	fmt.Fprintf(w, "%s %s (%s)   %s %s   %s %s\n\n", util.Bold("Instance:"), status.Name, status.Kind,
+		util.Bold("Status:"), status.Status, util.Bold("Age:"),
+		util.GetDuration(util.ConvertStringToTime(status.Created)))
+	for _, warning := range status.warnings {
+		fmt.Fprintln(w, util.YellowBold("\U000026A0 "+warning))
+	}
+	if len(status.warnings) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	summaries := map[string]*componentSummary{}
+	summary := func(name string) *componentSummary {
+		if _, ok := summaries[name]; !ok {
+			summaries[name] = &componentSummary{name: name}
+		}
+		return summaries[name]
+	}
+	for _, replica := range status.Replicas {
+		s := summary(replica.Component)
+		s.ready = replica.Ready
+		s.desired = replica.Desired
+	}
+	for _, component := range status.Components {
+		s := summary(component.Name)
+		s.restarts += component.Restarts
+		if s.status == "" || component.Status != "Running" {
+			s.status = component.Status
+		}
+		if s.lastTermination == "" {
+			s.lastTermination = component.LastTermination
+		}
+	}
+	var names []string
+	for name := range summaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	table := output.NewTable(w, []string{"COMPONENT", "READY", "STATUS", "RESTARTS", "LAST TERMINATION"},
+		tablewriter.Colors{tablewriter.FgHiBlueColor})
+	for _, name := range names {
+		s := summaries[name]
+		ready := fmt.Sprintf("%d/%d", s.ready, s.desired)
+		if status.replicasUnavailable {
+			ready = "-"
+		}
+		table.Append([]string{s.name, ready, s.status, strconv.Itoa(s.restarts), s.lastTermination})
+	}
+	table.Render()
+
+	if status.eventsUnavailable {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", util.Bold("Events:"))
+	if len(status.Events) == 0 {
+		fmt.Fprintln(w, "  No recent events.")
+		return
+	}
+	table = output.NewTable(w, []string{"LAST SEEN", "TYPE", "REASON", "OBJECT", "MESSAGE"})
+	table.SetAutoWrapText(false)
+	for _, event := range status.Events {
+		lastSeen := ""
+		if event.LastSeen != "" {
+			lastSeen = util.GetDuration(util.ConvertStringToTime(event.LastSeen))
+		}
+		table.Append([]string{lastSeen, event.Type, event.Reason, event.Object, event.Message})
+	}
+	table.Render()
+}
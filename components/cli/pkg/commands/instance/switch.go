@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/metrics"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// DefaultDrainQuery is the rate of the requests served by the workloads of the old instance.
+const DefaultDrainQuery = `sum(rate(istio_requests_total{destination_workload=~"{{.Dependency}}--.*"}[{{.Window}}]))`
+
+// maxDrainChecks is the number of drain periods to wait for the requests to the old instance to stop.
+const maxDrainChecks = 5
+
+// SwitchOptions describes how all traffic is switched from the dependency instance to the target instance.
+type SwitchOptions struct {
+	Dependency string
+	Target     string
+	// Drain is the time to wait for the in-flight requests to the dependency to complete before terminating it
+	Drain        time.Duration
+	TerminateOld bool
+	AssumeYes    bool
+	// MetricsEndpoint is the Prometheus compatible API which is queried to check whether the dependency still
+	// receives requests. It is required to terminate the dependency.
+	MetricsEndpoint string
+	DrainQuery      string
+}
+
+// RunSwitch routes all traffic of the instances depending on the dependency instance to the target instance.
+// If the old instance is to be terminated, it is only terminated once the metrics show that the requests to it
+// are drained and no instance depends on it anymore.
+func RunSwitch(cli cli.Cli, opts SwitchOptions) error {
+	if opts.TerminateOld && opts.MetricsEndpoint == "" {
+		return fmt.Errorf("a metrics endpoint is required to check that the requests to instance %s are drained "+
+			"before terminating it", opts.Dependency)
+	}
+	routed, err := routeTraffic(cli, nil, opts.Dependency, opts.Target, routing.RouteOptions{Percentage: 100},
+		opts.AssumeYes, false, "")
+	if err != nil {
+		return err
+	}
+	if !routed || !opts.TerminateOld {
+		return nil
+	}
+	if err = waitForDrain(cli, opts); err != nil {
+		return fmt.Errorf("not terminating instance %s, %v", opts.Dependency, err)
+	}
+	dependents, err := getDependents(cli, opts.Dependency)
+	if err != nil {
+		return err
+	}
+	if len(dependents) > 0 {
+		return fmt.Errorf("not terminating instance %s, instances %v still depend on it", opts.Dependency,
+			dependents)
+	}
+	if err = RunTerminate(cli, []string{opts.Dependency}, false); err != nil {
+		return err
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully switched to instance %s and terminated instance %s",
+		opts.Target, opts.Dependency))
+	return nil
+}
+
+// waitForDrain waits for the drain period until the metrics show that the dependency does not receive requests
+// anymore.
+func waitForDrain(cli cli.Cli, opts SwitchOptions) error {
+	if opts.MetricsEndpoint == "" {
+		return fmt.Errorf("no metrics endpoint given to check whether the requests are drained")
+	}
+	window := opts.Drain
+	if window < minAnalysisWindow {
+		window = minAnalysisWindow
+	}
+	query, err := renderQuery(opts.DrainQuery, struct {
+		Dependency string
+		Target     string
+		Window     string
+	}{
+		Dependency: opts.Dependency,
+		Target:     opts.Target,
+		Window:     fmt.Sprintf("%ds", int(window.Seconds())),
+	})
+	if err != nil {
+		return err
+	}
+	client := metrics.NewClient(opts.MetricsEndpoint)
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+	for check := 1; ; check++ {
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Waiting %s for the requests to instance %s to drain", opts.Drain,
+			opts.Dependency))
+		select {
+		case <-time.After(opts.Drain):
+		case <-interrupted:
+			return fmt.Errorf("draining interrupted")
+		}
+		rate, found, err := client.Query(query)
+		if err != nil {
+			return err
+		}
+		if !found || rate == 0 {
+			return nil
+		}
+		fmt.Fprintln(cli.Out(), fmt.Sprintf("Instance %s still receives %.2f requests per second", opts.Dependency,
+			rate))
+		if check == maxDrainChecks {
+			return fmt.Errorf("instance %s still receives requests after %s", opts.Dependency,
+				opts.Drain*maxDrainChecks)
+		}
+	}
+}
+
+// getDependents returns the cell and composite instances which depend on the given instance.
+func getDependents(cli cli.Cli, instanceName string) ([]string, error) {
+	var dependents []string
+	cells, err := cli.KubeCli().GetCells()
+	if err != nil {
+		return nil, fmt.Errorf("error getting cell instances, %v", err)
+	}
+	for _, cell := range cells {
+		dependsOn, err := dependsOn(cell.CellMetaData.Annotations.Dependencies, instanceName)
+		if err != nil {
+			return nil, err
+		}
+		if dependsOn {
+			dependents = append(dependents, cell.CellMetaData.Name)
+		}
+	}
+	composites, err := cli.KubeCli().GetComposites()
+	if err != nil {
+		return nil, fmt.Errorf("error getting composite instances, %v", err)
+	}
+	for _, composite := range composites {
+		dependsOn, err := dependsOn(composite.CompositeMetaData.Annotations.Dependencies, instanceName)
+		if err != nil {
+			return nil, err
+		}
+		if dependsOn {
+			dependents = append(dependents, composite.CompositeMetaData.Name)
+		}
+	}
+	return dependents, nil
+}
+
+func dependsOn(depJson string, instanceName string) (bool, error) {
+	dependencies, err := routing.ExtractDependencies(depJson)
+	if err != nil {
+		return false, err
+	}
+	for _, dependency := range dependencies {
+		if dependency["instance"] == instanceName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
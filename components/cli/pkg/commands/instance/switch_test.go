@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+// Switching the traffic is not tested as routing all traffic to the target reads the gateways using kubectl.
+func TestWaitForDrain(t *testing.T) {
+	var queries []string
+	// request rates are served in order, the last one is repeated
+	var rates []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query"))
+		rate := rates[0]
+		if len(rates) > 1 {
+			rates = rates[1:]
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[`+
+			`{"metric":{},"value":[1583056800.781,"%s"]}]}}`, rate)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		opts        SwitchOptions
+		rates       []string
+		wantErr     string
+		wantQueries int
+	}{
+		{
+			name:    "drain without metrics",
+			opts:    SwitchOptions{Dependency: "pet-be-dep", Drain: time.Millisecond},
+			wantErr: "no metrics endpoint given to check whether the requests are drained",
+		},
+		{
+			name:        "requests drained",
+			opts:        SwitchOptions{Dependency: "pet-be-dep", Drain: time.Millisecond, MetricsEndpoint: server.URL},
+			rates:       []string{"2.5", "0.1", "0"},
+			wantQueries: 3,
+		},
+		{
+			name:        "no requests served",
+			opts:        SwitchOptions{Dependency: "pet-be-dep", Drain: time.Millisecond, MetricsEndpoint: server.URL},
+			rates:       []string{"NaN"},
+			wantQueries: 1,
+		},
+		{
+			name:        "requests not drained",
+			opts:        SwitchOptions{Dependency: "pet-be-dep", Drain: time.Millisecond, MetricsEndpoint: server.URL},
+			rates:       []string{"1"},
+			wantErr:     "instance pet-be-dep still receives requests after 5ms",
+			wantQueries: maxDrainChecks,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			queries = nil
+			rates = tst.rates
+			tst.opts.DrainQuery = DefaultDrainQuery
+			err := waitForDrain(test.NewMockCli(), tst.opts)
+			if tst.wantErr == "" && err != nil {
+				t.Errorf("error in waitForDrain, %v", err)
+			}
+			if tst.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error %s", tst.wantErr)
+				}
+				if diff := cmp.Diff(tst.wantErr, err.Error()); diff != "" {
+					t.Errorf("invalid error (-want, +got)\n%v", diff)
+				}
+			}
+			if len(queries) != tst.wantQueries {
+				t.Errorf("expected %d queries, got %d", tst.wantQueries, len(queries))
+			}
+			for _, query := range queries {
+				if !strings.Contains(query, `destination_workload=~"pet-be-dep--.*"`) {
+					t.Errorf("expected the query to select the workloads of pet-be-dep, got %s", query)
+				}
+			}
+		})
+	}
+}
+
+func TestRunSwitchTerminateWithoutMetrics(t *testing.T) {
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli()))
+	err := RunSwitch(mockCli, SwitchOptions{Dependency: "pet-be-dep", Target: "pet-be-target", TerminateOld: true,
+		Drain: time.Millisecond})
+	want := "a metrics endpoint is required to check that the requests to instance pet-be-dep are drained " +
+		"before terminating it"
+	if err == nil {
+		t.Fatalf("expected an error terminating the old instance without a metrics endpoint")
+	}
+	if diff := cmp.Diff(want, err.Error()); diff != "" {
+		t.Errorf("invalid error (-want, +got)\n%v", diff)
+	}
+	if mockCli.OutBuffer().Len() != 0 {
+		t.Errorf("expected the traffic not to be routed, got output %s", mockCli.OutBuffer().String())
+	}
+}
+
+func TestGetDependents(t *testing.T) {
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name: "employee",
+				},
+			},
+			{
+				CellMetaData: kubernetes.K8SMetaData{
+					Name: "hr",
+					Annotations: kubernetes.CellAnnotations{
+						Dependencies: "[{\"org\":\"myorg\",\"name\":\"employee\",\"version\":\"1.0.0\",\"instance\":\"employee\",\"kind\":\"Cell\"}]",
+					},
+				},
+			},
+		},
+	}
+	composites := kubernetes.Composites{
+		Items: []kubernetes.Composite{
+			{
+				CompositeMetaData: kubernetes.K8SMetaData{
+					Name: "foo",
+					Annotations: kubernetes.CellAnnotations{
+						Dependencies: "[{\"org\":\"myorg\",\"name\":\"employee\",\"version\":\"1.0.0\",\"instance\":\"employee\",\"kind\":\"Cell\"}]",
+					},
+				},
+			},
+		},
+	}
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells),
+		test.WithComposites(composites))))
+	dependents, err := getDependents(mockCli, "employee")
+	if err != nil {
+		t.Fatalf("error in getDependents, %v", err)
+	}
+	if diff := cmp.Diff([]string{"hr", "foo"}, dependents); diff != "" {
+		t.Errorf("invalid dependents (-want, +got)\n%v", diff)
+	}
+	if dependents, err = getDependents(mockCli, "hr"); err != nil {
+		t.Fatalf("error in getDependents, %v", err)
+	}
+	if len(dependents) != 0 {
+		t.Errorf("expected no dependents of hr, got %v", dependents)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/policies"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// RunValidateAutoscalePolicy validates the autoscale policy file against the components of the instance without
+// applying it.
+func RunValidateAutoscalePolicy(cli cli.Cli, kind kubernetes.InstanceKind, instance string, file string) error {
+	fileData, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("error reading file %s, %v", file, err)
+	}
+	scaleResource, err := getScaleResource(cli, kind, instance)
+	if err != nil {
+		return err
+	}
+	validationErrors := policies.ValidateAutoscalePolicy(fileData, getScaleTargets(kind, scaleResource),
+		getMetricsApiChecker(cli))
+	if len(validationErrors) > 0 {
+		for _, validationError := range validationErrors {
+			fmt.Fprintln(cli.Out(), fmt.Sprintf("%s: %s", file, validationError.Error()))
+		}
+		return fmt.Errorf("found %d problem(s) in autoscale policy file %s", len(validationErrors), file)
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Autoscale policy file %q is valid for instance %q", file, instance))
+	return nil
+}
+
+// validateAutoscalePolicy returns an error listing the problems found in the autoscale policy file, if any.
+func validateAutoscalePolicy(cli cli.Cli, fileData []byte, file string, kind kubernetes.InstanceKind,
+	scaleResource *kubernetes.ScaleResource) error {
+	validationErrors := policies.ValidateAutoscalePolicy(fileData, getScaleTargets(kind, scaleResource),
+		getMetricsApiChecker(cli))
+	if len(validationErrors) == 0 {
+		return nil
+	}
+	var problems []string
+	for _, validationError := range validationErrors {
+		problems = append(problems, validationError.Error())
+	}
+	return fmt.Errorf("invalid autoscale policy in file %s\n  %s", file, strings.Join(problems, "\n  "))
+}
+
+func getScaleResource(cli cli.Cli, kind kubernetes.InstanceKind, instance string) (*kubernetes.ScaleResource, error) {
+	instanceData, err := cli.KubeCli().GetInstanceBytes(string(kind), instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance bytes of instance %s, %v", instance, err)
+	}
+	scaleResource := &kubernetes.ScaleResource{}
+	if err = json.Unmarshal(instanceData, scaleResource); err != nil {
+		return nil, fmt.Errorf("failed to unmarshall instance data of instance %s, %v", instance, err)
+	}
+	return scaleResource, nil
+}
+
+// getScaleTargets returns the scaling policies of the components and the gateway of the instance.
+func getScaleTargets(kind kubernetes.InstanceKind, scaleResource *kubernetes.ScaleResource) policies.ScaleTargets {
+	targets := policies.ScaleTargets{
+		Components: map[string]interface{}{},
+		HasGateway: kind == kubernetes.InstanceKindCell,
+		Gateway:    scaleResource.Spec.Gateway.Spec.ScalingPolicy,
+	}
+	for _, component := range scaleResource.Spec.Components {
+		targets.Components[component.Metadata.Name] = component.Spec.ScalingPolicy
+	}
+	return targets
+}
+
+// getMetricsApiChecker returns a checker which looks up whether the API service of the metrics API is available.
+func getMetricsApiChecker(cli cli.Cli) policies.MetricsApiChecker {
+	return func(api string) (bool, error) {
+		content, err := cli.KubeCli().GetInstanceBytes(apiService, "v1beta1."+api)
+		if err != nil && !isNotFoundError(err) {
+			return false, err
+		}
+		if len(content) == 0 {
+			return false, nil
+		}
+		service := struct {
+			Status struct {
+				Conditions []struct {
+					Type   string `json:"type"`
+					Status string `json:"status"`
+				} `json:"conditions"`
+			} `json:"status"`
+		}{}
+		if err = json.Unmarshal(content, &service); err != nil {
+			return false, err
+		}
+		for _, condition := range service.Status.Conditions {
+			if condition.Type == "Available" {
+				return condition.Status == "True", nil
+			}
+		}
+		return false, nil
+	}
+}
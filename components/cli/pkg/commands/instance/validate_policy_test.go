@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package instance
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+func TestRunValidateAutoscalePolicy(t *testing.T) {
+	petBeAutoCell, err := ioutil.ReadFile(filepath.Join("testdata", "cells", "pet-be-auto.json"))
+	if err != nil {
+		t.Fatalf("failed to read mock cell yaml file")
+	}
+	invalidPolicy, err := ioutil.TempFile("", "invalid-scale-policy-*.yaml")
+	if err != nil {
+		t.Fatalf("error creating policy file, %v", err)
+	}
+	defer os.Remove(invalidPolicy.Name())
+	if _, err = invalidPolicy.WriteString("components:\n- name: portal\n"); err != nil {
+		t.Fatalf("error writing policy file, %v", err)
+	}
+	_ = invalidPolicy.Close()
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string
+		wantOut string
+	}{
+		{
+			name: "valid policy",
+			file: filepath.Join("testdata", "policies", "autoscale", "myscalepolicy.yaml"),
+		},
+		{
+			name:    "invalid policy",
+			file:    invalidPolicy.Name(),
+			wantErr: "found 1 problem(s) in autoscale policy file",
+			wantOut: "line 2: components[0].name: component \"portal\" not found in the instance",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCellsAsBytes(
+				map[string][]byte{"pet-be-auto": petBeAutoCell}))))
+			err := RunValidateAutoscalePolicy(mockCli, kubernetes.InstanceKindCell, "pet-be-auto", tst.file)
+			if tst.wantErr == "" {
+				if err != nil {
+					t.Errorf("error in RunValidateAutoscalePolicy, %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tst.wantErr) {
+				t.Errorf("expected error %s, got %v", tst.wantErr, err)
+			}
+			if !strings.Contains(mockCli.OutBuffer().String(), tst.wantOut) {
+				t.Errorf("expected output %s, got %s", tst.wantOut, mockCli.OutBuffer().String())
+			}
+		})
+	}
+}
@@ -20,13 +20,13 @@ package setup
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 
 	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/output"
 	"cellery.io/cellery/components/cli/pkg/runtime"
 )
 
@@ -68,29 +68,14 @@ func RunSetupStatus(cli cli.Cli) error {
 		return fmt.Errorf("error getting cluster name, %v", err)
 	}
 	fmt.Fprintf(cli.Out(), componentLabelColor("cluster name: %s\n\n"), componentColor(clusterName))
-	displayClusterComponentsTable(systemComponents)
+	displayClusterComponentsTable(cli, systemComponents)
 	return nil
 }
 
-func displayClusterComponentsTable(systemComponents []*SystemComponent) {
-	var tableData [][]string
+func displayClusterComponentsTable(cli cli.Cli, systemComponents []*SystemComponent) {
+	table := output.NewTable(cli.Out(), []string{"SYSTEM COMPONENT", "STATUS"}, tablewriter.Colors{tablewriter.Bold})
 	for _, systemComponent := range systemComponents {
-		tableData = append(tableData, []string{string(systemComponent.component), systemComponent.status})
+		table.Append([]string{string(systemComponent.component), systemComponent.status})
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"SYSTEM COMPONENT", "STATUS"})
-	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
-	table.SetAlignment(3)
-	table.SetRowSeparator("-")
-	table.SetCenterSeparator(" ")
-	table.SetColumnSeparator(" ")
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold})
-	table.SetColumnColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{})
-
-	table.AppendBulk(tableData)
 	table.Render()
 }
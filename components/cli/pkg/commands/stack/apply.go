@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package stack
+
+import (
+	"fmt"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/image"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/routing"
+	"cellery.io/cellery/components/cli/pkg/stack"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// RunApply converges the cluster to the stack file. Instances which are not running are created, instances of
+// which the definition has changed are updated and instances removed from the stack file are terminated.
+// Autoscale policies and traffic routes in the stack are applied afterwards.
+func RunApply(cli cli.Cli, file string, insecureSkipVerify bool) error {
+	parsedStack, err := stack.Load(file)
+	if err != nil {
+		return err
+	}
+	running, err := runningInstances(cli)
+	if err != nil {
+		return err
+	}
+	changes, err := parsedStack.Plan(running)
+	if err != nil {
+		return fmt.Errorf("failed to plan stack %s, %v", parsedStack.Name, err)
+	}
+	printPlan(cli, parsedStack, changes)
+	for _, change := range changes {
+		if err := applyChange(cli, parsedStack, change, insecureSkipVerify); err != nil {
+			return err
+		}
+	}
+	for _, route := range parsedStack.Routes {
+		err := instance.RunRouteTrafficCommand(cli, route.Sources, route.Dependency, route.Target,
+			routing.RouteOptions{Percentage: route.Percentage, SessionAware: route.SessionAware}, true, false, "")
+		if err != nil {
+			return fmt.Errorf("failed to route traffic to instance %s, %v", route.Target, err)
+		}
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully applied stack %s", util.Bold(parsedStack.Name)))
+	return nil
+}
+
+func applyChange(cli cli.Cli, parsedStack *stack.Stack, change *stack.Change, insecureSkipVerify bool) error {
+	var kind string
+	switch change.Action {
+	case stack.ActionDelete:
+		if err := instance.RunTerminate(cli, []string{change.Name}, false); err != nil {
+			return fmt.Errorf("failed to terminate instance %s, %v", change.Name, err)
+		}
+		return nil
+	case stack.ActionUnchanged:
+		kind = change.Current.Kind
+	default:
+		desired := change.Instance
+		err := image.RunRun(cli, desired.Image, desired.Name, false, false, desired.LinkArgs(), desired.EnvArgs(),
+			insecureSkipVerify)
+		if err != nil {
+			return fmt.Errorf("failed to %s instance %s, %v", change.Action, change.Name, err)
+		}
+		if kind, err = instanceKind(cli, change.Name); err != nil {
+			return err
+		}
+		if err = labelInstance(cli, kind, change.Name, parsedStack.Name, change.Revision); err != nil {
+			return err
+		}
+	}
+	if change.Instance.AutoscalePolicy != "" {
+		err := instance.RunApplyAutoscalePolicies(cli, kubernetes.InstanceKind(kind), change.Name,
+			parsedStack.Path(change.Instance.AutoscalePolicy))
+		if err != nil {
+			return fmt.Errorf("failed to apply autoscale policy of instance %s, %v", change.Name, err)
+		}
+	}
+	return nil
+}
+
+// labelInstance marks the instance as owned by the stack along with the revision of its definition.
+func labelInstance(cli cli.Cli, kind, instanceName, stackName, revision string) error {
+	if err := cli.KubeCli().ApplyLabel(kind, instanceName, stack.LabelStack+"="+stackName, true); err != nil {
+		return fmt.Errorf("failed to label instance %s, %v", instanceName, err)
+	}
+	if err := cli.KubeCli().ApplyLabel(kind, instanceName, stack.LabelRevision+"="+revision, true); err != nil {
+		return fmt.Errorf("failed to label instance %s, %v", instanceName, err)
+	}
+	return nil
+}
+
+func printPlan(cli cli.Cli, parsedStack *stack.Stack, changes []*stack.Change) {
+	fmt.Fprintln(cli.Out(), fmt.Sprintf("Stack %s:", util.Bold(parsedStack.Name)))
+	for _, change := range changes {
+		switch change.Action {
+		case stack.ActionCreate:
+			fmt.Fprintln(cli.Out(), fmt.Sprintf("  %s %s (%s)", util.GreenBold("+"), change.Name,
+				change.Instance.Image))
+		case stack.ActionUpdate:
+			fmt.Fprintln(cli.Out(), fmt.Sprintf("  %s %s (%s -> %s)", util.YellowBold("~"), change.Name,
+				change.Current.Image, change.Instance.Image))
+		case stack.ActionUnchanged:
+			fmt.Fprintln(cli.Out(), fmt.Sprintf("  = %s (%s)", change.Name, change.Instance.Image))
+		case stack.ActionDelete:
+			fmt.Fprintln(cli.Out(), fmt.Sprintf("  %s %s (%s)", util.Red("-"), change.Name, change.Current.Image))
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package stack
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/stack"
+)
+
+func newStackCell(t *testing.T, stackFile, name, image, stackName string) kubernetes.Cell {
+	parsedStack, err := stack.Load(stackFile)
+	if err != nil {
+		t.Fatalf("error loading stack, %v", err)
+	}
+	labels := map[string]string{}
+	if stackName != "" {
+		labels[stack.LabelStack] = stackName
+	}
+	for _, instance := range parsedStack.Instances {
+		if instance.Name == name {
+			revision, err := instance.Revision(parsedStack)
+			if err != nil {
+				t.Fatalf("error in Revision, %v", err)
+			}
+			labels[stack.LabelRevision] = revision
+		}
+	}
+	imageSplit := strings.FieldsFunc(image, func(r rune) bool { return r == '/' || r == ':' })
+	return kubernetes.Cell{
+		CellMetaData: kubernetes.K8SMetaData{
+			Name: name,
+			Annotations: kubernetes.CellAnnotations{
+				Organization: imageSplit[0],
+				Name:         imageSplit[1],
+				Version:      imageSplit[2],
+			},
+			Labels: labels,
+		},
+	}
+}
+
+func TestRunApplyUnchangedStack(t *testing.T) {
+	color.NoColor = true
+	stackFile := filepath.Join("testdata", "unchanged_stack.yaml")
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			newStackCell(t, stackFile, "salary-inst", "myorg/salary:1.0.0", "hr-stack"),
+			newStackCell(t, stackFile, "old-inst", "myorg/old:1.0.0", "hr-stack"),
+			newStackCell(t, stackFile, "other-inst", "myorg/other:1.0.0", ""),
+		},
+	}
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells))))
+	if err := RunApply(mockCli, stackFile, false); err != nil {
+		t.Fatalf("error in RunApply, %v", err)
+	}
+	expected := "Stack hr-stack:\n" +
+		"  = salary-inst (myorg/salary:1.0.0)\n" +
+		"  - old-inst (myorg/old:1.0.0)\n"
+	if diff := cmp.Diff(expected, mockCli.OutBuffer().String()); diff != "" {
+		t.Errorf("RunApply: unexpected plan (-want, +got)\n%v", diff)
+	}
+}
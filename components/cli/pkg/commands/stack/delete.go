@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package stack
+
+import (
+	"fmt"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/commands/instance"
+	"cellery.io/cellery/components/cli/pkg/stack"
+	"cellery.io/cellery/components/cli/pkg/util"
+)
+
+// RunDelete terminates the running instances created from the stack file in the reverse dependency order.
+func RunDelete(cli cli.Cli, file string) error {
+	parsedStack, err := stack.Load(file)
+	if err != nil {
+		return err
+	}
+	running, err := runningInstances(cli)
+	if err != nil {
+		return err
+	}
+	changes, err := parsedStack.Teardown(running)
+	if err != nil {
+		return fmt.Errorf("failed to plan deletion of stack %s, %v", parsedStack.Name, err)
+	}
+	if len(changes) == 0 {
+		util.PrintSuccessMessage(fmt.Sprintf("No running instances found for stack %s", util.Bold(parsedStack.Name)))
+		return nil
+	}
+	printPlan(cli, parsedStack, changes)
+	for _, change := range changes {
+		if err := instance.RunTerminate(cli, []string{change.Name}, false); err != nil {
+			return fmt.Errorf("failed to terminate instance %s, %v", change.Name, err)
+		}
+	}
+	util.PrintSuccessMessage(fmt.Sprintf("Successfully deleted stack %s", util.Bold(parsedStack.Name)))
+	return nil
+}
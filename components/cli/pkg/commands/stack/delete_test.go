@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package stack
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+func TestRunDelete(t *testing.T) {
+	color.NoColor = true
+	stackFile := filepath.Join("testdata", "stack.yaml")
+	cells := kubernetes.Cells{
+		Items: []kubernetes.Cell{
+			newStackCell(t, stackFile, "salary-inst", "myorg/salary:1.0.0", "hr-stack"),
+			newStackCell(t, stackFile, "employee-inst", "myorg/employee:1.0.0", "hr-stack"),
+			newStackCell(t, stackFile, "stock-inst", "myorg/stock:1.0.0", ""),
+		},
+	}
+	mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithCells(cells))))
+	if err := RunDelete(mockCli, stackFile); err != nil {
+		t.Fatalf("error in RunDelete, %v", err)
+	}
+	expected := "Stack hr-stack:\n" +
+		"  - employee-inst (myorg/employee:1.0.0)\n" +
+		"  - salary-inst (myorg/salary:1.0.0)\n"
+	if diff := cmp.Diff(expected, mockCli.OutBuffer().String()); diff != "" {
+		t.Errorf("RunDelete: unexpected plan (-want, +got)\n%v", diff)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package stack
+
+import (
+	"fmt"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+	"cellery.io/cellery/components/cli/pkg/stack"
+)
+
+// runningInstances returns the cell and composite instances running in the cluster.
+func runningInstances(cli cli.Cli) ([]*stack.RunningInstance, error) {
+	cells, err := cli.KubeCli().GetCells()
+	if err != nil {
+		return nil, fmt.Errorf("error getting running cell instances, %v", err)
+	}
+	composites, err := cli.KubeCli().GetComposites()
+	if err != nil {
+		return nil, fmt.Errorf("error getting running composite instances, %v", err)
+	}
+	var running []*stack.RunningInstance
+	for _, cell := range cells {
+		running = append(running, newRunningInstance(kubernetes.InstanceKindCell, cell.CellMetaData))
+	}
+	for _, composite := range composites {
+		running = append(running, newRunningInstance(kubernetes.InstanceKindComposite, composite.CompositeMetaData))
+	}
+	return running, nil
+}
+
+func newRunningInstance(kind kubernetes.InstanceKind, metadata kubernetes.K8SMetaData) *stack.RunningInstance {
+	return &stack.RunningInstance{
+		Name: metadata.Name,
+		Kind: string(kind),
+		Image: fmt.Sprintf("%s/%s:%s", metadata.Annotations.Organization, metadata.Annotations.Name,
+			metadata.Annotations.Version),
+		Stack:    metadata.Labels[stack.LabelStack],
+		Revision: metadata.Labels[stack.LabelRevision],
+	}
+}
+
+// instanceKind returns the kubernetes resource of a running instance.
+func instanceKind(cli cli.Cli, instanceName string) (string, error) {
+	if _, err := cli.KubeCli().GetCell(instanceName); err == nil {
+		return string(kubernetes.InstanceKindCell), nil
+	}
+	if _, err := cli.KubeCli().GetComposite(instanceName); err == nil {
+		return string(kubernetes.InstanceKindComposite), nil
+	}
+	return "", fmt.Errorf("instance %s not found after starting", instanceName)
+}
@@ -33,9 +33,15 @@ const defaultHubUrl = "https://hub.cellery.io"
 const defaultIdpUrl = "https://id.choreo.dev"
 const defaultClientId = "s8jIVx9uJKE087FosgcSwNVjGd0a"
 
+// Kubernetes clients which can be selected in the config
+const KubeClientKubectl = "kubectl"
+const KubeClientNative = "native"
+
 type Conf struct {
-	Hub *HubConf `json:"hub"`
-	Idp *IdpConf `json:"idp"`
+	Hub        *HubConf        `json:"hub"`
+	Idp        *IdpConf        `json:"idp"`
+	Kubernetes *KubernetesConf `json:"kubernetes"`
+	Trust      *TrustConf      `json:"trust"`
 }
 
 type HubConf struct {
@@ -47,6 +53,25 @@ type IdpConf struct {
 	ClientId string `json:"clientId"`
 }
 
+type KubernetesConf struct {
+	// Client is either kubectl (default) or native
+	Client string `json:"client"`
+	// KubeConfig overrides the kubeconfig file used by the native client
+	KubeConfig string `json:"kubeConfig,omitempty"`
+}
+
+type TrustConf struct {
+	// SigningKeys maps an organization to the path of the PEM encoded private key file used to sign its images
+	// on push
+	SigningKeys map[string]string `json:"signingKeys,omitempty"`
+	// TrustedKeys maps an organization (or * for all organizations) to the paths of the PEM encoded public key
+	// or certificate files trusted to sign its images
+	TrustedKeys map[string][]string `json:"trustedKeys,omitempty"`
+	// AllowUntrustedOrganizations skips verifying the images of organizations without trusted keys instead of
+	// failing
+	AllowUntrustedOrganizations bool `json:"allowUntrustedOrganizations,omitempty"`
+}
+
 // LoadConfig reads the config file from the Cellery home and returns the Config struct
 func LoadConfig() *Conf {
 	// Default config
@@ -58,6 +83,10 @@ func LoadConfig() *Conf {
 			Url:      defaultIdpUrl,
 			ClientId: defaultClientId,
 		},
+		Kubernetes: &KubernetesConf{
+			Client: KubeClientKubectl,
+		},
+		Trust: &TrustConf{},
 	}
 
 	configFilePath := filepath.Join(util.UserHomeDir(), constants.CelleryHome, configFile)
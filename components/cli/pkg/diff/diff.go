@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package diff renders line based differences of text in the unified format.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+type operation struct {
+	kind byte
+	text string
+	// from and to are the indexes of the line in the original and modified text
+	from int
+	to   int
+}
+
+// Unified returns the unified diff of two texts labelled with the given names. An empty string is returned if
+// the texts are equal.
+func Unified(fromName, toName, from, to string) string {
+	ops := lineOperations(splitLines(from), splitLines(to))
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(changes); {
+		// Merge the changes whose contexts overlap into a single hunk
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*contextLines {
+			j++
+		}
+		start := max(changes[i]-contextLines, 0)
+		end := min(changes[j]+contextLines+1, len(ops))
+		writeHunk(out, ops[start:end])
+		i = j + 1
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, ops []operation) {
+	fromStart, fromCount, toStart, toCount := ops[0].from, 0, ops[0].to, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			fromCount++
+		}
+		if op.kind != '-' {
+			toCount++
+		}
+	}
+	// Line numbers start from one, an empty range refers to the line before it
+	if fromCount > 0 {
+		fromStart++
+	}
+	if toCount > 0 {
+		toStart++
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", fromStart, fromCount, toStart, toCount)
+	for _, op := range ops {
+		fmt.Fprintf(out, "%c%s\n", op.kind, op.text)
+	}
+}
+
+// lineOperations returns the shortest edit script which transforms the original lines to the modified lines,
+// based on their longest common subsequence.
+func lineOperations(from, to []string) []operation {
+	// common[i][j] holds the length of the longest common subsequence of from[i:] and to[j:]
+	common := make([][]int, len(from)+1)
+	for i := range common {
+		common[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+	var ops []operation
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && from[i] == to[j]:
+			ops = append(ops, operation{kind: ' ', text: from[i], from: i, to: j})
+			i++
+			j++
+		case j == len(to) || (i < len(from) && common[i+1][j] >= common[i][j+1]):
+			ops = append(ops, operation{kind: '-', text: from[i], from: i, to: j})
+			i++
+		default:
+			ops = append(ops, operation{kind: '+', text: to[j], from: i, to: j})
+			j++
+		}
+	}
+	return ops
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+const indexFile = "index.json"
+const indexVersion = 1
+
+// IndexEntry is the record of a cell image in the local repository index.
+type IndexEntry struct {
+	Name     string        `json:"name"`
+	Digest   digest.Digest `json:"digest"`
+	Size     int64         `json:"size"`
+	MetaData *MetaData     `json:"metadata"`
+	Added    time.Time     `json:"added"`
+	LastUsed time.Time     `json:"lastUsed"`
+}
+
+// Index keeps track of the cell images in the local repository so that they can be listed without
+// opening each image, and is used to deduplicate identical images and to prune unused ones.
+type Index struct {
+	Version int                    `json:"version"`
+	Images  map[string]*IndexEntry `json:"images"`
+	repo    string
+}
+
+// LoadIndex reads the index of the given repository. The index is rebuilt from the images in the
+// repository if it does not exist yet or if it was written by an incompatible version.
+func LoadIndex(repo string) (*Index, error) {
+	content, err := ioutil.ReadFile(filepath.Join(repo, indexFile))
+	if os.IsNotExist(err) {
+		return RebuildIndex(repo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading repository index, %v", err)
+	}
+	idx := &Index{}
+	if err := json.Unmarshal(content, idx); err != nil || idx.Version != indexVersion {
+		return RebuildIndex(repo)
+	}
+	if idx.Images == nil {
+		idx.Images = map[string]*IndexEntry{}
+	}
+	idx.repo = repo
+	return idx, nil
+}
+
+// RebuildIndex walks the repository, indexes every cell image found and saves the index.
+func RebuildIndex(repo string) (*Index, error) {
+	idx := &Index{Version: indexVersion, Images: map[string]*IndexEntry{}, repo: repo}
+	organizations, err := subDirectories(repo)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Nothing has been built or pulled yet
+			return idx, nil
+		}
+		return nil, fmt.Errorf("error reading repository, %v", err)
+	}
+	for _, organization := range organizations {
+		names, err := subDirectories(filepath.Join(repo, organization))
+		if err != nil {
+			return nil, fmt.Errorf("error reading repository, %v", err)
+		}
+		for _, name := range names {
+			versions, err := subDirectories(filepath.Join(repo, organization, name))
+			if err != nil {
+				return nil, fmt.Errorf("error reading repository, %v", err)
+			}
+			for _, version := range versions {
+				if _, err := os.Stat(filepath.Join(repo, organization, name, version, name+".zip")); err != nil {
+					continue
+				}
+				if _, err := idx.Add(fmt.Sprintf("%s/%s:%s", organization, name, version)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return idx, idx.Save()
+}
+
+// Save writes the index to the repository.
+func (idx *Index) Save() error {
+	content, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling repository index, %v", err)
+	}
+	if err := os.MkdirAll(idx.repo, 0755); err != nil {
+		return fmt.Errorf("error creating repository, %v", err)
+	}
+	// Write to a temporary file first so that an interrupted write does not corrupt the index
+	tempFile := filepath.Join(idx.repo, indexFile+".tmp")
+	if err := ioutil.WriteFile(tempFile, content, 0644); err != nil {
+		return fmt.Errorf("error writing repository index, %v", err)
+	}
+	if err := os.Rename(tempFile, filepath.Join(idx.repo, indexFile)); err != nil {
+		return fmt.Errorf("error writing repository index, %v", err)
+	}
+	return nil
+}
+
+// Add indexes the cell image with the given name which is already stored in the repository. If an
+// identical image exists in the repository, the image is replaced with a hard link to the existing one.
+func (idx *Index) Add(name string) (*IndexEntry, error) {
+	parsedCellImage, err := ParseImageTag(name)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing cell image %s, %v", name, err)
+	}
+	name = indexName(parsedCellImage)
+	zipFile := idx.zipFile(parsedCellImage)
+	file, err := os.Open(zipFile)
+	if err != nil {
+		return nil, fmt.Errorf("error indexing cell image %s, %v", name, err)
+	}
+	defer file.Close()
+	imageDigest, err := digest.FromReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating digest of cell image %s, %v", name, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error indexing cell image %s, %v", name, err)
+	}
+	meta, err := ReadMetaData(idx.repo, parsedCellImage.Organization, parsedCellImage.ImageName,
+		parsedCellImage.ImageVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error reading metadata of cell image %s, %v", name, err)
+	}
+	now := time.Now().UTC()
+	entry := &IndexEntry{
+		Name:     name,
+		Digest:   imageDigest,
+		Size:     info.Size(),
+		MetaData: meta,
+		Added:    now,
+		LastUsed: now,
+	}
+	if err := idx.deduplicate(entry, zipFile); err != nil {
+		return nil, err
+	}
+	idx.Images[name] = entry
+	return entry, nil
+}
+
+// deduplicate replaces the zip file of the entry with a hard link to an identical image in the repository.
+func (idx *Index) deduplicate(entry *IndexEntry, zipFile string) error {
+	info, err := os.Stat(zipFile)
+	if err != nil {
+		return err
+	}
+	for _, existing := range idx.Images {
+		if existing.Name == entry.Name || existing.Digest != entry.Digest {
+			continue
+		}
+		parsedCellImage, err := ParseImageTag(existing.Name)
+		if err != nil {
+			continue
+		}
+		existingZip := idx.zipFile(parsedCellImage)
+		existingInfo, err := os.Stat(existingZip)
+		if err != nil {
+			continue
+		}
+		if os.SameFile(info, existingInfo) {
+			return nil
+		}
+		// Link to a temporary file and rename it so that the image is never missing from the repository
+		tempFile := zipFile + ".link"
+		if err := os.Link(existingZip, tempFile); err != nil {
+			// Hard links are not supported by every file system, keeping the copy is harmless
+			return nil
+		}
+		if err := os.Rename(tempFile, zipFile); err != nil {
+			os.Remove(tempFile)
+			return fmt.Errorf("error deduplicating cell image %s, %v", entry.Name, err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// Remove removes the cell image with the given name from the index.
+func (idx *Index) Remove(name string) {
+	if parsedCellImage, err := ParseImageTag(name); err == nil {
+		name = indexName(parsedCellImage)
+	}
+	delete(idx.Images, name)
+}
+
+// Touch updates the last used time of the cell image with the given name.
+func (idx *Index) Touch(name string) {
+	if parsedCellImage, err := ParseImageTag(name); err == nil {
+		name = indexName(parsedCellImage)
+	}
+	if entry, ok := idx.Images[name]; ok {
+		entry.LastUsed = time.Now().UTC()
+	}
+}
+
+// Entries returns the indexed cell images sorted by name.
+func (idx *Index) Entries() []*IndexEntry {
+	var entries []*IndexEntry
+	for _, entry := range idx.Images {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// DiskUsage returns the disk space used by the given cell images, counting identical images once.
+func DiskUsage(entries []*IndexEntry) int64 {
+	var size int64
+	seen := map[digest.Digest]bool{}
+	for _, entry := range entries {
+		if !seen[entry.Digest] {
+			seen[entry.Digest] = true
+			size += entry.Size
+		}
+	}
+	return size
+}
+
+func (idx *Index) zipFile(cellImage *CellImage) string {
+	return filepath.Join(idx.repo, cellImage.Organization, cellImage.ImageName, cellImage.ImageVersion,
+		cellImage.ImageName+".zip")
+}
+
+func indexName(cellImage *CellImage) string {
+	return fmt.Sprintf("%s/%s:%s", cellImage.Organization, cellImage.ImageName, cellImage.ImageVersion)
+}
+
+func subDirectories(dir string) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		if file.IsDir() {
+			names = append(names, file.Name())
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeCellImage stores a cell image with the given metadata in the repository.
+func writeCellImage(t *testing.T, repo, org, name, version, content string) {
+	dir := filepath.Join(repo, org, name, version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(filepath.Join(dir, name+".zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	w := zip.NewWriter(file)
+	meta, err := json.Marshal(&MetaData{
+		CellImageName: CellImageName{Organization: org, Name: name, Version: version},
+		Kind:          "Cell",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for fileName, fileContent := range map[string][]byte{MetaDataFile(): meta, "content": []byte(content)} {
+		f, err := w.Create(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write(fileContent); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestRepo(t *testing.T) string {
+	repo, err := ioutil.TempDir("", "cellery-repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func names(entries []*IndexEntry) []string {
+	var result []string
+	for _, entry := range entries {
+		result = append(result, entry.Name)
+	}
+	return result
+}
+
+func TestLoadIndexRebuildsMissingIndex(t *testing.T) {
+	repo := newTestRepo(t)
+	defer os.RemoveAll(repo)
+	writeCellImage(t, repo, "myorg", "employee", "1.0.0", "employee")
+	writeCellImage(t, repo, "myorg", "stock", "1.0.0", "stock")
+
+	idx, err := LoadIndex(repo)
+	if err != nil {
+		t.Fatalf("error loading index, %v", err)
+	}
+	if diff := cmp.Diff([]string{"myorg/employee:1.0.0", "myorg/stock:1.0.0"}, names(idx.Entries())); diff != "" {
+		t.Errorf("unexpected images (-want, +got)\n%v", diff)
+	}
+	if idx.Images["myorg/employee:1.0.0"].MetaData.Kind != "Cell" {
+		t.Errorf("metadata not indexed")
+	}
+	if _, err := os.Stat(filepath.Join(repo, indexFile)); err != nil {
+		t.Errorf("index not saved, %v", err)
+	}
+	// The saved index is used without opening the images
+	os.RemoveAll(filepath.Join(repo, "myorg", "stock"))
+	idx, err = LoadIndex(repo)
+	if err != nil {
+		t.Fatalf("error loading index, %v", err)
+	}
+	if diff := cmp.Diff([]string{"myorg/employee:1.0.0", "myorg/stock:1.0.0"}, names(idx.Entries())); diff != "" {
+		t.Errorf("unexpected images (-want, +got)\n%v", diff)
+	}
+}
+
+func TestIndexDeduplicatesIdenticalImages(t *testing.T) {
+	repo := newTestRepo(t)
+	defer os.RemoveAll(repo)
+	writeCellImage(t, repo, "myorg", "employee", "1.0.0", "employee")
+	idx, err := LoadIndex(repo)
+	if err != nil {
+		t.Fatalf("error loading index, %v", err)
+	}
+	// Copy the image as a new version which results in an identical zip
+	content, err := ioutil.ReadFile(filepath.Join(repo, "myorg", "employee", "1.0.0", "employee.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "myorg", "employee", "latest"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(repo, "myorg", "employee", "latest", "employee.zip"), content,
+		0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idx.Add("myorg/employee:latest"); err != nil {
+		t.Fatalf("error adding image, %v", err)
+	}
+	original, err := os.Stat(filepath.Join(repo, "myorg", "employee", "1.0.0", "employee.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	duplicate, err := os.Stat(filepath.Join(repo, "myorg", "employee", "latest", "employee.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(original, duplicate) {
+		t.Errorf("identical images are not deduplicated")
+	}
+	if diff := cmp.Diff(original.Size(), DiskUsage(idx.Entries())); diff != "" {
+		t.Errorf("unexpected disk usage (-want, +got)\n%v", diff)
+	}
+}
+
+func TestPruneCandidates(t *testing.T) {
+	now := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	idx := &Index{Images: map[string]*IndexEntry{
+		"myorg/employee:1.0.0": {Name: "myorg/employee:1.0.0", Digest: "sha256:a", Size: 100, LastUsed: now.Add(-30 * day)},
+		"myorg/employee:1.0.1": {Name: "myorg/employee:1.0.1", Digest: "sha256:b", Size: 100, LastUsed: now.Add(-20 * day)},
+		"myorg/employee:1.0.2": {Name: "myorg/employee:1.0.2", Digest: "sha256:c", Size: 100, LastUsed: now.Add(-1 * day)},
+		"myorg/stock:1.0.0":    {Name: "myorg/stock:1.0.0", Digest: "sha256:d", Size: 50, LastUsed: now.Add(-10 * day)},
+		"myorg/stock:latest":   {Name: "myorg/stock:latest", Digest: "sha256:d", Size: 50, LastUsed: now.Add(-2 * day)},
+	}}
+	tests := []struct {
+		name   string
+		policy PrunePolicy
+		want   []string
+	}{
+		{
+			name:   "no policy",
+			policy: PrunePolicy{},
+		},
+		{
+			name:   "older than",
+			policy: PrunePolicy{OlderThan: 15 * day},
+			want:   []string{"myorg/employee:1.0.0", "myorg/employee:1.0.1"},
+		},
+		{
+			name:   "keep last",
+			policy: PrunePolicy{KeepLast: 1},
+			want:   []string{"myorg/employee:1.0.0", "myorg/employee:1.0.1", "myorg/stock:1.0.0"},
+		},
+		{
+			name:   "max size counts identical images once",
+			policy: PrunePolicy{MaxSize: 150},
+			want:   []string{"myorg/employee:1.0.0", "myorg/employee:1.0.1"},
+		},
+		{
+			name:   "max size releases shared images once all references are pruned",
+			policy: PrunePolicy{MaxSize: 100},
+			want:   []string{"myorg/employee:1.0.0", "myorg/employee:1.0.1", "myorg/stock:1.0.0", "myorg/stock:latest"},
+		},
+		{
+			name:   "combined policies",
+			policy: PrunePolicy{OlderThan: 25 * day, KeepLast: 2, MaxSize: 200},
+			want:   []string{"myorg/employee:1.0.0", "myorg/employee:1.0.1"},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			if diff := cmp.Diff(tst.want, names(idx.PruneCandidates(tst.policy, now))); diff != "" {
+				t.Errorf("unexpected prune candidates (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"archive/zip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
 )
 
@@ -31,7 +32,33 @@ func ReadMetaData(repo, organization, project, version string) (*MetaData, error
 		return nil, err
 	}
 	defer r.Close()
+	meta, err := readMetaDataFromZip(&r.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("missing metadata infomation in %s/%s:%s", organization, project, version)
+	}
+	return meta, nil
+}
+
+// ReadMetaDataFromZip reads the metadata of a cell image zip which is not stored in the local repository.
+func ReadMetaDataFromZip(cellImage io.ReaderAt, size int64) (*MetaData, error) {
+	r, err := zip.NewReader(cellImage, size)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := readMetaDataFromZip(r)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("missing metadata infomation in cell image")
+	}
+	return meta, nil
+}
 
+func readMetaDataFromZip(r *zip.Reader) (*MetaData, error) {
 	for _, f := range r.File {
 		if f.Name != MetaDataFile() {
 			continue
@@ -48,7 +75,7 @@ func ReadMetaData(repo, organization, project, version string) (*MetaData, error
 		metaReader.Close()
 		return meta, nil
 	}
-	return nil, fmt.Errorf("missing metadata infomation in %s/%s:%s", organization, project, version)
+	return nil, nil
 }
 
 func MetaDataFile() string {
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package image
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// PrunePolicy selects the cell images to be removed from the local repository. Policies which are not
+// set are ignored.
+type PrunePolicy struct {
+	// OlderThan prunes images which have not been used for longer than the given duration
+	OlderThan time.Duration
+	// KeepLast keeps only the given number of most recently used versions of each image
+	KeepLast int
+	// MaxSize prunes the least recently used images until the repository fits in the given number of bytes
+	MaxSize int64
+}
+
+// PruneCandidates returns the cell images which should be removed to satisfy the policy, sorted by name.
+func (idx *Index) PruneCandidates(policy PrunePolicy, now time.Time) []*IndexEntry {
+	pruned := map[string]bool{}
+	if policy.KeepLast > 0 {
+		versions := map[string][]*IndexEntry{}
+		for _, entry := range idx.Images {
+			repository := entry.Name[:strings.LastIndex(entry.Name, ":")]
+			versions[repository] = append(versions[repository], entry)
+		}
+		for _, entries := range versions {
+			sortByLastUsed(entries)
+			for i := 0; i < len(entries)-policy.KeepLast; i++ {
+				pruned[entries[i].Name] = true
+			}
+		}
+	}
+	if policy.OlderThan > 0 {
+		for _, entry := range idx.Images {
+			if now.Sub(entry.LastUsed) > policy.OlderThan {
+				pruned[entry.Name] = true
+			}
+		}
+	}
+	if policy.MaxSize > 0 {
+		var remaining []*IndexEntry
+		for _, entry := range idx.Images {
+			if !pruned[entry.Name] {
+				remaining = append(remaining, entry)
+			}
+		}
+		sortByLastUsed(remaining)
+		// Identical images share the disk space, so it is only released once all of them are removed
+		references := map[digest.Digest]int{}
+		for _, entry := range remaining {
+			references[entry.Digest]++
+		}
+		size := DiskUsage(remaining)
+		for _, entry := range remaining {
+			if size <= policy.MaxSize {
+				break
+			}
+			pruned[entry.Name] = true
+			references[entry.Digest]--
+			if references[entry.Digest] == 0 {
+				size -= entry.Size
+			}
+		}
+	}
+	var candidates []*IndexEntry
+	for _, entry := range idx.Entries() {
+		if pruned[entry.Name] {
+			candidates = append(candidates, entry)
+		}
+	}
+	return candidates
+}
+
+// sortByLastUsed sorts the entries from the least recently used to the most recently used.
+func sortByLastUsed(entries []*IndexEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].LastUsed.Equal(entries[j].LastUsed) {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+}
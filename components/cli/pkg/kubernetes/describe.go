@@ -19,13 +19,12 @@
 package kubernetes
 
 import (
-	"fmt"
 	"os/exec"
 
 	"cellery.io/cellery/components/cli/pkg/osexec"
 )
 
-func (kubeCli *CelleryKubeCli) DescribeCell(cellName string) error {
+func (kubeCli *CelleryKubeCli) DescribeCell(cellName string) ([]byte, error) {
 	cmd := exec.Command(
 		kubectl,
 		"describe",
@@ -33,7 +32,5 @@ func (kubeCli *CelleryKubeCli) DescribeCell(cellName string) error {
 		cellName,
 	)
 	displayVerboseOutput(cmd)
-	out, err := osexec.GetCommandOutputFromTextFile(cmd)
-	fmt.Print(string(out))
-	return err
+	return osexec.GetCommandOutputFromTextFile(cmd)
 }
@@ -216,6 +216,51 @@ func (kubeCli *CelleryKubeCli) GetPodsForComposite(compName string) (Pods, error
 	return jsonOutput, err
 }
 
+func (kubeCli *CelleryKubeCli) GetDeploymentsForCell(cellName string) (Deployments, error) {
+	return getDeployments(constants.GroupName + "/cell=" + cellName)
+}
+
+func (kubeCli *CelleryKubeCli) GetDeploymentsForComposite(compName string) (Deployments, error) {
+	return getDeployments(constants.GroupName + "/composite=" + compName)
+}
+
+func getDeployments(labelSelector string) (Deployments, error) {
+	cmd := exec.Command(kubectl,
+		"get",
+		"deployments",
+		"-l",
+		labelSelector,
+		"-o",
+		"json",
+	)
+	displayVerboseOutput(cmd)
+	jsonOutput := Deployments{}
+	out, err := osexec.GetCommandOutputFromTextFile(cmd)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+// GetEvents returns the events of the current namespace.
+func (kubeCli *CelleryKubeCli) GetEvents() (Events, error) {
+	cmd := exec.Command(kubectl,
+		"get",
+		"events",
+		"-o",
+		"json",
+	)
+	displayVerboseOutput(cmd)
+	jsonOutput := Events{}
+	out, err := osexec.GetCommandOutputFromTextFile(cmd)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
 func (kubeCli *CelleryKubeCli) GetServices(cellName string) (Services, error) {
 	cmd := exec.Command(
 		kubectl,
@@ -342,28 +387,6 @@ func GetService(service, namespace string) (Service, error) {
 	return jsonOutput, err
 }
 
-func getContainerCount(cellName string, considerSystemPods bool) (int, error) {
-	cmd := exec.Command(
-		kubectl,
-		"get",
-		"pods",
-		"-o",
-		"jsonpath={.items[*].spec['containers','initContainers'][*].name}",
-	)
-	if considerSystemPods {
-		cmd.Args = append(cmd.Args, "-l", constants.GroupName+"/cell="+cellName)
-	} else {
-		cmd.Args = append(cmd.Args, "-l",
-			constants.GroupName+"/cell="+cellName+","+constants.GroupName+"/component")
-	}
-	out, err := cmd.Output()
-	displayVerboseOutput(cmd)
-	if err != nil {
-		return 0, err
-	}
-	return len(strings.Split(string(out), " ")), nil
-}
-
 func (kubeCli *CelleryKubeCli) GetInstancesNames() ([]string, error) {
 	var instances []string
 	runningCellInstances, err := kubeCli.GetCells()
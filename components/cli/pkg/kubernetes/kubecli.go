@@ -30,18 +30,19 @@ type KubeCli interface {
 	GetCell(cellName string) (Cell, error)
 	GetComposite(compositeName string) (Composite, error)
 	GetInstanceBytes(instanceKind, InstanceName string) ([]byte, error)
-	DescribeCell(cellName string) error
+	DescribeCell(cellName string) ([]byte, error)
 	Version() (string, string, error)
 	GetServices(cellName string) (Services, error)
-	StreamCellLogsUserComponents(instanceName string, follow bool) error
-	StreamCellLogsAllComponents(instanceName string, follow bool) error
-	StreamComponentLogs(instanceName, componentName string, follow bool) error
+	StreamLogs(instances []string, component string, sysLog bool, options LogOptions, handle func(LogLine)) error
 	JsonPatch(kind, instance, jsonPatch string) error
 	ApplyFile(file string) error
 	GetCellInstanceAsMapInterface(cell string) (map[string]interface{}, error)
 	GetCompositeInstanceAsMapInterface(composite string) (map[string]interface{}, error)
 	GetPodsForCell(cellName string) (Pods, error)
 	GetPodsForComposite(compName string) (Pods, error)
+	GetDeploymentsForCell(cellName string) (Deployments, error)
+	GetDeploymentsForComposite(compName string) (Deployments, error)
+	GetEvents() (Events, error)
 	GetVirtualService(vs string) (VirtualService, error)
 	IsInstanceAvailable(instanceName string) error
 	IsComponentAvailable(instanceName, componentName string) error
@@ -19,60 +19,470 @@
 package kubernetes
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"cellery.io/cellery/components/cli/pkg/constants"
 	"cellery.io/cellery/components/cli/pkg/osexec"
 )
 
-func (kubeCli *CelleryKubeCli) StreamCellLogsUserComponents(instanceName string, follow bool) error {
-	cmd := exec.Command(kubectl,
-		"logs",
-		"-l",
-		constants.GroupName+"/cell="+instanceName+","+constants.GroupName+"/component",
-		"--all-containers=true",
-	)
-	if follow {
-		noOfContainers, err := getContainerCount(instanceName, false)
-		if err != nil {
-			return err
-		}
-		cmd.Args = append(cmd.Args, "-f", fmt.Sprintf("--max-log-requests=%d", noOfContainers))
-	}
-	displayVerboseOutput(cmd)
-	return osexec.PrintCommandOutput(cmd)
+const maxLogLineSize = 1024 * 1024
+
+// maxLogRetryInterval is the longest wait before reopening the log of a container which keeps failing.
+const maxLogRetryInterval = time.Minute
+
+// maxLogStreamFailures is the number of consecutive failures of every container after which following the logs
+// is given up.
+const maxLogStreamFailures = 5
+
+// logPollInterval is the interval at which new pods are looked up when following logs. It is also the wait before
+// reopening the log of a container which failed, which is doubled on every consecutive failure.
+var logPollInterval = 2 * time.Second
+
+// LogOptions controls which lines of the container logs are streamed.
+type LogOptions struct {
+	Follow bool
+	// Since only returns lines newer than the given duration if it is greater than zero
+	Since time.Duration
+	// Tail only returns the given number of recent lines of each container if it is not negative
+	Tail int
 }
 
-func (kubeCli *CelleryKubeCli) StreamCellLogsAllComponents(instanceName string, follow bool) error {
+// LogLine is a single line of a container log.
+type LogLine struct {
+	Instance  string
+	Component string
+	Pod       string
+	Container string
+	Time      time.Time
+	Text      string
+}
+
+// logSource lists the pods of which the logs are streamed and opens the log of a container.
+type logSource interface {
+	listLogPods(labelSelector string) ([]logPod, error)
+	openLog(pod, container string, query logQuery) (io.ReadCloser, error)
+}
+
+type logPods struct {
+	Items []logPod `json:"items"`
+}
+
+type logPod struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		InitContainers []ContainerTemplate `json:"initContainers"`
+		Containers     []ContainerTemplate `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		InitContainerStatuses []ContainerStatus `json:"initContainerStatuses"`
+		ContainerStatuses     []ContainerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type logQuery struct {
+	follow    bool
+	since     time.Duration
+	sinceTime time.Time
+	tail      int
+}
+
+type logTarget struct {
+	instance  string
+	component string
+	pod       string
+	container string
+	// running is true if the container was running when the pods were listed
+	running bool
+}
+
+func (target logTarget) key() string {
+	return target.pod + "/" + target.container
+}
+
+func (kubeCli *CelleryKubeCli) StreamLogs(instances []string, component string, sysLog bool, options LogOptions,
+	handle func(LogLine)) error {
+	return streamLogs(kubeCli, instances, component, sysLog, options, handle)
+}
+
+func (kubeCli *CelleryKubeCli) listLogPods(labelSelector string) ([]logPod, error) {
 	cmd := exec.Command(kubectl,
-		"logs",
+		"get",
+		"pods",
 		"-l",
-		constants.GroupName+"/cell="+instanceName,
-		"--all-containers=true",
+		labelSelector,
+		"-o",
+		"json",
 	)
-	if follow {
-		noOfContainers, err := getContainerCount(instanceName, true)
-		if err != nil {
-			return err
-		}
-		cmd.Args = append(cmd.Args, "-f", fmt.Sprintf("--max-log-requests=%d", noOfContainers))
-	}
 	displayVerboseOutput(cmd)
-	return osexec.PrintCommandOutput(cmd)
+	out, err := osexec.GetCommandOutputFromTextFile(cmd)
+	if err != nil {
+		return nil, err
+	}
+	pods := logPods{}
+	err = json.Unmarshal(out, &pods)
+	return pods.Items, err
 }
 
-func (kubeCli *CelleryKubeCli) StreamComponentLogs(instanceName string, componentName string, follow bool) error {
+func (kubeCli *CelleryKubeCli) openLog(pod, container string, query logQuery) (io.ReadCloser, error) {
 	cmd := exec.Command(kubectl,
 		"logs",
-		"-l",
-		constants.GroupName+"/cell="+instanceName+","+constants.GroupName+"/component="+instanceName+"--"+componentName,
+		pod,
 		"-c",
-		componentName,
+		container,
+		"--timestamps",
 	)
-	if follow {
+	if query.follow {
 		cmd.Args = append(cmd.Args, "-f")
 	}
+	if !query.sinceTime.IsZero() {
+		cmd.Args = append(cmd.Args, "--since-time="+query.sinceTime.UTC().Format(time.RFC3339))
+	} else if query.since > 0 {
+		cmd.Args = append(cmd.Args, "--since="+query.since.String())
+	}
+	if query.tail >= 0 {
+		cmd.Args = append(cmd.Args, "--tail="+strconv.Itoa(query.tail))
+	}
 	displayVerboseOutput(cmd)
-	return osexec.PrintCommandOutput(cmd)
+	logs := &kubectlLogReader{cmd: cmd}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	logs.ReadCloser = stdout
+	cmd.Stderr = &logs.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// kubectlLogReader reads the output of a kubectl logs command and reports its failure on Close.
+type kubectlLogReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr bytes.Buffer
+}
+
+// stopLog ends reading a log which is still being streamed.
+func stopLog(logs io.ReadCloser) {
+	if reader, ok := logs.(*kubectlLogReader); ok {
+		// kubectl does not exit on closing its output until it writes again
+		reader.cmd.Process.Kill()
+		return
+	}
+	logs.Close()
+}
+
+func (logs *kubectlLogReader) Close() error {
+	if err := logs.cmd.Wait(); err != nil {
+		if message := strings.TrimSpace(logs.stderr.String()); message != "" {
+			return fmt.Errorf("%s", message)
+		}
+		return err
+	}
+	return nil
+}
+
+// streamLogs passes the log lines of the containers of the given instances to the handler. Calls to the handler are
+// serialized. Unless following, the lines of all containers are interleaved in the order in which they were logged.
+// When following, the pods are polled so that the logs of restarted containers and new pods are picked up until the
+// process is interrupted. The log of a container which ended is reopened once the container is running again.
+// Containers of which the log cannot be read are retried with an increasing backoff, and following is given up if
+// the logs of every container keep failing.
+func streamLogs(source logSource, instances []string, component string, sysLog bool, options LogOptions,
+	handle func(LogLine)) error {
+	selectors := logSelectors(instances, component, sysLog)
+	targets, err := listLogTargets(source, selectors, component)
+	if err != nil {
+		return err
+	}
+	var mutex sync.Mutex
+	emit := func(line LogLine) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		handle(line)
+	}
+	query := logQuery{follow: options.Follow, since: options.Since, tail: options.Tail}
+	if !options.Follow {
+		return mergeLogs(source, targets, query, emit)
+	}
+
+	type streamEnd struct {
+		key  string
+		last time.Time
+		err  error
+	}
+	active := map[string]bool{}
+	// lastSeen holds the time of the last line read from each container. Streams which end because the container
+	// restarted are resumed from that time so that lines are not printed twice.
+	lastSeen := map[string]time.Time{}
+	// failures holds the number of consecutive failures of each container, and the last error
+	failures := map[string]int{}
+	failureErrors := map[string]error{}
+	retryAt := map[string]time.Time{}
+	// completed holds the containers of which the log ended without an error, until they are running again
+	completed := map[string]bool{}
+	ended := make(chan streamEnd)
+	// Closing stop ends the streams which are still being read once following is given up
+	stop := make(chan struct{})
+	defer close(stop)
+	for {
+		for _, target := range targets {
+			key := target.key()
+			if active[key] || time.Now().Before(retryAt[key]) || (completed[key] && !target.running) {
+				continue
+			}
+			delete(completed, key)
+			active[key] = true
+			after, resumed := lastSeen[key]
+			targetQuery := query
+			if resumed {
+				targetQuery = logQuery{follow: true, sinceTime: after, tail: -1}
+			}
+			go func(target logTarget, query logQuery, after time.Time) {
+				opened := time.Now()
+				last, err := readLog(source, target, query, after, stop, emit)
+				if last.IsZero() {
+					last = after
+				}
+				if last.IsZero() {
+					last = opened
+				}
+				select {
+				case ended <- streamEnd{key: target.key(), last: last, err: err}:
+				case <-stop:
+				}
+			}(target, targetQuery, after)
+		}
+		poll := time.After(logPollInterval)
+	wait:
+		for {
+			select {
+			case end := <-ended:
+				active[end.key] = false
+				lastSeen[end.key] = end.last
+				if end.err == nil {
+					delete(failures, end.key)
+					delete(retryAt, end.key)
+					completed[end.key] = true
+					continue
+				}
+				failures[end.key]++
+				failureErrors[end.key] = end.err
+				retryAt[end.key] = time.Now().Add(logRetryInterval(failures[end.key]))
+			case <-poll:
+				break wait
+			}
+		}
+		// Keep streaming the known containers if the pods cannot be listed for the moment
+		if latest, err := listLogTargets(source, selectors, component); err == nil {
+			targets = latest
+		}
+		if failed := failingLogTarget(targets, failures); failed != nil {
+			return fmt.Errorf("failed to read logs of container %s of pod %s, %v", failed.container, failed.pod,
+				failureErrors[failed.key()])
+		}
+	}
+}
+
+// logRetryInterval returns the wait before reopening the log of a container after the given number of
+// consecutive failures.
+func logRetryInterval(failures int) time.Duration {
+	interval := logPollInterval
+	for i := 1; i < failures && interval < maxLogRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxLogRetryInterval {
+		return maxLogRetryInterval
+	}
+	return interval
+}
+
+// failingLogTarget returns a container which failed maxLogStreamFailures times in a row if the logs of all the
+// containers keep failing, or nil if the logs of any container can be read.
+func failingLogTarget(targets []logTarget, failures map[string]int) *logTarget {
+	if len(targets) == 0 {
+		return nil
+	}
+	for _, target := range targets {
+		if failures[target.key()] < maxLogStreamFailures {
+			return nil
+		}
+	}
+	return &targets[0]
+}
+
+// mergeLogs reads the logs of the containers concurrently and passes the lines to emit in the order in which they
+// were logged. A line is passed as soon as the next line of every other container is known, hence the lines are
+// printed while the logs are read instead of after reading all of them.
+func mergeLogs(source logSource, targets []logTarget, query logQuery, emit func(LogLine)) error {
+	type logStream struct {
+		lines chan LogLine
+		err   error
+	}
+	streams := make([]*logStream, len(targets))
+	for i, target := range targets {
+		stream := &logStream{lines: make(chan LogLine)}
+		streams[i] = stream
+		go func(target logTarget) {
+			defer close(stream.lines)
+			_, stream.err = readLog(source, target, query, time.Time{}, nil, func(line LogLine) {
+				stream.lines <- line
+			})
+		}(target)
+	}
+	heads := make([]*LogLine, len(streams))
+	ended := make([]bool, len(streams))
+	for {
+		earliest := -1
+		for i, stream := range streams {
+			if heads[i] == nil && !ended[i] {
+				line, ok := <-stream.lines
+				if !ok {
+					ended[i] = true
+					continue
+				}
+				heads[i] = &line
+			}
+			if heads[i] != nil && (earliest < 0 || heads[i].Time.Before(heads[earliest].Time)) {
+				earliest = i
+			}
+		}
+		if earliest < 0 {
+			break
+		}
+		emit(*heads[earliest])
+		heads[earliest] = nil
+	}
+	for i, stream := range streams {
+		if stream.err != nil {
+			return fmt.Errorf("failed to read logs of container %s of pod %s, %v", targets[i].container,
+				targets[i].pod, stream.err)
+		}
+	}
+	return nil
+}
+
+// logSelectors returns the label selectors of the pods of the given instances. Cells and composites are labelled
+// differently, hence a selector is returned for each of them.
+func logSelectors(instances []string, component string, sysLog bool) []string {
+	if component != "" {
+		var components []string
+		for _, instance := range instances {
+			components = append(components, instance+"--"+component)
+		}
+		return []string{fmt.Sprintf("%s/component in (%s)", constants.GroupName, strings.Join(components, ","))}
+	}
+	var selectors []string
+	for _, kind := range []string{"cell", "composite"} {
+		selector := fmt.Sprintf("%s/%s in (%s)", constants.GroupName, kind, strings.Join(instances, ","))
+		if !sysLog {
+			selector += "," + constants.GroupName + "/component"
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors
+}
+
+func listLogTargets(source logSource, selectors []string, container string) ([]logTarget, error) {
+	var targets []logTarget
+	listed := map[string]bool{}
+	for _, selector := range selectors {
+		pods, err := source.listLogPods(selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods {
+			labels := pod.Metadata.Labels
+			instance := labels[constants.GroupName+"/cell"]
+			if instance == "" {
+				instance = labels[constants.GroupName+"/composite"]
+			}
+			component := strings.TrimPrefix(labels[constants.GroupName+"/component"], instance+"--")
+			running := map[string]bool{}
+			for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+				running[status.Name] = status.State.Running != nil
+			}
+			for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+				if container != "" && c.Name != container {
+					continue
+				}
+				target := logTarget{
+					instance:  instance,
+					component: component,
+					pod:       pod.Metadata.Name,
+					container: c.Name,
+					running:   running[c.Name],
+				}
+				if !listed[target.key()] {
+					listed[target.key()] = true
+					targets = append(targets, target)
+				}
+			}
+		}
+	}
+	return targets, nil
+}
+
+// readLog reads the log of a container until the stream ends or stop is closed, and returns the time of the last
+// line read. Lines which are not newer than after are skipped.
+func readLog(source logSource, target logTarget, query logQuery, after time.Time, stop <-chan struct{},
+	emit func(LogLine)) (time.Time, error) {
+	logs, err := source.openLog(target.pod, target.container, query)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if stop != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-stop:
+				stopLog(logs)
+			case <-done:
+			}
+		}()
+	}
+	var last time.Time
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		line := LogLine{
+			Instance:  target.instance,
+			Component: target.component,
+			Pod:       target.pod,
+			Container: target.container,
+			Text:      scanner.Text(),
+		}
+		// Lines are prefixed with the RFC3339 timestamp at which they were logged
+		if i := strings.IndexByte(line.Text, ' '); i > 0 {
+			if timestamp, err := time.Parse(time.RFC3339Nano, line.Text[:i]); err == nil {
+				line.Time, line.Text = timestamp, line.Text[i+1:]
+			}
+		}
+		if !line.Time.IsZero() {
+			if !after.IsZero() && !line.Time.After(after) {
+				continue
+			}
+			last = line.Time
+		}
+		emit(line)
+	}
+	if err := scanner.Err(); err != nil {
+		logs.Close()
+		return last, err
+	}
+	return last, logs.Close()
 }
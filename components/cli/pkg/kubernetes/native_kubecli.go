@@ -0,0 +1,544 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/pkg/constants"
+	errorpkg "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/version"
+)
+
+const fieldManager = "cellery"
+const waitPollInterval = 2 * time.Second
+
+// NativeKubeCli is a KubeCli which talks to the kubernetes API server directly instead of invoking kubectl.
+// Operations which only manipulate the local kubeconfig and describe are delegated to the kubectl client.
+type NativeKubeCli struct {
+	kubeConfigPath string
+	client         *restClient
+	kubectl        *CelleryKubeCli
+}
+
+// NewNativeKubeCli returns a NativeKubeCli instance which uses the current context of the given kubeconfig.
+func NewNativeKubeCli(kubeConfigPath string) (*NativeKubeCli, error) {
+	client, err := newRestClient(kubeConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	return &NativeKubeCli{
+		kubeConfigPath: kubeConfigPath,
+		client:         client,
+		kubectl:        NewCelleryKubeCli(),
+	}, nil
+}
+
+// reload re-reads the kubeconfig after the current context or namespace has been changed.
+func (kubeCli *NativeKubeCli) reload() error {
+	client, err := newRestClient(kubeCli.kubeConfigPath)
+	if err != nil {
+		return err
+	}
+	kubeCli.client = client
+	return nil
+}
+
+func (kubeCli *NativeKubeCli) SetVerboseMode(enable bool) {
+	verboseMode = enable
+}
+
+func (kubeCli *NativeKubeCli) list(resourceName, labelSelector string, output interface{}) error {
+	resource, err := lookupResource(resourceName)
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	if labelSelector != "" {
+		query.Set("labelSelector", labelSelector)
+	}
+	return kubeCli.client.get(resource, "", "", query, output)
+}
+
+func (kubeCli *NativeKubeCli) getBytes(resourceName, name string) ([]byte, error) {
+	resource, err := lookupResource(resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return kubeCli.client.do(http.MethodGet, kubeCli.client.resourcePath(resource, "", name), nil, "", nil)
+}
+
+func (kubeCli *NativeKubeCli) GetCells() ([]Cell, error) {
+	jsonOutput := Cells{}
+	err := kubeCli.list("cells", "", &jsonOutput)
+	return jsonOutput.Items, err
+}
+
+func (kubeCli *NativeKubeCli) GetComposites() ([]Composite, error) {
+	jsonOutput := Composites{}
+	err := kubeCli.list("composites", "", &jsonOutput)
+	return jsonOutput.Items, err
+}
+
+func (kubeCli *NativeKubeCli) GetCell(cellName string) (Cell, error) {
+	jsonOutput := Cell{}
+	out, err := kubeCli.getBytes("cells", cellName)
+	if err != nil {
+		if IsNotFound(err) {
+			return jsonOutput, fmt.Errorf("cell instance %s not found", cellName)
+		}
+		return jsonOutput, fmt.Errorf("unknown error: %v", err)
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *NativeKubeCli) GetComposite(compositeName string) (Composite, error) {
+	jsonOutput := Composite{}
+	out, err := kubeCli.getBytes("composites", compositeName)
+	if err != nil {
+		if IsNotFound(err) {
+			return jsonOutput, fmt.Errorf("composite instance %s not found", compositeName)
+		}
+		return jsonOutput, fmt.Errorf("unknown error: %v", err)
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *NativeKubeCli) GetInstancesNames() ([]string, error) {
+	var instances []string
+	runningCellInstances, err := kubeCli.GetCells()
+	if err != nil {
+		return nil, err
+	}
+	runningCompositeInstances, err := kubeCli.GetComposites()
+	if err != nil {
+		return nil, err
+	}
+	for _, runningInstance := range runningCellInstances {
+		instances = append(instances, runningInstance.CellMetaData.Name)
+	}
+	for _, runningInstance := range runningCompositeInstances {
+		instances = append(instances, runningInstance.CompositeMetaData.Name)
+	}
+	return instances, nil
+}
+
+func (kubeCli *NativeKubeCli) GetInstanceBytes(instanceKind, InstanceName string) ([]byte, error) {
+	return kubeCli.getBytes(instanceKind, InstanceName)
+}
+
+func (kubeCli *NativeKubeCli) DescribeCell(cellName string) ([]byte, error) {
+	return kubeCli.kubectl.DescribeCell(cellName)
+}
+
+// Version returns the version of the API server. Since the native client is not bound to a kubectl
+// version, the client is reported as the native client built into this CLI.
+func (kubeCli *NativeKubeCli) Version() (string, string, error) {
+	out, err := kubeCli.client.do(http.MethodGet, "/version", nil, "", nil)
+	if err != nil {
+		return "", "", err
+	}
+	serverVersion := struct {
+		GitVersion string `json:"gitVersion"`
+	}{}
+	err = json.Unmarshal(out, &serverVersion)
+	return serverVersion.GitVersion, "native (cellery " + version.BuildVersion() + ")", err
+}
+
+func (kubeCli *NativeKubeCli) GetServices(cellName string) (Services, error) {
+	jsonOutput := Services{}
+	err := kubeCli.list("services", constants.GroupName+"/cell="+cellName, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *NativeKubeCli) StreamLogs(instances []string, component string, sysLog bool, options LogOptions,
+	handle func(LogLine)) error {
+	return streamLogs(kubeCli, instances, component, sysLog, options, handle)
+}
+
+func (kubeCli *NativeKubeCli) listLogPods(labelSelector string) ([]logPod, error) {
+	pods := logPods{}
+	err := kubeCli.list("pods", labelSelector, &pods)
+	return pods.Items, err
+}
+
+func (kubeCli *NativeKubeCli) openLog(pod, container string, query logQuery) (io.ReadCloser, error) {
+	resource, err := lookupResource("pods")
+	if err != nil {
+		return nil, err
+	}
+	values := url.Values{}
+	values.Set("container", container)
+	values.Set("timestamps", "true")
+	if query.follow {
+		values.Set("follow", "true")
+	}
+	if !query.sinceTime.IsZero() {
+		values.Set("sinceTime", query.sinceTime.UTC().Format(time.RFC3339))
+	} else if query.since > 0 {
+		values.Set("sinceSeconds", strconv.FormatInt(int64(math.Ceil(query.since.Seconds())), 10))
+	}
+	if query.tail >= 0 {
+		values.Set("tailLines", strconv.Itoa(query.tail))
+	}
+	return kubeCli.client.stream(http.MethodGet, kubeCli.client.resourcePath(resource, "", pod)+"/log", values,
+		"", nil)
+}
+
+func (kubeCli *NativeKubeCli) JsonPatch(kind, instance, jsonPatch string) error {
+	return kubeCli.patch(kind, instance, "", "application/json-patch+json", []byte(jsonPatch))
+}
+
+func (kubeCli *NativeKubeCli) patch(kind, instance, namespace, patchType string, patch []byte) error {
+	resource, err := lookupResource(kind)
+	if err != nil {
+		return err
+	}
+	_, err = kubeCli.client.do(http.MethodPatch, kubeCli.client.resourcePath(resource, namespace, instance), nil,
+		patchType, patch)
+	return err
+}
+
+// ApplyFile applies all the objects in a yaml or json file using server side apply.
+func (kubeCli *NativeKubeCli) ApplyFile(file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	for _, document := range regexp.MustCompile(`(?m)^---\s*$`).Split(string(content), -1) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		objectJson, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return fmt.Errorf("failed to parse %s, %v", file, err)
+		}
+		if err := kubeCli.applyObject(objectJson); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (kubeCli *NativeKubeCli) applyObject(objectJson []byte) error {
+	object := struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Items []json.RawMessage `json:"items"`
+	}{}
+	if err := json.Unmarshal(objectJson, &object); err != nil {
+		return err
+	}
+	if object.Kind == "List" {
+		for _, item := range object.Items {
+			if err := kubeCli.applyObject(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	resource, err := lookupResourceByKind(object.APIVersion, object.Kind)
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("fieldManager", fieldManager)
+	query.Set("force", "true")
+	_, err = kubeCli.client.do(http.MethodPatch,
+		kubeCli.client.resourcePath(resource, object.Metadata.Namespace, object.Metadata.Name), query,
+		"application/apply-patch+yaml", objectJson)
+	if err != nil {
+		return fmt.Errorf("failed to apply %s %s, %v", resource.qualifiedName(), object.Metadata.Name, err)
+	}
+	return nil
+}
+
+func (kubeCli *NativeKubeCli) GetCellInstanceAsMapInterface(cell string) (map[string]interface{}, error) {
+	return kubeCli.getAsMapInterface("cells", cell)
+}
+
+func (kubeCli *NativeKubeCli) GetCompositeInstanceAsMapInterface(composite string) (map[string]interface{}, error) {
+	return kubeCli.getAsMapInterface("composites", composite)
+}
+
+func (kubeCli *NativeKubeCli) getAsMapInterface(kind, name string) (map[string]interface{}, error) {
+	var output map[string]interface{}
+	out, err := kubeCli.getBytes(kind, name)
+	if err != nil {
+		return output, err
+	}
+	err = json.Unmarshal(out, &output)
+	return output, err
+}
+
+func (kubeCli *NativeKubeCli) GetPodsForCell(cellName string) (Pods, error) {
+	jsonOutput := Pods{}
+	err := kubeCli.list("pods", constants.GroupName+"/cell="+cellName, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *NativeKubeCli) GetPodsForComposite(compName string) (Pods, error) {
+	jsonOutput := Pods{}
+	err := kubeCli.list("pods", constants.GroupName+"/composite="+compName, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *NativeKubeCli) GetDeploymentsForCell(cellName string) (Deployments, error) {
+	jsonOutput := Deployments{}
+	err := kubeCli.list("deployments", constants.GroupName+"/cell="+cellName, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *NativeKubeCli) GetDeploymentsForComposite(compName string) (Deployments, error) {
+	jsonOutput := Deployments{}
+	err := kubeCli.list("deployments", constants.GroupName+"/composite="+compName, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *NativeKubeCli) GetEvents() (Events, error) {
+	jsonOutput := Events{}
+	err := kubeCli.list("events", "", &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *NativeKubeCli) GetVirtualService(vs string) (VirtualService, error) {
+	jsonOutput := VirtualService{}
+	out, err := kubeCli.getBytes("virtualservices", vs)
+	if err != nil {
+		return jsonOutput, err
+	}
+	err = json.Unmarshal(out, &jsonOutput)
+	return jsonOutput, err
+}
+
+func (kubeCli *NativeKubeCli) IsInstanceAvailable(instanceName string) error {
+	_, err := kubeCli.GetCell(instanceName)
+	if err == nil {
+		return nil
+	}
+	if cellNotFound, _ := errorpkg.IsCellInstanceNotFoundError(instanceName, err); !cellNotFound {
+		return fmt.Errorf("failed to check available Cells, %v", err)
+	}
+	_, err = kubeCli.GetComposite(instanceName)
+	if err == nil {
+		return nil
+	}
+	if compositeNotFound, _ := errorpkg.IsCompositeInstanceNotFoundError(instanceName, err); compositeNotFound {
+		return fmt.Errorf("instance %s not available in the runtime", instanceName)
+	}
+	return fmt.Errorf("failed to check available Composites, %v", err)
+}
+
+func (kubeCli *NativeKubeCli) IsComponentAvailable(instanceName, componentName string) error {
+	_, err := kubeCli.getBytes("components", instanceName+"--"+componentName)
+	if err != nil {
+		if IsNotFound(err) {
+			return fmt.Errorf("component %s not found", componentName)
+		}
+		return fmt.Errorf("unknown error: %v", err)
+	}
+	return nil
+}
+
+func (kubeCli *NativeKubeCli) GetContext() (string, error) {
+	return kubeCli.kubectl.GetContext()
+}
+
+func (kubeCli *NativeKubeCli) GetContexts() ([]byte, error) {
+	return kubeCli.kubectl.GetContexts()
+}
+
+func (kubeCli *NativeKubeCli) UseContext(context string) error {
+	if err := kubeCli.kubectl.UseContext(context); err != nil {
+		return err
+	}
+	return kubeCli.reload()
+}
+
+func (kubeCli *NativeKubeCli) SetNamespace(namespace string) error {
+	if err := kubeCli.kubectl.SetNamespace(namespace); err != nil {
+		return err
+	}
+	return kubeCli.reload()
+}
+
+func (kubeCli *NativeKubeCli) GetMasterNodeName() (string, error) {
+	jsonOutput := &Node{}
+	if err := kubeCli.list("nodes", "node-role.kubernetes.io/master", jsonOutput); err != nil {
+		return "", err
+	}
+	if len(jsonOutput.Items) > 0 {
+		return jsonOutput.Items[0].Metadata.Name, nil
+	}
+	return "", fmt.Errorf("node with master role does not exist")
+}
+
+// ApplyLabel adds a label given in the key=value form to a resource.
+func (kubeCli *NativeKubeCli) ApplyLabel(itemType, itemName, labelName string, overWrite bool) error {
+	label := strings.SplitN(labelName, "=", 2)
+	if len(label) != 2 {
+		return fmt.Errorf("invalid label %s, expected key=value", labelName)
+	}
+	if !overWrite {
+		object := struct {
+			Metadata struct {
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+		}{}
+		out, err := kubeCli.getBytes(itemType, itemName)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(out, &object); err != nil {
+			return err
+		}
+		if value, ok := object.Metadata.Labels[label[0]]; ok && value != label[1] {
+			return fmt.Errorf("'%s' already has a value (%s), and overwrite is false", label[0], value)
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{label[0]: label[1]},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return kubeCli.patch(itemType, itemName, "", "application/merge-patch+json", patch)
+}
+
+func (kubeCli *NativeKubeCli) DeletePersistedVolume(persistedVolume string) error {
+	_, err := kubeCli.DeleteResource("persistentvolumes", persistedVolume)
+	return err
+}
+
+func (kubeCli *NativeKubeCli) DeleteAllCells() error {
+	return kubeCli.deleteCollection("cells")
+}
+
+func (kubeCli *NativeKubeCli) DeleteAllComposites() error {
+	return kubeCli.deleteCollection("composites")
+}
+
+func (kubeCli *NativeKubeCli) deleteCollection(kind string) error {
+	resource, err := lookupResource(kind)
+	if err != nil {
+		return err
+	}
+	_, err = kubeCli.client.do(http.MethodDelete, kubeCli.client.resourcePath(resource, "", ""), nil, "", nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (kubeCli *NativeKubeCli) DeleteNameSpace(nameSpace string) error {
+	_, err := kubeCli.DeleteResource("namespaces", nameSpace)
+	return err
+}
+
+func (kubeCli *NativeKubeCli) CreateNamespace(namespace string) error {
+	resource, _ := lookupResource("namespaces")
+	body, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]string{"name": namespace},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = kubeCli.client.do(http.MethodPost, kubeCli.client.resourcePath(resource, "", ""), nil,
+		"application/json", body)
+	return err
+}
+
+func (kubeCli *NativeKubeCli) GetNamespace(namespace string) ([]byte, error) {
+	return kubeCli.getBytes("namespaces", namespace)
+}
+
+// DeleteResource deletes a resource, ignoring it if it does not exist.
+func (kubeCli *NativeKubeCli) DeleteResource(kind, instance string) (string, error) {
+	resource, err := lookupResource(kind)
+	if err != nil {
+		return "", err
+	}
+	_, err = kubeCli.client.do(http.MethodDelete, kubeCli.client.resourcePath(resource, "", instance), nil, "", nil)
+	if err != nil {
+		if IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return fmt.Sprintf("%s \"%s\" deleted", resource.qualifiedName(), instance), nil
+}
+
+// WaitForResource polls a resource until the given status condition becomes true or the timeout is reached.
+func (kubeCli *NativeKubeCli) WaitForResource(condition string, timeoutSeconds int, resourceType, resourceName string,
+	namespace ...string) error {
+	resource, err := lookupResource(resourceType)
+	if err != nil {
+		return err
+	}
+	ns := ""
+	if len(namespace) > 0 {
+		ns = namespace[0]
+	}
+	object := struct {
+		Status struct {
+			Conditions []struct {
+				Type   string `json:"type"`
+				Status string `json:"status"`
+			} `json:"conditions"`
+		} `json:"status"`
+	}{}
+	timeout := time.Duration(timeoutSeconds) * time.Second
+	for start := time.Now(); ; time.Sleep(waitPollInterval) {
+		err := kubeCli.client.get(resource, ns, resourceName, nil, &object)
+		if err != nil && !IsNotFound(err) {
+			return err
+		}
+		for _, c := range object.Status.Conditions {
+			if strings.EqualFold(c.Type, condition) && strings.EqualFold(c.Status, "True") {
+				return nil
+			}
+		}
+		if time.Since(start)+waitPollInterval > timeout {
+			return fmt.Errorf("timed out waiting for the condition %s on %s/%s", condition,
+				resource.qualifiedName(), resourceName)
+		}
+	}
+}
@@ -0,0 +1,538 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const cellsPath = "/apis/mesh.cellery.io/v1alpha2/namespaces/default/cells"
+
+type recordedRequest struct {
+	method      string
+	path        string
+	query       string
+	contentType string
+	body        string
+}
+
+// newFakeApiServer starts a fake API server which serves the given responses keyed by "<METHOD> <path>"
+// and returns a NativeKubeCli connected to it.
+func newFakeApiServer(t *testing.T, responses map[string]string) (*NativeKubeCli, *[]recordedRequest, func()) {
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, recordedRequest{
+			method:      r.Method,
+			path:        r.URL.Path,
+			query:       r.URL.RawQuery,
+			contentType: r.Header.Get("Content-Type"),
+			body:        string(body),
+		})
+		if r.Header.Get("Authorization") != "Bearer foo-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"kind":"Status","status":"Failure","reason":"NotFound","code":404,`+
+				`"message":"%s not found"}`, r.URL.Path)
+			return
+		}
+		fmt.Fprint(w, response)
+	}))
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatalf("failed to create temp dir, %v", err)
+	}
+	kubeConfig := filepath.Join(dir, "config")
+	err = ioutil.WriteFile(kubeConfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: fake
+clusters:
+- name: fake
+  cluster:
+    server: %s
+contexts:
+- name: fake
+  context:
+    cluster: fake
+    user: fake
+users:
+- name: fake
+  user:
+    token: foo-token
+`, server.URL)), 0644)
+	if err != nil {
+		t.Fatalf("failed to write kubeconfig, %v", err)
+	}
+	kubeCli, err := NewNativeKubeCli(kubeConfig)
+	if err != nil {
+		t.Fatalf("failed to create native kube cli, %v", err)
+	}
+	return kubeCli, &requests, func() {
+		server.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestNativeGetCells(t *testing.T) {
+	kubeCli, _, closeServer := newFakeApiServer(t, map[string]string{
+		"GET " + cellsPath: `{"items":[{"metadata":{"name":"employee"},"status":{"status":"Ready"}},` +
+			`{"metadata":{"name":"stock"},"status":{"status":"NotReady"}}]}`,
+	})
+	defer closeServer()
+	cells, err := kubeCli.GetCells()
+	if err != nil {
+		t.Fatalf("error in GetCells, %v", err)
+	}
+	var names []string
+	for _, cell := range cells {
+		names = append(names, cell.CellMetaData.Name+":"+cell.CellStatus.Status)
+	}
+	if diff := cmp.Diff([]string{"employee:Ready", "stock:NotReady"}, names); diff != "" {
+		t.Errorf("GetCells: unexpected cells (-want, +got)\n%v", diff)
+	}
+}
+
+func TestNativeGetCellNotFound(t *testing.T) {
+	kubeCli, _, closeServer := newFakeApiServer(t, map[string]string{})
+	defer closeServer()
+	_, err := kubeCli.GetCell("employee")
+	if err == nil || err.Error() != "cell instance employee not found" {
+		t.Errorf("GetCell: expected not found error, got %v", err)
+	}
+	if err := kubeCli.IsInstanceAvailable("employee"); err == nil ||
+		err.Error() != "instance employee not available in the runtime" {
+		t.Errorf("IsInstanceAvailable: expected not available error, got %v", err)
+	}
+}
+
+func TestNativeVersion(t *testing.T) {
+	kubeCli, _, closeServer := newFakeApiServer(t, map[string]string{
+		"GET /version": `{"gitVersion":"v1.14.3"}`,
+	})
+	defer closeServer()
+	serverVersion, clientVersion, err := kubeCli.Version()
+	if err != nil {
+		t.Fatalf("error in Version, %v", err)
+	}
+	if serverVersion != "v1.14.3" {
+		t.Errorf("Version: expected server version v1.14.3, got %s", serverVersion)
+	}
+	if clientVersion != "native (cellery unknown)" {
+		t.Errorf("Version: expected the native client as the client version, got %s", clientVersion)
+	}
+}
+
+func TestNativeJsonPatch(t *testing.T) {
+	kubeCli, requests, closeServer := newFakeApiServer(t, map[string]string{
+		"PATCH " + cellsPath + "/employee": `{}`,
+	})
+	defer closeServer()
+	patch := `[{"op":"replace","path":"/spec/components/0/spec/replicas","value":2}]`
+	if err := kubeCli.JsonPatch("cells.mesh.cellery.io", "employee", patch); err != nil {
+		t.Fatalf("error in JsonPatch, %v", err)
+	}
+	got := (*requests)[0]
+	if got.contentType != "application/json-patch+json" || got.body != patch {
+		t.Errorf("JsonPatch: unexpected request %+v", got)
+	}
+}
+
+func TestNativeApplyFile(t *testing.T) {
+	kubeCli, requests, closeServer := newFakeApiServer(t, map[string]string{
+		"PATCH /apis/networking.istio.io/v1alpha3/namespaces/default/virtualservices/employee--vs": `{}`,
+		"PATCH " + cellsPath + "/employee": `{}`,
+	})
+	defer closeServer()
+	file, err := ioutil.TempFile("", "artifacts*.yaml")
+	if err != nil {
+		t.Fatalf("failed to create temp file, %v", err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString(`apiVersion: networking.istio.io/v1alpha3
+kind: VirtualService
+metadata:
+  name: employee--vs
+spec:
+  hosts:
+  - employee--gateway-service
+---
+apiVersion: mesh.cellery.io/v1alpha2
+kind: Cell
+metadata:
+  name: employee
+`)
+	file.Close()
+	if err := kubeCli.ApplyFile(file.Name()); err != nil {
+		t.Fatalf("error in ApplyFile, %v", err)
+	}
+	var got []string
+	for _, req := range *requests {
+		got = append(got, req.method+" "+req.path+"?"+req.query+" "+req.contentType)
+	}
+	want := []string{
+		"PATCH /apis/networking.istio.io/v1alpha3/namespaces/default/virtualservices/employee--vs" +
+			"?fieldManager=cellery&force=true application/apply-patch+yaml",
+		"PATCH " + cellsPath + "/employee?fieldManager=cellery&force=true application/apply-patch+yaml",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ApplyFile: unexpected requests (-want, +got)\n%v", diff)
+	}
+	if !strings.Contains((*requests)[0].body, `"hosts":["employee--gateway-service"]`) {
+		t.Errorf("ApplyFile: unexpected body %s", (*requests)[0].body)
+	}
+}
+
+func TestNativeDeleteResource(t *testing.T) {
+	kubeCli, _, closeServer := newFakeApiServer(t, map[string]string{
+		"DELETE " + cellsPath + "/employee": `{}`,
+	})
+	defer closeServer()
+	out, err := kubeCli.DeleteResource("cells", "employee")
+	if err != nil {
+		t.Fatalf("error in DeleteResource, %v", err)
+	}
+	if diff := cmp.Diff(`cell.mesh.cellery.io "employee" deleted`, out); diff != "" {
+		t.Errorf("DeleteResource: unexpected output (-want, +got)\n%v", diff)
+	}
+	// Deleting a missing resource is ignored
+	if _, err := kubeCli.DeleteResource("cells", "stock"); err != nil {
+		t.Errorf("DeleteResource: expected missing resource to be ignored, got %v", err)
+	}
+}
+
+func TestNativeWaitForResource(t *testing.T) {
+	kubeCli, _, closeServer := newFakeApiServer(t, map[string]string{
+		"GET /apis/apps/v1/namespaces/cellery-system/deployments/controller": `{"status":{"conditions":` +
+			`[{"type":"Available","status":"True"}]}}`,
+	})
+	defer closeServer()
+	if err := kubeCli.WaitForResource("available", 10, "deployment", "controller",
+		"cellery-system"); err != nil {
+		t.Errorf("error in WaitForResource, %v", err)
+	}
+	if err := kubeCli.WaitForResource("available", 0, "deployment", "missing",
+		"cellery-system"); err == nil {
+		t.Errorf("WaitForResource: expected timeout error")
+	}
+}
+
+func TestNativeStreamLogs(t *testing.T) {
+	kubeCli, requests, closeServer := newFakeApiServer(t, map[string]string{
+		"GET /api/v1/namespaces/default/pods": `{"items":[` +
+			`{"metadata":{"name":"employee--salary-7d4b9c","labels":{"mesh.cellery.io/cell":"employee",` +
+			`"mesh.cellery.io/component":"employee--salary"}},"spec":{"containers":[{"name":"salary"}]}},` +
+			`{"metadata":{"name":"stock--stock-5f6d7","labels":{"mesh.cellery.io/composite":"stock",` +
+			`"mesh.cellery.io/component":"stock--stock"}},"spec":{"containers":[{"name":"stock"}]}}]}`,
+		"GET /api/v1/namespaces/default/pods/employee--salary-7d4b9c/log": "" +
+			"2020-03-01T10:00:01.000000001Z salary started\n" +
+			"2020-03-01T10:00:03Z salary request received\n",
+		"GET /api/v1/namespaces/default/pods/stock--stock-5f6d7/log": "2020-03-01T10:00:02Z stock started\n",
+	})
+	defer closeServer()
+	var lines []string
+	err := kubeCli.StreamLogs([]string{"employee", "stock"}, "", false, LogOptions{Since: 90 * time.Second, Tail: 10},
+		func(line LogLine) {
+			lines = append(lines, fmt.Sprintf("%s/%s %s %s", line.Instance, line.Component,
+				line.Time.Format(time.RFC3339), line.Text))
+		})
+	if err != nil {
+		t.Fatalf("error in StreamLogs, %v", err)
+	}
+	want := []string{
+		"employee/salary 2020-03-01T10:00:01Z salary started",
+		"stock/stock 2020-03-01T10:00:02Z stock started",
+		"employee/salary 2020-03-01T10:00:03Z salary request received",
+	}
+	if diff := cmp.Diff(want, lines); diff != "" {
+		t.Errorf("StreamLogs: unexpected lines (-want, +got)\n%v", diff)
+	}
+	var queries []string
+	for _, request := range *requests {
+		queries = append(queries, request.query)
+	}
+	// The logs of the containers are read concurrently
+	sort.Strings(queries[2:])
+	wantQueries := []string{
+		"labelSelector=mesh.cellery.io%2Fcell+in+%28employee%2Cstock%29%2Cmesh.cellery.io%2Fcomponent",
+		"labelSelector=mesh.cellery.io%2Fcomposite+in+%28employee%2Cstock%29%2Cmesh.cellery.io%2Fcomponent",
+		"container=salary&sinceSeconds=90&tailLines=10&timestamps=true",
+		"container=stock&sinceSeconds=90&tailLines=10&timestamps=true",
+	}
+	if diff := cmp.Diff(wantQueries, queries); diff != "" {
+		t.Errorf("StreamLogs: unexpected queries (-want, +got)\n%v", diff)
+	}
+}
+
+// fakeLogSource serves the logs of a container which restarts after each stream.
+type fakeLogSource struct {
+	streams []string
+	queries []logQuery
+}
+
+func (source *fakeLogSource) listLogPods(labelSelector string) ([]logPod, error) {
+	return nil, nil
+}
+
+func (source *fakeLogSource) openLog(pod, container string, query logQuery) (io.ReadCloser, error) {
+	source.queries = append(source.queries, query)
+	stream := source.streams[0]
+	source.streams = source.streams[1:]
+	return ioutil.NopCloser(strings.NewReader(stream)), nil
+}
+
+func TestReadLogResumed(t *testing.T) {
+	source := &fakeLogSource{streams: []string{
+		"2020-03-01T10:00:01Z first\n2020-03-01T10:00:02.5Z second\n",
+		// The resumed stream starts from the last whole second and repeats the last line
+		"2020-03-01T10:00:02.5Z second\n2020-03-01T10:00:04Z third\n",
+	}}
+	target := logTarget{instance: "employee", component: "salary", pod: "employee--salary-7d4b9c",
+		container: "salary"}
+	var lines []string
+	emit := func(line LogLine) {
+		lines = append(lines, line.Text)
+	}
+	last, err := readLog(source, target, logQuery{follow: true, tail: -1}, time.Time{}, nil, emit)
+	if err != nil {
+		t.Fatalf("error in readLog, %v", err)
+	}
+	if _, err := readLog(source, target, logQuery{follow: true, sinceTime: last, tail: -1}, last, nil,
+		emit); err != nil {
+		t.Fatalf("error in readLog, %v", err)
+	}
+	if diff := cmp.Diff([]string{"first", "second", "third"}, lines); diff != "" {
+		t.Errorf("readLog: unexpected lines (-want, +got)\n%v", diff)
+	}
+}
+
+// failingLogSource lists a single container of which the log cannot be opened.
+type failingLogSource struct {
+	attempts []time.Time
+}
+
+func (source *failingLogSource) listLogPods(labelSelector string) ([]logPod, error) {
+	if !strings.HasPrefix(labelSelector, "mesh.cellery.io/cell ") {
+		return nil, nil
+	}
+	pod := logPod{}
+	pod.Metadata.Name = "employee--salary-7d4b9c"
+	pod.Metadata.Labels = map[string]string{"mesh.cellery.io/cell": "employee",
+		"mesh.cellery.io/component": "employee--salary"}
+	pod.Spec.Containers = []ContainerTemplate{{Name: "salary"}}
+	return []logPod{pod}, nil
+}
+
+func (source *failingLogSource) openLog(pod, container string, query logQuery) (io.ReadCloser, error) {
+	source.attempts = append(source.attempts, time.Now())
+	return nil, fmt.Errorf("container salary is waiting to start")
+}
+
+func TestStreamLogsFollowFailing(t *testing.T) {
+	defer func(interval time.Duration) { logPollInterval = interval }(logPollInterval)
+	logPollInterval = 2 * time.Millisecond
+	source := &failingLogSource{}
+	err := streamLogs(source, []string{"employee"}, "", false, LogOptions{Follow: true, Tail: -1},
+		func(line LogLine) {})
+	want := "failed to read logs of container salary of pod employee--salary-7d4b9c, container salary is " +
+		"waiting to start"
+	if err == nil {
+		t.Fatalf("streamLogs: expected an error for a failing container")
+	}
+	if diff := cmp.Diff(want, err.Error()); diff != "" {
+		t.Errorf("streamLogs: unexpected error (-want, +got)\n%v", diff)
+	}
+	if len(source.attempts) != maxLogStreamFailures {
+		t.Fatalf("streamLogs: expected %d attempts, got %d", maxLogStreamFailures, len(source.attempts))
+	}
+	// The wait before each retry is doubled
+	for i := 1; i < len(source.attempts); i++ {
+		if wait := source.attempts[i].Sub(source.attempts[i-1]); wait < logRetryInterval(i) {
+			t.Errorf("streamLogs: retry %d after %s, expected a backoff of %s", i, wait, logRetryInterval(i))
+		}
+	}
+}
+
+// followLogSource lists a pod with a container of which the log ends cleanly, and fails opening the log once the
+// container is running again.
+type followLogSource struct {
+	mutex   sync.Mutex
+	running bool
+	opens   int
+}
+
+func (source *followLogSource) listLogPods(labelSelector string) ([]logPod, error) {
+	if !strings.HasPrefix(labelSelector, "mesh.cellery.io/cell ") {
+		return nil, nil
+	}
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	pod := logPod{}
+	pod.Metadata.Name = "employee--salary-7d4b9c"
+	pod.Metadata.Labels = map[string]string{"mesh.cellery.io/cell": "employee",
+		"mesh.cellery.io/component": "employee--salary"}
+	pod.Spec.Containers = []ContainerTemplate{{Name: "salary"}}
+	status := ContainerStatus{Name: "salary"}
+	if source.running {
+		status.State.Running = &ContainerStateRunning{}
+	} else {
+		status.State.Terminated = &ContainerStateTerminated{Reason: "Completed"}
+	}
+	pod.Status.ContainerStatuses = []ContainerStatus{status}
+	return []logPod{pod}, nil
+}
+
+func (source *followLogSource) openLog(pod, container string, query logQuery) (io.ReadCloser, error) {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	source.opens++
+	if source.opens > 1 {
+		return nil, fmt.Errorf("container salary is waiting to start")
+	}
+	return ioutil.NopCloser(strings.NewReader("2020-03-01T10:00:01Z done\n")), nil
+}
+
+func (source *followLogSource) openCount() int {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	return source.opens
+}
+
+func TestStreamLogsFollowCompleted(t *testing.T) {
+	defer func(interval time.Duration) { logPollInterval = interval }(logPollInterval)
+	logPollInterval = 2 * time.Millisecond
+	source := &followLogSource{}
+	result := make(chan error, 1)
+	go func() {
+		result <- streamLogs(source, []string{"employee"}, "", false, LogOptions{Follow: true, Tail: -1},
+			func(line LogLine) {})
+	}()
+	time.Sleep(10 * logPollInterval)
+	if opens := source.openCount(); opens != 1 {
+		t.Errorf("streamLogs: expected the completed log to be opened once, got %d", opens)
+	}
+	source.mutex.Lock()
+	source.running = true
+	source.mutex.Unlock()
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Fatalf("streamLogs: expected an error for a failing container")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("streamLogs: did not give up on the failing container")
+	}
+	if opens := source.openCount(); opens != 1+maxLogStreamFailures {
+		t.Errorf("streamLogs: expected the log to be reopened once running, got %d opens", opens)
+	}
+}
+
+// stoppedLogSource lists a pod with a container of which the log is streamed until it is closed, and then a pod
+// of which the log cannot be opened.
+type stoppedLogSource struct {
+	listed int
+	closed chan struct{}
+}
+
+func (source *stoppedLogSource) listLogPods(labelSelector string) ([]logPod, error) {
+	if !strings.HasPrefix(labelSelector, "mesh.cellery.io/cell ") {
+		return nil, nil
+	}
+	source.listed++
+	var pods []logPod
+	for _, name := range []string{"salary", "stock"} {
+		if name == "salary" && source.listed > 1 {
+			continue
+		}
+		pod := logPod{}
+		pod.Metadata.Name = "employee--" + name + "-7d4b9c"
+		pod.Metadata.Labels = map[string]string{"mesh.cellery.io/cell": "employee",
+			"mesh.cellery.io/component": "employee--" + name}
+		pod.Spec.Containers = []ContainerTemplate{{Name: name}}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+func (source *stoppedLogSource) openLog(pod, container string, query logQuery) (io.ReadCloser, error) {
+	if container != "salary" {
+		return nil, fmt.Errorf("container %s is waiting to start", container)
+	}
+	reader, writer := io.Pipe()
+	return &closeNotifier{ReadCloser: reader, writer: writer, closed: source.closed}, nil
+}
+
+type closeNotifier struct {
+	io.ReadCloser
+	writer *io.PipeWriter
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (notifier *closeNotifier) Close() error {
+	notifier.once.Do(func() {
+		notifier.writer.Close()
+		close(notifier.closed)
+	})
+	return notifier.ReadCloser.Close()
+}
+
+func TestStreamLogsFollowStopsStreams(t *testing.T) {
+	defer func(interval time.Duration) { logPollInterval = interval }(logPollInterval)
+	logPollInterval = 2 * time.Millisecond
+	source := &stoppedLogSource{closed: make(chan struct{})}
+	err := streamLogs(source, []string{"employee"}, "", false, LogOptions{Follow: true, Tail: -1},
+		func(line LogLine) {})
+	if err == nil {
+		t.Fatalf("streamLogs: expected an error for a failing container")
+	}
+	select {
+	case <-source.closed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("streamLogs: the log which was still streamed is not closed")
+	}
+}
+
+func TestLogRetryInterval(t *testing.T) {
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second,
+		32 * time.Second, time.Minute, time.Minute}
+	var got []time.Duration
+	for failures := 1; failures <= len(want); failures++ {
+		got = append(got, logRetryInterval(failures))
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("logRetryInterval: unexpected intervals (-want, +got)\n%v", diff)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// apiResource describes a kubernetes resource type served by the API server.
+type apiResource struct {
+	group      string
+	version    string
+	name       string
+	kind       string
+	namespaced bool
+	aliases    []string
+}
+
+// apiResources lists the resource types used by cellery. Resources are matched in order, hence istio
+// gateways take precedence over cellery gateways when referred to by the plain resource name.
+var apiResources = []apiResource{
+	{group: "mesh.cellery.io", version: "v1alpha2", name: "cells", kind: "Cell", namespaced: true,
+		aliases: []string{"cell"}},
+	{group: "mesh.cellery.io", version: "v1alpha2", name: "composites", kind: "Composite", namespaced: true,
+		aliases: []string{"composite"}},
+	{group: "mesh.cellery.io", version: "v1alpha2", name: "components", kind: "Component", namespaced: true,
+		aliases: []string{"component"}},
+	{group: "mesh.cellery.io", version: "v1alpha2", name: "autoscalepolicies", kind: "AutoscalePolicy",
+		namespaced: true, aliases: []string{"autoscalepolicy"}},
+	{group: "networking.istio.io", version: "v1alpha3", name: "virtualservices", kind: "VirtualService",
+		namespaced: true, aliases: []string{"virtualservice", "vs"}},
+	{group: "networking.istio.io", version: "v1alpha3", name: "gateways", kind: "Gateway", namespaced: true,
+		aliases: []string{"gateway", "gw"}},
+	{group: "networking.istio.io", version: "v1alpha3", name: "destinationrules", kind: "DestinationRule",
+		namespaced: true, aliases: []string{"destinationrule", "dr"}},
+	{group: "mesh.cellery.io", version: "v1alpha2", name: "gateways", kind: "Gateway", namespaced: true},
+	{group: "apps", version: "v1", name: "deployments", kind: "Deployment", namespaced: true,
+		aliases: []string{"deployment", "deploy"}},
+	{group: "autoscaling", version: "v2beta2", name: "horizontalpodautoscalers", kind: "HorizontalPodAutoscaler",
+		namespaced: true, aliases: []string{"horizontalpodautoscaler", "hpa"}},
+	{group: "batch", version: "v1beta1", name: "cronjobs", kind: "CronJob", namespaced: true,
+		aliases: []string{"cronjob", "cj"}},
+	{version: "v1", name: "pods", kind: "Pod", namespaced: true, aliases: []string{"pod", "po"}},
+	{version: "v1", name: "services", kind: "Service", namespaced: true, aliases: []string{"service", "svc"}},
+	{version: "v1", name: "configmaps", kind: "ConfigMap", namespaced: true,
+		aliases: []string{"configmap", "cm"}},
+	{version: "v1", name: "secrets", kind: "Secret", namespaced: true, aliases: []string{"secret"}},
+	{version: "v1", name: "serviceaccounts", kind: "ServiceAccount", namespaced: true,
+		aliases: []string{"serviceaccount", "sa"}},
+	{group: "rbac.authorization.k8s.io", version: "v1", name: "roles", kind: "Role", namespaced: true,
+		aliases: []string{"role"}},
+	{group: "rbac.authorization.k8s.io", version: "v1", name: "rolebindings", kind: "RoleBinding",
+		namespaced: true, aliases: []string{"rolebinding"}},
+	{version: "v1", name: "events", kind: "Event", namespaced: true, aliases: []string{"event", "ev"}},
+	{version: "v1", name: "persistentvolumeclaims", kind: "PersistentVolumeClaim", namespaced: true,
+		aliases: []string{"persistentvolumeclaim", "pvc"}},
+	{version: "v1", name: "persistentvolumes", kind: "PersistentVolume", aliases: []string{"persistentvolume", "pv"}},
+	{version: "v1", name: "namespaces", kind: "Namespace", aliases: []string{"namespace", "ns"}},
+	{version: "v1", name: "nodes", kind: "Node", aliases: []string{"node", "no"}},
+	{group: "apiregistration.k8s.io", version: "v1", name: "apiservices", kind: "APIService",
+		aliases: []string{"apiservice"}},
+}
+
+// lookupResource finds a resource by its name, alias or fully qualified name (e.g. cells.mesh.cellery.io).
+func lookupResource(name string) (apiResource, error) {
+	name = strings.ToLower(name)
+	group := ""
+	if i := strings.Index(name, "."); i > 0 {
+		name, group = name[:i], name[i+1:]
+	}
+	for _, resource := range apiResources {
+		if group != "" && resource.group != group {
+			continue
+		}
+		if resource.name == name {
+			return resource, nil
+		}
+		for _, alias := range resource.aliases {
+			if alias == name {
+				return resource, nil
+			}
+		}
+	}
+	return apiResource{}, fmt.Errorf("the server doesn't have a resource type %q", name)
+}
+
+// lookupResourceByKind finds a resource by the apiVersion and kind of an object.
+func lookupResourceByKind(apiVersion, kind string) (apiResource, error) {
+	group := ""
+	version := apiVersion
+	if i := strings.LastIndex(apiVersion, "/"); i > 0 {
+		group, version = apiVersion[:i], apiVersion[i+1:]
+	}
+	for _, resource := range apiResources {
+		if resource.group == group && resource.kind == kind {
+			resource.version = version
+			return resource, nil
+		}
+	}
+	return apiResource{}, fmt.Errorf("no matches for kind %q in version %q", kind, apiVersion)
+}
+
+func (resource apiResource) qualifiedName() string {
+	if resource.group == "" {
+		return strings.ToLower(resource.kind)
+	}
+	return strings.ToLower(resource.kind) + "." + resource.group
+}
+
+// ResourceName returns the fully qualified resource name (e.g. cells.mesh.cellery.io) of an object with the given
+// apiVersion and kind, which can be used to get the object.
+func ResourceName(apiVersion, kind string) (string, error) {
+	resource, err := lookupResourceByKind(apiVersion, kind)
+	if err != nil {
+		return "", err
+	}
+	if resource.group == "" {
+		return resource.name, nil
+	}
+	return resource.name + "." + resource.group, nil
+}
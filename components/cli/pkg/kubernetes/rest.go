@@ -0,0 +1,286 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package kubernetes
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/mitchellh/go-homedir"
+)
+
+const defaultNamespace = "default"
+
+// APIError is the structured error returned by the API server for a failed request.
+type APIError struct {
+	Code    int    `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func (err *APIError) Error() string {
+	if err.Message != "" {
+		return err.Message
+	}
+	return fmt.Sprintf("request failed with status code %d", err.Code)
+}
+
+// IsNotFound returns true if the error is an API error with status code 404.
+func IsNotFound(err error) bool {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.Code == http.StatusNotFound
+	}
+	return false
+}
+
+// restClient talks to the kubernetes API server using the credentials of the current kubeconfig context.
+type restClient struct {
+	server     string
+	namespace  string
+	token      string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// KubeConfigPath returns the kubeconfig file used by kubectl.
+func KubeConfigPath() string {
+	if kubeConfig := os.Getenv("KUBECONFIG"); kubeConfig != "" {
+		return strings.Split(kubeConfig, string(os.PathListSeparator))[0]
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+func newRestClient(kubeConfigPath string) (*restClient, error) {
+	kubeConfigBytes, err := ioutil.ReadFile(kubeConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig %s, %v", kubeConfigPath, err)
+	}
+	kubeConfig := &Config{}
+	if err := yaml.Unmarshal(kubeConfigBytes, kubeConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig %s, %v", kubeConfigPath, err)
+	}
+	var context *Context
+	for _, c := range kubeConfig.Contexts {
+		if c.Name == kubeConfig.CurrentContext {
+			context = c.Context
+		}
+	}
+	if context == nil {
+		return nil, fmt.Errorf("current context %q not found in kubeconfig", kubeConfig.CurrentContext)
+	}
+	var cluster *Cluster
+	for _, c := range kubeConfig.Clusters {
+		if c.Name == context.Cluster {
+			cluster = c.Cluster
+		}
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster %q not found in kubeconfig", context.Cluster)
+	}
+	user := &AuthInfo{}
+	for _, u := range kubeConfig.AuthInfos {
+		if u.Name == context.AuthInfo && u.User != nil {
+			user = u.User
+		}
+	}
+	if user.Exec != nil || user.AuthProvider != nil {
+		return nil, fmt.Errorf("auth plugins configured for user %q are not supported by the native client, "+
+			"use the kubectl client instead", context.AuthInfo)
+	}
+	tlsConfig, err := buildTlsConfig(cluster, user, filepath.Dir(kubeConfigPath))
+	if err != nil {
+		return nil, err
+	}
+	client := &restClient{
+		server:    strings.TrimSuffix(cluster.Server, "/"),
+		namespace: context.Namespace,
+		token:     user.Token,
+		username:  user.Username,
+		password:  user.Password,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}
+	if client.namespace == "" {
+		client.namespace = defaultNamespace
+	}
+	if client.token == "" && user.TokenFile != "" {
+		token, err := ioutil.ReadFile(resolvePath(user.TokenFile, filepath.Dir(kubeConfigPath)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token file, %v", err)
+		}
+		client.token = strings.TrimSpace(string(token))
+	}
+	return client, nil
+}
+
+func buildTlsConfig(cluster *Cluster, user *AuthInfo, baseDir string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cluster.InsecureSkipTLSVerify}
+	caData := cluster.CertificateAuthorityData
+	if len(caData) == 0 && cluster.CertificateAuthority != "" {
+		var err error
+		if caData, err = ioutil.ReadFile(resolvePath(cluster.CertificateAuthority, baseDir)); err != nil {
+			return nil, fmt.Errorf("failed to read certificate authority, %v", err)
+		}
+	}
+	if len(caData) > 0 {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("failed to parse certificate authority data")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+	certData := user.ClientCertificateData
+	keyData := user.ClientKeyData
+	var err error
+	if len(certData) == 0 && user.ClientCertificate != "" {
+		if certData, err = ioutil.ReadFile(resolvePath(user.ClientCertificate, baseDir)); err != nil {
+			return nil, fmt.Errorf("failed to read client certificate, %v", err)
+		}
+	}
+	if len(keyData) == 0 && user.ClientKey != "" {
+		if keyData, err = ioutil.ReadFile(resolvePath(user.ClientKey, baseDir)); err != nil {
+			return nil, fmt.Errorf("failed to read client key, %v", err)
+		}
+	}
+	if len(certData) > 0 && len(keyData) > 0 {
+		cert, err := tls.X509KeyPair(certData, keyData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate, %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func resolvePath(path, baseDir string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// resourcePath builds the API path of a resource, or of the resource collection if the name is empty.
+func (client *restClient) resourcePath(resource apiResource, namespace, name string) string {
+	var path string
+	if resource.group == "" {
+		path = "/api/" + resource.version
+	} else {
+		path = "/apis/" + resource.group + "/" + resource.version
+	}
+	if resource.namespaced {
+		if namespace == "" {
+			namespace = client.namespace
+		}
+		path += "/namespaces/" + namespace
+	}
+	path += "/" + resource.name
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
+
+func (client *restClient) newRequest(method, path string, query url.Values, contentType string,
+	body []byte) (*http.Request, error) {
+	requestUrl := client.server + path
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+	displayVerboseRequest(method, requestUrl)
+	req, err := http.NewRequest(method, requestUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if client.token != "" {
+		req.Header.Set("Authorization", "Bearer "+client.token)
+	} else if client.username != "" {
+		req.SetBasicAuth(client.username, client.password)
+	}
+	return req, nil
+}
+
+// do executes a request and returns the response body. Failed requests are returned as *APIError.
+func (client *restClient) do(method, path string, query url.Values, contentType string, body []byte) ([]byte, error) {
+	resp, err := client.stream(method, path, query, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+	return ioutil.ReadAll(resp)
+}
+
+// stream executes a request and returns the response body without reading it.
+func (client *restClient) stream(method, path string, query url.Values, contentType string,
+	body []byte) (io.ReadCloser, error) {
+	req, err := client.newRequest(method, path, query, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		apiErr := &APIError{}
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		if err := json.Unmarshal(respBody, apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(respBody))
+		}
+		apiErr.Code = resp.StatusCode
+		return nil, apiErr
+	}
+	return resp.Body, nil
+}
+
+func (client *restClient) get(resource apiResource, namespace, name string, query url.Values,
+	output interface{}) error {
+	out, err := client.do(http.MethodGet, client.resourcePath(resource, namespace, name), query, "", nil)
+	if err != nil {
+		return err
+	}
+	if output == nil {
+		return nil
+	}
+	return json.Unmarshal(out, output)
+}
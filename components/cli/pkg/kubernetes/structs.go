@@ -18,6 +18,8 @@
 
 package kubernetes
 
+import "encoding/json"
+
 type Node struct {
 	Items []NodeItem `json:"items"`
 }
@@ -95,9 +97,10 @@ type Composite struct {
 }
 
 type K8SMetaData struct {
-	CreationTimestamp string          `json:"creationTimestamp"`
-	Annotations       CellAnnotations `json:"annotations"`
-	Name              string          `json:"name"`
+	CreationTimestamp string            `json:"creationTimestamp"`
+	Annotations       CellAnnotations   `json:"annotations"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Name              string            `json:"name"`
 }
 
 type CellSpec struct {
@@ -123,8 +126,9 @@ type PodTemplate struct {
 }
 
 type ComponentTemplateSpec struct {
-	PodTemplate PodTemplate `json:"template"`
-	Ports       []Port      `json:"ports"`
+	PodTemplate   PodTemplate `json:"template"`
+	Ports         []Port      `json:"ports"`
+	ScalingPolicy interface{} `json:"scalingPolicy,omitempty"`
 }
 
 type ContainerTemplate struct {
@@ -173,14 +177,14 @@ type ClusterIngress struct {
 }
 
 type GatewayHttpApi struct {
-	Context      string          `json:"context"`
-	Version      string          `json:"version"`
-	Definitions  []APIDefinition `json:"definitions"`
-	Global       bool            `json:"global"`
-	Authenticate bool            `json:"authenticate"`
-	Port         uint32          `json:"port"`
-	Destination  Destination     `json:"destination,omitempty"`
-	ZeroScale    bool            `json:"zeroScale,omitempty"`
+	Context      string             `json:"context"`
+	Version      string             `json:"version"`
+	Definitions  []APIDefinition    `json:"definitions"`
+	Global       bool               `json:"global"`
+	Authenticate bool               `json:"authenticate"`
+	Port         uint32             `json:"port"`
+	Destination  IngressDestination `json:"destination,omitempty"`
+	ZeroScale    bool               `json:"zeroScale,omitempty"`
 }
 
 type GatewayConfig struct {
@@ -206,6 +210,8 @@ type GatewayTcpApi struct {
 	Definitions []GatewayDefinition `json:"definitions"`
 	Global      bool                `json:"global"`
 	Vhost       string              `json:"vhost"`
+	Port        uint32              `json:"port,omitempty"`
+	Destination IngressDestination  `json:"destination,omitempty"`
 }
 
 type GatewayGrpcApi struct {
@@ -214,6 +220,14 @@ type GatewayGrpcApi struct {
 	Definitions []GatewayDefinition `json:"definitions"`
 	Global      bool                `json:"global"`
 	Vhost       string              `json:"vhost"`
+	Port        uint32              `json:"port,omitempty"`
+	Destination IngressDestination  `json:"destination,omitempty"`
+}
+
+// IngressDestination is the component and port to which the gateway forwards the requests of an ingress.
+type IngressDestination struct {
+	Host string `json:"host"`
+	Port uint32 `json:"port,omitempty"`
 }
 
 type GatewayDefinition struct {
@@ -261,9 +275,83 @@ type PodMetaData struct {
 }
 
 type PodStatus struct {
-	Phase      string         `json:"phase"`
-	StartTime  string         `json:"startTime"`
-	Conditions []PodCondition `json:"conditions"`
+	Phase             string            `json:"phase"`
+	StartTime         string            `json:"startTime"`
+	Conditions        []PodCondition    `json:"conditions"`
+	ContainerStatuses []ContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+type ContainerStatus struct {
+	Name         string         `json:"name"`
+	Ready        bool           `json:"ready"`
+	RestartCount int            `json:"restartCount"`
+	State        ContainerState `json:"state"`
+	LastState    ContainerState `json:"lastState"`
+}
+
+type ContainerState struct {
+	Waiting    *ContainerStateWaiting    `json:"waiting,omitempty"`
+	Running    *ContainerStateRunning    `json:"running,omitempty"`
+	Terminated *ContainerStateTerminated `json:"terminated,omitempty"`
+}
+
+type ContainerStateRunning struct {
+	StartedAt string `json:"startedAt"`
+}
+
+type ContainerStateWaiting struct {
+	Reason string `json:"reason"`
+}
+
+type ContainerStateTerminated struct {
+	Reason     string `json:"reason"`
+	ExitCode   int    `json:"exitCode"`
+	FinishedAt string `json:"finishedAt"`
+}
+
+type Deployments struct {
+	Items []Deployment `json:"items"`
+}
+
+type Deployment struct {
+	Metadata DeploymentMetadata `json:"metadata"`
+	Spec     DeploymentSpec     `json:"spec"`
+	Status   DeploymentStatus   `json:"status"`
+}
+
+type DeploymentMetadata struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type DeploymentSpec struct {
+	Replicas int `json:"replicas"`
+}
+
+type DeploymentStatus struct {
+	Replicas          int `json:"replicas"`
+	ReadyReplicas     int `json:"readyReplicas"`
+	AvailableReplicas int `json:"availableReplicas"`
+	UpdatedReplicas   int `json:"updatedReplicas"`
+}
+
+type Events struct {
+	Items []Event `json:"items"`
+}
+
+type Event struct {
+	InvolvedObject EventObject `json:"involvedObject"`
+	Type           string      `json:"type"`
+	Reason         string      `json:"reason"`
+	Message        string      `json:"message"`
+	Count          int         `json:"count"`
+	FirstTimestamp string      `json:"firstTimestamp"`
+	LastTimestamp  string      `json:"lastTimestamp"`
+}
+
+type EventObject struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
 }
 
 type PodCondition struct {
@@ -302,7 +390,22 @@ type VirtualService struct {
 }
 
 type VsMetaData struct {
-	Name string `json:"name"`
+	Name        string            `json:"name"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// UnmarshalJSON drops the last applied configuration recorded by kubectl from the annotations, as it must not be
+// applied back along with the virtual service.
+func (metadata *VsMetaData) UnmarshalJSON(data []byte) error {
+	type vsMetaData VsMetaData
+	if err := json.Unmarshal(data, (*vsMetaData)(metadata)); err != nil {
+		return err
+	}
+	delete(metadata.Annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	if len(metadata.Annotations) == 0 {
+		metadata.Annotations = nil
+	}
+	return nil
 }
 
 type VsSpec struct {
@@ -336,14 +439,43 @@ type TCPPort struct {
 }
 
 type HTTP struct {
-	Match []HTTPMatch `json:"match"`
-	Route []HTTPRoute `json:"route"`
+	Name             string       `json:"name,omitempty"`
+	Match            []HTTPMatch  `json:"match"`
+	Route            []HTTPRoute  `json:"route"`
+	Mirror           *Destination `json:"mirror,omitempty"`
+	MirrorPercentage *Percent     `json:"mirrorPercentage,omitempty"`
+	Fault            *HTTPFault   `json:"fault,omitempty"`
+	Timeout          string       `json:"timeout,omitempty"`
+	Retries          *HTTPRetry   `json:"retries,omitempty"`
+}
+
+type HTTPRetry struct {
+	Attempts      int    `json:"attempts"`
+	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+	RetryOn       string `json:"retryOn,omitempty"`
+}
+
+type HTTPFault struct {
+	Delay *FaultDelay `json:"delay,omitempty"`
+	Abort *FaultAbort `json:"abort,omitempty"`
+}
+
+type FaultDelay struct {
+	FixedDelay string   `json:"fixedDelay"`
+	Percentage *Percent `json:"percentage,omitempty"`
+}
+
+type FaultAbort struct {
+	HttpStatus int      `json:"httpStatus"`
+	Percentage *Percent `json:"percentage,omitempty"`
 }
 
 type HTTPMatch struct {
 	Authority    Authority               `json:"authority"`
 	SourceLabels map[string]string       `json:"sourceLabels"`
 	Headers      map[string]*StringMatch `json:"headers,omitempty"`
+	Uri          *StringMatch            `json:"uri,omitempty"`
+	QueryParams  map[string]*StringMatch `json:"queryParams,omitempty"`
 }
 
 type StringMatch struct {
@@ -362,7 +494,16 @@ type HTTPRoute struct {
 }
 
 type Destination struct {
-	Host string `json:"host"`
+	Host string        `json:"host"`
+	Port *PortSelector `json:"port,omitempty"`
+}
+
+type PortSelector struct {
+	Number int `json:"number"`
+}
+
+type Percent struct {
+	Value float64 `json:"value"`
 }
 
 type AutoscalePolicy struct {
@@ -388,9 +529,54 @@ type Policy struct {
 	Metrics        []Metric       `json:"metrics"`
 }
 
+// Metric is a metric to autoscale on, as in the autoscaling/v2beta2 API. The field matching the type is set.
 type Metric struct {
-	Type     string   `json:"type"`
-	Resource Resource `json:"resource"`
+	Type     string          `json:"type"`
+	Resource *Resource       `json:"resource,omitempty"`
+	Pods     *PodsMetric     `json:"pods,omitempty"`
+	Object   *ObjectMetric   `json:"object,omitempty"`
+	External *ExternalMetric `json:"external,omitempty"`
+}
+
+// PodsMetric is a metric averaged across the pods of the component, e.g. requests per second.
+type PodsMetric struct {
+	Metric MetricIdentifier `json:"metric"`
+	Target MetricTarget     `json:"target"`
+}
+
+// ObjectMetric is a metric describing another kubernetes object, e.g. the requests per second of an ingress.
+type ObjectMetric struct {
+	DescribedObject CrossVersionObjectReference `json:"describedObject"`
+	Metric          MetricIdentifier            `json:"metric"`
+	Target          MetricTarget                `json:"target"`
+}
+
+// ExternalMetric is a metric not related to any kubernetes object, e.g. the depth of a queue.
+type ExternalMetric struct {
+	Metric MetricIdentifier `json:"metric"`
+	Target MetricTarget     `json:"target"`
+}
+
+type MetricIdentifier struct {
+	Name     string         `json:"name"`
+	Selector *LabelSelector `json:"selector,omitempty"`
+}
+
+type LabelSelector struct {
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+type MetricTarget struct {
+	Type               string `json:"type"`
+	Value              string `json:"value,omitempty"`
+	AverageValue       string `json:"averageValue,omitempty"`
+	AverageUtilization int    `json:"averageUtilization,omitempty"`
+}
+
+type CrossVersionObjectReference struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
 }
 
 type ScaleTargetRef struct {
@@ -400,19 +586,23 @@ type ScaleTargetRef struct {
 }
 
 type Resource struct {
-	Name                     string `json:"name"`
-	TargetAverageUtilization int    `json:"targetAverageUtilization,omitempty"`
-	TargetAverageValue       string `json:"targetAverageValue,omitempty"`
+	Name                     string        `json:"name"`
+	Target                   *MetricTarget `json:"target,omitempty"`
+	TargetAverageUtilization int           `json:"targetAverageUtilization,omitempty"`
+	TargetAverageValue       string        `json:"targetAverageValue,omitempty"`
 }
 
 type ScaleResource struct {
 	Spec struct {
 		Components []struct {
 			Metadata struct {
-				Name string `json:"name,omitempty"`
+				Name        string            `json:"name,omitempty"`
+				Annotations map[string]string `json:"annotations,omitempty"`
 			} `json:"metadata,omitempty"`
 			Spec struct {
 				ScalingPolicy interface{} `json:"scalingPolicy,omitempty"`
+				// Type is the workload of the component, e.g. Deployment, StatefulSet or Job
+				Type string `json:"type,omitempty"`
 			} `json:"spec,omitempty"`
 		} `json:"components,omitempty"`
 		Gateway struct {
@@ -426,9 +616,79 @@ type ScaleResource struct {
 	} `json:"spec,omitempty"`
 }
 
+type DestinationRule struct {
+	Kind       string                  `json:"kind"`
+	APIVersion string                  `json:"apiVersion"`
+	Metadata   DestinationRuleMetadata `json:"metadata"`
+	Spec       DestinationRuleSpec     `json:"spec"`
+}
+
+type DestinationRuleMetadata struct {
+	Name string `json:"name"`
+}
+
+type DestinationRuleSpec struct {
+	Host          string         `json:"host"`
+	TrafficPolicy *TrafficPolicy `json:"trafficPolicy,omitempty"`
+}
+
+type TrafficPolicy struct {
+	OutlierDetection *OutlierDetection `json:"outlierDetection,omitempty"`
+}
+
+type OutlierDetection struct {
+	ConsecutiveErrors  int    `json:"consecutiveErrors,omitempty"`
+	Interval           string `json:"interval,omitempty"`
+	BaseEjectionTime   string `json:"baseEjectionTime,omitempty"`
+	MaxEjectionPercent int    `json:"maxEjectionPercent,omitempty"`
+}
+
+// ResiliencePolicy holds the timeouts, retries and outlier detection of the requests from an instance to its
+// dependencies.
+type ResiliencePolicy struct {
+	Dependencies []DependencyResiliencePolicy `json:"dependencies,omitempty"`
+}
+
+type DependencyResiliencePolicy struct {
+	Instance         string            `json:"instance"`
+	Timeout          string            `json:"timeout,omitempty"`
+	Retries          *HTTPRetry        `json:"retries,omitempty"`
+	OutlierDetection *OutlierDetection `json:"outlierDetection,omitempty"`
+}
+
+// RoutingPlan lists the dependencies of which the traffic is routed to new targets in a single operation.
+type RoutingPlan struct {
+	Routes []PlannedRoute `json:"routes,omitempty"`
+}
+
+type PlannedRoute struct {
+	Sources      []string `json:"sources,omitempty"`
+	Dependency   string   `json:"dependency"`
+	Target       string   `json:"target"`
+	Percentage   *int     `json:"percentage,omitempty"`
+	SessionAware bool     `json:"enableSessionAwareness,omitempty"`
+	Match        []string `json:"match,omitempty"`
+}
+
 type AutoScalingPolicy struct {
 	Components []ComponentScalePolicy `json:"components,omitempty"`
 	Gateway    GwScalePolicy          `json:"gateway,omitempty"`
+	Schedules  []ScalingSchedule      `json:"schedules,omitempty"`
+}
+
+// ScalingSchedule overrides the min and max replicas of the autoscaled components and gateway at the times of the
+// cron schedule, until the next schedule of the policy runs.
+type ScalingSchedule struct {
+	Name       string              `json:"name"`
+	Schedule   string              `json:"schedule"`
+	Components []ScheduledReplicas `json:"components,omitempty"`
+	Gateway    *ScheduledReplicas  `json:"gateway,omitempty"`
+}
+
+type ScheduledReplicas struct {
+	Name        string `json:"name,omitempty"`
+	MinReplicas *int   `json:"minReplicas,omitempty"`
+	MaxReplicas *int   `json:"maxReplicas,omitempty"`
 }
 
 type ScalingPolicy struct {
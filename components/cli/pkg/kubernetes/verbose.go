@@ -43,3 +43,10 @@ func displayVerboseOutput(cmd *exec.Cmd) {
 		fmt.Println(verboseColor(getCommandString(cmd)))
 	}
 }
+
+func displayVerboseRequest(method, url string) {
+	// If running on verbose mode expose the API server requests.
+	if verboseMode {
+		fmt.Println(verboseColor(fmt.Sprintf(">> %s %s", method, url)))
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package metrics queries the metrics of cell instances from a Prometheus compatible API.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const queryTimeout = 30 * time.Second
+
+// Client queries a Prometheus compatible HTTP API.
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+type queryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			// Value is a [<unix time>, "<value>"] pair
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// NewClient returns a Client for the API served at the given endpoint (e.g. http://prometheus:9090).
+func NewClient(endpoint string) *Client {
+	return &Client{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: &http.Client{Timeout: queryTimeout},
+	}
+}
+
+// Query evaluates an instant query and returns the value of the first sample of the result. False is returned if
+// the query did not return any samples, for example when no requests were served.
+func (client *Client) Query(query string) (float64, bool, error) {
+	resp, err := client.httpClient.Get(client.endpoint + "/api/v1/query?" + url.Values{"query": {query}}.Encode())
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query metrics, %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read metrics, %v", err)
+	}
+	result := queryResponse{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, false, fmt.Errorf("failed to query metrics, unexpected response with status %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if result.Status != "success" {
+		return 0, false, fmt.Errorf("failed to query metrics, %s: %s", result.ErrorType, result.Error)
+	}
+	if result.Data.ResultType != "vector" {
+		return 0, false, fmt.Errorf("failed to query metrics, unsupported result type %s", result.Data.ResultType)
+	}
+	if len(result.Data.Result) == 0 || len(result.Data.Result[0].Value) != 2 {
+		return 0, false, nil
+	}
+	sample, ok := result.Data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, false, fmt.Errorf("failed to query metrics, invalid sample %v", result.Data.Result[0].Value)
+	}
+	value, err := strconv.ParseFloat(sample, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query metrics, invalid sample %s", sample)
+	}
+	// NaN is returned for ratios when there is no traffic
+	if math.IsNaN(value) {
+		return 0, false, nil
+	}
+	return value, true, nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.URL.Query().Get("query") {
+		case "error_rate":
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[`+
+				`{"metric":{},"value":[1583056800.781,"0.025"]}]}}`)
+		case "no_traffic":
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[`+
+				`{"metric":{},"value":[1583056800.781,"NaN"]}]}}`)
+		case "empty":
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"parse error"}`)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL + "/")
+
+	tests := []struct {
+		name   string
+		query  string
+		value  float64
+		found  bool
+		errMsg string
+	}{
+		{name: "sample", query: "error_rate", value: 0.025, found: true},
+		{name: "NaN sample", query: "no_traffic"},
+		{name: "no samples", query: "empty"},
+		{name: "invalid query", query: "rate(", errMsg: "failed to query metrics, bad_data: parse error"},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			value, found, err := client.Query(tst.query)
+			if tst.errMsg != "" {
+				if err == nil {
+					t.Fatalf("expected error %s", tst.errMsg)
+				}
+				if diff := cmp.Diff(tst.errMsg, err.Error()); diff != "" {
+					t.Errorf("Query: unexpected error (-want, +got)\n%v", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in Query, %v", err)
+			}
+			if value != tst.value || found != tst.found {
+				t.Errorf("Query: expected (%v, %v), got (%v, %v)", tst.value, tst.found, value, found)
+			}
+		})
+	}
+}
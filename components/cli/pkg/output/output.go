@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	FormatTable = "table"
+	FormatWide  = "wide"
+	FormatJson  = "json"
+	FormatYaml  = "yaml"
+)
+
+// Options holds the output format selected by the user for list, status and describe commands.
+type Options struct {
+	Format   string
+	Template string
+}
+
+// Validate checks whether the selected format is supported.
+func (o Options) Validate() error {
+	switch o.Format {
+	case "", FormatTable, FormatWide, FormatJson, FormatYaml:
+	default:
+		return fmt.Errorf("unsupported output format %s, expected one of %s, %s, %s or %s", o.Format,
+			FormatTable, FormatWide, FormatJson, FormatYaml)
+	}
+	if o.Template != "" {
+		if o.Format != "" && o.Format != FormatTable {
+			return fmt.Errorf("--template cannot be used with output format %s", o.Format)
+		}
+		if _, err := template.New("output").Parse(o.Template); err != nil {
+			return fmt.Errorf("invalid template, %v", err)
+		}
+	}
+	return nil
+}
+
+// Wide returns true if tables should include the additional columns.
+func (o Options) Wide() bool {
+	return o.Format == FormatWide
+}
+
+// Structured returns true if the output should be rendered from the schema instead of a table.
+func (o Options) Structured() bool {
+	return o.Template != "" || o.Format == FormatJson || o.Format == FormatYaml
+}
+
+// Write renders the given value as json, yaml or through the template. Templates are executed against
+// the json representation of the value so that the field names match the json and yaml schemas.
+func Write(w io.Writer, o Options, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling output, %v", err)
+	}
+	switch {
+	case o.Template != "":
+		tmpl, err := template.New("output").Parse(o.Template)
+		if err != nil {
+			return fmt.Errorf("invalid template, %v", err)
+		}
+		var data interface{}
+		if err := json.Unmarshal(content, &data); err != nil {
+			return fmt.Errorf("error unmarshalling output, %v", err)
+		}
+		if err := tmpl.Execute(w, data); err != nil {
+			return fmt.Errorf("error executing template, %v", err)
+		}
+		return nil
+	case o.Format == FormatYaml:
+		if content, err = yaml.JSONToYAML(content); err != nil {
+			return fmt.Errorf("error converting output to yaml, %v", err)
+		}
+		_, err = w.Write(content)
+		return err
+	default:
+		_, err = fmt.Fprintln(w, string(content))
+		return err
+	}
+}
+
+// NewTable returns a table with the standard cellery style which renders to the given writer.
+// Column colors default to the terminal color if not specified.
+func NewTable(w io.Writer, header []string, columnColors ...tablewriter.Colors) *tablewriter.Table {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetAlignment(3)
+	table.SetRowSeparator("-")
+	table.SetCenterSeparator(" ")
+	table.SetColumnSeparator(" ")
+	headerColors := make([]tablewriter.Colors, len(header))
+	colors := make([]tablewriter.Colors, len(header))
+	for i := range header {
+		headerColors[i] = tablewriter.Colors{tablewriter.Bold}
+		if i < len(columnColors) {
+			colors[i] = columnColors[i]
+		} else {
+			colors[i] = tablewriter.Colors{}
+		}
+	}
+	table.SetHeaderColor(headerColors...)
+	table.SetColumnColor(colors...)
+	return table
+}
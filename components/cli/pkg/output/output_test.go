@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{
+			name: "default format",
+			opts: Options{},
+		},
+		{
+			name: "wide format",
+			opts: Options{Format: FormatWide},
+		},
+		{
+			name:    "unsupported format",
+			opts:    Options{Format: "xml"},
+			wantErr: "unsupported output format xml, expected one of table, wide, json or yaml",
+		},
+		{
+			name:    "template with json format",
+			opts:    Options{Format: FormatJson, Template: "{{.name}}"},
+			wantErr: "--template cannot be used with output format json",
+		},
+		{
+			name:    "invalid template",
+			opts:    Options{Template: "{{.name"},
+			wantErr: "invalid template, template: output:1: unclosed action",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := tst.opts.Validate()
+			if tst.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error, %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error %s", tst.wantErr)
+			}
+			if diff := cmp.Diff(tst.wantErr, err.Error()); diff != "" {
+				t.Errorf("invalid error message (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	type item struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	items := []item{{Name: "employee", Count: 2}, {Name: "stock", Count: 1}}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "json",
+			opts: Options{Format: FormatJson},
+			want: "[\n  {\n    \"name\": \"employee\",\n    \"count\": 2\n  },\n  {\n    \"name\": \"stock\",\n    \"count\": 1\n  }\n]\n",
+		},
+		{
+			name: "yaml",
+			opts: Options{Format: FormatYaml},
+			want: "- count: 2\n  name: employee\n- count: 1\n  name: stock\n",
+		},
+		{
+			name: "template uses json field names",
+			opts: Options{Template: "{{range .}}{{.name}}={{.count}};{{end}}"},
+			want: "employee=2;stock=1;",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if err := Write(out, tst.opts, items); err != nil {
+				t.Fatalf("error writing output, %v", err)
+			}
+			if diff := cmp.Diff(tst.want, out.String()); diff != "" {
+				t.Errorf("unexpected output (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
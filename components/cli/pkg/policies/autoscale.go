@@ -18,7 +18,11 @@
 
 package policies
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 const K8sScaleTargetApiVersion = "apps/v1"
 const K8sScaleTargetKind = "Deployment"
@@ -26,6 +30,18 @@ const CelleryApiVersion = "mesh.cellery.io/v1alpha1"
 const CelleryAutoscalePolicyKind = "AutoscalePolicy"
 const CellComponentTargetType = "component"
 const CellGatewayTargetType = "gateway"
+const CustomMetricsApi = "custom.metrics.k8s.io"
+const ExternalMetricsApi = "external.metrics.k8s.io"
+
+// KnativeAutoscalingAnnotationPrefix prefixes the annotations tuning the Knative autoscaler of a revision.
+const KnativeAutoscalingAnnotationPrefix = "autoscaling.knative.dev/"
+
+// knativeAutoscalingAnnotations maps the tuning fields of the kpa to the Knative autoscaling annotations.
+var knativeAutoscalingAnnotations = map[string]string{
+	"targetUtilizationPercentage": KnativeAutoscalingAnnotationPrefix + "targetUtilizationPercentage",
+	"stableWindow":                KnativeAutoscalingAnnotationPrefix + "window",
+	"scaleToZeroGracePeriod":      KnativeAutoscalingAnnotationPrefix + "scale-to-zero-pod-retention-period",
+}
 
 func GetComponentAutoscalePolicyName(instance string, component string) string {
 	return fmt.Sprintf("%s--%s-autoscalepolicy", instance, component)
@@ -46,3 +62,28 @@ func GetTargetGatewayeploymentName(instance string) string {
 func BuildAutoscalePolicyNonExistErrorMatcher(name string) string {
 	return fmt.Sprintf("autoscalepolicies.mesh.cellery.io(\\s)?\"%s\"(\\s)?not found", name)
 }
+
+// GetKnativeAutoscalingAnnotations returns the annotations of the component with the Knative autoscaling
+// annotations replaced by the tuning of the kpa of the scaling policy. Other annotations are kept as they are.
+func GetKnativeAutoscalingAnnotations(annotations map[string]string, scalingPolicy interface{}) map[string]string {
+	updated := map[string]string{}
+	for key, value := range annotations {
+		if !strings.HasPrefix(key, KnativeAutoscalingAnnotationPrefix) {
+			updated[key] = value
+		}
+	}
+	if autoscalerType, kpa := GetAutoscaler(scalingPolicy); autoscalerType == "kpa" {
+		for field, annotation := range knativeAutoscalingAnnotations {
+			switch value := kpa[field].(type) {
+			case string:
+				updated[annotation] = value
+			case float64:
+				updated[annotation] = strconv.FormatFloat(value, 'f', -1, 64)
+			}
+		}
+	}
+	if len(updated) == 0 {
+		return nil
+	}
+	return updated
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// indexLines returns the line of each field of a block style yaml document, with the fields named as in
+// components[0].scalingPolicy.hpa. Fields in flow style collections are not indexed.
+func indexLines(content []byte) map[string]int {
+	type frame struct {
+		indent int
+		field  string
+		item   bool
+	}
+	lines := map[string]int{}
+	items := map[string]int{}
+	var stack []frame
+	parent := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return stack[len(stack)-1].field
+	}
+	for i, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		text := strings.TrimRight(line[indent:], " \r")
+		// each dash starts an item of the list of the enclosing field, e.g. "- - a" starts two items
+		for text == "-" || strings.HasPrefix(text, "- ") {
+			for len(stack) > 0 && (stack[len(stack)-1].indent > indent ||
+				stack[len(stack)-1].indent == indent && stack[len(stack)-1].item) {
+				stack = stack[:len(stack)-1]
+			}
+			field := fmt.Sprintf("%s[%d]", parent(), items[parent()])
+			items[parent()]++
+			lines[field] = i + 1
+			stack = append(stack, frame{indent: indent, field: field, item: true})
+			rest := strings.TrimLeft(text[1:], " ")
+			indent += len(text) - len(rest)
+			text = rest
+		}
+		key := ""
+		if separator := strings.Index(text, ": "); separator > 0 {
+			key = text[:separator]
+		} else if strings.HasSuffix(text, ":") {
+			key = strings.TrimSuffix(text, ":")
+		}
+		if key == "" || strings.HasPrefix(key, "{") || strings.HasPrefix(key, "[") {
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		field := strings.Trim(key, `"'`)
+		if parent() != "" {
+			field = parent() + "." + field
+		}
+		lines[field] = i + 1
+		stack = append(stack, frame{indent: indent, field: field})
+	}
+	return lines
+}
@@ -37,18 +37,60 @@ type Target struct {
 }
 
 type Policy struct {
-	MinReplicas string   `json:"minReplicas"`
+	MinReplicas int      `json:"minReplicas"`
 	MaxReplicas int      `json:"maxReplicas"`
 	Metrics     []Metric `json:"metrics,omitempty"`
 }
 
 type Metric struct {
-	Type     string   `json:"type,omitempty"`
-	Resource Resource `json:"resource,omitempty"`
+	Type     string          `json:"type,omitempty"`
+	Resource *Resource       `json:"resource,omitempty"`
+	Pods     *PodsMetric     `json:"pods,omitempty"`
+	Object   *ObjectMetric   `json:"object,omitempty"`
+	External *ExternalMetric `json:"external,omitempty"`
 }
 
 type Resource struct {
-	Name                     string `json:"name"`
-	TargetAverageUtilization int    `json:"targetAverageUtilization,omitempty"`
-	TargetAverageValue       string `json:"targetAverageValue,omitempty"`
+	Name                     string        `json:"name"`
+	Target                   *MetricTarget `json:"target,omitempty"`
+	TargetAverageUtilization int           `json:"targetAverageUtilization,omitempty"`
+	TargetAverageValue       string        `json:"targetAverageValue,omitempty"`
+}
+
+type PodsMetric struct {
+	Metric MetricIdentifier `json:"metric"`
+	Target MetricTarget     `json:"target"`
+}
+
+type ObjectMetric struct {
+	DescribedObject ObjectReference  `json:"describedObject"`
+	Metric          MetricIdentifier `json:"metric"`
+	Target          MetricTarget     `json:"target"`
+}
+
+type ExternalMetric struct {
+	Metric MetricIdentifier `json:"metric"`
+	Target MetricTarget     `json:"target"`
+}
+
+type MetricIdentifier struct {
+	Name     string         `json:"name"`
+	Selector *LabelSelector `json:"selector,omitempty"`
+}
+
+type LabelSelector struct {
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+type MetricTarget struct {
+	Type               string `json:"type"`
+	Value              string `json:"value,omitempty"`
+	AverageValue       string `json:"averageValue,omitempty"`
+	AverageUtilization int    `json:"averageUtilization,omitempty"`
+}
+
+type ObjectReference struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
 }
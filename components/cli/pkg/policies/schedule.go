@@ -0,0 +1,191 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package policies
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros are the predefined schedules supported by the Kubernetes CronJob controller.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// CronSchedule is a parsed cron expression with the minute, hour, day of month, month and day of week fields.
+type CronSchedule struct {
+	minutes  map[int]bool
+	hours    map[int]bool
+	days     map[int]bool
+	months   map[int]bool
+	weekdays map[int]bool
+	// anyDay and anyWeekday are set if the field is a wildcard, in which case a day matches if the other field
+	// matches
+	anyDay     bool
+	anyWeekday bool
+}
+
+// ParseCronSchedule parses a five field cron expression such as "0 8 * * mon-fri", or one of the macros such as
+// @daily.
+func ParseCronSchedule(expression string) (*CronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := cronMacros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expects 5 fields (minute, hour, day of month, month, day of week), received %d",
+			len(fields))
+	}
+	schedule := &CronSchedule{
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	if schedule.minutes, err = parseCronField(fields[0], "minute", 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = parseCronField(fields[1], "hour", 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if schedule.days, err = parseCronField(fields[2], "day of month", 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = parseCronField(fields[3], "month", 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+	// 7 is also accepted for Sunday
+	if schedule.weekdays, err = parseCronField(fields[4], "day of week", 0, 7, weekdayNames); err != nil {
+		return nil, err
+	}
+	if schedule.weekdays[7] {
+		schedule.weekdays[0] = true
+	}
+	return schedule, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps such as 1,15 or 9-17 or */10. Names are
+// the names of the values starting from the minimum, e.g. jan for the months.
+func parseCronField(field string, fieldName string, minimum int, maximum int, names []string) (map[int]bool,
+	error) {
+	values := map[int]bool{}
+	parseValue := func(value string) (int, error) {
+		for i, name := range names {
+			if strings.EqualFold(value, name) {
+				return minimum + i, nil
+			}
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil || number < minimum || number > maximum {
+			return 0, fmt.Errorf("invalid %s %q, expects a value from %d to %d", fieldName, value, minimum, maximum)
+		}
+		return number, nil
+	}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %q of %s", part[i+1:], fieldName)
+			}
+			part = part[:i]
+		}
+		start, end := minimum, maximum
+		if part != "*" {
+			var err error
+			bounds := strings.SplitN(part, "-", 2)
+			if start, err = parseValue(bounds[0]); err != nil {
+				return nil, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = parseValue(bounds[1]); err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				// a step from a value, e.g. 5/15, runs up to the maximum
+				end = maximum
+			}
+			if end < start {
+				return nil, fmt.Errorf("invalid %s range %q", fieldName, part)
+			}
+		}
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// Previous returns the latest time at or before t, truncated to the minute, at which the schedule runs. False is
+// returned if the schedule does not run within the past five years, e.g. for the 31st of February.
+func (s *CronSchedule) Previous(t time.Time) (time.Time, bool) {
+	t = t.Truncate(time.Minute)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for i := 0; i < 5*366; i++ {
+		if s.matchesDay(day) {
+			for hour := 23; hour >= 0; hour-- {
+				if !s.hours[hour] {
+					continue
+				}
+				for minute := 59; minute >= 0; minute-- {
+					if !s.minutes[minute] {
+						continue
+					}
+					run := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+					if !run.After(t) {
+						return run, true
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return time.Time{}, false
+}
+
+// matchesDay returns whether the schedule runs on the day. As in cron, the day matches either the day of month or
+// the day of week if both are restricted.
+func (s *CronSchedule) matchesDay(day time.Time) bool {
+	if !s.months[int(day.Month())] {
+		return false
+	}
+	dayMatches := s.days[day.Day()]
+	weekdayMatches := s.weekdays[int(day.Weekday())]
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekdayMatches
+	case s.anyWeekday:
+		return dayMatches
+	}
+	return dayMatches || weekdayMatches
+}
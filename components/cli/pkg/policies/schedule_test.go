@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package policies
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    string
+	}{
+		{name: "weekdays", expression: "0 8 * * mon-fri"},
+		{name: "steps and lists", expression: "*/15 9-17,20 1,15 */2 *"},
+		{name: "macro", expression: "@hourly"},
+		{name: "missing field", expression: "0 8 * *",
+			wantErr: "expects 5 fields (minute, hour, day of month, month, day of week), received 4"},
+		{name: "out of range", expression: "60 * * * *",
+			wantErr: "invalid minute \"60\", expects a value from 0 to 59"},
+		{name: "unknown name", expression: "0 0 * * mo", wantErr: "invalid day of week \"mo\", expects a value from 0 to 7"},
+		{name: "reversed range", expression: "0 17-9 * * *", wantErr: "invalid hour range \"17-9\""},
+		{name: "invalid step", expression: "*/0 * * * *", wantErr: "invalid step \"0\" of minute"},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			_, err := ParseCronSchedule(tst.expression)
+			if tst.wantErr == "" && err != nil {
+				t.Errorf("unexpected error, %v", err)
+			} else if tst.wantErr != "" && (err == nil || err.Error() != tst.wantErr) {
+				t.Errorf("expected error %s, got %v", tst.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCronSchedulePrevious(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2020, time.January, 15, 12, 30, 45, 0, time.UTC)
+	tests := []struct {
+		name       string
+		expression string
+		want       time.Time
+	}{
+		{name: "same day", expression: "0 8 * * mon-fri", want: time.Date(2020, time.January, 15, 8, 0, 0, 0, time.UTC)},
+		{name: "previous day", expression: "0 18 * * 1-5", want: time.Date(2020, time.January, 14, 18, 0, 0, 0, time.UTC)},
+		{name: "current minute", expression: "30 12 * * *", want: time.Date(2020, time.January, 15, 12, 30, 0, 0, time.UTC)},
+		{name: "weekend", expression: "0 10 * * sat,sun", want: time.Date(2020, time.January, 12, 10, 0, 0, 0, time.UTC)},
+		{name: "day of month or week", expression: "0 0 1 * fri", want: time.Date(2020, time.January, 10, 0, 0, 0, 0, time.UTC)},
+		{name: "previous year", expression: "0 0 1 3 *", want: time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "steps", expression: "*/20 */5 * * *", want: time.Date(2020, time.January, 15, 10, 40, 0, 0, time.UTC)},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(tst.expression)
+			if err != nil {
+				t.Fatalf("error parsing schedule, %v", err)
+			}
+			got, ok := schedule.Previous(now)
+			if !ok || !got.Equal(tst.want) {
+				t.Errorf("expected %v, got %v", tst.want, got)
+			}
+		})
+	}
+	if _, ok := (&CronSchedule{}).Previous(now); ok {
+		t.Errorf("expected a schedule which never runs not to have run")
+	}
+}
@@ -0,0 +1,715 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package policies
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// resourceMetricNames are the resources of which the usage can be used to autoscale a component.
+var resourceMetricNames = []string{"cpu", "memory"}
+
+// metricSources are the fields describing the source of each type of metric.
+var metricSources = map[string]string{
+	"Resource": "resource",
+	"Pods":     "pods",
+	"Object":   "object",
+	"External": "external",
+}
+
+// metricsApis are the aggregated APIs serving the custom and external metrics. Resource metrics are not checked
+// since they are served by the metrics server of the runtime.
+var metricsApis = map[string]string{
+	"Pods":     CustomMetricsApi,
+	"Object":   CustomMetricsApi,
+	"External": ExternalMetricsApi,
+}
+
+// targetValues are the fields holding the value of each type of metric target.
+var targetValues = map[string]string{
+	"Utilization":  "averageUtilization",
+	"AverageValue": "averageValue",
+	"Value":        "value",
+}
+
+// The bounds of the windows of the Knative autoscaler, which does not accept shorter windows than its tick
+// interval.
+const minStableWindow = 6 * time.Second
+const maxStableWindow = time.Hour
+const minScaleToZeroGracePeriod = 6 * time.Second
+
+var quantityPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei)?$`)
+var scheduleNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ValidationError is a problem found in a policy file. The line is 0 if the field could not be located.
+type ValidationError struct {
+	Line    int
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	message := e.Message
+	if e.Field != "" {
+		message = fmt.Sprintf("%s: %s", e.Field, e.Message)
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, message)
+	}
+	return message
+}
+
+// ScaleTargets are the scaling policies of the components and the gateway of a live instance.
+type ScaleTargets struct {
+	Components map[string]interface{}
+	// HasGateway is false for composites, which do not have a gateway to scale
+	HasGateway bool
+	Gateway    interface{}
+}
+
+// IsOverridable returns false if the scaling policy is marked as not overridable.
+func IsOverridable(scalingPolicy interface{}) bool {
+	policy, _ := scalingPolicy.(map[string]interface{})
+	if overridable, ok := policy["overridable"].(bool); ok && !overridable {
+		return false
+	}
+	hpa, _ := policy["hpa"].(map[string]interface{})
+	if overridable, ok := hpa["overridable"].(bool); ok && !overridable {
+		return false
+	}
+	return true
+}
+
+// GetAutoscaler returns the type (hpa or kpa) and the autoscaler of the scaling policy, if any.
+func GetAutoscaler(scalingPolicy interface{}) (string, map[string]interface{}) {
+	policy, _ := scalingPolicy.(map[string]interface{})
+	for _, autoscalerType := range []string{"hpa", "kpa"} {
+		if autoscaler, ok := policy[autoscalerType].(map[string]interface{}); ok {
+			return autoscalerType, autoscaler
+		}
+	}
+	return "", nil
+}
+
+// MetricsApiChecker returns whether the metrics API (e.g. custom.metrics.k8s.io) is available in the cluster.
+type MetricsApiChecker func(api string) (bool, error)
+
+// ValidateAutoscalePolicy validates the autoscale policy file content against the scale targets of the live
+// instance, and returns the problems found ordered by line. The metrics APIs are not checked if the checker is nil.
+func ValidateAutoscalePolicy(content []byte, targets ScaleTargets,
+	isMetricsApiAvailable MetricsApiChecker) []ValidationError {
+	policyJson, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return []ValidationError{{Message: err.Error()}}
+	}
+	var policy interface{}
+	if err = json.Unmarshal(policyJson, &policy); err != nil {
+		return []ValidationError{{Message: err.Error()}}
+	}
+	v := &validator{lines: indexLines(content), isMetricsApiAvailable: isMetricsApiAvailable,
+		metricsApis: map[string]bool{}}
+	if root, ok := v.object("", policy); ok {
+		v.checkFields("", root, "components", "gateway", "schedules")
+		v.validateComponents(root["components"], targets.Components)
+		if gateway, exists := root["gateway"]; exists && gateway != nil {
+			if !targets.HasGateway {
+				v.report("gateway", "composites do not have a gateway to scale")
+			} else {
+				v.validateTarget("gateway", gateway, targets.Gateway)
+			}
+		}
+		v.validateSchedules(root["schedules"], effectivePolicies(root, targets))
+	}
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		return v.errors[i].Line < v.errors[j].Line
+	})
+	return v.errors
+}
+
+type validator struct {
+	lines                 map[string]int
+	errors                []ValidationError
+	isMetricsApiAvailable MetricsApiChecker
+	// metricsApis caches the availability of the metrics APIs which are checked
+	metricsApis map[string]bool
+}
+
+func (v *validator) validateComponents(value interface{}, components map[string]interface{}) {
+	if value == nil {
+		return
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		v.report("components", "should be a list")
+		return
+	}
+	names := map[string]bool{}
+	for i, item := range items {
+		field := fmt.Sprintf("components[%d]", i)
+		component, ok := v.object(field, item)
+		if !ok {
+			continue
+		}
+		name, ok := component["name"].(string)
+		if !ok || name == "" {
+			v.report(field+".name", "the name of the component is required")
+			continue
+		}
+		if names[name] {
+			v.report(field+".name", "component %q is listed more than once", name)
+			continue
+		}
+		names[name] = true
+		livePolicy, exists := components[name]
+		if !exists {
+			v.report(field+".name", "component %q not found in the instance", name)
+			continue
+		}
+		v.validateTarget(field, component, livePolicy)
+	}
+}
+
+// validateTarget validates the scaling policy of a component or the gateway, which cannot be changed if the live
+// scaling policy is not overridable.
+func (v *validator) validateTarget(field string, value interface{}, livePolicy interface{}) {
+	target, ok := v.object(field, value)
+	if !ok {
+		return
+	}
+	if field == "gateway" {
+		v.checkFields(field, target, "scalingPolicy")
+	} else {
+		v.checkFields(field, target, "name", "scalingPolicy")
+	}
+	scalingPolicy := target["scalingPolicy"]
+	if scalingPolicy == nil {
+		return
+	}
+	field += ".scalingPolicy"
+	v.validateScalingPolicy(field, scalingPolicy)
+	if !IsOverridable(livePolicy) && !reflect.DeepEqual(withoutNulls(scalingPolicy), withoutNulls(livePolicy)) {
+		v.report(field, "the scaling policy is not overridable, hence cannot be changed")
+	}
+}
+
+func (v *validator) validateScalingPolicy(field string, value interface{}) {
+	policy, ok := v.object(field, value)
+	if !ok {
+		return
+	}
+	v.checkFields(field, policy, "replicas", "hpa", "kpa", "overridable")
+	v.integer(field+".replicas", policy["replicas"], 0)
+	v.boolean(field+".overridable", policy["overridable"])
+	if policy["hpa"] != nil && policy["kpa"] != nil {
+		v.report(field, "hpa and kpa cannot be used together, kpa is ignored when hpa is set")
+	}
+	if hpa, ok := v.object(field+".hpa", policy["hpa"]); ok && hpa != nil {
+		v.checkFields(field+".hpa", hpa, "minReplicas", "maxReplicas", "metrics", "overridable")
+		v.boolean(field+".hpa.overridable", hpa["overridable"])
+		v.validateReplicaRange(field+".hpa", hpa, 1)
+		v.validateMetrics(field+".hpa.metrics", hpa["metrics"])
+	}
+	if kpa, ok := v.object(field+".kpa", policy["kpa"]); ok && kpa != nil {
+		v.checkFields(field+".kpa", kpa, "minReplicas", "maxReplicas", "concurrency",
+			"targetUtilizationPercentage", "stableWindow", "scaleToZeroGracePeriod")
+		v.validateReplicaRange(field+".kpa", kpa, 0)
+		v.integer(field+".kpa.concurrency", kpa["concurrency"], 1)
+		if utilization, ok := v.integer(field+".kpa.targetUtilizationPercentage",
+			kpa["targetUtilizationPercentage"], 1); ok && utilization > 100 {
+			v.report(field+".kpa.targetUtilizationPercentage", "should be at most 100, received %d", utilization)
+		}
+		v.duration(field+".kpa.stableWindow", kpa["stableWindow"], minStableWindow, maxStableWindow)
+		v.duration(field+".kpa.scaleToZeroGracePeriod", kpa["scaleToZeroGracePeriod"], minScaleToZeroGracePeriod, 0)
+	}
+}
+
+// effectivePolicies returns the scale targets with the scaling policies of the file applied over the overridable
+// live scaling policies.
+func effectivePolicies(root map[string]interface{}, targets ScaleTargets) ScaleTargets {
+	effective := ScaleTargets{
+		Components: map[string]interface{}{},
+		HasGateway: targets.HasGateway,
+		Gateway:    targets.Gateway,
+	}
+	for name, scalingPolicy := range targets.Components {
+		effective.Components[name] = scalingPolicy
+	}
+	components, _ := root["components"].([]interface{})
+	for _, item := range components {
+		component, _ := item.(map[string]interface{})
+		name, _ := component["name"].(string)
+		livePolicy, exists := targets.Components[name]
+		if exists && component["scalingPolicy"] != nil && IsOverridable(livePolicy) {
+			effective.Components[name] = component["scalingPolicy"]
+		}
+	}
+	gateway, _ := root["gateway"].(map[string]interface{})
+	if gateway["scalingPolicy"] != nil && IsOverridable(targets.Gateway) {
+		effective.Gateway = gateway["scalingPolicy"]
+	}
+	return effective
+}
+
+func (v *validator) validateSchedules(value interface{}, targets ScaleTargets) {
+	if value == nil {
+		return
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		v.report("schedules", "should be a list")
+		return
+	}
+	names := map[string]bool{}
+	for i, item := range items {
+		field := fmt.Sprintf("schedules[%d]", i)
+		schedule, ok := v.object(field, item)
+		if !ok || schedule == nil {
+			continue
+		}
+		v.checkFields(field, schedule, "name", "schedule", "components", "gateway")
+		name, _ := schedule["name"].(string)
+		if !scheduleNamePattern.MatchString(name) {
+			v.report(field+".name", "the name of the schedule should consist of lower case alphanumeric "+
+				"characters or '-', received %q", name)
+		} else if names[name] {
+			v.report(field+".name", "schedule %q is listed more than once", name)
+		}
+		names[name] = true
+		if expression, _ := schedule["schedule"].(string); expression == "" {
+			v.report(field+".schedule", "the cron schedule is required")
+		} else if _, err := ParseCronSchedule(expression); err != nil {
+			v.report(field+".schedule", "invalid cron schedule, %v", err)
+		}
+		if schedule["components"] == nil && schedule["gateway"] == nil {
+			v.report(field, "expects the replicas of the components or the gateway to override")
+		}
+		v.validateScheduledComponents(field+".components", schedule["components"], targets.Components)
+		if schedule["gateway"] != nil {
+			if !targets.HasGateway {
+				v.report(field+".gateway", "composites do not have a gateway to scale")
+			} else if gateway, ok := v.object(field+".gateway", schedule["gateway"]); ok {
+				v.checkFields(field+".gateway", gateway, "minReplicas", "maxReplicas")
+				v.validateScheduledReplicas(field+".gateway", gateway, targets.Gateway)
+			}
+		}
+	}
+}
+
+func (v *validator) validateScheduledComponents(field string, value interface{}, components map[string]interface{}) {
+	if value == nil {
+		return
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		v.report(field, "should be a list")
+		return
+	}
+	names := map[string]bool{}
+	for i, item := range items {
+		componentField := fmt.Sprintf("%s[%d]", field, i)
+		component, ok := v.object(componentField, item)
+		if !ok || component == nil {
+			continue
+		}
+		v.checkFields(componentField, component, "name", "minReplicas", "maxReplicas")
+		name, _ := component["name"].(string)
+		if name == "" {
+			v.report(componentField+".name", "the name of the component is required")
+			continue
+		}
+		if names[name] {
+			v.report(componentField+".name", "component %q is listed more than once", name)
+			continue
+		}
+		names[name] = true
+		scalingPolicy, exists := components[name]
+		if !exists {
+			v.report(componentField+".name", "component %q not found in the instance", name)
+			continue
+		}
+		v.validateScheduledReplicas(componentField, component, scalingPolicy)
+	}
+}
+
+// validateScheduledReplicas validates the min and max replicas overriding those of the autoscaler of the scaling
+// policy. The replicas which are not overridden are taken from the autoscaler.
+func (v *validator) validateScheduledReplicas(field string, replicas map[string]interface{},
+	scalingPolicy interface{}) {
+	if replicas["minReplicas"] == nil && replicas["maxReplicas"] == nil {
+		v.report(field, "expects minReplicas or maxReplicas to override")
+		return
+	}
+	if !IsOverridable(scalingPolicy) {
+		v.report(field, "the scaling policy is not overridable, hence cannot be scheduled")
+		return
+	}
+	autoscalerType, autoscaler := GetAutoscaler(scalingPolicy)
+	if autoscaler == nil {
+		v.report(field, "the scaling policy does not have an hpa or kpa to override the replicas of")
+		return
+	}
+	lowestMinReplicas := 1
+	if autoscalerType == "kpa" {
+		lowestMinReplicas = 0
+	}
+	minReplicas, hasMin := v.integer(field+".minReplicas", replicas["minReplicas"], lowestMinReplicas)
+	if replicas["minReplicas"] == nil {
+		minReplicas, hasMin = toInt(autoscaler["minReplicas"])
+	}
+	maxReplicas, hasMax := v.integer(field+".maxReplicas", replicas["maxReplicas"], 1)
+	if replicas["maxReplicas"] == nil {
+		maxReplicas, hasMax = toInt(autoscaler["maxReplicas"])
+	}
+	if hasMin && hasMax && maxReplicas < minReplicas {
+		v.report(field, "maxReplicas %d is lower than minReplicas %d of the %s", maxReplicas, minReplicas,
+			autoscalerType)
+	}
+}
+
+// validateReplicaRange validates the required maxReplicas, and the minReplicas which should not be higher.
+func (v *validator) validateReplicaRange(field string, autoscaler map[string]interface{}, lowestMinReplicas int) {
+	minReplicas, hasMin := v.integer(field+".minReplicas", autoscaler["minReplicas"], lowestMinReplicas)
+	if autoscaler["maxReplicas"] == nil {
+		v.report(field+".maxReplicas", "maxReplicas is required")
+		return
+	}
+	maxReplicas, hasMax := v.integer(field+".maxReplicas", autoscaler["maxReplicas"], 1)
+	if hasMin && hasMax && maxReplicas < minReplicas {
+		v.report(field+".maxReplicas", "maxReplicas %d is lower than minReplicas %d", maxReplicas, minReplicas)
+	}
+}
+
+func (v *validator) validateMetrics(field string, value interface{}) {
+	if value == nil {
+		return
+	}
+	metrics, ok := value.([]interface{})
+	if !ok || len(metrics) == 0 {
+		v.report(field, "should be a non empty list")
+		return
+	}
+	for i, item := range metrics {
+		metricField := fmt.Sprintf("%s[%d]", field, i)
+		metric, ok := v.object(metricField, item)
+		if !ok {
+			continue
+		}
+		metricType, _ := metric["type"].(string)
+		source, supported := metricSources[metricType]
+		if !supported {
+			v.report(metricField+".type", "unsupported metric type %v, expects one of Resource, Pods, Object, "+
+				"External", metric["type"])
+			continue
+		}
+		v.checkFields(metricField, metric, "type", source)
+		if metric[source] == nil {
+			v.report(metricField+"."+source, "%s is required for %s metrics", source, metricType)
+			continue
+		}
+		sourceField := metricField + "." + source
+		switch metricType {
+		case "Resource":
+			v.validateResourceMetric(sourceField, metric[source])
+		case "Pods":
+			v.validateCustomMetric(sourceField, metric[source], "AverageValue")
+		case "Object":
+			v.validateCustomMetric(sourceField, metric[source], "Value", "AverageValue")
+		case "External":
+			v.validateCustomMetric(sourceField, metric[source], "Value", "AverageValue")
+		}
+		if api := metricsApis[metricType]; api != "" {
+			v.requireMetricsApi(metricField+".type", api, metricType)
+		}
+	}
+}
+
+// validateResourceMetric validates a resource metric with a target, or with a target average utilization or value
+// as in the autoscaling/v2beta1 API.
+func (v *validator) validateResourceMetric(field string, value interface{}) {
+	resource, ok := v.object(field, value)
+	if !ok {
+		return
+	}
+	v.checkFields(field, resource, "name", "target", "targetAverageUtilization", "targetAverageValue")
+	name, _ := resource["name"].(string)
+	if !contains(resourceMetricNames, name) {
+		v.report(field+".name", "unsupported resource %q, expects one of %s", name,
+			strings.Join(resourceMetricNames, ", "))
+	}
+	targets := 0
+	for _, key := range []string{"target", "targetAverageUtilization", "targetAverageValue"} {
+		if resource[key] != nil {
+			targets++
+		}
+	}
+	if targets != 1 {
+		v.report(field, "expects exactly one of target, targetAverageUtilization and targetAverageValue")
+		return
+	}
+	v.integer(field+".targetAverageUtilization", resource["targetAverageUtilization"], 1)
+	v.quantity(field+".targetAverageValue", resource["targetAverageValue"])
+	if resource["target"] != nil {
+		v.validateMetricTarget(field+".target", resource["target"], "Utilization", "AverageValue")
+	}
+}
+
+// validateCustomMetric validates a Pods, Object or External metric, which identifies the metric by name and an
+// optional label selector. Object metrics also refer to the object described by the metric.
+func (v *validator) validateCustomMetric(field string, value interface{}, targetTypes ...string) {
+	source, ok := v.object(field, value)
+	if !ok {
+		return
+	}
+	if strings.HasSuffix(field, ".object") {
+		v.checkFields(field, source, "describedObject", "metric", "target")
+		if describedObject, ok := v.object(field+".describedObject", source["describedObject"]); ok {
+			v.checkFields(field+".describedObject", describedObject, "apiVersion", "kind", "name")
+			for _, key := range []string{"kind", "name"} {
+				if name, _ := describedObject[key].(string); name == "" {
+					v.report(field+".describedObject."+key, "%s of the described object is required", key)
+				}
+			}
+		}
+	} else {
+		v.checkFields(field, source, "metric", "target")
+	}
+	if metric, ok := v.object(field+".metric", source["metric"]); ok {
+		v.checkFields(field+".metric", metric, "name", "selector")
+		if name, _ := metric["name"].(string); name == "" {
+			v.report(field+".metric.name", "the name of the metric is required")
+		}
+		if selector, ok := v.object(field+".metric.selector", metric["selector"]); ok {
+			v.checkFields(field+".metric.selector", selector, "matchLabels")
+			if labels, ok := v.object(field+".metric.selector.matchLabels", selector["matchLabels"]); ok {
+				for label, labelValue := range labels {
+					if _, isString := labelValue.(string); !isString {
+						v.report(field+".metric.selector.matchLabels."+label, "should be a string, received %v",
+							labelValue)
+					}
+				}
+			}
+		}
+	}
+	if source["target"] == nil {
+		v.report(field+".target", "target is required")
+		return
+	}
+	v.validateMetricTarget(field+".target", source["target"], targetTypes...)
+}
+
+// validateMetricTarget validates a target of one of the given types along with the value of the type.
+func (v *validator) validateMetricTarget(field string, value interface{}, types ...string) {
+	target, ok := v.object(field, value)
+	if !ok {
+		return
+	}
+	v.checkFields(field, target, "type", "averageUtilization", "averageValue", "value")
+	targetType, _ := target["type"].(string)
+	if !contains(types, targetType) {
+		v.report(field+".type", "unsupported target type %v, expects one of %s", target["type"],
+			strings.Join(types, ", "))
+		return
+	}
+	key := targetValues[targetType]
+	for _, otherKey := range []string{"averageUtilization", "averageValue", "value"} {
+		if otherKey != key && target[otherKey] != nil {
+			v.report(field+"."+otherKey, "cannot be used with %s targets", targetType)
+		}
+	}
+	if target[key] == nil {
+		v.report(field+"."+key, "%s is required for %s targets", key, targetType)
+		return
+	}
+	if key == "averageUtilization" {
+		v.integer(field+"."+key, target[key], 1)
+	} else {
+		v.quantity(field+"."+key, target[key])
+	}
+}
+
+// requireMetricsApi reports the metric if the metrics API serving it is not available in the cluster.
+func (v *validator) requireMetricsApi(field string, api string, metricType string) {
+	if v.isMetricsApiAvailable == nil {
+		return
+	}
+	available, checked := v.metricsApis[api]
+	if !checked {
+		var err error
+		if available, err = v.isMetricsApiAvailable(api); err != nil {
+			v.report(field, "unable to check whether the %s API is available, %v", api, err)
+			return
+		}
+		v.metricsApis[api] = available
+	}
+	if !available {
+		v.report(field, "%s metrics are served by the %s API, which is not available in the cluster", metricType,
+			api)
+	}
+}
+
+// report adds a problem of the field, located at the line of the field or of its closest parent.
+func (v *validator) report(field string, format string, args ...interface{}) {
+	line := 0
+	for located := field; located != ""; located = parentField(located) {
+		if line = v.lines[located]; line > 0 {
+			break
+		}
+	}
+	v.errors = append(v.errors, ValidationError{Line: line, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkFields reports the fields of the object which are not allowed.
+func (v *validator) checkFields(field string, object map[string]interface{}, allowed ...string) {
+	var unknown []string
+	for key := range object {
+		if !contains(allowed, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		if field != "" {
+			key = field + "." + key
+		}
+		v.report(key, "unknown field")
+	}
+}
+
+// object returns the value as an object, reporting it if it is not. Null values are returned as nil.
+func (v *validator) object(field string, value interface{}) (map[string]interface{}, bool) {
+	if value == nil {
+		return nil, true
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		v.report(field, "should be an object")
+	}
+	return object, ok
+}
+
+// integer returns the value as an integer if it is set, reporting it if it is not an integer of at least the
+// minimum.
+func (v *validator) integer(field string, value interface{}, minimum int) (int, bool) {
+	if value == nil {
+		return 0, false
+	}
+	number, ok := value.(float64)
+	if !ok || number != math.Trunc(number) {
+		v.report(field, "should be an integer, received %v", value)
+		return 0, false
+	}
+	if int(number) < minimum {
+		v.report(field, "should be at least %d, received %d", minimum, int(number))
+		return 0, false
+	}
+	return int(number), true
+}
+
+func (v *validator) boolean(field string, value interface{}) {
+	if _, ok := value.(bool); value != nil && !ok {
+		v.report(field, "should be true or false, received %v", value)
+	}
+}
+
+func (v *validator) quantity(field string, value interface{}) {
+	if value == nil {
+		return
+	}
+	if number, ok := value.(float64); ok && number >= 0 {
+		return
+	}
+	if quantity, ok := value.(string); !ok || !quantityPattern.MatchString(quantity) {
+		v.report(field, "should be a quantity such as 500m or 64Mi, received %v", value)
+	}
+}
+
+// duration reports the value if it is not a duration such as 60s within the bounds. The maximum is not checked
+// if it is 0.
+func (v *validator) duration(field string, value interface{}, minimum time.Duration, maximum time.Duration) {
+	if value == nil {
+		return
+	}
+	text, _ := value.(string)
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		v.report(field, "should be a duration such as 60s or 5m, received %v", value)
+		return
+	}
+	if duration < minimum || maximum > 0 && duration > maximum {
+		if maximum > 0 {
+			v.report(field, "should be from %s to %s, received %s", minimum, maximum, text)
+		} else {
+			v.report(field, "should be at least %s, received %s", minimum, text)
+		}
+	}
+}
+
+// parentField returns the enclosing field, e.g. components[0] of components[0].name and components of
+// components[0].
+func parentField(field string) string {
+	if i := strings.LastIndexAny(field, ".["); i > 0 {
+		return field[:i]
+	}
+	return ""
+}
+
+// withoutNulls returns the value without the null fields, which are the same as the fields not being set.
+func withoutNulls(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		object := map[string]interface{}{}
+		for key, fieldValue := range typed {
+			if fieldValue != nil {
+				object[key] = withoutNulls(fieldValue)
+			}
+		}
+		return object
+	case []interface{}:
+		var items []interface{}
+		for _, item := range typed {
+			items = append(items, withoutNulls(item))
+		}
+		return items
+	}
+	return value
+}
+
+// toInt returns the value of a json number, if it is one.
+func toInt(value interface{}) (int, bool) {
+	number, ok := value.(float64)
+	return int(number), ok
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package policies
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateAutoscalePolicy(t *testing.T) {
+	targets := ScaleTargets{
+		Components: map[string]interface{}{
+			"controller": map[string]interface{}{"replicas": float64(1)},
+			"catalog": map[string]interface{}{
+				"hpa": map[string]interface{}{"overridable": false, "minReplicas": float64(1),
+					"maxReplicas": float64(2)},
+			},
+		},
+		HasGateway: true,
+	}
+	customMetricsOnly := func(api string) (bool, error) {
+		return api == CustomMetricsApi, nil
+	}
+	tests := []struct {
+		name                  string
+		policy                string
+		targets               ScaleTargets
+		isMetricsApiAvailable MetricsApiChecker
+		want                  []string
+	}{
+		{
+			name: "valid policy",
+			policy: `components:
+- name: controller
+  scalingPolicy:
+    hpa:
+      minReplicas: 1
+      maxReplicas: 5
+      metrics:
+      - type: Resource
+        resource:
+          name: cpu
+          target:
+            type: Utilization
+            averageUtilization: 50
+      - type: Resource
+        resource:
+          name: memory
+          targetAverageValue: 64Mi
+    kpa: null
+    replicas: 1
+- name: catalog
+  scalingPolicy:
+    hpa:
+      overridable: false
+      minReplicas: 1
+      maxReplicas: 2
+gateway:
+  scalingPolicy:
+    replicas: 2
+`,
+			targets: targets,
+		},
+		{
+			name: "invalid policy",
+			policy: `components:
+- name: controller
+  scalingPolicy:
+    hpa:
+      minReplicas: "2"
+      maxReplicas: 1
+      metrics:
+      - type: Resource
+        resource:
+          name: disk
+          targetAverageUtilization: 50
+- name: catalog
+  scalingPolicy:
+    replicas: 3
+- name: orders
+gateway:
+  scalingPolicy:
+    replica: 2
+`,
+			targets: targets,
+			want: []string{
+				"line 5: components[0].scalingPolicy.hpa.minReplicas: should be an integer, received 2",
+				"line 10: components[0].scalingPolicy.hpa.metrics[0].resource.name: unsupported resource \"disk\", expects one of cpu, memory",
+				"line 13: components[1].scalingPolicy: the scaling policy is not overridable, hence cannot be changed",
+				"line 15: components[2].name: component \"orders\" not found in the instance",
+				"line 18: gateway.scalingPolicy.replica: unknown field",
+			},
+		},
+		{
+			name: "replica range",
+			policy: `components:
+  - name: controller
+    scalingPolicy:
+      kpa:
+        minReplicas: 3
+        maxReplicas: 2
+        concurrency: 0
+`,
+			targets: targets,
+			want: []string{
+				"line 6: components[0].scalingPolicy.kpa.maxReplicas: maxReplicas 2 is lower than minReplicas 3",
+				"line 7: components[0].scalingPolicy.kpa.concurrency: should be at least 1, received 0",
+			},
+		},
+		{
+			name: "custom and external metrics",
+			policy: `components:
+- name: controller
+  scalingPolicy:
+    hpa:
+      maxReplicas: 10
+      metrics:
+      - type: Pods
+        pods:
+          metric:
+            name: http_requests_per_second
+          target:
+            type: AverageValue
+            averageValue: 100
+      - type: Object
+        object:
+          describedObject:
+            apiVersion: networking.k8s.io/v1beta1
+            kind: Ingress
+          metric:
+            name: requests_per_second
+          target:
+            type: Utilization
+            averageUtilization: 50
+      - type: External
+        external:
+          metric:
+            name: queue_messages_ready
+            selector:
+              matchLabels:
+                queue: orders
+          target:
+            type: Value
+            value: 30
+`,
+			targets:               targets,
+			isMetricsApiAvailable: customMetricsOnly,
+			want: []string{
+				"line 16: components[0].scalingPolicy.hpa.metrics[1].object.describedObject.name: name of the described object is required",
+				"line 22: components[0].scalingPolicy.hpa.metrics[1].object.target.type: unsupported target type Utilization, expects one of Value, AverageValue",
+				"line 24: components[0].scalingPolicy.hpa.metrics[2].type: External metrics are served by the external.metrics.k8s.io API, which is not available in the cluster",
+			},
+		},
+		{
+			name: "scaling schedules",
+			policy: `components:
+- name: controller
+  scalingPolicy:
+    hpa:
+      minReplicas: 1
+      maxReplicas: 5
+schedules:
+- name: office-hours
+  schedule: "0 8 * * mon-fri"
+  components:
+  - name: controller
+    minReplicas: 3
+    maxReplicas: 8
+- name: after-hours
+  schedule: "0 18 * * 1-5"
+  components:
+  - name: controller
+    minReplicas: 1
+`,
+			targets: targets,
+		},
+		{
+			name: "invalid scaling schedules",
+			policy: `schedules:
+- name: Office Hours
+  schedule: "0 25 * * *"
+  components:
+  - name: controller
+    minReplicas: 2
+  - name: catalog
+    maxReplicas: 3
+  - name: orders
+    minReplicas: 1
+- name: nightly
+  schedule: "@daily"
+  gateway:
+    replicas: 2
+`,
+			targets: targets,
+			want: []string{
+				"line 2: schedules[0].name: the name of the schedule should consist of lower case alphanumeric characters or '-', received \"Office Hours\"",
+				"line 3: schedules[0].schedule: invalid cron schedule, invalid hour \"25\", expects a value from 0 to 23",
+				"line 5: schedules[0].components[0]: the scaling policy does not have an hpa or kpa to override the replicas of",
+				"line 7: schedules[0].components[1]: the scaling policy is not overridable, hence cannot be scheduled",
+				"line 9: schedules[0].components[2].name: component \"orders\" not found in the instance",
+				"line 13: schedules[1].gateway: expects minReplicas or maxReplicas to override",
+				"line 14: schedules[1].gateway.replicas: unknown field",
+			},
+		},
+		{
+			name: "scheduled replica range",
+			policy: `components:
+- name: controller
+  scalingPolicy:
+    hpa:
+      minReplicas: 2
+      maxReplicas: 4
+schedules:
+- name: nightly
+  schedule: "0 0 * * *"
+  components:
+  - name: controller
+    maxReplicas: 1
+`,
+			targets: targets,
+			want: []string{
+				"line 11: schedules[0].components[0]: maxReplicas 1 is lower than minReplicas 2 of the hpa",
+			},
+		},
+		{
+			name: "scale-to-zero tuning",
+			policy: `components:
+- name: controller
+  scalingPolicy:
+    kpa:
+      minReplicas: 0
+      maxReplicas: 5
+      concurrency: 10
+      targetUtilizationPercentage: 70
+      stableWindow: 2m
+      scaleToZeroGracePeriod: 30s
+`,
+			targets: targets,
+		},
+		{
+			name: "invalid scale-to-zero tuning",
+			policy: `components:
+- name: controller
+  scalingPolicy:
+    kpa:
+      minReplicas: 0
+      maxReplicas: 5
+      concurrency: 10
+      targetUtilizationPercentage: 150
+      stableWindow: 2s
+      scaleToZeroGracePeriod: soon
+`,
+			targets: targets,
+			want: []string{
+				"line 8: components[0].scalingPolicy.kpa.targetUtilizationPercentage: should be at most 100, received 150",
+				"line 9: components[0].scalingPolicy.kpa.stableWindow: should be from 6s to 1h0m0s, received 2s",
+				"line 10: components[0].scalingPolicy.kpa.scaleToZeroGracePeriod: should be a duration such as 60s or 5m, received soon",
+			},
+		},
+		{
+			name: "gateway of a composite",
+			policy: `gateway:
+  scalingPolicy:
+    replicas: 1
+`,
+			targets: ScaleTargets{},
+			want:    []string{"line 1: gateway: composites do not have a gateway to scale"},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			var got []string
+			for _, err := range ValidateAutoscalePolicy([]byte(tst.policy), tst.targets, tst.isMetricsApiAvailable) {
+				got = append(got, err.Error())
+			}
+			if diff := cmp.Diff(tst.want, got); diff != "" {
+				t.Errorf("invalid validation errors (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
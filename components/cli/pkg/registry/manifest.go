@@ -0,0 +1,199 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	registry2 "github.com/nokia/docker-registry-client/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+const (
+	// MediaTypeCellImageConfig is the artifact media type of a cell image. It is used as the media type of the
+	// config blob, which carries the cell image metadata.
+	MediaTypeCellImageConfig = "application/vnd.cellery.cell.image.config.v1+json"
+	// MediaTypeCellImageLayer is the media type of the layer which holds the cell image zip.
+	MediaTypeCellImageLayer = "application/vnd.cellery.cell.image.layer.v1+zip"
+)
+
+// Annotations added to the cell image manifest
+const (
+	annotationImageOrg     = "io.cellery.image.org"
+	annotationImageName    = "io.cellery.image.name"
+	annotationImageVersion = "io.cellery.image.version"
+)
+
+// acceptedManifestMediaTypes lists the manifest types which can be pulled. Legacy cell images were pushed
+// as signed schema1 manifests.
+var acceptedManifestMediaTypes = []string{
+	ocispec.MediaTypeImageManifest,
+	schema2.MediaTypeManifest,
+	schema1.MediaTypeSignedManifest,
+	schema1.MediaTypeManifest,
+}
+
+// buildManifest creates the OCI image manifest of a cell image with the given config and layer blobs.
+func buildManifest(parsedCellImage *image.CellImage, config, layer ocispec.Descriptor) ([]byte, error) {
+	cellImageManifest := ocispec.Manifest{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2,
+		},
+		Config: config,
+		Layers: []ocispec.Descriptor{layer},
+		Annotations: map[string]string{
+			annotationImageOrg:     parsedCellImage.Organization,
+			annotationImageName:    parsedCellImage.ImageName,
+			annotationImageVersion: parsedCellImage.ImageVersion,
+		},
+	}
+	// The vendored image spec predates the mediaType field of the manifest, hence it is added separately
+	manifestJson, err := json.Marshal(struct {
+		MediaType string `json:"mediaType"`
+		ocispec.Manifest
+	}{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Manifest:  cellImageManifest,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manifest, %v", err)
+	}
+	return manifestJson, nil
+}
+
+// buildConfig creates the config blob of a cell image, which carries the cell image metadata.
+func buildConfig(metadata *image.MetaData) ([]byte, ocispec.Descriptor, error) {
+	configJson, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, ocispec.Descriptor{}, fmt.Errorf("failed to create image config, %v", err)
+	}
+	return configJson, ocispec.Descriptor{
+		MediaType: MediaTypeCellImageConfig,
+		Digest:    digest.FromBytes(configJson),
+		Size:      int64(len(configJson)),
+	}, nil
+}
+
+// putManifest uploads a manifest of the given media type and tags it with the reference.
+func putManifest(hub *registry2.Registry, repository, reference, mediaType string, payload []byte) error {
+	url := fmt.Sprintf("%s/v2/%s/manifests/%s", hub.URL, repository, reference)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mediaType)
+	resp, err := hub.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// getManifest fetches the manifest tagged with the reference and returns its media type and content.
+func getManifest(hub *registry2.Registry, repository, reference string) (string, []byte, error) {
+	url := fmt.Sprintf("%s/v2/%s/manifests/%s", hub.URL, repository, reference)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("Accept", strings.Join(acceptedManifestMediaTypes, ", "))
+	resp, err := hub.Client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	mediaType := resp.Header.Get("Content-Type")
+	if i := strings.Index(mediaType, ";"); i > 0 {
+		mediaType = mediaType[:i]
+	}
+	// Some registries respond with a generic content type, hence falling back to the media type in the payload
+	if mediaType == "" || mediaType == "application/json" || mediaType == "text/plain" {
+		manifestType := struct {
+			SchemaVersion int    `json:"schemaVersion"`
+			MediaType     string `json:"mediaType"`
+		}{}
+		if err := json.Unmarshal(payload, &manifestType); err != nil {
+			return "", nil, fmt.Errorf("failed to parse manifest, %v", err)
+		}
+		mediaType = manifestType.MediaType
+		if mediaType == "" && manifestType.SchemaVersion == 1 {
+			mediaType = schema1.MediaTypeManifest
+		} else if mediaType == "" {
+			mediaType = ocispec.MediaTypeImageManifest
+		}
+	}
+	return mediaType, payload, nil
+}
+
+// cellImageLayer returns the digest and the size of the layer holding the cell image zip from a manifest.
+// The size is not available in legacy schema1 manifests.
+func cellImageLayer(mediaType string, payload []byte) (digest.Digest, int64, error) {
+	switch mediaType {
+	case ocispec.MediaTypeImageManifest:
+		cellImageManifest := ocispec.Manifest{}
+		if err := json.Unmarshal(payload, &cellImageManifest); err != nil {
+			return "", 0, fmt.Errorf("failed to parse manifest, %v", err)
+		}
+		if cellImageManifest.Config.MediaType != MediaTypeCellImageConfig {
+			return "", 0, fmt.Errorf("%s is not a cell image artifact", cellImageManifest.Config.MediaType)
+		}
+		for _, layer := range cellImageManifest.Layers {
+			if layer.MediaType == MediaTypeCellImageLayer {
+				return layer.Digest, layer.Size, nil
+			}
+		}
+		return "", 0, fmt.Errorf("cell image layer not found in the manifest")
+	case schema2.MediaTypeManifest:
+		cellImageManifest := schema2.Manifest{}
+		if err := json.Unmarshal(payload, &cellImageManifest); err != nil {
+			return "", 0, fmt.Errorf("failed to parse manifest, %v", err)
+		}
+		if len(cellImageManifest.Layers) != 1 {
+			return "", 0, fmt.Errorf("expected exactly 1 File Layer, but found %d", len(cellImageManifest.Layers))
+		}
+		return cellImageManifest.Layers[0].Digest, cellImageManifest.Layers[0].Size, nil
+	case schema1.MediaTypeSignedManifest, schema1.MediaTypeManifest:
+		cellImageManifest := schema1.Manifest{}
+		if err := json.Unmarshal(payload, &cellImageManifest); err != nil {
+			return "", 0, fmt.Errorf("failed to parse manifest, %v", err)
+		}
+		if len(cellImageManifest.FSLayers) != 1 {
+			return "", 0, fmt.Errorf("expected exactly 1 File Layer, but found %d", len(cellImageManifest.FSLayers))
+		}
+		return cellImageManifest.FSLayers[0].BlobSum, 0, nil
+	default:
+		return "", 0, fmt.Errorf("unsupported manifest type %s", mediaType)
+	}
+}
@@ -20,28 +20,23 @@ package registry
 
 import (
 	"bytes"
-	"crypto/sha256"
-	"encoding/hex"
-	"errors"
 	"fmt"
 	"io"
 	"os"
 
-	"io/ioutil"
-
-	"github.com/docker/distribution/manifest"
-	"github.com/docker/distribution/manifest/schema1"
-	"github.com/docker/libtrust"
 	registry2 "github.com/nokia/docker-registry-client/registry"
-	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"cellery.io/cellery/components/cli/pkg/image"
 	"cellery.io/cellery/components/cli/pkg/util"
 )
 
 type Registry interface {
-	Pull(parsedCellImage *image.CellImage, username string, password string) ([]byte, error)
-	Push(parsedCellImage *image.CellImage, fileBytes []byte, username, password string) error
+	Pull(parsedCellImage *image.CellImage, cellImage io.Writer, username, password string, progress Progress) error
+	Push(parsedCellImage *image.CellImage, cellImage io.ReaderAt, size int64, username, password string,
+		progress Progress) error
+	PullSignature(parsedCellImage *image.CellImage, username, password string) ([]byte, error)
+	PushSignature(parsedCellImage *image.CellImage, signature []byte, username, password string) error
 	Out() io.Writer
 }
 
@@ -54,7 +49,9 @@ func NewCelleryRegistry() *CelleryRegistry {
 	return registry
 }
 
-func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, fileBytes []byte, username, password string) error {
+// Push uploads the cell image zip of the given size. The cell image is streamed to the registry in chunks.
+func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, cellImage io.ReaderAt, size int64,
+	username, password string, progress Progress) error {
 	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nConnecting to %s", util.Bold(parsedCellImage.Registry)))
 	// Initiating a connection to Cellery Registry
 	hub, err := registry2.New("https://"+parsedCellImage.Registry, username, password)
@@ -64,11 +61,19 @@ func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, fileByte
 	imageName := fmt.Sprintf("%s/%s:%s", parsedCellImage.Organization, parsedCellImage.ImageName,
 		parsedCellImage.ImageVersion)
 	repository := parsedCellImage.Organization + "/" + parsedCellImage.ImageName
-	// Creating the Cell Image Digest (Docker file Layer digest)
-	hash := sha256.New()
-	hash.Write(fileBytes)
-	sha256sum := hex.EncodeToString(hash.Sum(nil))
-	cellImageDigest := digest.NewDigestFromEncoded(digest.SHA256, sha256sum)
+	// Creating the Cell Image Digest (Cell Image layer digest)
+	cellImageDigest, err := computeDigest(cellImage, size)
+	if err != nil {
+		return fmt.Errorf("error occurred while reading the cell image, %v", err)
+	}
+	metadata, err := image.ReadMetaDataFromZip(cellImage, size)
+	if err != nil {
+		return fmt.Errorf("invalid cell image, %v", err)
+	}
+	configBytes, configDescriptor, err := buildConfig(metadata)
+	if err != nil {
+		return err
+	}
 
 	// Checking if the the Cell Image already exists in the registry
 	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nChecking if the image %s already exists in the Registry", util.Bold(imageName)))
@@ -80,8 +85,7 @@ func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, fileByte
 	// Pushing the cell image if it is not already uploaded
 	if !cellImageDigestExists {
 		fmt.Fprintln(registry.Out(), fmt.Sprintf("\nPushing image %s", util.Bold(imageName)))
-		// Read stream of files
-		err = hub.UploadBlob(repository, cellImageDigest, bytes.NewReader(fileBytes), nil)
+		err = uploadBlob(hub, repository, cellImageDigest, cellImage, size, progress)
 		if err != nil {
 			return err
 		}
@@ -89,35 +93,29 @@ func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, fileByte
 		fmt.Fprintln(registry.Out(), fmt.Sprintf("\nUsing already existing image %s in %s Registry", util.Bold(imageName),
 			util.Bold(parsedCellImage.Registry)))
 	}
-
-	// Creating a Docker manifest to be uploaded
-	cellImageManifest := &schema1.Manifest{
-		Name: repository,
-		Versioned: manifest.Versioned{
-			SchemaVersion: 1,
-			MediaType:     schema1.MediaTypeSignedManifest,
-		},
-		Tag:          parsedCellImage.ImageVersion,
-		Architecture: "amd64",
-		FSLayers: []schema1.FSLayer{
-			{BlobSum: cellImageDigest},
-		},
-		History: []schema1.History{
-			{},
-		},
-	}
-	// Signing the Docker Manifest
-	key, err := libtrust.GenerateECP256PrivateKey()
+	configDigestExists, err := hub.HasBlob(repository, configDescriptor.Digest)
 	if err != nil {
-		return fmt.Errorf("error occurred while pushing the cell image, %v", err)
+		return err
+	}
+	if !configDigestExists {
+		err = hub.UploadBlob(repository, configDescriptor.Digest, bytes.NewReader(configBytes), nil)
+		if err != nil {
+			return err
+		}
 	}
-	signedCellImageManifest, err := schema1.Sign(cellImageManifest, key)
+
+	// Creating an OCI image manifest to be uploaded
+	cellImageManifest, err := buildManifest(parsedCellImage, configDescriptor, ocispec.Descriptor{
+		MediaType: MediaTypeCellImageLayer,
+		Digest:    cellImageDigest,
+		Size:      size,
+	})
 	if err != nil {
 		return fmt.Errorf("error occurred while pushing the cell image, %v", err)
 	}
 
 	// Uploading the manifest to the Cellery Registry (Docker Registry)
-	err = hub.PutManifest(repository, parsedCellImage.ImageVersion, signedCellImageManifest)
+	err = putManifest(hub, repository, parsedCellImage.ImageVersion, ocispec.MediaTypeImageManifest, cellImageManifest)
 	if err != nil {
 		return err
 	}
@@ -125,54 +123,42 @@ func (registry *CelleryRegistry) Push(parsedCellImage *image.CellImage, fileByte
 	return nil
 }
 
-func (registry *CelleryRegistry) Pull(parsedCellImage *image.CellImage, username string, password string) ([]byte, error) {
-	var cellImage []byte
+// Pull downloads the cell image zip and writes it to the given writer.
+func (registry *CelleryRegistry) Pull(parsedCellImage *image.CellImage, cellImage io.Writer, username,
+	password string, progress Progress) error {
 	repository := parsedCellImage.Organization + "/" + parsedCellImage.ImageName
 	// Initiating a connection to Cellery Registry
 	hub, err := registry2.New("https://"+parsedCellImage.Registry, username, password)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
+		return fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
 	}
-	// Fetching the Docker Image Manifest
-	cellImageManifest, err := hub.Manifest(repository, parsedCellImage.ImageVersion)
+	// Fetching the Cell Image Manifest (OCI image manifest or legacy Docker manifest)
+	mediaType, cellImageManifest, err := getManifest(hub, repository, parsedCellImage.ImageVersion)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	var cellImageDigest digest.Digest
-	if len(cellImageManifest.References()) == 1 {
-		cellImageReference := cellImageManifest.References()[0]
-		cellImageDigest = cellImageReference.Digest
-
-		imageName := fmt.Sprintf("%s/%s:%s", parsedCellImage.Organization, parsedCellImage.ImageName,
-			parsedCellImage.ImageVersion)
-		fmt.Fprintln(registry.Out(), fmt.Sprintf("\nPulling image %s", util.Bold(imageName)))
-
-		// Downloading the Cell Image from the repository
-		reader, err := hub.DownloadBlob(repository, cellImageReference.Digest)
-		if err != nil {
-			return nil, err
-		}
-		if reader != nil {
-			defer func() error {
-				err = reader.Close()
-				if err != nil {
-					return fmt.Errorf("error occurred while cleaning up, %v", err)
-				}
-				return nil
-			}()
-		}
-		cellImage, err = ioutil.ReadAll(reader)
-		if err != nil {
-			return nil, fmt.Errorf("error occurred while pulling cell image, %v", err)
+	cellImageDigest, size, err := cellImageLayer(mediaType, cellImageManifest)
+	if err != nil {
+		return fmt.Errorf("invalid cell image, %v", err)
+	}
+	if size <= 0 {
+		// Legacy manifests do not include the layer size
+		descriptor, err := hub.BlobMetadata(repository, cellImageDigest)
+		if err == nil {
+			size = descriptor.Size
 		}
+	}
+	imageName := fmt.Sprintf("%s/%s:%s", parsedCellImage.Organization, parsedCellImage.ImageName,
+		parsedCellImage.ImageVersion)
+	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nPulling image %s", util.Bold(imageName)))
 
-	} else {
-		return nil, fmt.Errorf("invalid cell image, %v",
-			errors.New(fmt.Sprintf("expected exactly 1 File Layer, but found %d",
-				len(cellImageManifest.References()))))
+	// Downloading the Cell Image from the repository
+	err = downloadBlob(hub, repository, cellImageDigest, size, cellImage, progress)
+	if err != nil {
+		return fmt.Errorf("error occurred while pulling cell image, %v", err)
 	}
 	fmt.Fprintln(registry.Out(), fmt.Sprintf("\nImage Digest : %s\n", util.Bold(cellImageDigest)))
-	return cellImage, nil
+	return nil
 }
 
 // Out returns the writer used for the stdout.
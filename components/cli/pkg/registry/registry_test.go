@@ -0,0 +1,416 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+// fakeRegistry is an in memory docker registry which implements the parts of the registry API used by cellery.
+type fakeRegistry struct {
+	mutex          sync.Mutex
+	blobs          map[string][]byte
+	manifests      map[string][]byte
+	manifestTypes  map[string]string
+	uploads        map[string]*bytes.Buffer
+	uploadSequence int
+	// uploadStates is the state of each upload session, which changes with every request to the session
+	// hence a chunk is only accepted at the location returned by the last response
+	uploadStates map[string]int
+	// failPatches is the number of chunk uploads to fail after storing half of the chunk
+	failPatches int
+	// rejectPatches fails every chunk upload with a 403 response
+	rejectPatches bool
+	// failDownloads is the number of blob downloads to interrupt after sending half of the blob
+	failDownloads int
+	patches       int
+	rangeRequests int
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		blobs:         map[string][]byte{},
+		manifests:     map[string][]byte{},
+		manifestTypes: map[string]string{},
+		uploads:       map[string]*bytes.Buffer{},
+		uploadStates:  map[string]int{},
+	}
+}
+
+func (fake *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	path := r.URL.Path
+	switch {
+	case path == "/v2/":
+		w.WriteHeader(http.StatusOK)
+	case strings.Contains(path, "/manifests/"):
+		fake.serveManifest(w, r)
+	case strings.Contains(path, "/blobs/uploads/"):
+		fake.serveUpload(w, r)
+	case strings.Contains(path, "/blobs/"):
+		fake.serveBlob(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (fake *fakeRegistry) serveBlob(w http.ResponseWriter, r *http.Request) {
+	blob, ok := fake.blobs[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Length", fmt.Sprint(len(blob)))
+		return
+	}
+	status := http.StatusOK
+	if r.Header.Get("Range") != "" {
+		var start int
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+		blob = blob[start:]
+		status = http.StatusPartialContent
+		fake.rangeRequests++
+	}
+	w.Header().Set("Content-Length", fmt.Sprint(len(blob)))
+	w.WriteHeader(status)
+	if fake.failDownloads > 0 {
+		fake.failDownloads--
+		w.Write(blob[:len(blob)/2])
+		// Dropping the connection before the whole blob is sent
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+		return
+	}
+	w.Write(blob)
+}
+
+func (fake *fakeRegistry) serveManifest(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPut {
+		payload, _ := ioutil.ReadAll(r.Body)
+		fake.manifests[r.URL.Path] = payload
+		fake.manifestTypes[r.URL.Path] = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	payload, ok := fake.manifests[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", fake.manifestTypes[r.URL.Path])
+	w.Write(payload)
+}
+
+func (fake *fakeRegistry) serveUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		fake.uploadSequence++
+		id := fmt.Sprint(fake.uploadSequence)
+		fake.uploads[id] = new(bytes.Buffer)
+		w.Header().Set("Location", fake.uploadLocation(r, r.URL.Path+id, id))
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	upload, ok := fake.uploads[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method == http.MethodGet {
+		w.Header().Set("Location", fake.uploadLocation(r, r.URL.Path, id))
+		w.Header().Set("Range", fmt.Sprintf("0-%d", upload.Len()-1))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.URL.Query().Get("_state") != fmt.Sprint(fake.uploadStates[id]) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	if r.Method == http.MethodPatch {
+		var start, end int
+		fmt.Sscanf(r.Header.Get("Content-Range"), "%d-%d", &start, &end)
+		if start != upload.Len() || end-start+1 != len(body) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		fake.patches++
+		if fake.rejectPatches {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if fake.failPatches > 0 {
+			fake.failPatches--
+			upload.Write(body[:len(body)/2])
+			fake.uploadStates[id]++
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+	upload.Write(body)
+	if r.Method == http.MethodPut {
+		dgst := r.URL.Query().Get("digest")
+		if digest.FromBytes(upload.Bytes()).String() != dgst {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fake.blobs[dgst] = upload.Bytes()
+		delete(fake.uploads, id)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.Header().Set("Location", fake.uploadLocation(r, r.URL.Path, id))
+	w.Header().Set("Range", fmt.Sprintf("0-%d", upload.Len()-1))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// uploadLocation moves the upload session to a new state and returns the location of the new state.
+func (fake *fakeRegistry) uploadLocation(r *http.Request, path, id string) string {
+	fake.uploadStates[id]++
+	return fmt.Sprintf("https://%s%s?_state=%d", r.Host, path, fake.uploadStates[id])
+}
+
+// startFakeRegistry starts a TLS server for the fake registry and returns the registry host.
+func startFakeRegistry(t *testing.T, fake *fakeRegistry) (string, func()) {
+	server := httptest.NewTLSServer(fake)
+	transport := http.DefaultTransport.(*http.Transport)
+	tlsConfig := transport.TLSClientConfig
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return strings.TrimPrefix(server.URL, "https://"), func() {
+		transport.TLSClientConfig = tlsConfig
+		server.Close()
+	}
+}
+
+func newCellImageZip(t *testing.T, metadata *image.MetaData, padding int) []byte {
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+	metadataFile, err := writer.Create(image.MetaDataFile())
+	if err != nil {
+		t.Fatalf("failed to create cell image zip, %v", err)
+	}
+	if err := json.NewEncoder(metadataFile).Encode(metadata); err != nil {
+		t.Fatalf("failed to write metadata, %v", err)
+	}
+	if padding > 0 {
+		paddingFile, err := writer.CreateHeader(&zip.FileHeader{Name: "padding", Method: zip.Store})
+		if err != nil {
+			t.Fatalf("failed to create cell image zip, %v", err)
+		}
+		paddingFile.Write(make([]byte, padding))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to create cell image zip, %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestPushAndPull(t *testing.T) {
+	fake := newFakeRegistry()
+	host, stop := startFakeRegistry(t, fake)
+	defer stop()
+	metadata := &image.MetaData{
+		CellImageName: image.CellImageName{Organization: "myorg", Name: "hello", Version: "1.0.0"},
+		Kind:          "Cell",
+	}
+	cellImage := newCellImageZip(t, metadata, 0)
+	parsedCellImage := &image.CellImage{Registry: host, Organization: "myorg", ImageName: "hello",
+		ImageVersion: "1.0.0"}
+	registry := NewCelleryRegistry()
+	if err := registry.Push(parsedCellImage, bytes.NewReader(cellImage), int64(len(cellImage)), "", "",
+		nil); err != nil {
+		t.Fatalf("error in Push, %v", err)
+	}
+
+	manifestPath := "/v2/myorg/hello/manifests/1.0.0"
+	if diff := cmp.Diff(ocispec.MediaTypeImageManifest, fake.manifestTypes[manifestPath]); diff != "" {
+		t.Errorf("Push: unexpected manifest media type (-want, +got)\n%v", diff)
+	}
+	manifest := ocispec.Manifest{}
+	if err := json.Unmarshal(fake.manifests[manifestPath], &manifest); err != nil {
+		t.Fatalf("failed to parse pushed manifest, %v", err)
+	}
+	if manifest.Config.MediaType != MediaTypeCellImageConfig {
+		t.Errorf("Push: unexpected config media type %s", manifest.Config.MediaType)
+	}
+	config := &image.MetaData{}
+	if err := json.Unmarshal(fake.blobs[manifest.Config.Digest.String()], config); err != nil {
+		t.Fatalf("failed to parse pushed config, %v", err)
+	}
+	if diff := cmp.Diff(metadata, config); diff != "" {
+		t.Errorf("Push: unexpected config (-want, +got)\n%v", diff)
+	}
+
+	pulledImage := new(bytes.Buffer)
+	if err := registry.Pull(parsedCellImage, pulledImage, "", "", nil); err != nil {
+		t.Fatalf("error in Pull, %v", err)
+	}
+	if !bytes.Equal(cellImage, pulledImage.Bytes()) {
+		t.Errorf("Pull: pulled image does not match the pushed image")
+	}
+}
+
+func TestPullLegacySchema1Image(t *testing.T) {
+	fake := newFakeRegistry()
+	host, stop := startFakeRegistry(t, fake)
+	defer stop()
+	cellImage := newCellImageZip(t, &image.MetaData{Kind: "Cell"}, 0)
+	cellImageDigest := digest.FromBytes(cellImage)
+	fake.blobs[cellImageDigest.String()] = cellImage
+	legacyManifest, _ := json.Marshal(schema1.Manifest{
+		Name:     "myorg/legacy",
+		Tag:      "1.0.0",
+		FSLayers: []schema1.FSLayer{{BlobSum: cellImageDigest}},
+	})
+	fake.manifests["/v2/myorg/legacy/manifests/1.0.0"] = legacyManifest
+	fake.manifestTypes["/v2/myorg/legacy/manifests/1.0.0"] = schema1.MediaTypeSignedManifest
+
+	pulledImage := new(bytes.Buffer)
+	err := NewCelleryRegistry().Pull(&image.CellImage{Registry: host, Organization: "myorg",
+		ImageName: "legacy", ImageVersion: "1.0.0"}, pulledImage, "", "", nil)
+	if err != nil {
+		t.Fatalf("error in Pull, %v", err)
+	}
+	if !bytes.Equal(cellImage, pulledImage.Bytes()) {
+		t.Errorf("Pull: pulled image does not match the legacy image")
+	}
+}
+
+func TestResumeInterruptedTransfers(t *testing.T) {
+	defer func(backoff time.Duration) { transferRetryBackoff = backoff }(transferRetryBackoff)
+	transferRetryBackoff = time.Millisecond
+	fake := newFakeRegistry()
+	fake.failPatches = 1
+	fake.failDownloads = 1
+	host, stop := startFakeRegistry(t, fake)
+	defer stop()
+	metadata := &image.MetaData{
+		CellImageName: image.CellImageName{Organization: "myorg", Name: "large", Version: "1.0.0"},
+		Kind:          "Cell",
+	}
+	// Padding the image to span multiple upload chunks
+	cellImage := newCellImageZip(t, metadata, uploadChunkSize+1024)
+	parsedCellImage := &image.CellImage{Registry: host, Organization: "myorg", ImageName: "large",
+		ImageVersion: "1.0.0"}
+	registry := NewCelleryRegistry()
+
+	var pushed, pushTotal int64
+	err := registry.Push(parsedCellImage, bytes.NewReader(cellImage), int64(len(cellImage)), "", "",
+		func(transferred, total int64) {
+			pushed, pushTotal = transferred, total
+		})
+	if err != nil {
+		t.Fatalf("error in Push, %v", err)
+	}
+	// The failed chunk is resumed from the middle, hence the rest fits in a single chunk
+	if fake.patches != 2 {
+		t.Errorf("Push: expected 2 chunk uploads including the resumed chunk, got %d", fake.patches)
+	}
+	if pushed != int64(len(cellImage)) || pushTotal != int64(len(cellImage)) {
+		t.Errorf("Push: unexpected progress %d/%d", pushed, pushTotal)
+	}
+
+	pulledImage := new(bytes.Buffer)
+	var pulled int64
+	err = registry.Pull(parsedCellImage, pulledImage, "", "", func(transferred, total int64) {
+		pulled = transferred
+	})
+	if err != nil {
+		t.Fatalf("error in Pull, %v", err)
+	}
+	if fake.rangeRequests != 1 {
+		t.Errorf("Pull: expected the download to be resumed with a range request, got %d", fake.rangeRequests)
+	}
+	if pulled != int64(len(cellImage)) {
+		t.Errorf("Pull: unexpected progress %d", pulled)
+	}
+	if !bytes.Equal(cellImage, pulledImage.Bytes()) {
+		t.Errorf("Pull: pulled image does not match the pushed image")
+	}
+}
+
+func TestPushRejectedChunk(t *testing.T) {
+	defer func(backoff time.Duration) { transferRetryBackoff = backoff }(transferRetryBackoff)
+	transferRetryBackoff = time.Millisecond
+	fake := newFakeRegistry()
+	fake.rejectPatches = true
+	host, stop := startFakeRegistry(t, fake)
+	defer stop()
+	metadata := &image.MetaData{
+		CellImageName: image.CellImageName{Organization: "myorg", Name: "rejected", Version: "1.0.0"},
+		Kind:          "Cell",
+	}
+	cellImage := newCellImageZip(t, metadata, 0)
+	err := NewCelleryRegistry().Push(&image.CellImage{Registry: host, Organization: "myorg",
+		ImageName: "rejected", ImageVersion: "1.0.0"}, bytes.NewReader(cellImage), int64(len(cellImage)), "", "",
+		nil)
+	if err == nil {
+		t.Fatalf("Push: expected an error for a rejected chunk upload")
+	}
+	// A 4xx response is not retried
+	if fake.patches != 1 {
+		t.Errorf("Push: expected a single chunk upload without retries, got %d", fake.patches)
+	}
+}
+
+func TestPushAndPullSignature(t *testing.T) {
+	fake := newFakeRegistry()
+	host, stop := startFakeRegistry(t, fake)
+	defer stop()
+	parsedCellImage := &image.CellImage{Registry: host, Organization: "myorg", ImageName: "hello",
+		ImageVersion: "1.0.0"}
+	registry := NewCelleryRegistry()
+	if _, err := registry.PullSignature(parsedCellImage, "", ""); err != ErrSignatureNotFound {
+		t.Errorf("PullSignature: expected ErrSignatureNotFound for unsigned image, got %v", err)
+	}
+	signature := []byte(`{"payload":"e30=","signature":"c2lnbmF0dXJl","keyId":"sha256:1234"}`)
+	if err := registry.PushSignature(parsedCellImage, signature, "", ""); err != nil {
+		t.Fatalf("error in PushSignature, %v", err)
+	}
+	if _, ok := fake.manifests["/v2/myorg/hello/manifests/1.0.0.sig"]; !ok {
+		t.Errorf("PushSignature: signature manifest not tagged with 1.0.0.sig")
+	}
+	pulledSignature, err := registry.PullSignature(parsedCellImage, "", "")
+	if err != nil {
+		t.Fatalf("error in PullSignature, %v", err)
+	}
+	if diff := cmp.Diff(signature, pulledSignature); diff != "" {
+		t.Errorf("PullSignature: unexpected signature (-want, +got)\n%v", diff)
+	}
+}
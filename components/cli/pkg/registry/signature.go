@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	registry2 "github.com/nokia/docker-registry-client/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+// MediaTypeCellImageSignature is the media type of the blob holding the signature of a cell image.
+const MediaTypeCellImageSignature = "application/vnd.cellery.cell.image.signature.v1+json"
+
+// signatureTagSuffix is appended to the image version to create the tag of the signature manifest.
+const signatureTagSuffix = ".sig"
+
+// ErrSignatureNotFound is returned when a cell image does not have a signature in the registry.
+var ErrSignatureNotFound = errors.New("signature not found")
+
+// PushSignature uploads the signature of a cell image. The signature is pushed as a separate manifest
+// tagged <version>.sig which has the signature as the config blob.
+func (registry *CelleryRegistry) PushSignature(parsedCellImage *image.CellImage, signature []byte, username,
+	password string) error {
+	hub, err := registry2.New("https://"+parsedCellImage.Registry, username, password)
+	if err != nil {
+		return fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
+	}
+	repository := parsedCellImage.Organization + "/" + parsedCellImage.ImageName
+	signatureDigest := digest.FromBytes(signature)
+	if err = hub.UploadBlob(repository, signatureDigest, bytes.NewReader(signature), nil); err != nil {
+		return fmt.Errorf("failed to upload signature, %v", err)
+	}
+	signatureManifest, err := json.Marshal(struct {
+		MediaType string `json:"mediaType"`
+		ocispec.Manifest
+	}{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Manifest: ocispec.Manifest{
+			Versioned: specs.Versioned{
+				SchemaVersion: 2,
+			},
+			Config: ocispec.Descriptor{
+				MediaType: MediaTypeCellImageSignature,
+				Digest:    signatureDigest,
+				Size:      int64(len(signature)),
+			},
+			Layers: []ocispec.Descriptor{},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create signature manifest, %v", err)
+	}
+	err = putManifest(hub, repository, parsedCellImage.ImageVersion+signatureTagSuffix,
+		ocispec.MediaTypeImageManifest, signatureManifest)
+	if err != nil {
+		return fmt.Errorf("failed to push signature, %v", err)
+	}
+	return nil
+}
+
+// PullSignature downloads the signature of a cell image. ErrSignatureNotFound is returned if the image is
+// not signed.
+func (registry *CelleryRegistry) PullSignature(parsedCellImage *image.CellImage, username,
+	password string) ([]byte, error) {
+	hub, err := registry2.New("https://"+parsedCellImage.Registry, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize connection to Cellery Registry %v", err)
+	}
+	repository := parsedCellImage.Organization + "/" + parsedCellImage.ImageName
+	mediaType, payload, err := getManifest(hub, repository, parsedCellImage.ImageVersion+signatureTagSuffix)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrSignatureNotFound
+		}
+		return nil, fmt.Errorf("failed to pull signature, %v", err)
+	}
+	signatureManifest := ocispec.Manifest{}
+	if mediaType != ocispec.MediaTypeImageManifest {
+		return nil, fmt.Errorf("unsupported signature manifest type %s", mediaType)
+	}
+	if err := json.Unmarshal(payload, &signatureManifest); err != nil {
+		return nil, fmt.Errorf("failed to parse signature manifest, %v", err)
+	}
+	if signatureManifest.Config.MediaType != MediaTypeCellImageSignature {
+		return nil, fmt.Errorf("%s is not a cell image signature", signatureManifest.Config.MediaType)
+	}
+	signature := new(bytes.Buffer)
+	err = downloadBlob(hub, repository, signatureManifest.Config.Digest, signatureManifest.Config.Size, signature,
+		nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull signature, %v", err)
+	}
+	return signature.Bytes(), nil
+}
+
+// isNotFound checks whether the error was caused by a 404 response from the registry.
+func isNotFound(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	httpErr, ok := err.(*registry2.HttpStatusError)
+	return ok && httpErr.Response.StatusCode == http.StatusNotFound
+}
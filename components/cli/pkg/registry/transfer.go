@@ -0,0 +1,289 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	registry2 "github.com/nokia/docker-registry-client/registry"
+	"github.com/opencontainers/go-digest"
+)
+
+// uploadChunkSize is the size of a single PATCH request of a chunked blob upload.
+const uploadChunkSize = 5 * 1024 * 1024
+
+// maxTransferRetries is the number of times an interrupted upload or download is resumed before failing.
+const maxTransferRetries = 5
+
+// transferRetryBackoff is the wait before the first retry of a failed transfer, which is doubled on every retry.
+var transferRetryBackoff = time.Second
+
+// Progress is called with the number of bytes transferred so far and the total number of bytes.
+type Progress func(transferred, total int64)
+
+// computeDigest calculates the digest of a blob without reading it into memory.
+func computeDigest(blob io.ReaderAt, size int64) (digest.Digest, error) {
+	return digest.SHA256.FromReader(io.NewSectionReader(blob, 0, size))
+}
+
+// uploadBlob uploads a blob using a chunked upload session. If a chunk fails, the upload is resumed from the
+// offset and the location the registry returns for the upload session.
+func uploadBlob(hub *registry2.Registry, repository string, blobDigest digest.Digest, blob io.ReaderAt,
+	size int64, progress Progress) error {
+	location, err := initiateUpload(hub, repository)
+	if err != nil {
+		return fmt.Errorf("failed to initiate upload, %v", err)
+	}
+	var offset int64
+	retries := 0
+	resume := false
+	chunk := make([]byte, uploadChunkSize)
+	for offset < size {
+		if resume {
+			resumedOffset, resumedLocation, err := uploadStatus(hub, location)
+			if err != nil {
+				if err := waitBeforeRetry(err, retries); err != nil {
+					return fmt.Errorf("failed to resume upload, %v", err)
+				}
+				retries++
+				continue
+			}
+			offset, location, resume = resumedOffset, resumedLocation, false
+			continue
+		}
+		n, err := blob.ReadAt(chunk, offset)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		nextLocation, err := uploadChunk(hub, location, chunk[:n], offset)
+		if err != nil {
+			if err := waitBeforeRetry(err, retries); err != nil {
+				return fmt.Errorf("failed to upload blob, %v", err)
+			}
+			retries++
+			log.Printf("Upload of chunk at offset %d failed, resuming upload: %v", offset, err)
+			resume = true
+			continue
+		}
+		location = nextLocation
+		offset += int64(n)
+		if progress != nil {
+			progress(offset, size)
+		}
+	}
+	return completeUpload(hub, location, blobDigest)
+}
+
+// waitBeforeRetry returns the error if the failed transfer cannot be retried, or waits for an exponentially
+// increasing backoff before the next attempt.
+func waitBeforeRetry(err error, retries int) error {
+	if !isRetryable(err) {
+		return err
+	}
+	if retries >= maxTransferRetries {
+		return fmt.Errorf("giving up after %d retries, %v", retries, err)
+	}
+	time.Sleep(transferRetryBackoff << uint(retries))
+	return nil
+}
+
+// isRetryable checks whether a transfer failed due to a network error or a 5xx response from the registry.
+// Any other response from the registry fails the same way on a retry.
+func isRetryable(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	if httpErr, ok := err.(*registry2.HttpStatusError); ok {
+		return httpErr.Response.StatusCode >= http.StatusInternalServerError
+	}
+	if err == io.ErrUnexpectedEOF {
+		return true
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
+
+func initiateUpload(hub *registry2.Registry, repository string) (*url.URL, error) {
+	resp, err := hub.Client.Post(fmt.Sprintf("%s/v2/%s/blobs/uploads/", hub.URL, repository),
+		"application/octet-stream", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return uploadLocation(hub, resp)
+}
+
+// uploadLocation resolves the location of the upload session returned by the registry, which can be relative.
+func uploadLocation(hub *registry2.Registry, resp *http.Response) (*url.URL, error) {
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return nil, fmt.Errorf("registry did not return an upload location")
+	}
+	base, err := url.Parse(hub.URL)
+	if err != nil {
+		return nil, err
+	}
+	locationUrl, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(locationUrl), nil
+}
+
+func uploadChunk(hub *registry2.Registry, location *url.URL, chunk []byte, offset int64) (*url.URL, error) {
+	req, err := http.NewRequest(http.MethodPatch, location.String(), bytes.NewReader(chunk))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Range", fmt.Sprintf("%d-%d", offset, offset+int64(len(chunk))-1))
+	resp, err := hub.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return uploadLocation(hub, resp)
+}
+
+// uploadStatus returns the number of bytes the registry has received for an upload session and the location
+// to continue the upload from.
+func uploadStatus(hub *registry2.Registry, location *url.URL) (int64, *url.URL, error) {
+	resp, err := hub.Client.Get(location.String())
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	nextLocation, err := uploadLocation(hub, resp)
+	if err != nil {
+		return 0, nil, err
+	}
+	// The Range header is of the form 0-<last byte received>
+	uploadedRange := strings.Split(resp.Header.Get("Range"), "-")
+	if len(uploadedRange) != 2 {
+		return 0, nextLocation, nil
+	}
+	lastByte, err := strconv.ParseInt(uploadedRange[1], 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid upload range %s", resp.Header.Get("Range"))
+	}
+	return lastByte + 1, nextLocation, nil
+}
+
+func completeUpload(hub *registry2.Registry, location *url.URL, blobDigest digest.Digest) error {
+	completeUrl := *location
+	query := completeUrl.Query()
+	query.Set("digest", blobDigest.String())
+	completeUrl.RawQuery = query.Encode()
+	req, err := http.NewRequest(http.MethodPut, completeUrl.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := hub.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to complete upload, %v", err)
+	}
+	return resp.Body.Close()
+}
+
+// downloadBlob streams a blob to the writer. If the connection drops, the download is resumed using a
+// range request. The downloaded content is verified against the digest.
+func downloadBlob(hub *registry2.Registry, repository string, blobDigest digest.Digest, size int64, out io.Writer,
+	progress Progress) error {
+	if err := blobDigest.Validate(); err != nil {
+		return fmt.Errorf("invalid digest %s, %v", blobDigest, err)
+	}
+	verifier := blobDigest.Verifier()
+	writer := &progressWriter{
+		writer:   io.MultiWriter(out, verifier),
+		total:    size,
+		progress: progress,
+	}
+	retries := 0
+	for {
+		err := downloadRange(hub, repository, blobDigest, writer)
+		if err == nil {
+			break
+		}
+		if err := waitBeforeRetry(err, retries); err != nil {
+			return fmt.Errorf("failed to download blob, %v", err)
+		}
+		retries++
+		log.Printf("Download interrupted at offset %d, resuming download: %v", writer.written, err)
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("digest of the downloaded content does not match %s", blobDigest)
+	}
+	return nil
+}
+
+// downloadRange downloads the part of the blob after the bytes already written.
+func downloadRange(hub *registry2.Registry, repository string, blobDigest digest.Digest,
+	writer *progressWriter) error {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v2/%s/blobs/%s", hub.URL, repository, blobDigest), nil)
+	if err != nil {
+		return err
+	}
+	if writer.written > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", writer.written))
+	}
+	resp, err := hub.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if writer.written > 0 && resp.StatusCode != http.StatusPartialContent {
+		// The registry does not support range requests, hence skipping the bytes already written
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, writer.written); err != nil {
+			return err
+		}
+	}
+	if writer.total <= 0 && resp.StatusCode == http.StatusOK {
+		writer.total = resp.ContentLength
+	}
+	_, err = io.Copy(writer, resp.Body)
+	return err
+}
+
+// progressWriter counts the bytes written and reports the progress.
+type progressWriter struct {
+	writer   io.Writer
+	written  int64
+	total    int64
+	progress Progress
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	if w.progress != nil {
+		w.progress(w.written, w.total)
+	}
+	return n, err
+}
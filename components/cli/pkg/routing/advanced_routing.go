@@ -41,3 +41,7 @@ func getCompositeServiceHost(instance string, component string) string {
 func getCompositeName(instance string, component string) string {
 	return fmt.Sprintf("%s--%s", instance, component)
 }
+
+func getDestinationRuleName(host string) string {
+	return fmt.Sprintf("%s--dr", host)
+}
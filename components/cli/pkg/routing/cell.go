@@ -33,14 +33,45 @@ const k8sMetadata = "metadata"
 const k8sAnnotations = "annotations"
 const instanceIdHeaderName = "x-instance-id"
 
-func buildRoutesForCellTarget(cli cli.Cli, newTarget *kubernetes.Cell, src string, currentTarget string, percentage int, isSessionAware bool) (*kubernetes.VirtualService, error) {
+func buildRoutesForCellTarget(cli cli.Cli, newTarget *kubernetes.Cell, src string, currentTarget string, options RouteOptions) (*kubernetes.VirtualService, error) {
 	vs, err := cli.KubeCli().GetVirtualService(getVsName(src))
 	if err != nil {
 		return nil, err
 	}
+	if options.Mirror {
+		vs.VsSpec.HTTP = mirrorRequests(vs.VsSpec.HTTP, options.Percentage,
+			func(rule *kubernetes.HTTP) *kubernetes.Destination {
+				for _, route := range rule.Route {
+					if strings.HasPrefix(route.Destination.Host, currentTarget) {
+						routes := withDestinationPort(&[]kubernetes.HTTPRoute{{Destination: kubernetes.Destination{
+							Host: getCellGatewayHost(newTarget.CellMetaData.Name),
+						}}}, rule)
+						return &(*routes)[0].Destination
+					}
+				}
+				return nil
+			})
+		return &vs, nil
+	}
+	// the rules of a previous match based routing and the mirrors to the target are replaced by this routing
+	vs.VsSpec.HTTP = withoutMirrors(withoutMatchRules(vs.VsSpec.HTTP), newTarget.CellMetaData.Name)
+	if len(options.Matches) > 0 {
+		vs.VsSpec.HTTP = routeMatchingRequests(vs.VsSpec.HTTP, options.Matches, options.Percentage,
+			func(rule *kubernetes.HTTP, percentage int) *[]kubernetes.HTTPRoute {
+				for _, route := range rule.Route {
+					if strings.HasPrefix(route.Destination.Host, currentTarget) ||
+						strings.HasPrefix(route.Destination.Host, newTarget.CellMetaData.Name) {
+						return withDestinationPort(buildPercentageBasedHttpRoutesForCellInstance(currentTarget,
+							newTarget.CellMetaData.Name, percentage), rule)
+					}
+				}
+				return nil
+			})
+		return &vs, nil
+	}
 	// modify the vs to include new route information.
-	modfiedVss, err := getModifiedVsForCellTarget(vs, currentTarget, newTarget.CellMetaData.Name, percentage,
-		isSessionAware)
+	modfiedVss, err := getModifiedVsForCellTarget(vs, currentTarget, newTarget.CellMetaData.Name, options.Percentage,
+		options.SessionAware)
 	if err != nil {
 		return nil, err
 	}
@@ -180,16 +211,16 @@ func getModifiedVsForCellTarget(vs kubernetes.VirtualService, dependencyInst str
 						if err != nil {
 							return nil, err
 						}
-						httpRule.Route = *route
+						httpRule.Route = *withDestinationPort(route, &httpRule)
 
 					} else {
-						httpRule.Route = *buildPercentageBasedHttpRoutesForCellInstance(dependencyInst, targetInst,
-							percentageForTarget)
+						httpRule.Route = *withDestinationPort(buildPercentageBasedHttpRoutesForCellInstance(
+							dependencyInst, targetInst, percentageForTarget), &httpRule)
 					}
 					//goto outermostloop
 				} else {
-					httpRule.Route = *buildPercentageBasedHttpRoutesForCellInstance(dependencyInst, targetInst,
-						percentageForTarget)
+					httpRule.Route = *withDestinationPort(buildPercentageBasedHttpRoutesForCellInstance(dependencyInst,
+						targetInst, percentageForTarget), &httpRule)
 					//goto outermostloop
 				}
 			}
@@ -197,17 +228,18 @@ func getModifiedVsForCellTarget(vs kubernetes.VirtualService, dependencyInst str
 		//outermostloop:
 		vs.VsSpec.HTTP[i] = httpRule
 	}
-	// not supported atm
-	// TODO: support TCP
-	// TCP
-	//for i, tcpRule := range vs.VsSpec.TCP {
-	//	for _, route := range tcpRule.Route {
-	//		if strings.HasPrefix(route.Destination.Host, dependencyInst) {
-	//			tcpRule.Route = *buildTcpRoutes(dependencyInst, targetInst, route.Destination.Port, percentageForTarget)
-	//		}
-	//	}
-	//	vs.VsSpec.TCP[i] = tcpRule
-	//}
+	// tcp, session awareness does not apply as the requests cannot be inspected
+	for i, tcpRule := range vs.VsSpec.TCP {
+		for _, route := range tcpRule.Route {
+			if strings.HasPrefix(route.Destination.Host, dependencyInst) ||
+				strings.HasPrefix(route.Destination.Host, targetInst) {
+				tcpRule.Route = *buildTcpRoutes(getCellGatewayHost(dependencyInst), getCellGatewayHost(targetInst),
+					route.Destination.Port, percentageForTarget)
+				break
+			}
+		}
+		vs.VsSpec.TCP[i] = tcpRule
+	}
 	return &vs, nil
 }
 
@@ -284,13 +316,32 @@ func buildPercentageBasedHttpRoutesForCellInstance(dependencyInst string, target
 	return &routes
 }
 
-func buildTcpRoutes(dependencyInst string, targetInst string, port kubernetes.TCPPort, percentageForTarget int) *[]kubernetes.TCPRoute {
+// withDestinationPort sets the port of the rule's destinations on the routes, so that the requests to a gRPC or
+// other non default port of the dependency are routed to the same port of the target.
+func withDestinationPort(routes *[]kubernetes.HTTPRoute, rule *kubernetes.HTTP) *[]kubernetes.HTTPRoute {
+	var port *kubernetes.PortSelector
+	for _, route := range rule.Route {
+		if route.Destination.Port != nil {
+			port = route.Destination.Port
+			break
+		}
+	}
+	if port == nil {
+		return routes
+	}
+	for i := range *routes {
+		(*routes)[i].Destination.Port = &kubernetes.PortSelector{Number: port.Number}
+	}
+	return routes
+}
+
+func buildTcpRoutes(dependencyHost string, targetHost string, port kubernetes.TCPPort, percentageForTarget int) *[]kubernetes.TCPRoute {
 	var routes []kubernetes.TCPRoute
 	if percentageForTarget == 100 {
 		// full traffic switch to target, need only one route
 		routes = append(routes, kubernetes.TCPRoute{
 			Destination: kubernetes.TCPDestination{
-				Host: getCellGatewayHost(targetInst),
+				Host: targetHost,
 				Port: port,
 			},
 		})
@@ -298,7 +349,7 @@ func buildTcpRoutes(dependencyInst string, targetInst string, port kubernetes.TC
 		// modify the existing Route's weight
 		existingRoute := kubernetes.TCPRoute{
 			Destination: kubernetes.TCPDestination{
-				Host: getCellGatewayHost(dependencyInst),
+				Host: dependencyHost,
 				Port: port,
 			},
 			Weight: 100 - percentageForTarget,
@@ -306,7 +357,7 @@ func buildTcpRoutes(dependencyInst string, targetInst string, port kubernetes.TC
 		// add the new route
 		newRoute := kubernetes.TCPRoute{
 			Destination: kubernetes.TCPDestination{
-				Host: getCellGatewayHost(targetInst),
+				Host: targetHost,
 				Port: port,
 			},
 			Weight: percentageForTarget,
@@ -318,6 +369,10 @@ func buildTcpRoutes(dependencyInst string, targetInst string, port kubernetes.TC
 }
 
 func checkForMatchingApis(currentTarget *kubernetes.Cell, newTarget *kubernetes.Cell) error {
+	// the gRPC and TCP ports are checked first as the traffic cannot be routed if they do not match
+	if err := checkForMatchingPorts(currentTarget, newTarget); err != nil {
+		return err
+	}
 outer:
 	for _, currTargetGwApi := range currentTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.HttpApis {
 		for _, newTargetGwApi := range newTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.HttpApis {
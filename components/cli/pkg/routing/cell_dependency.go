@@ -36,7 +36,28 @@ const dependencyKind = "kind"
 
 type Route interface {
 	Check() error
-	Build(cli cli.Cli, percentage int, isSessionAware bool, routesFile string) error
+	Build(cli cli.Cli, options RouteOptions, routesFile string) error
+	// ReroutedPorts returns the ports of the new target receiving the traffic, per protocol
+	ReroutedPorts(options RouteOptions) []ProtocolPorts
+}
+
+// RouteOptions describes how the traffic to the dependency is routed to the new target.
+type RouteOptions struct {
+	// Percentage of the traffic routed to the new target
+	Percentage int
+	// SessionAware routes all the requests of a user to the same instance
+	SessionAware bool
+	// Matches restrict the routing to the requests matching any of them. The rest of the requests are
+	// routed to the dependency.
+	Matches []kubernetes.HTTPMatch
+	// Mirror mirrors the percentage of the requests to the new target instead of routing them, discarding
+	// the responses. Mirroring is stopped if the percentage is 0.
+	Mirror bool
+}
+
+// switchesDependency returns true if the sources fully depend on the new target after the routing.
+func (options RouteOptions) switchesDependency() bool {
+	return options.Percentage == 100 && len(options.Matches) == 0 && !options.Mirror
 }
 
 func ExtractDependencies(depJson string) ([]map[string]string, error) {
@@ -19,7 +19,6 @@
 package routing
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/ghodss/yaml"
@@ -35,12 +34,6 @@ type CellToCellRoute struct {
 }
 
 func (router *CellToCellRoute) Check() error {
-	// if the target instance has only TCP components exposed, will not work.
-	// TODO: remove this once TCP is supported
-	if len(router.NewTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.HttpApis) == 0 &&
-		len(router.NewTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress.TcpApis) > 0 {
-		return fmt.Errorf("traffic switching to TCP cells not supported")
-	}
 	// check if APIs are matching
 	err := checkForMatchingApis(&router.CurrentTarget, &router.NewTarget)
 	if err != nil {
@@ -49,18 +42,18 @@ func (router *CellToCellRoute) Check() error {
 	return nil
 }
 
-func (router *CellToCellRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, routesFile string) error {
+func (router *CellToCellRoute) Build(cli cli.Cli, options RouteOptions, routesFile string) error {
 	modfiedVss, err := buildRoutesForCellTarget(cli, &router.NewTarget, router.Src.CellMetaData.Name,
-		router.CurrentTarget.CellMetaData.Name, percentage, isSessionAware)
+		router.CurrentTarget.CellMetaData.Name, options)
 	if err != nil {
 		return err
 	}
-	// if the percentage is 100, the running cell instance now fully depends on the new instance,
+	// if the percentage is 100 for all the requests, the running cell instance now fully depends on the new instance,
 	// hence update the dependency annotation
 	// additionally, if the percentage is 100, include the original gateway service name as an annotation.
 	var modifiedSrcCellInst *kubernetes.Cell
 	var gw []byte
-	if percentage == 100 {
+	if options.switchesDependency() {
 		modifiedSrcCellInst, err = getModifiedCellInstance(&router.Src, router.CurrentTarget.CellMetaData.Name,
 			router.NewTarget.CellMetaData.Name, router.NewTarget.CellMetaData.Annotations.Name,
 			router.NewTarget.CellMetaData.Annotations.Version, router.NewTarget.CellMetaData.Annotations.Organization,
@@ -83,6 +76,10 @@ func (router *CellToCellRoute) Build(cli cli.Cli, percentage int, isSessionAware
 	return nil
 }
 
+func (router *CellToCellRoute) ReroutedPorts(options RouteOptions) []ProtocolPorts {
+	return getCellReroutedPorts(&router.NewTarget, options)
+}
+
 func writeCellToCellArtifactsToFile(policiesFile string, vs *kubernetes.VirtualService, cellInstance *kubernetes.Cell, gw []byte) error {
 	f, err := os.OpenFile(policiesFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -34,20 +34,20 @@ type CellToCompositeRoute struct {
 }
 
 func (router *CellToCompositeRoute) Check() error {
-	return nil
+	return checkForMatchingComponentPorts(&router.CurrentTarget, &router.NewTarget)
 }
 
-func (router *CellToCompositeRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, routesFile string) error {
+func (router *CellToCompositeRoute) Build(cli cli.Cli, options RouteOptions, routesFile string) error {
 
-	modfiedVs, err := buildRoutesForCompositeTarget(cli, router.Src.CellMetaData.Name, &router.NewTarget, &router.CurrentTarget, percentage)
+	modfiedVs, err := buildRoutesForCompositeTarget(cli, router.Src.CellMetaData.Name, &router.NewTarget, &router.CurrentTarget, options)
 	if err != nil {
 		return err
 	}
-	// if the percentage is 100, the cell instance now fully depends on the new composite instance,
+	// if the percentage is 100 for all the requests, the cell instance now fully depends on the new composite instance,
 	// hence update the dependency annotation.
 	var modifiedTargetCompInst *kubernetes.Composite
 	var modifiedSrcCellInst *kubernetes.Cell
-	if percentage == 100 {
+	if options.switchesDependency() {
 		modifiedSrcCellInst, err = getModifiedCellInstance(&router.Src, router.CurrentTarget.CompositeMetaData.Name, router.NewTarget.CompositeMetaData.Name,
 			router.NewTarget.CompositeMetaData.Annotations.Name, router.NewTarget.CompositeMetaData.Annotations.Version,
 			router.NewTarget.CompositeMetaData.Annotations.Organization, compositeDependencyKind)
@@ -69,6 +69,10 @@ func (router *CellToCompositeRoute) Build(cli cli.Cli, percentage int, isSession
 	return nil
 }
 
+func (router *CellToCompositeRoute) ReroutedPorts(options RouteOptions) []ProtocolPorts {
+	return getCompositeReroutedPorts(&router.NewTarget, options)
+}
+
 func writeCellToCompositeArtifactsToFile(policiesFile string, vs *kubernetes.VirtualService, cellInstance *kubernetes.Cell, compositeInstance *kubernetes.Composite) error {
 	f, err := os.OpenFile(policiesFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -33,7 +33,7 @@ type origComponentData struct {
 }
 
 func buildRoutesForCompositeTarget(cli cli.Cli, src string, newTarget *kubernetes.Composite, currentTarget *kubernetes.Composite,
-	percentage int) (*kubernetes.VirtualService, error) {
+	options RouteOptions) (*kubernetes.VirtualService, error) {
 	// check if components in previous dependency and this dependency matches
 	if !doComponentsMatch(&currentTarget.CompositeSpec.ComponentTemplates,
 		&newTarget.CompositeSpec.ComponentTemplates) {
@@ -43,9 +43,41 @@ func buildRoutesForCompositeTarget(cli cli.Cli, src string, newTarget *kubernete
 	if err != nil {
 		return nil, err
 	}
+	if options.Mirror {
+		vs.VsSpec.HTTP = mirrorRequests(vs.VsSpec.HTTP, options.Percentage,
+			func(rule *kubernetes.HTTP) *kubernetes.Destination {
+				// only the requests routed to the dependency are mirrored
+				compTemplate := getRoutedComponent(rule, currentTarget.CompositeMetaData.Name,
+					currentTarget.CompositeMetaData.Name, &newTarget.CompositeSpec.ComponentTemplates)
+				if compTemplate == nil {
+					return nil
+				}
+				routes := withDestinationPort(&[]kubernetes.HTTPRoute{{Destination: kubernetes.Destination{
+					Host: getCompositeServiceHost(newTarget.CompositeMetaData.Name, compTemplate.Metadata.Name),
+				}}}, rule)
+				return &(*routes)[0].Destination
+			})
+		return &vs, nil
+	}
+	// the rules of a previous match based routing and the mirrors to the target are replaced by this routing
+	vs.VsSpec.HTTP = withoutMirrors(withoutMatchRules(vs.VsSpec.HTTP), newTarget.CompositeMetaData.Name)
+	if len(options.Matches) > 0 {
+		vs.VsSpec.HTTP = routeMatchingRequests(vs.VsSpec.HTTP, options.Matches, options.Percentage,
+			func(rule *kubernetes.HTTP, percentage int) *[]kubernetes.HTTPRoute {
+				compTemplate := getRoutedComponent(rule, currentTarget.CompositeMetaData.Name,
+					newTarget.CompositeMetaData.Name, &newTarget.CompositeSpec.ComponentTemplates)
+				if compTemplate == nil {
+					return nil
+				}
+				return withDestinationPort(buildPercentageBasedHttpRoutesForCompositeInstance(
+					currentTarget.CompositeMetaData.Name, newTarget.CompositeMetaData.Name, compTemplate, percentage),
+					rule)
+			})
+		return &vs, nil
+	}
 	// modify the vs to include new route information.
 	modifiedVs, err := getModifiedVsForCompositeTarget(&vs, currentTarget.CompositeMetaData.Name,
-		newTarget.CompositeMetaData.Name, percentage, &newTarget.CompositeSpec.ComponentTemplates)
+		newTarget.CompositeMetaData.Name, options.Percentage, &newTarget.CompositeSpec.ComponentTemplates)
 	if err != nil {
 		return nil, err
 	}
@@ -63,8 +95,8 @@ func getModifiedVsForCompositeTarget(vs *kubernetes.VirtualService, dependencyIn
 				for _, compTemplate := range *componentTemplates {
 					if strings.Contains(route.Destination.Host, "--"+compTemplate.Metadata.Name) {
 						// for each component in target composite inst, modify the rules
-						httpRule.Route = *buildPercentageBasedHttpRoutesForCompositeInstance(dependencyInst, targetInst,
-							&compTemplate, percentageForTarget)
+						httpRule.Route = *withDestinationPort(buildPercentageBasedHttpRoutesForCompositeInstance(
+							dependencyInst, targetInst, &compTemplate, percentageForTarget), &httpRule)
 						goto outermostloop
 					}
 				}
@@ -73,9 +105,54 @@ func getModifiedVsForCompositeTarget(vs *kubernetes.VirtualService, dependencyIn
 	outermostloop:
 		vs.VsSpec.HTTP[i] = httpRule
 	}
+	// tcp
+	for i, tcpRule := range vs.VsSpec.TCP {
+		for _, route := range tcpRule.Route {
+			compTemplate := getTcpRoutedComponent(&route, dependencyInst, targetInst, componentTemplates)
+			if compTemplate != nil {
+				tcpRule.Route = *buildTcpRoutes(getCompositeServiceHost(dependencyInst, compTemplate.Metadata.Name),
+					getCompositeServiceHost(targetInst, compTemplate.Metadata.Name), route.Destination.Port,
+					percentageForTarget)
+				break
+			}
+		}
+		vs.VsSpec.TCP[i] = tcpRule
+	}
 	return vs, nil
 }
 
+// getTcpRoutedComponent returns the component of the dependency or the target to which the route routes, if any.
+func getTcpRoutedComponent(route *kubernetes.TCPRoute, dependencyInst string, targetInst string,
+	componentTemplates *[]kubernetes.ComponentTemplate) *kubernetes.ComponentTemplate {
+	if !strings.HasPrefix(route.Destination.Host, dependencyInst) &&
+		!strings.HasPrefix(route.Destination.Host, targetInst) {
+		return nil
+	}
+	for i, compTemplate := range *componentTemplates {
+		if strings.Contains(route.Destination.Host, "--"+compTemplate.Metadata.Name) {
+			return &(*componentTemplates)[i]
+		}
+	}
+	return nil
+}
+
+// getRoutedComponent returns the component of the dependency or the target to which the rule routes, if any.
+func getRoutedComponent(rule *kubernetes.HTTP, dependencyInst string, targetInst string,
+	componentTemplates *[]kubernetes.ComponentTemplate) *kubernetes.ComponentTemplate {
+	for _, route := range rule.Route {
+		if !strings.HasPrefix(route.Destination.Host, dependencyInst) &&
+			!strings.HasPrefix(route.Destination.Host, targetInst) {
+			continue
+		}
+		for i, compTemplate := range *componentTemplates {
+			if strings.Contains(route.Destination.Host, "--"+compTemplate.Metadata.Name) {
+				return &(*componentTemplates)[i]
+			}
+		}
+	}
+	return nil
+}
+
 func doComponentsMatch(currentDepComponents *[]kubernetes.ComponentTemplate, newDepComponents *[]kubernetes.ComponentTemplate) bool {
 	var matchCount int
 	for _, currentDep := range *currentDepComponents {
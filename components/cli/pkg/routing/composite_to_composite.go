@@ -34,21 +34,21 @@ type CompositeToCompositeRoute struct {
 }
 
 func (router *CompositeToCompositeRoute) Check() error {
-	return nil
+	return checkForMatchingComponentPorts(&router.CurrentTarget, &router.NewTarget)
 }
 
-func (router *CompositeToCompositeRoute) Build(cli cli.Cli, percentage int, isSessionAware bool, routesFile string) error {
+func (router *CompositeToCompositeRoute) Build(cli cli.Cli, options RouteOptions, routesFile string) error {
 
 	modfiedVs, err := buildRoutesForCompositeTarget(cli, router.Src.CompositeMetaData.Name, &router.NewTarget,
-		&router.CurrentTarget, percentage)
+		&router.CurrentTarget, options)
 	if err != nil {
 		return err
 	}
-	// if the percentage is 100, the cell instance now fully depends on the new composite instance,
+	// if the percentage is 100 for all the requests, the cell instance now fully depends on the new composite instance,
 	// hence update the dependency annotation.
 	var modifiedTargetCompInst *kubernetes.Composite
 	var modifiedSrcCompositeInst *kubernetes.Composite
-	if percentage == 100 {
+	if options.switchesDependency() {
 		modifiedSrcCompositeInst, err = getModifiedCompositeSrcInstance(&router.Src,
 			router.CurrentTarget.CompositeMetaData.Name, router.NewTarget.CompositeMetaData.Name,
 			router.NewTarget.CompositeMetaData.Annotations.Name, router.NewTarget.CompositeMetaData.Annotations.Version,
@@ -71,6 +71,10 @@ func (router *CompositeToCompositeRoute) Build(cli cli.Cli, percentage int, isSe
 	return nil
 }
 
+func (router *CompositeToCompositeRoute) ReroutedPorts(options RouteOptions) []ProtocolPorts {
+	return getCompositeReroutedPorts(&router.NewTarget, options)
+}
+
 func writeCompositeToCompositeArtifactsToFile(policiesFile string, vs *kubernetes.VirtualService, compositeSrcInstance *kubernetes.Composite, compositeTargetInstance *kubernetes.Composite) error {
 	f, err := os.OpenFile(policiesFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+// faultExpiryAnnotationPrefix prefixes the annotations of the virtual service recording when the faults injected
+// into the requests to a dependency expire, e.g. fault-expiry.cellery.io/hr-inst: 2020-03-01T10:00:00Z.
+const faultExpiryAnnotationPrefix = "fault-expiry.cellery.io/"
+
+// SetFault returns the virtual service of the source instance with the fault set on the rules routing to the
+// dependency instance. The faults are cleared if the fault is nil, from all the rules if no dependency is given.
+// If an expiry is given, it is recorded on the virtual service so that any later change clears the fault once it
+// has expired, even if the command waiting to clear it did not complete. Expired faults are cleared in the
+// returned virtual service.
+func SetFault(cli cli.Cli, source string, dependency string, fault *kubernetes.HTTPFault,
+	expiresAt time.Time) (*kubernetes.VirtualService, error) {
+	vs, err := cli.KubeCli().GetVirtualService(getVsName(source))
+	if err != nil {
+		return nil, err
+	}
+	clearExpiredFaults(&vs, time.Now())
+	var modified bool
+	for i, rule := range vs.VsSpec.HTTP {
+		if dependency != "" && !routesTo(&rule, dependency) {
+			continue
+		}
+		vs.VsSpec.HTTP[i].Fault = fault
+		modified = true
+	}
+	if !modified && dependency != "" {
+		return nil, fmt.Errorf("instance %s does not route traffic to instance %s", source, dependency)
+	}
+	if dependency == "" {
+		for key := range vs.VsMetaData.Annotations {
+			if strings.HasPrefix(key, faultExpiryAnnotationPrefix) {
+				delete(vs.VsMetaData.Annotations, key)
+			}
+		}
+	} else if fault != nil && !expiresAt.IsZero() {
+		if vs.VsMetaData.Annotations == nil {
+			vs.VsMetaData.Annotations = map[string]string{}
+		}
+		vs.VsMetaData.Annotations[faultExpiryAnnotationPrefix+dependency] = expiresAt.UTC().Format(time.RFC3339)
+	} else {
+		delete(vs.VsMetaData.Annotations, faultExpiryAnnotationPrefix+dependency)
+	}
+	if len(vs.VsMetaData.Annotations) == 0 {
+		vs.VsMetaData.Annotations = nil
+	}
+	return &vs, nil
+}
+
+// clearExpiredFaults clears the faults on the rules routing to the dependencies whose fault expiry has passed,
+// along with the expiry annotations.
+func clearExpiredFaults(vs *kubernetes.VirtualService, now time.Time) {
+	for key, value := range vs.VsMetaData.Annotations {
+		if !strings.HasPrefix(key, faultExpiryAnnotationPrefix) {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, value)
+		if err == nil && expiresAt.After(now) {
+			continue
+		}
+		dependency := strings.TrimPrefix(key, faultExpiryAnnotationPrefix)
+		for i, rule := range vs.VsSpec.HTTP {
+			if routesTo(&rule, dependency) {
+				vs.VsSpec.HTTP[i].Fault = nil
+			}
+		}
+		delete(vs.VsMetaData.Annotations, key)
+	}
+}
+
+func routesTo(rule *kubernetes.HTTP, instance string) bool {
+	for _, route := range rule.Route {
+		if strings.HasPrefix(route.Destination.Host, instance+"--") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+func TestSetFault(t *testing.T) {
+	abort := &kubernetes.HTTPFault{Abort: &kubernetes.FaultAbort{HttpStatus: 503,
+		Percentage: &kubernetes.Percent{Value: 100}}}
+	expired := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	pending := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	expiresAt := time.Now().Add(time.Hour)
+	newVs := func(annotations map[string]string, hrFault, stockFault *kubernetes.HTTPFault) kubernetes.VirtualService {
+		return kubernetes.VirtualService{
+			VsMetaData: kubernetes.VsMetaData{Name: "hr-client--vs", Annotations: annotations},
+			VsSpec: kubernetes.VsSpec{HTTP: []kubernetes.HTTP{
+				{
+					Route: []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{Host: "hr--gateway-service"}}},
+					Fault: hrFault,
+				},
+				{
+					Route: []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{Host: "stock--gateway-service"}}},
+					Fault: stockFault,
+				},
+			}},
+		}
+	}
+	tests := []struct {
+		name       string
+		vs         kubernetes.VirtualService
+		dependency string
+		fault      *kubernetes.HTTPFault
+		expiresAt  time.Time
+		want       kubernetes.VirtualService
+	}{
+		{
+			name:       "fault with an expiry",
+			vs:         newVs(nil, nil, nil),
+			dependency: "stock",
+			fault:      abort,
+			expiresAt:  expiresAt,
+			want: newVs(map[string]string{"fault-expiry.cellery.io/stock": expiresAt.UTC().Format(time.RFC3339)},
+				nil, abort),
+		},
+		{
+			name:       "expired fault cleared by a later run",
+			vs:         newVs(map[string]string{"fault-expiry.cellery.io/hr": expired}, abort, nil),
+			dependency: "stock",
+			fault:      abort,
+			want:       newVs(nil, nil, abort),
+		},
+		{
+			name:       "fault which has not expired kept",
+			vs:         newVs(map[string]string{"fault-expiry.cellery.io/hr": pending}, abort, nil),
+			dependency: "stock",
+			fault:      abort,
+			want:       newVs(map[string]string{"fault-expiry.cellery.io/hr": pending}, abort, abort),
+		},
+		{
+			name:       "expiry removed when the faults are cleared",
+			vs:         newVs(map[string]string{"fault-expiry.cellery.io/hr": pending}, abort, abort),
+			dependency: "",
+			want:       newVs(nil, nil, nil),
+		},
+		{
+			name: "expiry of a dependency removed when its faults are cleared",
+			vs: newVs(map[string]string{"fault-expiry.cellery.io/hr": pending, "fault-expiry.cellery.io/stock": pending},
+				abort, abort),
+			dependency: "hr",
+			want:       newVs(map[string]string{"fault-expiry.cellery.io/stock": pending}, nil, abort),
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(
+				test.WithVirtualServices(map[string]kubernetes.VirtualService{"hr-client--vs": tst.vs}))))
+			got, err := SetFault(mockCli, "hr-client", tst.dependency, tst.fault, tst.expiresAt)
+			if err != nil {
+				t.Fatalf("error in SetFault, %v", err)
+			}
+			if diff := cmp.Diff(tst.want, *got); diff != "" {
+				t.Errorf("invalid virtual service (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
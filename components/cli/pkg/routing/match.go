@@ -0,0 +1,208 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+// matchRuleName is the name of the rules which route the requests matching the user given match expressions.
+const matchRuleName = "cellery-match"
+const cookieHeaderName = "cookie"
+
+const matchConditionSeparator = "&"
+const headerMatchPrefix = "header:"
+const cookieMatchPrefix = "cookie:"
+const queryMatchPrefix = "query:"
+const uriMatchKey = "uri"
+
+// ParseMatch parses a match expression into a http match. A match expression is a list of conditions separated
+// by '&', all of which should hold for a request to match. A condition compares a header, cookie, query parameter
+// or the uri with a value either exactly (=), by prefix (^=) or with a regular expression (~=),
+// e.g. header:x-tenant=beta&uri^=/api/v2
+func ParseMatch(expression string) (kubernetes.HTTPMatch, error) {
+	var match kubernetes.HTTPMatch
+	var cookieMatch *kubernetes.StringMatch
+	for _, condition := range strings.Split(expression, matchConditionSeparator) {
+		key, value, err := parseMatchCondition(condition)
+		if err != nil {
+			return match, fmt.Errorf("invalid match expression %q, %v", expression, err)
+		}
+		switch {
+		case key == uriMatchKey:
+			match.Uri = value
+		case strings.HasPrefix(key, headerMatchPrefix):
+			name := strings.ToLower(strings.TrimPrefix(key, headerMatchPrefix))
+			if name == cookieHeaderName {
+				return match, fmt.Errorf("invalid match expression %q, use cookie:<name> to match cookies",
+					expression)
+			}
+			if match.Headers == nil {
+				match.Headers = map[string]*kubernetes.StringMatch{}
+			}
+			match.Headers[name] = value
+		case strings.HasPrefix(key, queryMatchPrefix):
+			// prefix matches are not supported for query parameters, hence use a regex instead
+			if value.Prefix != "" {
+				value = &kubernetes.StringMatch{Regex: "^" + regexp.QuoteMeta(value.Prefix) + ".*"}
+			}
+			if match.QueryParams == nil {
+				match.QueryParams = map[string]*kubernetes.StringMatch{}
+			}
+			match.QueryParams[strings.TrimPrefix(key, queryMatchPrefix)] = value
+		case strings.HasPrefix(key, cookieMatchPrefix):
+			// cookies are sent in a single header, hence only one of them can be matched
+			if cookieMatch != nil {
+				return match, fmt.Errorf("invalid match expression %q, only one cookie can be matched", expression)
+			}
+			cookieMatch = &kubernetes.StringMatch{Regex: getCookieRegex(strings.TrimPrefix(key, cookieMatchPrefix),
+				value)}
+			if match.Headers == nil {
+				match.Headers = map[string]*kubernetes.StringMatch{}
+			}
+			match.Headers[cookieHeaderName] = cookieMatch
+		default:
+			return match, fmt.Errorf("invalid match expression %q, expected one of header:<name>, cookie:<name>, "+
+				"query:<name> or uri but found %q", expression, key)
+		}
+	}
+	return match, nil
+}
+
+func parseMatchCondition(condition string) (string, *kubernetes.StringMatch, error) {
+	index := strings.Index(condition, "=")
+	if index < 0 {
+		return "", nil, fmt.Errorf("expected one of =, ^= or ~= in condition %q", condition)
+	}
+	key := strings.TrimSpace(condition[:index])
+	value := strings.TrimSpace(condition[index+1:])
+	var match *kubernetes.StringMatch
+	switch {
+	case strings.HasSuffix(key, "^"):
+		key = strings.TrimSpace(strings.TrimSuffix(key, "^"))
+		match = &kubernetes.StringMatch{Prefix: value}
+	case strings.HasSuffix(key, "~"):
+		key = strings.TrimSpace(strings.TrimSuffix(key, "~"))
+		if _, err := regexp.Compile(value); err != nil {
+			return "", nil, err
+		}
+		match = &kubernetes.StringMatch{Regex: value}
+	default:
+		match = &kubernetes.StringMatch{Exact: value}
+	}
+	if value == "" {
+		return "", nil, fmt.Errorf("no value given in condition %q", condition)
+	}
+	if key == "" || strings.HasSuffix(key, ":") {
+		return "", nil, fmt.Errorf("no name given in condition %q", condition)
+	}
+	return key, match, nil
+}
+
+func getCookieRegex(name string, value *kubernetes.StringMatch) string {
+	var valueRegex string
+	if value.Exact != "" {
+		valueRegex = regexp.QuoteMeta(value.Exact)
+	} else if value.Prefix != "" {
+		valueRegex = regexp.QuoteMeta(value.Prefix) + "[^;]*"
+	} else {
+		valueRegex = "(" + value.Regex + ")"
+	}
+	return fmt.Sprintf(`^(.*;\s*)?%s=%s(;.*)?$`, regexp.QuoteMeta(name), valueRegex)
+}
+
+// withoutMatchRules removes the rules added for the matches of a previous traffic routing.
+func withoutMatchRules(rules []kubernetes.HTTP) []kubernetes.HTTP {
+	var filtered []kubernetes.HTTP
+	for _, rule := range rules {
+		if rule.Name != matchRuleName {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered
+}
+
+// routeMatchingRequests adds a rule for the requests matching any of the given matches ahead of each rule routing
+// to the dependency. Matching requests are routed by the given percentage while the rest of the requests are
+// routed to the dependency. routesFor returns nil if the rule does not route to the dependency.
+func routeMatchingRequests(rules []kubernetes.HTTP, matches []kubernetes.HTTPMatch, percentageForTarget int,
+	routesFor func(rule *kubernetes.HTTP, percentage int) *[]kubernetes.HTTPRoute) []kubernetes.HTTP {
+	var modified []kubernetes.HTTP
+	for _, rule := range rules {
+		routes := routesFor(&rule, percentageForTarget)
+		if routes == nil {
+			modified = append(modified, rule)
+			continue
+		}
+		modified = append(modified, kubernetes.HTTP{
+			Name:    matchRuleName,
+			Match:   combineMatches(rule.Match, matches),
+			Route:   *routes,
+			Timeout: rule.Timeout,
+			Retries: rule.Retries,
+		})
+		rule.Route = *routesFor(&rule, 0)
+		modified = append(modified, rule)
+	}
+	return modified
+}
+
+// combineMatches returns the matches which hold when both one of the rule matches and one of the user given
+// matches hold. A rule without matches holds for all requests, hence only the user given matches are returned.
+func combineMatches(ruleMatches []kubernetes.HTTPMatch, matches []kubernetes.HTTPMatch) []kubernetes.HTTPMatch {
+	if len(ruleMatches) == 0 {
+		return append([]kubernetes.HTTPMatch{}, matches...)
+	}
+	var combined []kubernetes.HTTPMatch
+	for _, ruleMatch := range ruleMatches {
+		for _, match := range matches {
+			uri := match.Uri
+			if uri == nil {
+				uri = ruleMatch.Uri
+			}
+			combined = append(combined, kubernetes.HTTPMatch{
+				Authority:    ruleMatch.Authority,
+				SourceLabels: ruleMatch.SourceLabels,
+				Headers:      mergeStringMatches(ruleMatch.Headers, match.Headers),
+				Uri:          uri,
+				QueryParams:  mergeStringMatches(ruleMatch.QueryParams, match.QueryParams),
+			})
+		}
+	}
+	return combined
+}
+
+// mergeStringMatches returns the matches of both maps, preferring the user given matches for the same name.
+func mergeStringMatches(ruleMatches, matches map[string]*kubernetes.StringMatch) map[string]*kubernetes.StringMatch {
+	if len(ruleMatches)+len(matches) == 0 {
+		return nil
+	}
+	merged := make(map[string]*kubernetes.StringMatch, len(ruleMatches)+len(matches))
+	for name, value := range ruleMatches {
+		merged[name] = value
+	}
+	for name, value := range matches {
+		merged[name] = value
+	}
+	return merged
+}
@@ -0,0 +1,336 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cellery.io/cellery/components/cli/internal/test"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+func TestParseMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       kubernetes.HTTPMatch
+		wantErr    string
+	}{
+		{
+			name:       "exact header",
+			expression: "header:X-Tenant=beta",
+			want: kubernetes.HTTPMatch{
+				Headers: map[string]*kubernetes.StringMatch{"x-tenant": {Exact: "beta"}},
+			},
+		},
+		{
+			name:       "uri prefix and query regex",
+			expression: "uri^=/api/v2 & query:debug~=^(1|true)$",
+			want: kubernetes.HTTPMatch{
+				Uri:         &kubernetes.StringMatch{Prefix: "/api/v2"},
+				QueryParams: map[string]*kubernetes.StringMatch{"debug": {Regex: "^(1|true)$"}},
+			},
+		},
+		{
+			name:       "query prefix",
+			expression: "query:version^=2.",
+			want: kubernetes.HTTPMatch{
+				QueryParams: map[string]*kubernetes.StringMatch{"version": {Regex: `^2\..*`}},
+			},
+		},
+		{
+			name:       "cookie",
+			expression: "cookie:canary=true",
+			want: kubernetes.HTTPMatch{
+				Headers: map[string]*kubernetes.StringMatch{"cookie": {Regex: `^(.*;\s*)?canary=true(;.*)?$`}},
+			},
+		},
+		{
+			name:       "missing operator",
+			expression: "header:x-tenant",
+			wantErr: `invalid match expression "header:x-tenant", expected one of =, ^= or ~= in condition ` +
+				`"header:x-tenant"`,
+		},
+		{
+			name:       "missing value",
+			expression: "uri^=",
+			wantErr:    `invalid match expression "uri^=", no value given in condition "uri^="`,
+		},
+		{
+			name:       "missing name",
+			expression: "header:=beta",
+			wantErr:    `invalid match expression "header:=beta", no name given in condition "header:=beta"`,
+		},
+		{
+			name:       "empty condition",
+			expression: "header:x-tenant=beta&",
+			wantErr:    `invalid match expression "header:x-tenant=beta&", expected one of =, ^= or ~= in condition ""`,
+		},
+		{
+			name:       "unknown key",
+			expression: "method=GET",
+			wantErr: `invalid match expression "method=GET", expected one of header:<name>, cookie:<name>, ` +
+				`query:<name> or uri but found "method"`,
+		},
+		{
+			name:       "invalid regex",
+			expression: "uri~=(",
+			wantErr:    "invalid match expression \"uri~=(\", error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:       "cookie header",
+			expression: "header:Cookie=canary=true",
+			wantErr:    `invalid match expression "header:Cookie=canary=true", use cookie:<name> to match cookies`,
+		},
+		{
+			name:       "multiple cookies",
+			expression: "cookie:canary=true&cookie:tenant=beta",
+			wantErr: `invalid match expression "cookie:canary=true&cookie:tenant=beta", only one cookie can be ` +
+				`matched`,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			match, err := ParseMatch(tst.expression)
+			if tst.wantErr != "" {
+				if err == nil {
+					t.Fatalf("ParseMatch: expected error %q", tst.wantErr)
+				}
+				if diff := cmp.Diff(tst.wantErr, err.Error()); diff != "" {
+					t.Errorf("ParseMatch: unexpected error (-want, +got)\n%v", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in ParseMatch, %v", err)
+			}
+			if diff := cmp.Diff(tst.want, match); diff != "" {
+				t.Errorf("ParseMatch: unexpected match (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestCombineMatches(t *testing.T) {
+	tenantMatch := kubernetes.HTTPMatch{
+		Headers: map[string]*kubernetes.StringMatch{"x-tenant": {Exact: "beta"}},
+	}
+	apiMatch := kubernetes.HTTPMatch{
+		Uri: &kubernetes.StringMatch{Prefix: "/api"},
+	}
+	tests := []struct {
+		name        string
+		ruleMatches []kubernetes.HTTPMatch
+		matches     []kubernetes.HTTPMatch
+		want        []kubernetes.HTTPMatch
+	}{
+		{
+			name:    "rule without matches",
+			matches: []kubernetes.HTTPMatch{tenantMatch, apiMatch},
+			want:    []kubernetes.HTTPMatch{tenantMatch, apiMatch},
+		},
+		{
+			name: "rule matching the source and authority",
+			ruleMatches: []kubernetes.HTTPMatch{{
+				Authority:    kubernetes.Authority{Regex: "^(pet-be)(--gateway-service)(\\S*)$"},
+				SourceLabels: map[string]string{"mesh.cellery.io/cell": "pet-fe"},
+				Headers:      map[string]*kubernetes.StringMatch{"x-instance-id": {Exact: "1"}},
+			}},
+			matches: []kubernetes.HTTPMatch{tenantMatch},
+			want: []kubernetes.HTTPMatch{{
+				Authority:    kubernetes.Authority{Regex: "^(pet-be)(--gateway-service)(\\S*)$"},
+				SourceLabels: map[string]string{"mesh.cellery.io/cell": "pet-fe"},
+				Headers: map[string]*kubernetes.StringMatch{"x-instance-id": {Exact: "1"},
+					"x-tenant": {Exact: "beta"}},
+			}},
+		},
+		{
+			name: "rule matching a uri",
+			ruleMatches: []kubernetes.HTTPMatch{{
+				Uri:         &kubernetes.StringMatch{Prefix: "/orders"},
+				QueryParams: map[string]*kubernetes.StringMatch{"region": {Exact: "eu"}},
+			}},
+			matches: []kubernetes.HTTPMatch{tenantMatch, apiMatch},
+			want: []kubernetes.HTTPMatch{
+				{
+					Headers:     map[string]*kubernetes.StringMatch{"x-tenant": {Exact: "beta"}},
+					Uri:         &kubernetes.StringMatch{Prefix: "/orders"},
+					QueryParams: map[string]*kubernetes.StringMatch{"region": {Exact: "eu"}},
+				},
+				{
+					Uri:         &kubernetes.StringMatch{Prefix: "/api"},
+					QueryParams: map[string]*kubernetes.StringMatch{"region": {Exact: "eu"}},
+				},
+			},
+		},
+		{
+			name: "multiple rule matches",
+			ruleMatches: []kubernetes.HTTPMatch{
+				{SourceLabels: map[string]string{"mesh.cellery.io/cell": "pet-fe"}},
+				{SourceLabels: map[string]string{"mesh.cellery.io/cell": "pet-admin"}},
+			},
+			matches: []kubernetes.HTTPMatch{apiMatch},
+			want: []kubernetes.HTTPMatch{
+				{SourceLabels: map[string]string{"mesh.cellery.io/cell": "pet-fe"}, Uri: apiMatch.Uri},
+				{SourceLabels: map[string]string{"mesh.cellery.io/cell": "pet-admin"}, Uri: apiMatch.Uri},
+			},
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			if diff := cmp.Diff(tst.want, combineMatches(tst.ruleMatches, tst.matches)); diff != "" {
+				t.Errorf("combineMatches: unexpected matches (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestRouteMatchingRequests(t *testing.T) {
+	tenantMatch := kubernetes.HTTPMatch{
+		Headers: map[string]*kubernetes.StringMatch{"x-tenant": {Exact: "beta"}},
+	}
+	sourceMatch := kubernetes.HTTPMatch{
+		SourceLabels: map[string]string{"mesh.cellery.io/cell": "pet-fe"},
+	}
+	port := &kubernetes.PortSelector{Number: 8080}
+	otherRule := kubernetes.HTTP{
+		Match: []kubernetes.HTTPMatch{sourceMatch},
+		Route: []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{Host: "stock--gateway-service"}}},
+	}
+	tests := []struct {
+		name  string
+		kind  string
+		rules []kubernetes.HTTP
+		want  []kubernetes.HTTP
+	}{
+		{
+			name: "gateway target routed without matches",
+			kind: "Cell",
+			rules: []kubernetes.HTTP{{
+				Route:   []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{Host: "pet-be--gateway-service"}}},
+				Timeout: "5s",
+			}, otherRule},
+			want: []kubernetes.HTTP{
+				{
+					Name:  matchRuleName,
+					Match: []kubernetes.HTTPMatch{tenantMatch},
+					Route: []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{
+						Host: "pet-be-v2--gateway-service"}, Weight: 100}},
+					Timeout: "5s",
+				},
+				{
+					Route: []kubernetes.HTTPRoute{
+						{Destination: kubernetes.Destination{Host: "pet-be--gateway-service"}, Weight: 100},
+						{Destination: kubernetes.Destination{Host: "pet-be-v2--gateway-service"}, Weight: 0},
+					},
+					Timeout: "5s",
+				},
+				otherRule,
+			},
+		},
+		{
+			name: "gateway target routed with source matches",
+			kind: "Cell",
+			rules: []kubernetes.HTTP{{
+				Match: []kubernetes.HTTPMatch{sourceMatch},
+				Route: []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{Host: "pet-be--gateway-service",
+					Port: port}}},
+			}},
+			want: []kubernetes.HTTP{
+				{
+					Name: matchRuleName,
+					Match: []kubernetes.HTTPMatch{{SourceLabels: sourceMatch.SourceLabels,
+						Headers: tenantMatch.Headers}},
+					Route: []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{
+						Host: "pet-be-v2--gateway-service", Port: port}, Weight: 100}},
+				},
+				{
+					Match: []kubernetes.HTTPMatch{sourceMatch},
+					Route: []kubernetes.HTTPRoute{
+						{Destination: kubernetes.Destination{Host: "pet-be--gateway-service", Port: port},
+							Weight: 100},
+						{Destination: kubernetes.Destination{Host: "pet-be-v2--gateway-service", Port: port},
+							Weight: 0},
+					},
+				},
+			},
+		},
+		{
+			name: "composite target",
+			kind: "Composite",
+			rules: []kubernetes.HTTP{{
+				Match: []kubernetes.HTTPMatch{sourceMatch},
+				Route: []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{Host: "pet-be--orders-service",
+					Port: port}}},
+			}, otherRule},
+			want: []kubernetes.HTTP{
+				{
+					Name: matchRuleName,
+					Match: []kubernetes.HTTPMatch{{SourceLabels: sourceMatch.SourceLabels,
+						Headers: tenantMatch.Headers}},
+					Route: []kubernetes.HTTPRoute{{Destination: kubernetes.Destination{
+						Host: "pet-be-v2--orders-service", Port: port}, Weight: 100}},
+				},
+				{
+					Match: []kubernetes.HTTPMatch{sourceMatch},
+					Route: []kubernetes.HTTPRoute{
+						{Destination: kubernetes.Destination{Host: "pet-be--orders-service", Port: port},
+							Weight: 100},
+						{Destination: kubernetes.Destination{Host: "pet-be-v2--orders-service", Port: port},
+							Weight: 0},
+					},
+				},
+				otherRule,
+			},
+		},
+	}
+	components := []kubernetes.ComponentTemplate{{Metadata: kubernetes.ComponentTemplateMetadata{Name: "orders"}}}
+	options := RouteOptions{Percentage: 100, Matches: []kubernetes.HTTPMatch{tenantMatch}}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			mockCli := test.NewMockCli(test.SetKubeCli(test.NewMockKubeCli(test.WithVirtualServices(
+				map[string]kubernetes.VirtualService{
+					"pet-fe--vs": {VsSpec: kubernetes.VsSpec{HTTP: tst.rules}},
+				}))))
+			var vs *kubernetes.VirtualService
+			var err error
+			if tst.kind == "Cell" {
+				vs, err = buildRoutesForCellTarget(mockCli, &kubernetes.Cell{
+					CellMetaData: kubernetes.K8SMetaData{Name: "pet-be-v2"},
+				}, "pet-fe", "pet-be", options)
+			} else {
+				vs, err = buildRoutesForCompositeTarget(mockCli, "pet-fe", &kubernetes.Composite{
+					CompositeMetaData: kubernetes.K8SMetaData{Name: "pet-be-v2"},
+					CompositeSpec:     kubernetes.CompositeSpec{ComponentTemplates: components},
+				}, &kubernetes.Composite{
+					CompositeMetaData: kubernetes.K8SMetaData{Name: "pet-be"},
+					CompositeSpec:     kubernetes.CompositeSpec{ComponentTemplates: components},
+				}, options)
+			}
+			if err != nil {
+				t.Fatalf("error in building routes, %v", err)
+			}
+			if diff := cmp.Diff(tst.want, vs.VsSpec.HTTP); diff != "" {
+				t.Errorf("routeMatchingRequests: unexpected rules (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
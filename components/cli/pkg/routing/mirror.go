@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"strings"
+
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+// mirrorRequests mirrors the given percentage of the requests of each rule routing to the dependency to the
+// destination returned for the rule, while the requests are still routed as before. Mirroring is stopped if the
+// percentage is 0. mirrorFor returns nil if the rule does not route to the dependency.
+func mirrorRequests(rules []kubernetes.HTTP, percentage int,
+	mirrorFor func(rule *kubernetes.HTTP) *kubernetes.Destination) []kubernetes.HTTP {
+	for i := range rules {
+		mirror := mirrorFor(&rules[i])
+		if mirror == nil {
+			continue
+		}
+		if percentage == 0 {
+			rules[i].Mirror = nil
+			rules[i].MirrorPercentage = nil
+			continue
+		}
+		rules[i].Mirror = mirror
+		rules[i].MirrorPercentage = &kubernetes.Percent{Value: float64(percentage)}
+	}
+	return rules
+}
+
+// withoutMirrors stops mirroring requests to the target, since the target receives the requests once traffic
+// is routed to it.
+func withoutMirrors(rules []kubernetes.HTTP, targetInst string) []kubernetes.HTTP {
+	for i := range rules {
+		if rules[i].Mirror != nil && strings.HasPrefix(rules[i].Mirror.Host, targetInst) {
+			rules[i].Mirror = nil
+			rules[i].MirrorPercentage = nil
+		}
+	}
+	return rules
+}
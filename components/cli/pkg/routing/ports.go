@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+const protocolHttp = "HTTP"
+const protocolGrpc = "gRPC"
+const protocolTcp = "TCP"
+
+// ProtocolPorts are the ports of the new target of a protocol, to which the traffic is routed.
+type ProtocolPorts struct {
+	Protocol string
+	Ports    []int
+}
+
+// checkForMatchingPorts checks whether the new target exposes the gRPC and TCP ports of the current target,
+// forwarding them to the same component ports.
+func checkForMatchingPorts(currentTarget *kubernetes.Cell, newTarget *kubernetes.Cell) error {
+	currentIngress := &currentTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress
+	newIngress := &newTarget.CellSpec.GateWayTemplate.GatewaySpec.Ingress
+	if err := checkForMatchingIngressPorts(protocolGrpc, currentTarget.CellMetaData.Name, getGrpcPorts(currentIngress),
+		newTarget.CellMetaData.Name, getGrpcPorts(newIngress)); err != nil {
+		return err
+	}
+	return checkForMatchingIngressPorts(protocolTcp, currentTarget.CellMetaData.Name, getTcpPorts(currentIngress),
+		newTarget.CellMetaData.Name, getTcpPorts(newIngress))
+}
+
+func checkForMatchingIngressPorts(protocol string, currentTarget string,
+	currentPorts map[uint32]kubernetes.IngressDestination, newTarget string,
+	newPorts map[uint32]kubernetes.IngressDestination) error {
+	for port, currentDestination := range currentPorts {
+		newDestination, exists := newPorts[port]
+		if !exists {
+			return fmt.Errorf("%s port %d of instance %s is not exposed by instance %s", protocol, port,
+				currentTarget, newTarget)
+		}
+		if newDestination != currentDestination {
+			return fmt.Errorf("%s port %d is forwarded to %s:%d in instance %s, but to %s:%d in instance %s",
+				protocol, port, currentDestination.Host, currentDestination.Port, currentTarget,
+				newDestination.Host, newDestination.Port, newTarget)
+		}
+	}
+	return nil
+}
+
+func getGrpcPorts(ingress *kubernetes.Ingress) map[uint32]kubernetes.IngressDestination {
+	ports := map[uint32]kubernetes.IngressDestination{}
+	for _, api := range ingress.GrpcApis {
+		if api.Port > 0 {
+			ports[api.Port] = api.Destination
+		}
+	}
+	return ports
+}
+
+func getTcpPorts(ingress *kubernetes.Ingress) map[uint32]kubernetes.IngressDestination {
+	ports := map[uint32]kubernetes.IngressDestination{}
+	for _, api := range ingress.TcpApis {
+		if api.Port > 0 {
+			ports[api.Port] = api.Destination
+		}
+	}
+	return ports
+}
+
+// checkForMatchingComponentPorts checks whether the components of the new target expose the gRPC and TCP ports of
+// the components of the current target, forwarding them to the same container ports.
+func checkForMatchingComponentPorts(currentTarget *kubernetes.Composite, newTarget *kubernetes.Composite) error {
+	for _, currentComponent := range currentTarget.CompositeSpec.ComponentTemplates {
+		var newComponent *kubernetes.ComponentTemplate
+		for i, component := range newTarget.CompositeSpec.ComponentTemplates {
+			if component.Metadata.Name == currentComponent.Metadata.Name {
+				newComponent = &newTarget.CompositeSpec.ComponentTemplates[i]
+				break
+			}
+		}
+		if newComponent == nil {
+			// missing components are reported when the routes are built
+			continue
+		}
+	outer:
+		for _, currentPort := range currentComponent.Spec.Ports {
+			protocol := getProtocol(currentPort.Protocol)
+			if protocol != protocolGrpc && protocol != protocolTcp {
+				continue
+			}
+			for _, newPort := range newComponent.Spec.Ports {
+				if newPort.Port != currentPort.Port || getProtocol(newPort.Protocol) != protocol {
+					continue
+				}
+				if newPort.TargetPort != currentPort.TargetPort {
+					return fmt.Errorf("%s port %d of component %s is forwarded to port %d in instance %s, but to "+
+						"port %d in instance %s", protocol, currentPort.Port, currentComponent.Metadata.Name,
+						currentPort.TargetPort, currentTarget.CompositeMetaData.Name, newPort.TargetPort,
+						newTarget.CompositeMetaData.Name)
+				}
+				continue outer
+			}
+			return fmt.Errorf("%s port %d of component %s in instance %s is not exposed by instance %s", protocol,
+				currentPort.Port, currentComponent.Metadata.Name, currentTarget.CompositeMetaData.Name,
+				newTarget.CompositeMetaData.Name)
+		}
+	}
+	return nil
+}
+
+// getCellReroutedPorts returns the ports of the gateway of the cell receiving the traffic, per protocol.
+func getCellReroutedPorts(cell *kubernetes.Cell, options RouteOptions) []ProtocolPorts {
+	ingress := &cell.CellSpec.GateWayTemplate.GatewaySpec.Ingress
+	ports := map[string][]int{}
+	for _, api := range ingress.HttpApis {
+		ports[protocolHttp] = appendPort(ports[protocolHttp], int(api.Port))
+	}
+	for _, api := range ingress.GrpcApis {
+		ports[protocolGrpc] = appendPort(ports[protocolGrpc], int(api.Port))
+	}
+	for _, api := range ingress.TcpApis {
+		ports[protocolTcp] = appendPort(ports[protocolTcp], int(api.Port))
+	}
+	return toProtocolPorts(ports, options)
+}
+
+// getCompositeReroutedPorts returns the ports of the components of the composite receiving the traffic, per
+// protocol.
+func getCompositeReroutedPorts(composite *kubernetes.Composite, options RouteOptions) []ProtocolPorts {
+	ports := map[string][]int{}
+	for _, component := range composite.CompositeSpec.ComponentTemplates {
+		for _, port := range component.Spec.Ports {
+			protocol := getProtocol(port.Protocol)
+			ports[protocol] = appendPort(ports[protocol], int(port.Port))
+		}
+	}
+	return toProtocolPorts(ports, options)
+}
+
+func toProtocolPorts(ports map[string][]int, options RouteOptions) []ProtocolPorts {
+	var protocolPorts []ProtocolPorts
+	for _, protocol := range []string{protocolHttp, protocolGrpc, protocolTcp} {
+		// the requests are inspected for matches and mirroring, hence they do not apply to tcp
+		if protocol == protocolTcp && (options.Mirror || len(options.Matches) > 0) {
+			continue
+		}
+		if len(ports[protocol]) == 0 {
+			continue
+		}
+		sort.Ints(ports[protocol])
+		protocolPorts = append(protocolPorts, ProtocolPorts{Protocol: protocol, Ports: ports[protocol]})
+	}
+	return protocolPorts
+}
+
+func getProtocol(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "grpc":
+		return protocolGrpc
+	case "tcp":
+		return protocolTcp
+	default:
+		return protocolHttp
+	}
+}
+
+func appendPort(ports []int, port int) []int {
+	if port == 0 {
+		return ports
+	}
+	for _, existing := range ports {
+		if existing == port {
+			return ports
+		}
+	}
+	return append(ports, port)
+}
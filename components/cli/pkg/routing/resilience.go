@@ -0,0 +1,216 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cellery.io/cellery/components/cli/cli"
+	errors "cellery.io/cellery/components/cli/pkg/error"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+const destinationRuleKind = "DestinationRule"
+const destinationRuleApiVersion = "networking.istio.io/v1alpha3"
+const destinationRuleResource = "destinationrules.networking.istio.io"
+
+// SetResilience returns the virtual service of the source instance with the timeouts and retries of the policies
+// set on the rules routing to the dependencies, along with the destination rules holding the outlier detection
+// of the dependency hosts. Outlier detection applies to the requests from all the sources of a dependency.
+func SetResilience(cli cli.Cli, source string, policies []kubernetes.DependencyResiliencePolicy) (
+	*kubernetes.VirtualService, []kubernetes.DestinationRule, error) {
+	vs, err := cli.KubeCli().GetVirtualService(getVsName(source))
+	if err != nil {
+		return nil, nil, err
+	}
+	var destinationRules []kubernetes.DestinationRule
+	for _, policy := range policies {
+		var hosts []string
+		for i, rule := range vs.VsSpec.HTTP {
+			if !routesTo(&rule, policy.Instance) {
+				continue
+			}
+			vs.VsSpec.HTTP[i].Timeout = policy.Timeout
+			vs.VsSpec.HTTP[i].Retries = policy.Retries
+			hosts = appendRoutedHosts(hosts, &rule, policy.Instance)
+		}
+		if len(hosts) == 0 {
+			return nil, nil, fmt.Errorf("instance %s does not route traffic to instance %s", source, policy.Instance)
+		}
+		for _, host := range hosts {
+			// destination rules are only needed to clear the outlier detection if it is not set
+			if policy.OutlierDetection == nil {
+				existing, err := getDestinationRule(cli, host)
+				if err != nil {
+					return nil, nil, err
+				}
+				if existing == nil {
+					continue
+				}
+			}
+			destinationRules = append(destinationRules, buildDestinationRule(host, policy.OutlierDetection))
+		}
+	}
+	return &vs, destinationRules, nil
+}
+
+// GetResilience returns the timeouts, retries and outlier detection of the requests from the source instance to
+// its dependencies.
+func GetResilience(cli cli.Cli, source string) (*kubernetes.ResiliencePolicy, error) {
+	dependencies, err := getDependencyInstances(cli, source)
+	if err != nil {
+		return nil, err
+	}
+	vs, err := cli.KubeCli().GetVirtualService(getVsName(source))
+	if err != nil {
+		return nil, err
+	}
+	policy := &kubernetes.ResiliencePolicy{}
+	for _, dependency := range dependencies {
+		dependencyPolicy := kubernetes.DependencyResiliencePolicy{Instance: dependency}
+		for _, rule := range vs.VsSpec.HTTP {
+			if !routesTo(&rule, dependency) {
+				continue
+			}
+			dependencyPolicy.Timeout = rule.Timeout
+			dependencyPolicy.Retries = rule.Retries
+			for _, host := range appendRoutedHosts(nil, &rule, dependency) {
+				destinationRule, err := getDestinationRule(cli, host)
+				if err != nil {
+					return nil, err
+				}
+				if destinationRule != nil && destinationRule.Spec.TrafficPolicy != nil {
+					dependencyPolicy.OutlierDetection = destinationRule.Spec.TrafficPolicy.OutlierDetection
+				}
+			}
+			break
+		}
+		if dependencyPolicy.Timeout != "" || dependencyPolicy.Retries != nil || dependencyPolicy.OutlierDetection != nil {
+			policy.Dependencies = append(policy.Dependencies, dependencyPolicy)
+		}
+	}
+	return policy, nil
+}
+
+// GetTargetDestinationRules returns copies of the destination rules of the dependency hosts for the target hosts
+// routed to by the virtual service, so that the outlier detection of the dependency applies to the target as well.
+// Targets which already have destination rules are left as they are.
+func GetTargetDestinationRules(cli cli.Cli, vs *kubernetes.VirtualService, dependency string, target string) (
+	[]kubernetes.DestinationRule, error) {
+	var targetHosts []string
+	for _, rule := range vs.VsSpec.HTTP {
+		targetHosts = appendRoutedHosts(targetHosts, &rule, target)
+	}
+	var destinationRules []kubernetes.DestinationRule
+	for _, targetHost := range targetHosts {
+		dependencyRule, err := getDestinationRule(cli, dependency+strings.TrimPrefix(targetHost, target))
+		if err != nil {
+			return nil, err
+		}
+		if dependencyRule == nil || dependencyRule.Spec.TrafficPolicy == nil {
+			continue
+		}
+		targetRule, err := getDestinationRule(cli, targetHost)
+		if err != nil {
+			return nil, err
+		}
+		if targetRule == nil {
+			destinationRules = append(destinationRules, buildDestinationRule(targetHost,
+				dependencyRule.Spec.TrafficPolicy.OutlierDetection))
+		}
+	}
+	return destinationRules, nil
+}
+
+func buildDestinationRule(host string, outlierDetection *kubernetes.OutlierDetection) kubernetes.DestinationRule {
+	destinationRule := kubernetes.DestinationRule{
+		Kind:       destinationRuleKind,
+		APIVersion: destinationRuleApiVersion,
+		Metadata:   kubernetes.DestinationRuleMetadata{Name: getDestinationRuleName(host)},
+		Spec:       kubernetes.DestinationRuleSpec{Host: host},
+	}
+	if outlierDetection != nil {
+		destinationRule.Spec.TrafficPolicy = &kubernetes.TrafficPolicy{OutlierDetection: outlierDetection}
+	}
+	return destinationRule
+}
+
+func getDestinationRule(cli cli.Cli, host string) (*kubernetes.DestinationRule, error) {
+	content, err := cli.KubeCli().GetInstanceBytes(destinationRuleResource, getDestinationRuleName(host))
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting destination rule of %s, %v", host, err)
+	}
+	if len(content) == 0 {
+		return nil, nil
+	}
+	destinationRule := &kubernetes.DestinationRule{}
+	if err := json.Unmarshal(content, destinationRule); err != nil {
+		return nil, fmt.Errorf("error parsing destination rule of %s, %v", host, err)
+	}
+	return destinationRule, nil
+}
+
+// appendRoutedHosts appends the hosts of the instance routed to by the rule, which are not in the hosts already.
+func appendRoutedHosts(hosts []string, rule *kubernetes.HTTP, instance string) []string {
+outer:
+	for _, route := range rule.Route {
+		if !strings.HasPrefix(route.Destination.Host, instance+"--") {
+			continue
+		}
+		for _, host := range hosts {
+			if host == route.Destination.Host {
+				continue outer
+			}
+		}
+		hosts = append(hosts, route.Destination.Host)
+	}
+	return hosts
+}
+
+// getDependencyInstances returns the names of the dependency instances of the cell or composite instance.
+func getDependencyInstances(cli cli.Cli, instanceName string) ([]string, error) {
+	var depJson string
+	cellInst, err := cli.KubeCli().GetCell(instanceName)
+	if err != nil {
+		if notFound, _ := errors.IsCellInstanceNotFoundError(instanceName, err); !notFound {
+			return nil, err
+		}
+		compInst, err := cli.KubeCli().GetComposite(instanceName)
+		if err != nil {
+			return nil, err
+		}
+		depJson = compInst.CompositeMetaData.Annotations.Dependencies
+	} else {
+		depJson = cellInst.CellMetaData.Annotations.Dependencies
+	}
+	dependencies, err := ExtractDependencies(depJson)
+	if err != nil {
+		return nil, err
+	}
+	var instances []string
+	for _, dependency := range dependencies {
+		instances = append(instances, dependency[instance])
+	}
+	return instances, nil
+}
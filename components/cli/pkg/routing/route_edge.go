@@ -0,0 +1,273 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cellery.io/cellery/components/cli/cli"
+	"cellery.io/cellery/components/cli/pkg/image"
+	"cellery.io/cellery/components/cli/pkg/kubernetes"
+)
+
+// RouteEdge describes how the requests from a source instance to one of its dependencies are routed.
+type RouteEdge struct {
+	Source string
+	// Dependency is the instance the source sends the requests to
+	Dependency string
+	// Alias is the name the source image refers to the dependency by, it is empty if the source image is not
+	// in the local repository or refers to the dependency image by several aliases
+	Alias   string
+	Targets []RouteTarget
+	// SessionAware is true if the requests of a user session are routed to the same target
+	SessionAware bool
+	// ApisMatch tells whether the APIs of all the targets match, it is nil if there is a single target
+	ApisMatch *bool
+}
+
+// RouteTarget is an instance receiving a share of the requests of a route edge.
+type RouteTarget struct {
+	Instance string
+	Weight   int
+}
+
+// GetRouteEdges returns the route edges of all the cell and composite instances, ordered by the source and
+// the dependency.
+func GetRouteEdges(cli cli.Cli) ([]RouteEdge, error) {
+	var edges []RouteEdge
+	cells, err := cli.KubeCli().GetCells()
+	if err != nil {
+		return nil, err
+	}
+	for _, cell := range cells {
+		cellEdges, err := getInstanceRouteEdges(cli, cell.CellMetaData.Name, &cell.CellMetaData.Annotations)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, cellEdges...)
+	}
+	composites, err := cli.KubeCli().GetComposites()
+	if err != nil {
+		return nil, err
+	}
+	for _, composite := range composites {
+		compositeEdges, err := getInstanceRouteEdges(cli, composite.CompositeMetaData.Name,
+			&composite.CompositeMetaData.Annotations)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, compositeEdges...)
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		return edges[i].Dependency < edges[j].Dependency
+	})
+	return edges, nil
+}
+
+func getInstanceRouteEdges(cli cli.Cli, source string, annotations *kubernetes.CellAnnotations) ([]RouteEdge,
+	error) {
+	dependencies, err := ExtractDependencies(annotations.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	if len(dependencies) == 0 {
+		return nil, nil
+	}
+	vs, err := cli.KubeCli().GetVirtualService(getVsName(source))
+	if err != nil && !strings.Contains(err.Error(), "NotFound") && !strings.Contains(err.Error(), "not found") {
+		return nil, err
+	}
+	aliases := getDependencyAliases(cli, annotations)
+	var edges []RouteEdge
+	for _, dependency := range dependencies {
+		edge := RouteEdge{
+			Source:     source,
+			Dependency: dependency[instance],
+			Alias: aliases[dependencyImage(dependency[imageOrg], dependency[imageName],
+				dependency[imageVersion])],
+			Targets: []RouteTarget{{Instance: dependency[instance], Weight: 100}},
+		}
+		if rule := getDefaultRule(vs.VsSpec.HTTP, dependency[instance]); rule != nil {
+			edge.Dependency = getRequestedInstance(&vs, rule)
+			edge.Targets = getRouteTargets(rule)
+		}
+		edge.SessionAware = isSessionAware(vs.VsSpec.HTTP, edge.Targets)
+		if len(edge.Targets) > 1 {
+			apisMatch := doTargetApisMatch(cli, dependency[dependencyKind], dependency[instance], edge.Targets)
+			edge.ApisMatch = &apisMatch
+		}
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
+
+// getDependencyAliases returns the aliases by which the source image refers to its dependency images, keyed by
+// the dependency image. The aliases are read from the metadata of the source image in the local repository, as
+// the instance does not record them. Images referred to by several aliases are left out as the alias of an
+// instance of them cannot be told.
+func getDependencyAliases(cli cli.Cli, annotations *kubernetes.CellAnnotations) map[string]string {
+	aliases := map[string]string{}
+	metadata, err := image.ReadMetaData(cli.FileSystem().Repository(), annotations.Organization, annotations.Name,
+		annotations.Version)
+	if err != nil {
+		return aliases
+	}
+	ambiguous := map[string]bool{}
+	addAlias := func(alias string, dependency *image.MetaData) {
+		key := dependencyImage(dependency.Organization, dependency.Name, dependency.Version)
+		if existing, ok := aliases[key]; ok && existing != alias {
+			ambiguous[key] = true
+		}
+		aliases[key] = alias
+	}
+	for _, component := range metadata.Components {
+		if component.Dependencies == nil {
+			continue
+		}
+		for alias, dependency := range component.Dependencies.Cells {
+			addAlias(alias, dependency)
+		}
+		for alias, dependency := range component.Dependencies.Composites {
+			addAlias(alias, dependency)
+		}
+	}
+	for key := range ambiguous {
+		delete(aliases, key)
+	}
+	return aliases
+}
+
+func dependencyImage(organization string, name string, version string) string {
+	return fmt.Sprintf("%s/%s:%s", organization, name, version)
+}
+
+// getDefaultRule returns the rule which routes the requests to the dependency which are neither matched by a
+// user given match expression nor by a session header.
+func getDefaultRule(rules []kubernetes.HTTP, dependency string) *kubernetes.HTTP {
+	for i, rule := range rules {
+		if rule.Name == matchRuleName || isSessionHeaderBasedRule(&rule, instanceIdHeaderName) {
+			continue
+		}
+		if routesTo(&rule, dependency) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// getRequestedInstance returns the instance of the virtual service host the rule serves, which is the instance
+// the source sends the requests to.
+func getRequestedInstance(vs *kubernetes.VirtualService, rule *kubernetes.HTTP) string {
+	routedInstance, service := splitHost(rule.Route[0].Destination.Host)
+	for _, host := range vs.VsSpec.Hosts {
+		if hostInstance, hostService := splitHost(host); hostService == service {
+			return hostInstance
+		}
+	}
+	return routedInstance
+}
+
+func getRouteTargets(rule *kubernetes.HTTP) []RouteTarget {
+	var targets []RouteTarget
+outer:
+	for _, route := range rule.Route {
+		targetInstance, _ := splitHost(route.Destination.Host)
+		weight := route.Weight
+		if len(rule.Route) == 1 && weight == 0 {
+			weight = 100
+		}
+		for i := range targets {
+			if targets[i].Instance == targetInstance {
+				targets[i].Weight += weight
+				continue outer
+			}
+		}
+		targets = append(targets, RouteTarget{Instance: targetInstance, Weight: weight})
+	}
+	return targets
+}
+
+// isSessionAware returns true if the session header based rules route the requests to more than one of the
+// targets.
+func isSessionAware(rules []kubernetes.HTTP, targets []RouteTarget) bool {
+	routed := map[string]bool{}
+	for _, rule := range rules {
+		if !isSessionHeaderBasedRule(&rule, instanceIdHeaderName) {
+			continue
+		}
+		for _, target := range targets {
+			if routesTo(&rule, target.Instance) {
+				routed[target.Instance] = true
+			}
+		}
+	}
+	return len(routed) > 1
+}
+
+// doTargetApisMatch returns true if the APIs of all the targets match the APIs of the dependency. Targets which
+// cannot be read are considered not to match.
+func doTargetApisMatch(cli cli.Cli, kind string, dependency string, targets []RouteTarget) bool {
+	for _, target := range targets {
+		if target.Instance == dependency {
+			continue
+		}
+		if kind == compositeDependencyKind {
+			dependencyInst, err := cli.KubeCli().GetComposite(dependency)
+			if err != nil {
+				return false
+			}
+			targetInst, err := cli.KubeCli().GetComposite(target.Instance)
+			if err != nil {
+				return false
+			}
+			if !doComponentsMatch(&dependencyInst.CompositeSpec.ComponentTemplates,
+				&targetInst.CompositeSpec.ComponentTemplates) {
+				return false
+			}
+			continue
+		}
+		dependencyInst, err := cli.KubeCli().GetCell(dependency)
+		if err != nil {
+			return false
+		}
+		targetInst, err := cli.KubeCli().GetCell(target.Instance)
+		if err != nil {
+			return false
+		}
+		if checkForMatchingApis(&dependencyInst, &targetInst) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// splitHost splits a service host into the instance and the service, e.g. hr--gateway-service into hr and
+// gateway-service.
+func splitHost(host string) (string, string) {
+	parts := strings.SplitN(host, "--", 2)
+	if len(parts) < 2 {
+		return host, ""
+	}
+	return parts[0], parts[1]
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package stack
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Action is the change done to an instance to converge the cluster to the stack.
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionUnchanged Action = "unchanged"
+	ActionDelete    Action = "delete"
+)
+
+// RunningInstance is a cell/composite instance running in the cluster.
+type RunningInstance struct {
+	Name string
+	// Kind is the kubernetes resource of the instance, cells.mesh.cellery.io or composites.mesh.cellery.io
+	Kind string
+	// Image is the cell image of the instance in the format <organization>/<name>:<version>
+	Image string
+	// Stack is the name of the stack which owns the instance, empty if it is not created from a stack
+	Stack    string
+	Revision string
+}
+
+// Change is a change to be done to an instance.
+type Change struct {
+	Action Action
+	Name   string
+	// Instance is the desired state of the instance, nil if the instance is deleted
+	Instance *Instance
+	// Current is the running instance, nil if the instance is created
+	Current *RunningInstance
+	// Revision is the revision of the desired instance
+	Revision string
+}
+
+// Plan compares the stack with the running instances and returns the changes required to converge the cluster
+// to the stack. Instances are created and updated in the dependency order, and running instances of the stack
+// which are removed from the stack file are deleted.
+func (stack *Stack) Plan(running []*RunningInstance) ([]*Change, error) {
+	runningInstances := map[string]*RunningInstance{}
+	for _, instance := range running {
+		runningInstances[instance.Name] = instance
+	}
+	ordered, err := stack.Order()
+	if err != nil {
+		return nil, err
+	}
+	var changes []*Change
+	desired := map[string]bool{}
+	for _, instance := range ordered {
+		desired[instance.Name] = true
+		revision, err := instance.Revision(stack)
+		if err != nil {
+			return nil, err
+		}
+		change := &Change{
+			Action:   ActionCreate,
+			Name:     instance.Name,
+			Instance: instance,
+			Revision: revision,
+		}
+		if current, ok := runningInstances[instance.Name]; ok {
+			if current.Stack != "" && current.Stack != stack.Name {
+				return nil, fmt.Errorf("instance %s belongs to stack %s", instance.Name, current.Stack)
+			}
+			change.Current = current
+			if current.Stack == stack.Name && current.Revision == revision {
+				change.Action = ActionUnchanged
+			} else {
+				change.Action = ActionUpdate
+			}
+		}
+		changes = append(changes, change)
+	}
+	var removed []*Change
+	for _, instance := range running {
+		if instance.Stack == stack.Name && !desired[instance.Name] {
+			removed = append(removed, &Change{
+				Action:  ActionDelete,
+				Name:    instance.Name,
+				Current: instance,
+			})
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].Name < removed[j].Name
+	})
+	return append(changes, removed...), nil
+}
+
+// Teardown returns the changes required to delete the running instances owned by the stack. Instances are
+// deleted in the reverse dependency order. Instances with the same name which are not created from the stack
+// are left untouched.
+func (stack *Stack) Teardown(running []*RunningInstance) ([]*Change, error) {
+	ordered, err := stack.Order()
+	if err != nil {
+		return nil, err
+	}
+	owned := map[string]*RunningInstance{}
+	for _, instance := range running {
+		if instance.Stack == stack.Name {
+			owned[instance.Name] = instance
+		}
+	}
+	var changes []*Change
+	for i := len(ordered) - 1; i >= 0; i-- {
+		if current, ok := owned[ordered[i].Name]; ok {
+			changes = append(changes, &Change{
+				Action:   ActionDelete,
+				Name:     current.Name,
+				Instance: ordered[i],
+				Current:  current,
+			})
+			delete(owned, current.Name)
+		}
+	}
+	// Instances removed from the stack file after they were created
+	var removed []*Change
+	for _, current := range owned {
+		removed = append(removed, &Change{
+			Action:  ActionDelete,
+			Name:    current.Name,
+			Current: current,
+		})
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].Name < removed[j].Name
+	})
+	return append(removed, changes...), nil
+}
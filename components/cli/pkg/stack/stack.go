@@ -0,0 +1,229 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package stack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"cellery.io/cellery/components/cli/pkg/constants"
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+// Labels added to the instances created from a stack
+const (
+	// LabelStack holds the name of the stack which owns the instance
+	LabelStack = "stack.cellery.io/name"
+	// LabelRevision holds the hash of the instance definition in the stack, used to detect changes
+	LabelRevision = "stack.cellery.io/revision"
+)
+
+const stackKind = "Stack"
+
+// Stack is a set of cell/composite instances deployed and managed together.
+type Stack struct {
+	Kind      string      `json:"kind"`
+	Name      string      `json:"name"`
+	Instances []*Instance `json:"instances"`
+	Routes    []*Route    `json:"routes,omitempty"`
+	// dir is the directory of the stack file, used to resolve the relative paths in the stack
+	dir string
+}
+
+// Instance is a cell/composite instance of the stack.
+type Instance struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	// Links maps a dependency alias ([<parent-instance>.]<alias>) to the dependency instance
+	Links map[string]string `json:"links,omitempty"`
+	// Env holds the environment variables passed to the cellery run method of the instance
+	Env map[string]string `json:"env,omitempty"`
+	// AutoscalePolicy is the path of an autoscale policy file applied to the instance
+	AutoscalePolicy string `json:"autoscalePolicy,omitempty"`
+}
+
+// Route routes a percentage of the traffic to a dependency instance to a target instance.
+type Route struct {
+	// Sources are the instances of which the traffic is routed. All instances depending on the dependency
+	// instance are considered if not specified.
+	Sources      []string `json:"sources,omitempty"`
+	Dependency   string   `json:"dependency"`
+	Target       string   `json:"target"`
+	Percentage   int      `json:"percentage"`
+	SessionAware bool     `json:"sessionAware,omitempty"`
+}
+
+// Load reads and validates a stack file.
+func Load(file string) (*Stack, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading stack file %s, %v", file, err)
+	}
+	stack := &Stack{}
+	if err := yaml.Unmarshal(content, stack); err != nil {
+		return nil, fmt.Errorf("error parsing stack file %s, %v", file, err)
+	}
+	if err := stack.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid stack file %s, %v", file, err)
+	}
+	stack.dir = filepath.Dir(file)
+	return stack, nil
+}
+
+// Validate checks the stack for missing fields and invalid references between instances.
+func (stack *Stack) Validate() error {
+	if stack.Kind != stackKind {
+		return fmt.Errorf("expects kind %s, received %q", stackKind, stack.Kind)
+	}
+	idPattern := regexp.MustCompile(fmt.Sprintf("^%s$", constants.CelleryIdPattern))
+	if !idPattern.MatchString(stack.Name) {
+		return fmt.Errorf("expects a valid stack name, received %q", stack.Name)
+	}
+	if len(stack.Instances) == 0 {
+		return fmt.Errorf("stack %s does not have any instances", stack.Name)
+	}
+	instances := map[string]bool{}
+	for _, instance := range stack.Instances {
+		if !idPattern.MatchString(instance.Name) {
+			return fmt.Errorf("expects a valid instance name, received %q", instance.Name)
+		}
+		if instances[instance.Name] {
+			return fmt.Errorf("instance %s is defined more than once", instance.Name)
+		}
+		instances[instance.Name] = true
+		if err := image.ValidateImageTagWithRegistry(instance.Image); err != nil {
+			return fmt.Errorf("invalid image of instance %s, %v", instance.Name, err)
+		}
+		linkPattern := regexp.MustCompile(fmt.Sprintf("^%s$", constants.DependencyLinkPattern))
+		for alias, dependency := range instance.Links {
+			if !linkPattern.MatchString(alias + ":" + dependency) {
+				return fmt.Errorf("invalid link %s: %s of instance %s", alias, dependency, instance.Name)
+			}
+		}
+	}
+	for _, route := range stack.Routes {
+		if route.Percentage < 0 || route.Percentage > 100 {
+			return fmt.Errorf("expects a percentage between 0 and 100 for route to %s, received %d",
+				route.Target, route.Percentage)
+		}
+		if route.Dependency == "" || route.Target == "" {
+			return fmt.Errorf("expects both dependency and target instances in routes")
+		}
+	}
+	if _, err := stack.Order(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Order returns the instances of the stack sorted such that the instances linked by an instance are placed
+// before it. Links to instances which are not in the stack are expected to be running already.
+func (stack *Stack) Order() ([]*Instance, error) {
+	instances := map[string]*Instance{}
+	for _, instance := range stack.Instances {
+		instances[instance.Name] = instance
+	}
+	var ordered []*Instance
+	visited := map[string]bool{}
+	visiting := map[string]bool{}
+	var visit func(instance *Instance) error
+	visit = func(instance *Instance) error {
+		if visited[instance.Name] {
+			return nil
+		}
+		if visiting[instance.Name] {
+			return fmt.Errorf("cyclic links found in instance %s", instance.Name)
+		}
+		visiting[instance.Name] = true
+		for _, link := range instance.LinkArgs() {
+			if dependency, ok := instances[link[strings.LastIndex(link, ":")+1:]]; ok {
+				if err := visit(dependency); err != nil {
+					return err
+				}
+			}
+		}
+		visiting[instance.Name] = false
+		visited[instance.Name] = true
+		ordered = append(ordered, instance)
+		return nil
+	}
+	for _, instance := range stack.Instances {
+		if err := visit(instance); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// LinkArgs returns the links of the instance in the format accepted by cellery run.
+func (instance *Instance) LinkArgs() []string {
+	var links []string
+	for alias, dependency := range instance.Links {
+		links = append(links, alias+":"+dependency)
+	}
+	sort.Strings(links)
+	return links
+}
+
+// EnvArgs returns the environment variables of the instance in the format accepted by cellery run.
+func (instance *Instance) EnvArgs() []string {
+	var envVars []string
+	for key, value := range instance.Env {
+		envVars = append(envVars, key+"="+value)
+	}
+	sort.Strings(envVars)
+	return envVars
+}
+
+// Revision returns a hash of the instance definition. The hash changes when the image, links, environment
+// variables or the autoscale policy of the instance change.
+func (instance *Instance) Revision(stack *Stack) (string, error) {
+	definition, err := json.Marshal(instance)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write(definition)
+	if instance.AutoscalePolicy != "" {
+		policy, err := ioutil.ReadFile(stack.Path(instance.AutoscalePolicy))
+		if err != nil {
+			return "", fmt.Errorf("error reading autoscale policy of instance %s, %v", instance.Name, err)
+		}
+		hash.Write(policy)
+	}
+	// Labels values are limited to 63 characters
+	return hex.EncodeToString(hash.Sum(nil))[:32], nil
+}
+
+// Path resolves a path in the stack file relative to the directory of the stack file.
+func (stack *Stack) Path(path string) string {
+	if filepath.IsAbs(path) || stack.dir == "" {
+		return path
+	}
+	return filepath.Join(stack.dir, path)
+}
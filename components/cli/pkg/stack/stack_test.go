@@ -0,0 +1,187 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package stack
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadAndOrder(t *testing.T) {
+	stack, err := Load(filepath.Join("testdata", "stack.yaml"))
+	if err != nil {
+		t.Fatalf("error in Load, %v", err)
+	}
+	ordered, err := stack.Order()
+	if err != nil {
+		t.Fatalf("error in Order, %v", err)
+	}
+	var names []string
+	for _, instance := range ordered {
+		names = append(names, instance.Name)
+	}
+	expected := []string{"salary-inst", "employee-inst", "stock-inst", "hr-inst"}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("Order: unexpected order (-want, +got)\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{"employeeCellDep:employee-inst", "stockCellDep:stock-inst"},
+		ordered[3].LinkArgs()); diff != "" {
+		t.Errorf("LinkArgs: unexpected links (-want, +got)\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{"mode=dev"}, ordered[3].EnvArgs()); diff != "" {
+		t.Errorf("EnvArgs: unexpected environment variables (-want, +got)\n%v", diff)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		stack       *Stack
+		expectedErr string
+	}{
+		{
+			name: "invalid kind",
+			stack: &Stack{Kind: "Cell", Name: "foo",
+				Instances: []*Instance{{Name: "foo", Image: "myorg/foo:1.0.0"}}},
+			expectedErr: `expects kind Stack, received "Cell"`,
+		},
+		{
+			name:        "no instances",
+			stack:       &Stack{Kind: "Stack", Name: "foo"},
+			expectedErr: "stack foo does not have any instances",
+		},
+		{
+			name: "duplicate instance",
+			stack: &Stack{Kind: "Stack", Name: "foo", Instances: []*Instance{
+				{Name: "foo", Image: "myorg/foo:1.0.0"},
+				{Name: "foo", Image: "myorg/bar:1.0.0"},
+			}},
+			expectedErr: "instance foo is defined more than once",
+		},
+		{
+			name: "invalid image",
+			stack: &Stack{Kind: "Stack", Name: "foo", Instances: []*Instance{
+				{Name: "foo", Image: "foo"},
+			}},
+			expectedErr: "invalid image of instance foo, expects [<registry>]/<organization>/<cell-image>:<version> " +
+				"as the tag, received foo",
+		},
+		{
+			name: "cyclic links",
+			stack: &Stack{Kind: "Stack", Name: "foo", Instances: []*Instance{
+				{Name: "foo", Image: "myorg/foo:1.0.0", Links: map[string]string{"barDep": "bar"}},
+				{Name: "bar", Image: "myorg/bar:1.0.0", Links: map[string]string{"fooDep": "foo"}},
+			}},
+			expectedErr: "cyclic links found in instance foo",
+		},
+		{
+			name: "invalid route percentage",
+			stack: &Stack{Kind: "Stack", Name: "foo",
+				Instances: []*Instance{{Name: "foo", Image: "myorg/foo:1.0.0"}},
+				Routes:    []*Route{{Dependency: "foo", Target: "bar", Percentage: 120}},
+			},
+			expectedErr: "expects a percentage between 0 and 100 for route to bar, received 120",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := tst.stack.Validate()
+			if err == nil {
+				t.Fatalf("Validate: expected error %q", tst.expectedErr)
+			}
+			if diff := cmp.Diff(tst.expectedErr, err.Error()); diff != "" {
+				t.Errorf("Validate: unexpected error (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	stack, err := Load(filepath.Join("testdata", "stack.yaml"))
+	if err != nil {
+		t.Fatalf("error in Load, %v", err)
+	}
+	revision := func(name string) string {
+		for _, instance := range stack.Instances {
+			if instance.Name == name {
+				revision, err := instance.Revision(stack)
+				if err != nil {
+					t.Fatalf("error in Revision, %v", err)
+				}
+				return revision
+			}
+		}
+		return ""
+	}
+	running := []*RunningInstance{
+		{Name: "salary-inst", Image: "myorg/salary:1.0.0", Stack: "hr-stack", Revision: revision("salary-inst")},
+		{Name: "employee-inst", Image: "myorg/employee:0.9.0", Stack: "hr-stack", Revision: "outdated"},
+		{Name: "stock-inst", Image: "myorg/stock:1.0.0"},
+		{Name: "old-inst", Image: "myorg/old:1.0.0", Stack: "hr-stack"},
+		{Name: "other-inst", Image: "myorg/other:1.0.0", Stack: "other-stack"},
+	}
+	changes, err := stack.Plan(running)
+	if err != nil {
+		t.Fatalf("error in Plan, %v", err)
+	}
+	var actions []string
+	for _, change := range changes {
+		actions = append(actions, string(change.Action)+" "+change.Name)
+	}
+	expected := []string{
+		"unchanged salary-inst",
+		"update employee-inst",
+		"update stock-inst",
+		"create hr-inst",
+		"delete old-inst",
+	}
+	if diff := cmp.Diff(expected, actions); diff != "" {
+		t.Errorf("Plan: unexpected changes (-want, +got)\n%v", diff)
+	}
+
+	teardown, err := stack.Teardown(running)
+	if err != nil {
+		t.Fatalf("error in Teardown, %v", err)
+	}
+	actions = nil
+	for _, change := range teardown {
+		actions = append(actions, string(change.Action)+" "+change.Name)
+	}
+	expected = []string{
+		"delete old-inst",
+		"delete employee-inst",
+		"delete salary-inst",
+	}
+	if diff := cmp.Diff(expected, actions); diff != "" {
+		t.Errorf("Teardown: unexpected changes (-want, +got)\n%v", diff)
+	}
+}
+
+func TestPlanInstanceOfAnotherStack(t *testing.T) {
+	stack := &Stack{Kind: "Stack", Name: "foo", Instances: []*Instance{{Name: "bar", Image: "myorg/bar:1.0.0"}}}
+	_, err := stack.Plan([]*RunningInstance{{Name: "bar", Image: "myorg/bar:1.0.0", Stack: "other"}})
+	if err == nil {
+		t.Fatalf("Plan: expected error for an instance of another stack")
+	}
+	if diff := cmp.Diff("instance bar belongs to stack other", err.Error()); diff != "" {
+		t.Errorf("Plan: unexpected error (-want, +got)\n%v", diff)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package trust
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// Signature is the detached signature of a cell image. It is stored in the registry along with the image and
+// next to the cell image zip in the local repository.
+type Signature struct {
+	// Payload is the signed content, which is a JSON encoded SignedImage
+	Payload []byte `json:"payload"`
+	// Signature is the signature of the SHA256 hash of the payload
+	Signature []byte `json:"signature"`
+	// KeyId identifies the key used for signing
+	KeyId string `json:"keyId"`
+}
+
+// SignedImage identifies the cell image covered by a signature.
+type SignedImage struct {
+	// Image is the cell image name in the format <organization>/<name>:<version>
+	Image string `json:"image"`
+	// Digest is the digest of the cell image zip
+	Digest digest.Digest `json:"digest"`
+}
+
+// Sign creates a signature for the cell image with the given digest.
+func Sign(signer crypto.Signer, imageName string, imageDigest digest.Digest) ([]byte, error) {
+	payload, err := json.Marshal(&SignedImage{
+		Image:  imageName,
+		Digest: imageDigest,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signature payload, %v", err)
+	}
+	hash := sha256.Sum256(payload)
+	signature, err := signer.Sign(rand.Reader, hash[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign cell image, %v", err)
+	}
+	keyId, err := KeyId(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&Signature{
+		Payload:   payload,
+		Signature: signature,
+		KeyId:     keyId,
+	})
+}
+
+// KeyId returns the identifier of a public key, which is the SHA256 hash of the DER encoded key.
+func KeyId(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("unsupported public key, %v", err)
+	}
+	hash := sha256.Sum256(der)
+	return "sha256:" + hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package trust
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/opencontainers/go-digest"
+
+	"cellery.io/cellery/components/cli/pkg/config"
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+// anyOrganization can be used in the trust store to trust a key for the images of all organizations.
+const anyOrganization = "*"
+
+// Store holds the keys used for signing cell images and the keys trusted for verifying them.
+type Store struct {
+	conf *config.TrustConf
+}
+
+// NewStore returns a trust store backed by the trust configuration.
+func NewStore(conf *config.TrustConf) *Store {
+	if conf == nil {
+		conf = &config.TrustConf{}
+	}
+	return &Store{conf: conf}
+}
+
+// SigningKey returns the private key configured for signing the images of the organization.
+// nil is returned if a signing key is not configured.
+func (store *Store) SigningKey(organization string) (crypto.Signer, error) {
+	keyFile, ok := store.conf.SigningKeys[organization]
+	if !ok {
+		return nil, nil
+	}
+	block, err := readPem(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key of organization %s, %v", organization, err)
+	}
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s, %v", keyFile, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key %s", keyFile)
+	}
+	return signer, nil
+}
+
+// IsVerificationRequired checks whether the images of the organization need to be verified. Images are verified
+// if keys are trusted for the organization or for all organizations. Otherwise an error is returned, unless
+// untrusted organizations are explicitly allowed in the trust configuration.
+func (store *Store) IsVerificationRequired(organization string) (bool, error) {
+	if len(store.conf.TrustedKeys[organization]) > 0 || len(store.conf.TrustedKeys[anyOrganization]) > 0 {
+		return true, nil
+	}
+	if store.conf.AllowUntrustedOrganizations {
+		return false, nil
+	}
+	return false, fmt.Errorf("no trusted keys configured for organization %s. Trust a key for the organization "+
+		"in the trust section of the Cellery config or use --insecure-skip-verify to skip the verification",
+		organization)
+}
+
+// VerifyImage verifies the signature of a cell image zip against the keys trusted for its organization.
+func (store *Store) VerifyImage(cellImage *image.CellImage, cellImageFile string, signature []byte) error {
+	if len(signature) == 0 {
+		return fmt.Errorf("image %s is not signed", imageName(cellImage))
+	}
+	file, err := os.Open(cellImageFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	imageDigest, err := digest.SHA256.FromReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to calculate digest of image %s, %v", imageName(cellImage), err)
+	}
+	return store.Verify(cellImage, imageDigest, signature)
+}
+
+// Verify verifies that the signature covers the cell image with the given digest and that it was created by a
+// key trusted for the organization of the image.
+func (store *Store) Verify(cellImage *image.CellImage, imageDigest digest.Digest, signature []byte) error {
+	sig := &Signature{}
+	if err := json.Unmarshal(signature, sig); err != nil {
+		return fmt.Errorf("invalid signature, %v", err)
+	}
+	keys, err := store.trustedKeys(cellImage.Organization)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no trusted keys configured for organization %s", cellImage.Organization)
+	}
+	publicKey, ok := keys[sig.KeyId]
+	if !ok {
+		return fmt.Errorf("image %s is signed with an untrusted key %s", imageName(cellImage), sig.KeyId)
+	}
+	hash := sha256.Sum256(sig.Payload)
+	if !verifySignature(publicKey, hash[:], sig.Signature) {
+		return fmt.Errorf("invalid signature for image %s", imageName(cellImage))
+	}
+	signedImage := &SignedImage{}
+	if err := json.Unmarshal(sig.Payload, signedImage); err != nil {
+		return fmt.Errorf("invalid signature payload, %v", err)
+	}
+	if signedImage.Image != imageName(cellImage) {
+		return fmt.Errorf("signature is for image %s, not %s", signedImage.Image, imageName(cellImage))
+	}
+	if signedImage.Digest != imageDigest {
+		return fmt.Errorf("image %s has been modified, expected digest %s but found %s", imageName(cellImage),
+			signedImage.Digest, imageDigest)
+	}
+	return nil
+}
+
+// trustedKeys returns the public keys trusted for the organization mapped by the key id.
+func (store *Store) trustedKeys(organization string) (map[string]crypto.PublicKey, error) {
+	keys := map[string]crypto.PublicKey{}
+	// Copying the keys of the organization to avoid appending to the slice held by the configuration
+	keyFiles := append([]string{}, store.conf.TrustedKeys[organization]...)
+	keyFiles = append(keyFiles, store.conf.TrustedKeys[anyOrganization]...)
+	for _, keyFile := range keyFiles {
+		block, err := readPem(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trusted key, %v", err)
+		}
+		var publicKey crypto.PublicKey
+		if block.Type == "CERTIFICATE" {
+			var certificate *x509.Certificate
+			if certificate, err = x509.ParseCertificate(block.Bytes); err == nil {
+				publicKey = certificate.PublicKey
+			}
+		} else {
+			publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse trusted key %s, %v", keyFile, err)
+		}
+		keyId, err := KeyId(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		keys[keyId] = publicKey
+	}
+	return keys, nil
+}
+
+func verifySignature(publicKey crypto.PublicKey, hash, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, hash, signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash, signature) == nil
+	default:
+		return false
+	}
+}
+
+func readPem(file string) (*pem.Block, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM encoded file", file)
+	}
+	return block, nil
+}
+
+func imageName(cellImage *image.CellImage) string {
+	return fmt.Sprintf("%s/%s:%s", cellImage.Organization, cellImage.ImageName, cellImage.ImageVersion)
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright (c) 2020 WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+ *
+ * WSO2 Inc. licenses this file to you under the Apache License,
+ * Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package trust
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/opencontainers/go-digest"
+
+	"cellery.io/cellery/components/cli/pkg/config"
+	"cellery.io/cellery/components/cli/pkg/image"
+)
+
+// writeKeyPair generates an ECDSA key pair and writes the PEM encoded keys to the directory.
+func writeKeyPair(t *testing.T, dir, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key, %v", err)
+	}
+	privateKey, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal private key, %v", err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("failed to marshal public key, %v", err)
+	}
+	privateKeyFile := filepath.Join(dir, name+".key")
+	publicKeyFile := filepath.Join(dir, name+".pub")
+	if err := ioutil.WriteFile(privateKeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY",
+		Bytes: privateKey}), 0600); err != nil {
+		t.Fatalf("failed to write private key, %v", err)
+	}
+	if err := ioutil.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY",
+		Bytes: publicKey}), 0644); err != nil {
+		t.Fatalf("failed to write public key, %v", err)
+	}
+	return privateKeyFile, publicKeyFile
+}
+
+func TestVerify(t *testing.T) {
+	keyDir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatalf("failed to create key dir, %v", err)
+	}
+	defer os.RemoveAll(keyDir)
+	myorgKey, myorgPub := writeKeyPair(t, keyDir, "myorg")
+	otherKey, otherPub := writeKeyPair(t, keyDir, "other")
+	store := NewStore(&config.TrustConf{
+		SigningKeys: map[string]string{"myorg": myorgKey, "other": otherKey},
+		TrustedKeys: map[string][]string{"myorg": {myorgPub}, "other": {otherPub}},
+	})
+	cellImage := &image.CellImage{Organization: "myorg", ImageName: "hello", ImageVersion: "1.0.0"}
+	imageDigest := digest.FromString("hello")
+	sign := func(org, imageName string, imageDigest digest.Digest) []byte {
+		signer, err := store.SigningKey(org)
+		if err != nil {
+			t.Fatalf("error in SigningKey, %v", err)
+		}
+		signature, err := Sign(signer, imageName, imageDigest)
+		if err != nil {
+			t.Fatalf("error in Sign, %v", err)
+		}
+		return signature
+	}
+
+	tests := []struct {
+		name        string
+		store       *Store
+		signature   []byte
+		expectedErr string
+	}{
+		{
+			name:      "valid signature",
+			store:     store,
+			signature: sign("myorg", "myorg/hello:1.0.0", imageDigest),
+		},
+		{
+			name:        "tampered image",
+			store:       store,
+			signature:   sign("myorg", "myorg/hello:1.0.0", digest.FromString("tampered")),
+			expectedErr: "image myorg/hello:1.0.0 has been modified",
+		},
+		{
+			name:        "signature of another image",
+			store:       store,
+			signature:   sign("myorg", "myorg/foo:1.0.0", imageDigest),
+			expectedErr: "signature is for image myorg/foo:1.0.0, not myorg/hello:1.0.0",
+		},
+		{
+			name:        "key of another organization",
+			store:       store,
+			signature:   sign("other", "myorg/hello:1.0.0", imageDigest),
+			expectedErr: "image myorg/hello:1.0.0 is signed with an untrusted key",
+		},
+		{
+			name: "key trusted for all organizations",
+			store: NewStore(&config.TrustConf{
+				TrustedKeys: map[string][]string{"*": {otherPub}},
+			}),
+			signature: sign("other", "myorg/hello:1.0.0", imageDigest),
+		},
+		{
+			name:        "no trusted keys",
+			store:       NewStore(nil),
+			signature:   sign("myorg", "myorg/hello:1.0.0", imageDigest),
+			expectedErr: "no trusted keys configured for organization myorg",
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := tst.store.Verify(cellImage, imageDigest, tst.signature)
+			if tst.expectedErr == "" {
+				if err != nil {
+					t.Errorf("error in Verify, %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Verify: expected error %q", tst.expectedErr)
+			}
+			if diff := cmp.Diff(tst.expectedErr, err.Error()[:len(tst.expectedErr)]); diff != "" {
+				t.Errorf("Verify: unexpected error (-want, +got)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestVerifyImageUnsigned(t *testing.T) {
+	cellImage := &image.CellImage{Organization: "myorg", ImageName: "hello", ImageVersion: "1.0.0"}
+	err := NewStore(nil).VerifyImage(cellImage, "hello.zip", nil)
+	if diff := cmp.Diff("image myorg/hello:1.0.0 is not signed", err.Error()); diff != "" {
+		t.Errorf("VerifyImage: unexpected error (-want, +got)\n%v", diff)
+	}
+}
+
+func TestSigningKeyNotConfigured(t *testing.T) {
+	signer, err := NewStore(nil).SigningKey("myorg")
+	if err != nil || signer != nil {
+		t.Errorf("SigningKey: expected no signing key, got %v, %v", signer, err)
+	}
+}
+
+func TestIsVerificationRequired(t *testing.T) {
+	tests := []struct {
+		name          string
+		conf          *config.TrustConf
+		expected      bool
+		expectedError bool
+	}{
+		{
+			name:          "no trust configuration",
+			conf:          nil,
+			expectedError: true,
+		},
+		{
+			name:          "keys trusted for another organization",
+			conf:          &config.TrustConf{TrustedKeys: map[string][]string{"other": {"other.pub"}}},
+			expectedError: true,
+		},
+		{
+			name: "untrusted organizations allowed",
+			conf: &config.TrustConf{
+				TrustedKeys:                 map[string][]string{"other": {"other.pub"}},
+				AllowUntrustedOrganizations: true,
+			},
+			expected: false,
+		},
+		{
+			name:     "keys trusted for the organization",
+			conf:     &config.TrustConf{TrustedKeys: map[string][]string{"myorg": {"myorg.pub"}}},
+			expected: true,
+		},
+		{
+			name:     "keys trusted for all organizations",
+			conf:     &config.TrustConf{TrustedKeys: map[string][]string{"*": {"all.pub"}}},
+			expected: true,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			got, err := NewStore(tst.conf).IsVerificationRequired("myorg")
+			if tst.expectedError {
+				if err == nil || !strings.Contains(err.Error(), "organization myorg") {
+					t.Errorf("IsVerificationRequired: expected an error naming the organization, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error in IsVerificationRequired, %v", err)
+			}
+			if got != tst.expected {
+				t.Errorf("IsVerificationRequired: expected %v, got %v", tst.expected, got)
+			}
+		})
+	}
+}
+
+func TestTrustedKeysDoNotModifyConfig(t *testing.T) {
+	keyDir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatalf("failed to create key dir, %v", err)
+	}
+	defer os.RemoveAll(keyDir)
+	_, myorgPub := writeKeyPair(t, keyDir, "myorg")
+	_, allPub := writeKeyPair(t, keyDir, "all")
+	// Leaving spare capacity in the keys of the organization which append would write into
+	myorgKeys := make([]string, 1, 2)
+	myorgKeys[0] = myorgPub
+	store := NewStore(&config.TrustConf{
+		TrustedKeys: map[string][]string{"myorg": myorgKeys, "*": {allPub}},
+	})
+	if _, err := store.trustedKeys("myorg"); err != nil {
+		t.Fatalf("error in trustedKeys, %v", err)
+	}
+	if spare := myorgKeys[:2][1]; spare != "" {
+		t.Errorf("trustedKeys: keys of the organization modified with %s", spare)
+	}
+}
@@ -55,6 +55,14 @@ func (s *Spinner) SetNewAction(action string) {
 	s.mux.Unlock()
 }
 
+// SetProgress sets the progress shown next to the current action of a spinner
+func (s *Spinner) SetProgress(progress string) {
+	s.mux.Lock()
+	s.progress = progress
+	s.spin()
+	s.mux.Unlock()
+}
+
 // Pause the spinner and clear the line
 func (s *Spinner) Pause() {
 	s.mux.Lock()
@@ -95,9 +103,10 @@ func (s *Spinner) spin() {
 				fmt.Printf("\r\x1b[2K%s %s\n", icon, s.previousAction)
 			}
 			s.previousAction = s.action
+			s.progress = ""
 		}
 		if s.action != "" {
-			fmt.Printf("\r\x1b[2K\033[36m%s\033[m %s", s.core.Next(), s.action)
+			fmt.Printf("\r\x1b[2K\033[36m%s\033[m %s %s", s.core.Next(), s.action, s.progress)
 		}
 	}
 }